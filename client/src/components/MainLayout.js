@@ -103,6 +103,7 @@ const MainLayout = ({ children, title, subtitle, actions }) => {
     localStorage.removeItem("userId");
     localStorage.removeItem("userRole");
     localStorage.removeItem("userName");
+    localStorage.removeItem("userEmail");
     localStorage.removeItem("token");
     navigate("/");
   };
@@ -3,4 +3,19 @@ const API_BASE_URL =
   process.env.REACT_APP_API_URL ||
   "http://localhost:5000/api"
 
+// Send the signed-in user's email with every API request, so the server can record who made a change
+if (typeof window !== "undefined" && window.fetch) {
+  const nativeFetch = window.fetch.bind(window)
+  window.fetch = (input, init = {}) => {
+    const url = typeof input === "string" ? input : input.url
+    const email = localStorage.getItem("userEmail")
+    if (!email || !String(url).startsWith(API_BASE_URL)) {
+      return nativeFetch(input, init)
+    }
+    const headers = new Headers(init.headers || (typeof input === "string" ? undefined : input.headers))
+    headers.set("X-User-Email", email)
+    return nativeFetch(input, { ...init, headers })
+  }
+}
+
 export { API_BASE_URL };
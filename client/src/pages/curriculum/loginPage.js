@@ -29,6 +29,7 @@ function LoginPage() {
         localStorage.setItem('userRole', data.user.role)
         localStorage.setItem('userName', data.user.full_name)
         localStorage.setItem('userId', data.user.id)
+        localStorage.setItem('userEmail', data.user.email)
         
         setUsername('')
         setPassword('')
@@ -1,11 +1,16 @@
 import React, { useState, useEffect } from 'react'
-import { useParams, useNavigate } from 'react-router-dom'
+import { useParams, useNavigate, useSearchParams } from 'react-router-dom'
 import MainLayout from '../../components/MainLayout'
 import { API_BASE_URL } from '../../config'
 
 function MappingPage() {
   const { courseId } = useParams()
   const navigate = useNavigate()
+  // Viewed through a curriculum pinned to a published version, the page shows that version
+  const [searchParams] = useSearchParams()
+  const curriculumId = searchParams.get('curriculum_id')
+  const curriculumQuery = curriculumId ? `?curriculum_id=${curriculumId}` : ''
+  const [pinnedVersion, setPinnedVersion] = useState(0)
   
   const [cos, setCos] = useState([])
   const [coPoMatrix, setCoPoMatrix] = useState({})
@@ -20,12 +25,12 @@ function MappingPage() {
   useEffect(() => {
     fetchMapping()
     // eslint-disable-next-line react-hooks/exhaustive-deps
-  }, [courseId])
+  }, [courseId, curriculumId])
 
   const fetchMapping = async () => {
     try {
       setLoading(true)
-      const response = await fetch(`${API_BASE_URL}/course/${courseId}/mapping`)
+      const response = await fetch(`${API_BASE_URL}/course/${courseId}/mapping${curriculumQuery}`)
       if (!response.ok) {
         throw new Error('Failed to fetch mapping data')
       }
@@ -33,6 +38,7 @@ function MappingPage() {
       setCos(data.cos || [])
       setCoPoMatrix(data.co_po_matrix || {})
       setCoPsoMatrix(data.co_pso_matrix || {})
+      setPinnedVersion(data.pinned_version || 0)
       setError('')
     } catch (err) {
       console.error('Error fetching mapping:', err)
@@ -164,7 +170,7 @@ function MappingPage() {
           </svg>
           <h3 className="text-xl font-semibold text-gray-900 mb-2">No Course Outcomes Found</h3>
           <p className="text-gray-600 mb-6">Please add course outcomes in the syllabus page before creating mappings.</p>
-          <button onClick={() => navigate(`/course/${courseId}/syllabus`)} className="btn-primary-custom">Go to Syllabus</button>
+          <button onClick={() => navigate(`/course/${courseId}/syllabus${curriculumQuery}`)} className="btn-primary-custom">Go to Syllabus</button>
         </div>
       </MainLayout>
     )
@@ -182,18 +188,23 @@ function MappingPage() {
             </svg>
             <span>Back</span>
           </button>
-          <button onClick={handleSave} className="btn-primary-custom flex items-center space-x-2">
+          {!pinnedVersion && <button onClick={handleSave} className="btn-primary-custom flex items-center space-x-2">
             <svg className="w-5 h-5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
               <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M8 7H5a2 2 0 00-2 2v9a2 2 0 002 2h14a2 2 0 002-2V9a2 2 0 00-2-2h-3m-1 4l-3 3m0 0l-3-3m3 3V4" />
             </svg>
             <span>Save Mapping</span>
-          </button>
+          </button>}
         </div>
       }
     >
       <div className="max-w-7xl mx-auto space-y-6">
 
         {/* Messages */}
+        {pinnedVersion > 0 && (
+          <div className="p-4 bg-amber-50 border border-amber-200 rounded-lg text-amber-800">
+            This curriculum is pinned to version {pinnedVersion} of the course. The mapping is read-only here; upgrade the pin to pick up newer changes.
+          </div>
+        )}
         {error && (
           <div className="flex items-start space-x-3 p-4 bg-red-50 border border-red-200 rounded-lg">
             <svg className="w-5 h-5 text-red-600 flex-shrink-0 mt-0.5" fill="currentColor" viewBox="0 0 20 20">
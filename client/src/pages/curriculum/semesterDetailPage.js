@@ -193,14 +193,28 @@ function SemesterDetailPage() {
         courseData.activity_total_hrs = activityHrs * 15
       }
       
-      const response = await fetch(`${API_BASE_URL}/curriculum/${id}/semester/${semId}/course`, {
+      const postCourse = (data) => fetch(`${API_BASE_URL}/curriculum/${id}/semester/${semId}/course`, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
         },
-        body: JSON.stringify(courseData),
+        body: JSON.stringify(data),
       })
 
+      let response = await postCourse(courseData)
+
+      // Course code already exists in the catalogue - ask whether to share it or fork a copy
+      if (response.status === 409) {
+        const conflict = await response.json()
+        if (!conflict.link_modes) {
+          throw new Error(conflict.error || 'Failed to add course')
+        }
+        const share = window.confirm(
+          `${conflict.error}\n\nOK: link the shared course (edits apply to every curriculum using it)\nCancel: fork a copy for this curriculum`
+        )
+        response = await postCourse({ ...courseData, link_mode: share ? 'link' : 'fork' })
+      }
+
       if (!response.ok) {
         const errorData = await response.json()
         throw new Error(errorData.error || 'Failed to add course')
@@ -980,13 +994,13 @@ function SemesterDetailPage() {
                             Edit
                           </button>
                           <button
-                            onClick={() => navigate(`/course/${course.id}/syllabus`)}
+                            onClick={() => navigate(`/course/${course.id}/syllabus?curriculum_id=${id}`)}
                             className="px-3 py-1.5 bg-blue-600 hover:bg-blue-700 text-white text-xs rounded-lg transition-all"
                           >
                             Syllabus
                           </button>
                           <button
-                            onClick={() => navigate(`/course/${course.id}/mapping`)}
+                            onClick={() => navigate(`/course/${course.id}/mapping?curriculum_id=${id}`)}
                             className="px-3 py-1.5 bg-purple-600 hover:bg-purple-700 text-white text-xs rounded-lg transition-all"
                           >
                             Mapping
@@ -1,11 +1,16 @@
 import React, { useState, useEffect } from 'react'
-import { useParams, useNavigate } from 'react-router-dom'
+import { useParams, useNavigate, useSearchParams } from 'react-router-dom'
 import MainLayout from '../../components/MainLayout'
 import { API_BASE_URL } from '../../config'
 
 function SyllabusPage() {
   const { courseId } = useParams()
   const navigate = useNavigate()
+  // Viewed through a curriculum pinned to a published version, the page shows that version
+  const [searchParams] = useSearchParams()
+  const curriculumId = searchParams.get('curriculum_id')
+  const curriculumQuery = curriculumId ? `?curriculum_id=${curriculumId}` : ''
+  const [pinnedVersion, setPinnedVersion] = useState(0)
   
   // Course info
   const [courseInfo, setCourseInfo] = useState(null)
@@ -40,7 +45,7 @@ function SyllabusPage() {
     fetchCourseInfo()
     fetchSyllabus()
     // eslint-disable-next-line react-hooks/exhaustive-deps
-  }, [courseId])
+  }, [courseId, curriculumId])
 
   // Update active tab when curriculum template changes
   useEffect(() => {
@@ -68,7 +73,7 @@ function SyllabusPage() {
   const fetchSyllabus = async () => {
     try {
       setLoading(true)
-      const response = await fetch(`${API_BASE_URL}/course/${courseId}/syllabus`)
+      const response = await fetch(`${API_BASE_URL}/course/${courseId}/syllabus${curriculumQuery}`)
       if (!response.ok) throw new Error('Failed to fetch syllabus')
       
       const data = await response.json()
@@ -84,6 +89,14 @@ function SyllabusPage() {
       
       setModels(data.models || [])
       setExperiments(data.experiments || [])
+      setPinnedVersion(data.pinned_version || 0)
+      if (data.pinned_version) {
+        // The pinned view is read-only, so open everything up front
+        const open = (list) => Object.fromEntries(list.map(item => [item.id, true]))
+        setExpandedModels(open(data.models || []))
+        setExpandedTitles(open((data.models || []).flatMap(m => m.titles || [])))
+        setExpandedExperiments(open(data.experiments || []))
+      }
       
       if (data.curriculum_template) {
         setCurriculumTemplate(data.curriculum_template)
@@ -1245,8 +1258,15 @@ function SyllabusPage() {
           </nav>
         </div>
 
+        {pinnedVersion > 0 && (
+          <div className="p-4 bg-amber-50 border border-amber-200 rounded-lg text-amber-800">
+            This curriculum is pinned to version {pinnedVersion} of the course. The syllabus is read-only here; upgrade the pin to pick up newer changes.
+          </div>
+        )}
+
         {/* Tab Content */}
         <div className="card-custom p-6">
+          <fieldset disabled={pinnedVersion > 0}>
           {activeTab === 'objectives' && curriculumTemplate === '2022' && (
             <form onSubmit={handleSaveHeader}>
               {renderHeaderField('objectives', 'Objectives')}
@@ -1322,6 +1342,7 @@ function SyllabusPage() {
               </button>
             </form>
           )}
+          </fieldset>
         </div>
       </div>
     </MainLayout>
@@ -195,6 +195,8 @@ func CreateCurriculumCoursesTable() error {
 		curriculum_id INT NOT NULL,
 		semester_id INT NOT NULL,
 		course_id INT NOT NULL,
+		count_towards_limit TINYINT(1) DEFAULT 1,
+		status TINYINT(1) DEFAULT 1,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
 		UNIQUE KEY unique_course_semester (curriculum_id, semester_id, course_id)
@@ -206,6 +208,8 @@ func CreateCurriculumCoursesTable() error {
 		log.Fatal("Failed to create curriculum_courses table:", err)
 		return err
 	}
+	_ = ensureColumnExists("curriculum_courses", "count_towards_limit", "TINYINT(1) DEFAULT 1")
+	_ = ensureColumnExists("curriculum_courses", "status", "TINYINT(1) DEFAULT 1")
 
 	fmt.Println("Curriculum courses table created/verified successfully!")
 	return nil
@@ -597,6 +601,22 @@ func columnExists(table, column string) (bool, error) {
 	return rows.Next(), nil
 }
 
+// ensureUniqueIndexExists adds a unique index to an existing table unless an index of that name is present
+func ensureUniqueIndexExists(table, index, columns string) error {
+	q := fmt.Sprintf("SHOW INDEX FROM %s WHERE Key_name = '%s'", table, index)
+	rows, err := DB.Query(q)
+	if err != nil {
+		return err
+	}
+	exists := rows.Next()
+	rows.Close()
+	if exists {
+		return nil
+	}
+	_, err = DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD UNIQUE KEY %s (%s)", table, index, columns))
+	return err
+}
+
 // dropColumnIfExists drops a column only if it exists.
 func dropColumnIfExists(table, column string) error {
 	exists, err := columnExists(table, column)
@@ -954,3 +974,538 @@ func RemoveNameColumnFromNormalCards() error {
 	fmt.Println("Successfully removed name column from normal_cards!")
 	return nil
 }
+
+// CreateAssessmentTables creates tables for CIA components, marks and submission locks
+func CreateAssessmentTables() error {
+	componentsTable := `
+	CREATE TABLE IF NOT EXISTS assessment_components (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		course_id INT NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		component_type VARCHAR(50) NOT NULL DEFAULT 'TEST',
+		max_marks DECIMAL(6,2) NOT NULL,
+		weightage DECIMAL(6,2) NOT NULL,
+		position INT NOT NULL DEFAULT 0,
+		status TINYINT(1) DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+		INDEX idx_course (course_id)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(componentsTable); err != nil {
+		return fmt.Errorf("failed to create assessment_components table: %w", err)
+	}
+
+	marksTable := `
+	CREATE TABLE IF NOT EXISTS assessment_marks (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		component_id INT NOT NULL,
+		student_id INT NOT NULL,
+		academic_year VARCHAR(50) NOT NULL,
+		section VARCHAR(10) NOT NULL DEFAULT 'A',
+		marks_obtained DECIMAL(6,2) NOT NULL,
+		entered_by INT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		FOREIGN KEY (component_id) REFERENCES assessment_components(id) ON DELETE CASCADE,
+		UNIQUE KEY unique_component_student (component_id, student_id, academic_year),
+		INDEX idx_component (component_id),
+		INDEX idx_student (student_id)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(marksTable); err != nil {
+		return fmt.Errorf("failed to create assessment_marks table: %w", err)
+	}
+
+	submissionsTable := `
+	CREATE TABLE IF NOT EXISTS assessment_submissions (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		course_id INT NOT NULL,
+		academic_year VARCHAR(50) NOT NULL,
+		section VARCHAR(10) NOT NULL DEFAULT 'A',
+		submitted_by INT NOT NULL,
+		is_locked TINYINT(1) DEFAULT 1,
+		submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+		UNIQUE KEY unique_course_section (course_id, academic_year, section)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(submissionsTable); err != nil {
+		return fmt.Errorf("failed to create assessment_submissions table: %w", err)
+	}
+
+	return nil
+}
+
+// CreateAttainmentTables creates CO-tagged assessment questions, question marks and attainment targets
+func CreateAttainmentTables() error {
+	questionsTable := `
+	CREATE TABLE IF NOT EXISTS assessment_questions (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		component_id INT NOT NULL,
+		question_no VARCHAR(20) NOT NULL,
+		max_marks DECIMAL(6,2) NOT NULL,
+		co_index INT NOT NULL,
+		outcome_id INT NULL,
+		position INT NOT NULL DEFAULT 0,
+		status TINYINT(1) DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (component_id) REFERENCES assessment_components(id) ON DELETE CASCADE,
+		INDEX idx_component (component_id)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(questionsTable); err != nil {
+		return fmt.Errorf("failed to create assessment_questions table: %w", err)
+	}
+	// outcome_id keeps a question on its outcome when course outcomes are reordered or deleted
+	if err := ensureColumnExists("assessment_questions", "outcome_id", "INT NULL"); err != nil {
+		return fmt.Errorf("failed to add outcome_id to assessment_questions: %w", err)
+	}
+
+	questionMarksTable := `
+	CREATE TABLE IF NOT EXISTS assessment_question_marks (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		question_id INT NOT NULL,
+		student_id INT NOT NULL,
+		academic_year VARCHAR(50) NOT NULL,
+		section VARCHAR(10) NOT NULL DEFAULT 'A',
+		marks_obtained DECIMAL(6,2) NOT NULL,
+		entered_by INT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		FOREIGN KEY (question_id) REFERENCES assessment_questions(id) ON DELETE CASCADE,
+		UNIQUE KEY unique_question_student (question_id, student_id, academic_year),
+		INDEX idx_question (question_id)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(questionMarksTable); err != nil {
+		return fmt.Errorf("failed to create assessment_question_marks table: %w", err)
+	}
+
+	targetsTable := `
+	CREATE TABLE IF NOT EXISTS co_attainment_targets (
+		course_id INT NOT NULL PRIMARY KEY,
+		student_threshold DECIMAL(5,2) NOT NULL DEFAULT 60,
+		level_1 DECIMAL(5,2) NOT NULL DEFAULT 50,
+		level_2 DECIMAL(5,2) NOT NULL DEFAULT 60,
+		level_3 DECIMAL(5,2) NOT NULL DEFAULT 70,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(targetsTable); err != nil {
+		return fmt.Errorf("failed to create co_attainment_targets table: %w", err)
+	}
+
+	return nil
+}
+
+// CreateProgramAttainmentTables creates tables for program-level PO/PSO attainment configuration
+func CreateProgramAttainmentTables() error {
+	configTable := `
+	CREATE TABLE IF NOT EXISTS program_attainment_config (
+		curriculum_id INT NOT NULL PRIMARY KEY,
+		direct_weight DECIMAL(5,2) NOT NULL DEFAULT 80,
+		indirect_weight DECIMAL(5,2) NOT NULL DEFAULT 20,
+		default_target DECIMAL(4,2) NOT NULL DEFAULT 2,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		FOREIGN KEY (curriculum_id) REFERENCES curriculum(id) ON DELETE CASCADE
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(configTable); err != nil {
+		return fmt.Errorf("failed to create program_attainment_config table: %w", err)
+	}
+
+	targetsTable := `
+	CREATE TABLE IF NOT EXISTS program_outcome_targets (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		curriculum_id INT NOT NULL,
+		outcome_type ENUM('PO','PSO') NOT NULL,
+		outcome_index INT NOT NULL,
+		target DECIMAL(4,2) NOT NULL,
+		FOREIGN KEY (curriculum_id) REFERENCES curriculum(id) ON DELETE CASCADE,
+		UNIQUE KEY unique_outcome_target (curriculum_id, outcome_type, outcome_index)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(targetsTable); err != nil {
+		return fmt.Errorf("failed to create program_outcome_targets table: %w", err)
+	}
+
+	indirectTable := `
+	CREATE TABLE IF NOT EXISTS indirect_attainment (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		curriculum_id INT NOT NULL,
+		academic_year VARCHAR(50) NOT NULL,
+		outcome_type ENUM('PO','PSO') NOT NULL,
+		outcome_index INT NOT NULL,
+		value DECIMAL(4,2) NOT NULL,
+		source VARCHAR(50) NOT NULL DEFAULT 'MANUAL',
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		FOREIGN KEY (curriculum_id) REFERENCES curriculum(id) ON DELETE CASCADE,
+		UNIQUE KEY unique_indirect (curriculum_id, academic_year, outcome_type, outcome_index)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(indirectTable); err != nil {
+		return fmt.Errorf("failed to create indirect_attainment table: %w", err)
+	}
+
+	return nil
+}
+
+// CreateSurveyTables creates tables for indirect assessment surveys
+func CreateSurveyTables() error {
+	surveysTable := `
+	CREATE TABLE IF NOT EXISTS surveys (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		curriculum_id INT NOT NULL,
+		course_id INT NULL,
+		title VARCHAR(255) NOT NULL,
+		survey_type ENUM('COURSE_EXIT','GRADUATE_EXIT','ALUMNI','EMPLOYER') NOT NULL,
+		academic_year VARCHAR(50) NOT NULL,
+		is_open TINYINT(1) DEFAULT 1,
+		status TINYINT(1) DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (curriculum_id) REFERENCES curriculum(id) ON DELETE CASCADE,
+		FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+		INDEX idx_survey_year (curriculum_id, academic_year)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(surveysTable); err != nil {
+		return fmt.Errorf("failed to create surveys table: %w", err)
+	}
+
+	questionsTable := `
+	CREATE TABLE IF NOT EXISTS survey_questions (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		survey_id INT NOT NULL,
+		question_text TEXT NOT NULL,
+		outcome_type ENUM('PO','PSO') NOT NULL,
+		outcome_index INT NOT NULL,
+		position INT DEFAULT 0,
+		status TINYINT(1) DEFAULT 1,
+		FOREIGN KEY (survey_id) REFERENCES surveys(id) ON DELETE CASCADE
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(questionsTable); err != nil {
+		return fmt.Errorf("failed to create survey_questions table: %w", err)
+	}
+
+	recipientsTable := `
+	CREATE TABLE IF NOT EXISTS survey_recipients (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		survey_id INT NOT NULL,
+		student_id INT NOT NULL,
+		issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (survey_id) REFERENCES surveys(id) ON DELETE CASCADE,
+		UNIQUE KEY unique_survey_recipient (survey_id, student_id)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(recipientsTable); err != nil {
+		return fmt.Errorf("failed to create survey_recipients table: %w", err)
+	}
+
+	responsesTable := `
+	CREATE TABLE IF NOT EXISTS survey_responses (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		survey_id INT NOT NULL,
+		student_id INT NULL,
+		respondent_name VARCHAR(255) NULL,
+		respondent_key VARCHAR(255) NULL,
+		submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (survey_id) REFERENCES surveys(id) ON DELETE CASCADE,
+		UNIQUE KEY unique_survey_student (survey_id, student_id),
+		UNIQUE KEY unique_survey_respondent (survey_id, respondent_key)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(responsesTable); err != nil {
+		return fmt.Errorf("failed to create survey_responses table: %w", err)
+	}
+	// Alumni and employer responses are unique per respondent email
+	if err := ensureColumnExists("survey_responses", "respondent_key", "VARCHAR(255) NULL"); err != nil {
+		return fmt.Errorf("failed to add respondent_key to survey_responses: %w", err)
+	}
+	if err := ensureUniqueIndexExists("survey_responses", "unique_survey_respondent", "survey_id, respondent_key"); err != nil {
+		return fmt.Errorf("failed to add unique_survey_respondent to survey_responses: %w", err)
+	}
+
+	answersTable := `
+	CREATE TABLE IF NOT EXISTS survey_answers (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		response_id INT NOT NULL,
+		question_id INT NOT NULL,
+		rating TINYINT NOT NULL,
+		FOREIGN KEY (response_id) REFERENCES survey_responses(id) ON DELETE CASCADE,
+		FOREIGN KEY (question_id) REFERENCES survey_questions(id) ON DELETE CASCADE,
+		UNIQUE KEY unique_response_question (response_id, question_id)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(answersTable); err != nil {
+		return fmt.Errorf("failed to create survey_answers table: %w", err)
+	}
+
+	return nil
+}
+
+// AddMappingJustificationColumns adds justification text and the mapped outcome's id to CO-PO/PSO mappings
+// outcome_id lets mappings that no longer line up with course_outcomes be detected after edits
+func AddMappingJustificationColumns() error {
+	for _, table := range []string{"co_po_mapping", "co_pso_mapping"} {
+		if err := ensureColumnExists(table, "justification", "TEXT NULL"); err != nil {
+			return fmt.Errorf("failed to add justification to %s: %w", table, err)
+		}
+		if err := ensureColumnExists(table, "outcome_id", "INT NULL"); err != nil {
+			return fmt.Errorf("failed to add outcome_id to %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// AddBloomLevelColumns adds a Bloom's taxonomy level (K1-K6) to course outcomes and objectives
+// bloom_level_manual marks levels set by hand, which text edits leave alone
+func AddBloomLevelColumns() error {
+	for _, table := range []string{"course_outcomes", "course_objectives"} {
+		if err := ensureColumnExists(table, "bloom_level", "VARCHAR(2) NULL"); err != nil {
+			return fmt.Errorf("failed to add bloom_level to %s: %w", table, err)
+		}
+		if err := ensureColumnExists(table, "bloom_level_manual", "TINYINT(1) DEFAULT 0"); err != nil {
+			return fmt.Errorf("failed to add bloom_level_manual to %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// AddPrerequisiteCourseColumn lets a free-text prerequisite reference a real course
+func AddPrerequisiteCourseColumn() error {
+	if err := ensureColumnExists("course_prerequisites", "prerequisite_course_id", "INT NULL"); err != nil {
+		return fmt.Errorf("failed to add prerequisite_course_id to course_prerequisites: %w", err)
+	}
+	return nil
+}
+
+// AddCourseCatalogueColumns adds catalogue versioning and fork tracking to courses.
+// A course with owner_curriculum_id set is a curriculum-specific copy of forked_from_course_id
+func AddCourseCatalogueColumns() error {
+	columns := []struct{ name, colType string }{
+		{"version", "INT NOT NULL DEFAULT 1"},
+		{"forked_from_course_id", "INT NULL"},
+		{"owner_curriculum_id", "INT NULL"},
+	}
+	for _, c := range columns {
+		if err := ensureColumnExists("courses", c.name, c.colType); err != nil {
+			return fmt.Errorf("failed to add %s to courses: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// CreateCourseVersionTables creates published course snapshots and the version each curriculum is pinned to
+func CreateCourseVersionTables() error {
+	versionsTable := `
+	CREATE TABLE IF NOT EXISTS course_versions (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		course_id INT NOT NULL,
+		version INT NOT NULL,
+		snapshot LONGTEXT NOT NULL,
+		note VARCHAR(500) NULL,
+		created_by VARCHAR(100) DEFAULT 'System',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+		UNIQUE KEY unique_course_version (course_id, version)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(versionsTable); err != nil {
+		return fmt.Errorf("failed to create course_versions table: %w", err)
+	}
+
+	pinsTable := `
+	CREATE TABLE IF NOT EXISTS curriculum_course_versions (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		curriculum_id INT NOT NULL,
+		course_id INT NOT NULL,
+		version INT NOT NULL,
+		pinned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		FOREIGN KEY (curriculum_id) REFERENCES curriculum(id) ON DELETE CASCADE,
+		FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+		UNIQUE KEY unique_curriculum_course (curriculum_id, course_id)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(pinsTable); err != nil {
+		return fmt.Errorf("failed to create curriculum_course_versions table: %w", err)
+	}
+
+	return nil
+}
+
+// CreateCurriculumSnapshotTables creates named point-in-time snapshots of whole curricula
+func CreateCurriculumSnapshotTables() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS curriculum_snapshots (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		curriculum_id INT NOT NULL,
+		version INT NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		snapshot LONGTEXT NOT NULL,
+		created_by VARCHAR(100) DEFAULT 'System',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (curriculum_id) REFERENCES curriculum(id) ON DELETE CASCADE,
+		UNIQUE KEY unique_curriculum_snapshot_version (curriculum_id, version)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(query); err != nil {
+		return fmt.Errorf("failed to create curriculum_snapshots table: %w", err)
+	}
+	return nil
+}
+
+// CreateRecycleBinTable creates the log of soft-deleted items and the rows each delete cascaded to
+func CreateRecycleBinTable() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS recycle_bin (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		curriculum_id INT NULL,
+		course_id INT NULL,
+		item_type VARCHAR(30) NOT NULL,
+		item_id INT NOT NULL,
+		label VARCHAR(255) NOT NULL DEFAULT '',
+		deleted_rows LONGTEXT NOT NULL,
+		deleted_by VARCHAR(100) DEFAULT 'System',
+		deleted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		restored_at TIMESTAMP NULL,
+		restored_by VARCHAR(100) NULL,
+		INDEX idx_recycle_bin_curriculum (curriculum_id),
+		INDEX idx_recycle_bin_course (course_id),
+		INDEX idx_recycle_bin_deleted_at (deleted_at)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(query); err != nil {
+		return fmt.Errorf("failed to create recycle_bin table: %w", err)
+	}
+	return nil
+}
+
+// AddCurriculumLogChangeColumns adds the changed entity to curriculum_logs and
+// creates curriculum_log_changes, which holds one row per changed field so changes can be queried
+func AddCurriculumLogChangeColumns() error {
+	columns := []struct{ name, colType string }{
+		{"entity_type", "VARCHAR(50) NULL"},
+		{"entity_id", "INT NULL"},
+		{"operation", "VARCHAR(20) NULL"},
+	}
+	for _, c := range columns {
+		if err := ensureColumnExists("curriculum_logs", c.name, c.colType); err != nil {
+			return fmt.Errorf("failed to add %s to curriculum_logs: %w", c.name, err)
+		}
+	}
+
+	changesTable := `
+	CREATE TABLE IF NOT EXISTS curriculum_log_changes (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		log_id INT NOT NULL,
+		field VARCHAR(255) NOT NULL,
+		old_value JSON NULL,
+		new_value JSON NULL,
+		FOREIGN KEY (log_id) REFERENCES curriculum_logs(id) ON DELETE CASCADE,
+		INDEX idx_log_changes_field (field)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(changesTable); err != nil {
+		return fmt.Errorf("failed to create curriculum_log_changes table: %w", err)
+	}
+	return nil
+}
+
+// CreatePDFTemplateTables adds a department and publish state to curricula and creates the
+// per-institution/department branding and the registry of uploaded PDF templates.
+// department_id 0 in both tables is the institution-wide default
+func CreatePDFTemplateTables() error {
+	if err := ensureColumnExists("curriculum", "department_id", "INT NULL"); err != nil {
+		return fmt.Errorf("failed to add department_id to curriculum: %w", err)
+	}
+	// Curricula that existed before publishing was tracked are treated as published
+	hadPublishStatus, err := columnExists("curriculum", "publish_status")
+	if err != nil {
+		return fmt.Errorf("failed to check curriculum publish_status: %w", err)
+	}
+	if !hadPublishStatus {
+		if err := ensureColumnExists("curriculum", "publish_status", "ENUM('DRAFT', 'PUBLISHED') NOT NULL DEFAULT 'DRAFT'"); err != nil {
+			return fmt.Errorf("failed to add publish_status to curriculum: %w", err)
+		}
+		if _, err := DB.Exec("UPDATE curriculum SET publish_status = 'PUBLISHED'"); err != nil {
+			return fmt.Errorf("failed to publish existing curricula: %w", err)
+		}
+	}
+
+	brandingTable := `
+	CREATE TABLE IF NOT EXISTS pdf_brandings (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		department_id INT NOT NULL DEFAULT 0,
+		institution_name VARCHAR(255) NOT NULL,
+		info_lines TEXT NULL,
+		address_lines TEXT NULL,
+		contact_lines TEXT NULL,
+		logo_path VARCHAR(500) NOT NULL DEFAULT '',
+		header_text VARCHAR(255) NOT NULL DEFAULT '',
+		footer_text VARCHAR(255) NOT NULL DEFAULT '',
+		paper_size VARCHAR(20) NOT NULL DEFAULT 'A4',
+		landscape BOOLEAN NOT NULL DEFAULT FALSE,
+		margin_top DECIMAL(5,1) NOT NULL DEFAULT 10,
+		margin_bottom DECIMAL(5,1) NOT NULL DEFAULT 10,
+		margin_left DECIMAL(5,1) NOT NULL DEFAULT 15,
+		margin_right DECIMAL(5,1) NOT NULL DEFAULT 15,
+		page_numbers BOOLEAN NOT NULL DEFAULT TRUE,
+		updated_by VARCHAR(100) DEFAULT 'System',
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		UNIQUE KEY unique_pdf_branding_department (department_id)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(brandingTable); err != nil {
+		return fmt.Errorf("failed to create pdf_brandings table: %w", err)
+	}
+
+	templatesTable := `
+	CREATE TABLE IF NOT EXISTS pdf_templates (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		kind ENUM('regulation', 'syllabus') NOT NULL,
+		department_id INT NOT NULL DEFAULT 0,
+		curriculum_template VARCHAR(20) NOT NULL DEFAULT '',
+		html LONGTEXT NOT NULL,
+		status TINYINT NOT NULL DEFAULT 1,
+		created_by VARCHAR(100) DEFAULT 'System',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		INDEX idx_pdf_templates_lookup (kind, department_id, curriculum_template)
+	) ENGINE=InnoDB
+	`
+	if _, err := DB.Exec(templatesTable); err != nil {
+		return fmt.Errorf("failed to create pdf_templates table: %w", err)
+	}
+	return nil
+}
+
+// AddCurriculumRegulationColumn links a curriculum to the regulation document whose clauses its book prints
+func AddCurriculumRegulationColumn() error {
+	if err := ensureColumnExists("curriculum", "regulation_id", "INT NULL"); err != nil {
+		return fmt.Errorf("failed to add regulation_id to curriculum: %w", err)
+	}
+	return nil
+}
+
+// AddRegulationClauseParentColumn lets a clause be a sub-clause of another clause in its section;
+// deleting a clause deletes its sub-clauses
+func AddRegulationClauseParentColumn() error {
+	exists, err := columnExists("regulation_clauses", "parent_clause_id")
+	if err != nil {
+		return fmt.Errorf("failed to check regulation_clauses parent_clause_id: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	_, err = DB.Exec(`
+		ALTER TABLE regulation_clauses
+		ADD COLUMN parent_clause_id INT NULL,
+		ADD CONSTRAINT fk_regulation_clause_parent FOREIGN KEY (parent_clause_id) REFERENCES regulation_clauses(id) ON DELETE CASCADE`)
+	if err != nil {
+		return fmt.Errorf("failed to add parent_clause_id to regulation_clauses: %w", err)
+	}
+	return nil
+}
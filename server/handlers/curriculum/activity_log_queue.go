@@ -0,0 +1,212 @@
+package curriculum
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"server/db"
+)
+
+const (
+	activityLogQueueSize   = 1024
+	activityLogMaxAttempts = 5
+	activityLogRetryDelay  = 200 * time.Millisecond
+)
+
+// activityLogEntry is one curriculum log write waiting in the activity log queue
+type activityLogEntry struct {
+	CurriculumID int             `json:"curriculum_id"`
+	EntityType   string          `json:"entity_type,omitempty"`
+	EntityID     int             `json:"entity_id,omitempty"`
+	Operation    string          `json:"operation,omitempty"`
+	Action       string          `json:"action"`
+	Description  string          `json:"description"`
+	ChangedBy    string          `json:"changed_by"`
+	Diff         json.RawMessage `json:"diff,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// activityLogger writes queued entries one at a time, in the order they were logged
+var activityLogger struct {
+	mu      sync.RWMutex
+	queue   chan activityLogEntry
+	done    chan struct{}
+	stopped bool
+	spoolMu sync.Mutex
+}
+
+// activityLogSpoolPath is the file holding entries that could not be written; they are replayed on the next start
+func activityLogSpoolPath() string {
+	if path := os.Getenv("ACTIVITY_LOG_SPOOL"); path != "" {
+		return path
+	}
+	return "activity_log_spool.jsonl"
+}
+
+// StartActivityLogger starts the activity log writer and replays entries spooled by an earlier run
+func StartActivityLogger() {
+	activityLogger.mu.Lock()
+	if activityLogger.queue != nil {
+		activityLogger.mu.Unlock()
+		return
+	}
+	activityLogger.queue = make(chan activityLogEntry, activityLogQueueSize)
+	activityLogger.done = make(chan struct{})
+	go runActivityLogger(activityLogger.queue, activityLogger.done)
+	activityLogger.mu.Unlock()
+
+	spooled, err := takeSpooledActivityLogs()
+	if err != nil {
+		log.Printf("Warning: Failed to read activity log spool: %v", err)
+	}
+	if len(spooled) > 0 {
+		log.Printf("Replaying %d spooled activity log entries", len(spooled))
+	}
+	for _, entry := range spooled {
+		enqueueActivityLog(entry)
+	}
+}
+
+// StopActivityLogger stops accepting queued entries and waits for the pending ones to be written.
+// Entries still pending when ctx ends are spooled to disk for the next start
+func StopActivityLogger(ctx context.Context) error {
+	activityLogger.mu.Lock()
+	if activityLogger.queue == nil || activityLogger.stopped {
+		activityLogger.mu.Unlock()
+		return nil
+	}
+	activityLogger.stopped = true
+	queue, done := activityLogger.queue, activityLogger.done
+	close(queue)
+	activityLogger.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		spooled := 0
+		for entry := range queue {
+			spoolActivityLog(entry)
+			spooled++
+		}
+		log.Printf("Warning: Activity log flush timed out; spooled %d entries", spooled)
+		return ctx.Err()
+	}
+}
+
+// enqueueActivityLog hands an entry to the writer without waiting; when the queue is full
+// the entry is spooled to disk and replayed on the next start.
+// Without a running writer (before start or after stop) the entry is written right away
+func enqueueActivityLog(entry activityLogEntry) {
+	activityLogger.mu.RLock()
+	defer activityLogger.mu.RUnlock()
+	if activityLogger.queue == nil || activityLogger.stopped {
+		if err := writeActivityLog(entry); err != nil {
+			spoolActivityLog(entry)
+		}
+		return
+	}
+	select {
+	case activityLogger.queue <- entry:
+	default:
+		spoolActivityLog(entry)
+	}
+}
+
+// runActivityLogger writes entries until the queue is closed
+func runActivityLogger(queue <-chan activityLogEntry, done chan<- struct{}) {
+	defer close(done)
+	for entry := range queue {
+		if err := writeActivityLog(entry); err != nil {
+			spoolActivityLog(entry)
+		}
+	}
+}
+
+// writeActivityLog writes an entry in its own transaction, retrying with backoff
+func writeActivityLog(entry activityLogEntry) error {
+	delay := activityLogRetryDelay
+	var err error
+	for attempt := 1; attempt <= activityLogMaxAttempts; attempt++ {
+		if err = writeActivityLogOnce(entry); err == nil {
+			return nil
+		}
+		if attempt < activityLogMaxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	log.Printf("Warning: Failed to log activity for curriculum %d after %d attempts: %v",
+		entry.CurriculumID, activityLogMaxAttempts, err)
+	return err
+}
+
+func writeActivityLogOnce(entry activityLogEntry) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := insertActivityLog(tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// spoolActivityLog appends an entry that could not be written to the spool file
+func spoolActivityLog(entry activityLogEntry) {
+	activityLogger.spoolMu.Lock()
+	defer activityLogger.spoolMu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Warning: Failed to encode activity log entry: %v", err)
+		return
+	}
+	file, err := os.OpenFile(activityLogSpoolPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("Warning: Failed to open activity log spool, entry lost: %v", err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		log.Printf("Warning: Failed to spool activity log entry: %v", err)
+	}
+}
+
+// takeSpooledActivityLogs reads and removes the spool file
+func takeSpooledActivityLogs() ([]activityLogEntry, error) {
+	activityLogger.spoolMu.Lock()
+	defer activityLogger.spoolMu.Unlock()
+
+	path := activityLogSpoolPath()
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []activityLogEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry activityLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Printf("Warning: Skipping unreadable spooled activity log entry: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, os.Remove(path)
+}
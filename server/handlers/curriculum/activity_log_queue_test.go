@@ -0,0 +1,35 @@
+package curriculum
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEnqueueActivityLogSpoolsWhenFull(t *testing.T) {
+	t.Setenv("ACTIVITY_LOG_SPOOL", filepath.Join(t.TempDir(), "spool.jsonl"))
+
+	// A queue with no writer draining it, filled to capacity
+	activityLogger.mu.Lock()
+	activityLogger.queue = make(chan activityLogEntry, 1)
+	activityLogger.stopped = false
+	activityLogger.mu.Unlock()
+	defer func() {
+		activityLogger.mu.Lock()
+		activityLogger.queue = nil
+		activityLogger.mu.Unlock()
+	}()
+
+	enqueueActivityLog(activityLogEntry{CurriculumID: 1, Action: "queued"})
+	enqueueActivityLog(activityLogEntry{CurriculumID: 2, Action: "overflow"})
+
+	if got := len(activityLogger.queue); got != 1 {
+		t.Errorf("queue holds %d entries, want 1", got)
+	}
+	spooled, err := takeSpooledActivityLogs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spooled) != 1 || spooled[0].CurriculumID != 2 {
+		t.Errorf("spooled = %+v, want only the overflow entry", spooled)
+	}
+}
@@ -28,7 +28,7 @@ func GetCourseAllocations(w http.ResponseWriter, r *http.Request) {
 		SELECT c.course_id, c.course_code, c.course_name, c.course_type, c.credit
 		FROM courses c
 		JOIN curriculum_courses cc ON c.course_id = cc.course_id
-		WHERE cc.semester_id = ? AND c.status = 1
+		WHERE cc.semester_id = ? AND cc.status = 1 AND c.status = 1
 	`
 	rows, err := db.DB.Query(courseQuery, semesterID)
 	if err != nil {
@@ -328,7 +328,7 @@ func GetUnassignedCourses(w http.ResponseWriter, r *http.Request) {
 		SELECT c.course_id, c.course_code, c.course_name, c.course_type, c.credit
 		FROM courses c
 		JOIN curriculum_courses cc ON c.course_id = cc.course_id
-		WHERE cc.semester_id = ? AND c.status = 1
+		WHERE cc.semester_id = ? AND cc.status = 1 AND c.status = 1
 		AND NOT EXISTS (
 			SELECT 1 FROM teacher_course_allocation ca
 			WHERE ca.course_id = c.course_id 
@@ -394,7 +394,7 @@ func GetAllocationSummary(w http.ResponseWriter, r *http.Request) {
 		SELECT COUNT(DISTINCT c.course_id)
 		FROM courses c
 		JOIN curriculum_courses cc ON c.course_id = cc.course_id
-		WHERE cc.semester_id = ? AND c.status = 1
+		WHERE cc.semester_id = ? AND cc.status = 1 AND c.status = 1
 	`, semesterID).Scan(&summary.TotalCourses)
 	if err != nil {
 		log.Printf("Error counting total courses: %v", err)
@@ -405,7 +405,7 @@ func GetAllocationSummary(w http.ResponseWriter, r *http.Request) {
 		SELECT COUNT(DISTINCT ca.course_id)
 		FROM teacher_course_allocation ca
 		JOIN curriculum_courses cc ON ca.course_id = cc.course_id
-		WHERE cc.semester_id = ? AND ca.academic_year = ? 
+		WHERE cc.semester_id = ? AND cc.status = 1 AND ca.academic_year = ? 
 		AND ca.status = 1 AND ca.role = 'Primary'
 	`, semesterID, academicYear).Scan(&summary.AssignedCourses)
 	if err != nil {
@@ -425,7 +425,7 @@ func GetAllocationSummary(w http.ResponseWriter, r *http.Request) {
 		SELECT COUNT(DISTINCT ca.teacher_id)
 		FROM teacher_course_allocation ca
 		JOIN curriculum_courses cc ON ca.course_id = cc.course_id
-		WHERE cc.semester_id = ? AND ca.academic_year = ? AND ca.status = 1
+		WHERE cc.semester_id = ? AND cc.status = 1 AND ca.academic_year = ? AND ca.status = 1
 	`, semesterID, academicYear).Scan(&summary.ActiveTeachers)
 	if err != nil {
 		log.Printf("Error counting active teachers: %v", err)
@@ -0,0 +1,154 @@
+package curriculum
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultWeakCoverageThreshold flags a PO/PSO whose average articulation across courses falls below it
+const defaultWeakCoverageThreshold = 1.5
+
+// articulationRow averages the non-zero CO mappings of a course for each of count outcomes
+func articulationRow(matrix map[int]map[int]int, count int) []*float64 {
+	sums := make([]int, count+1)
+	counts := make([]int, count+1)
+	for _, row := range matrix {
+		for index, value := range row {
+			if index < 1 || index > count || value <= 0 {
+				continue
+			}
+			sums[index] += value
+			counts[index]++
+		}
+	}
+
+	values := make([]*float64, count)
+	for index := 1; index <= count; index++ {
+		if counts[index] > 0 {
+			avg := roundTo2(float64(sums[index]) / float64(counts[index]))
+			values[index-1] = &avg
+		}
+	}
+	return values
+}
+
+// articulationCoverage averages each outcome column over the courses that address it
+func articulationCoverage(outcomeType string, rows [][]*float64, count int, threshold float64) []models.OutcomeCoverage {
+	coverage := make([]models.OutcomeCoverage, count)
+	for i := 0; i < count; i++ {
+		var sum float64
+		mapped := 0
+		for _, row := range rows {
+			if row[i] != nil {
+				sum += *row[i]
+				mapped++
+			}
+		}
+
+		c := models.OutcomeCoverage{OutcomeType: outcomeType, Index: i + 1, CoursesMapped: mapped}
+		if mapped > 0 {
+			avg := roundTo2(sum / float64(mapped))
+			c.Average = &avg
+		}
+		c.Weak = c.Average == nil || *c.Average < threshold
+		coverage[i] = c
+	}
+	return coverage
+}
+
+// computeArticulationMatrix builds the course x PO/PSO articulation matrix of a curriculum
+func computeArticulationMatrix(curriculumID int, threshold float64) (*models.ArticulationMatrix, error) {
+	result := &models.ArticulationMatrix{
+		CurriculumID:  curriculumID,
+		POCount:       len(fetchDepartmentList(curriculumID, "curriculum_pos", "po_text")),
+		PSOCount:      len(fetchDepartmentList(curriculumID, "curriculum_psos", "pso_text")),
+		WeakThreshold: threshold,
+		Courses:       []models.ArticulationRow{},
+		WeakOutcomes:  []string{},
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT c.course_id, c.course_code, c.course_name, COALESCE(MIN(nc.semester_number), 0)
+		FROM curriculum_courses cc
+		INNER JOIN courses c ON c.course_id = cc.course_id
+		LEFT JOIN normal_cards nc ON nc.id = cc.semester_id
+		WHERE cc.curriculum_id = ? AND cc.status = 1 AND c.status = 1
+		GROUP BY c.course_id, c.course_code, c.course_name
+		ORDER BY COALESCE(MIN(nc.semester_number), 0), c.course_code`, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var row models.ArticulationRow
+		if err := rows.Scan(&row.CourseID, &row.CourseCode, &row.CourseName, &row.Semester); err == nil {
+			result.Courses = append(result.Courses, row)
+		}
+	}
+	rows.Close()
+
+	var poRows, psoRows [][]*float64
+	for i := range result.Courses {
+		course := &result.Courses[i]
+		poMatrix, err := fetchCOMappingMatrix(course.CourseID, "co_po_mapping", "po_index")
+		if err != nil {
+			return nil, err
+		}
+		psoMatrix, err := fetchCOMappingMatrix(course.CourseID, "co_pso_mapping", "pso_index")
+		if err != nil {
+			return nil, err
+		}
+		course.POValues = articulationRow(poMatrix, result.POCount)
+		course.PSOValues = articulationRow(psoMatrix, result.PSOCount)
+		poRows = append(poRows, course.POValues)
+		psoRows = append(psoRows, course.PSOValues)
+	}
+
+	result.POCoverage = articulationCoverage("PO", poRows, result.POCount, threshold)
+	result.PSOCoverage = articulationCoverage("PSO", psoRows, result.PSOCount, threshold)
+	for _, coverage := range [][]models.OutcomeCoverage{result.POCoverage, result.PSOCoverage} {
+		for _, c := range coverage {
+			if c.Weak {
+				result.WeakOutcomes = append(result.WeakOutcomes, fmt.Sprintf("%s%d", c.OutcomeType, c.Index))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// GetArticulationMatrix handles GET /curriculum/:id/articulation-matrix?weak_threshold=
+func GetArticulationMatrix(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	threshold := defaultWeakCoverageThreshold
+	if v := r.URL.Query().Get("weak_threshold"); v != "" {
+		threshold, err = strconv.ParseFloat(v, 64)
+		if err != nil || threshold < 0 || threshold > maxMappingValue {
+			http.Error(w, "weak_threshold must be a number between 0 and 3", http.StatusBadRequest)
+			return
+		}
+	}
+
+	matrix, err := computeArticulationMatrix(curriculumID, threshold)
+	if err != nil {
+		log.Println("Error computing articulation matrix:", err)
+		http.Error(w, "Failed to compute articulation matrix", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(matrix)
+}
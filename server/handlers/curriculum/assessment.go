@@ -0,0 +1,921 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// weightageTolerance absorbs DECIMAL rounding when comparing component weights to cia_marks
+const weightageTolerance = 0.01
+
+var validComponentTypes = map[string]bool{
+	"TEST":       true,
+	"ASSIGNMENT": true,
+	"LAB_RECORD": true,
+	"OTHER":      true,
+}
+
+// fetchAssessmentComponents returns the active CIA components of a course in display order
+func fetchAssessmentComponents(courseID int) ([]models.AssessmentComponent, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, course_id, name, component_type, max_marks, weightage, position
+		FROM assessment_components
+		WHERE course_id = ? AND status = 1
+		ORDER BY position, id`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := []models.AssessmentComponent{}
+	for rows.Next() {
+		var c models.AssessmentComponent
+		if err := rows.Scan(&c.ID, &c.CourseID, &c.Name, &c.ComponentType, &c.MaxMarks, &c.Weightage, &c.Position); err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, rows.Err()
+}
+
+// isTeacherAllocated checks that a teacher holds an active allocation for the course/section
+func isTeacherAllocated(teacherID, courseID int, academicYear, section string) (bool, error) {
+	var count int
+	err := db.DB.QueryRow(`
+		SELECT COUNT(*)
+		FROM teacher_course_allocation
+		WHERE teacher_id = ? AND course_id = ? AND academic_year = ? AND section = ? AND status = 1
+	`, teacherID, courseID, academicYear, section).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// errNotSignedIn and errNotTeacher are returned when a request has no user or its user is not an active teacher
+var (
+	errNotSignedIn = errors.New("no signed-in user")
+	errNotTeacher  = errors.New("the signed-in user is not a teacher")
+)
+
+// requestTeacherID returns the teachers id of the signed-in user, identified by the same
+// X-User-Email header as requestUser; marks are never entered on behalf of an id from the body
+func requestTeacherID(r *http.Request) (int, error) {
+	email := strings.TrimSpace(r.Header.Get("X-User-Email"))
+	if email == "" {
+		return 0, errNotSignedIn
+	}
+	var teacherID int
+	err := db.DB.QueryRow("SELECT id FROM teachers WHERE email = ? AND (status = 1 OR status IS NULL)", email).Scan(&teacherID)
+	if err == sql.ErrNoRows {
+		return 0, errNotTeacher
+	}
+	return teacherID, err
+}
+
+// writeTeacherError reports a failed requestTeacherID
+func writeTeacherError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errNotSignedIn):
+		http.Error(w, "Sign in to enter marks", http.StatusUnauthorized)
+		return
+	case errors.Is(err, errNotTeacher):
+		http.Error(w, "Only teachers can enter marks", http.StatusForbidden)
+		return
+	}
+	log.Println("Error identifying teacher:", err)
+	http.Error(w, "Failed to verify teacher", http.StatusInternalServerError)
+}
+
+// isMarksLocked reports whether the marks sheet for a course/section has been submitted
+func isMarksLocked(courseID int, academicYear, section string) (bool, error) {
+	var locked bool
+	err := db.DB.QueryRow(`
+		SELECT is_locked FROM assessment_submissions
+		WHERE course_id = ? AND academic_year = ? AND section = ?
+	`, courseID, academicYear, section).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return locked, nil
+}
+
+// markKey identifies a stored mark by its component (or question) and student
+type markKey struct {
+	itemID    int
+	studentID int
+}
+
+// distinctMarkIDs returns the distinct item and student ids of a set of marks
+func distinctMarkIDs(keys []markKey) (items, students []int) {
+	seenItem := make(map[int]bool)
+	seenStudent := make(map[int]bool)
+	for _, k := range keys {
+		if !seenItem[k.itemID] {
+			seenItem[k.itemID] = true
+			items = append(items, k.itemID)
+		}
+		if !seenStudent[k.studentID] {
+			seenStudent[k.studentID] = true
+			students = append(students, k.studentID)
+		}
+	}
+	return items, students
+}
+
+// lockMarkSections locks the stored marks of an academic year that a save is about to write and
+// returns the section each was entered for. Marks are unique per item, student and academic year,
+// so a row entered for another section would otherwise be taken over by the save
+func lockMarkSections(tx *sql.Tx, table, itemColumn string, keys []markKey, academicYear string) (map[markKey]string, error) {
+	sections := make(map[markKey]string)
+	if len(keys) == 0 {
+		return sections, nil
+	}
+	items, students := distinctMarkIDs(keys)
+	itemList, itemArgs := idPlaceholders(items)
+	studentList, studentArgs := idPlaceholders(students)
+	args := append(append([]interface{}{academicYear}, itemArgs...), studentArgs...)
+
+	rows, err := tx.Query(fmt.Sprintf(`
+		SELECT %s, student_id, section FROM %s
+		WHERE academic_year = ? AND %s IN (%s) AND student_id IN (%s)
+		FOR UPDATE`, itemColumn, table, itemColumn, itemList, studentList), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k markKey
+		var section string
+		if err := rows.Scan(&k.itemID, &k.studentID, &section); err != nil {
+			return nil, err
+		}
+		sections[k] = section
+	}
+	return sections, rows.Err()
+}
+
+// foreignSectionMarks returns the marks of a save that are already stored for another section
+func foreignSectionMarks(keys []markKey, stored map[markKey]string, section string) []markKey {
+	foreign := []markKey{}
+	for _, k := range keys {
+		if existing, ok := stored[k]; ok && existing != section {
+			foreign = append(foreign, k)
+		}
+	}
+	return foreign
+}
+
+// studentsOutsideSection returns the students of a save whose academic details place them in
+// another section, or who have none
+func studentsOutsideSection(tx *sql.Tx, keys []markKey, section string) ([]int, error) {
+	_, students := distinctMarkIDs(keys)
+	if len(students) == 0 {
+		return nil, nil
+	}
+	studentList, args := idPlaceholders(students)
+	rows, err := tx.Query(fmt.Sprintf(`
+		SELECT student_id FROM academic_details
+		WHERE student_id IN (%s) AND section = ?`, studentList), append(args, section)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inSection := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		inSection[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return missingIDs(students, inSection), nil
+}
+
+// missingIDs returns the ids that are not in the set, in their original order
+func missingIDs(ids []int, set map[int]bool) []int {
+	missing := []int{}
+	for _, id := range ids {
+		if !set[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// checkMarkOwnership rejects a marks save once the section's sheet is locked, or when it touches
+// students outside the teacher's section or marks already entered for another section. It reports
+// whether the save may go ahead
+func checkMarkOwnership(w http.ResponseWriter, tx *sql.Tx, courseID int, table, itemColumn, itemName string, keys []markKey,
+	academicYear, section string) bool {
+	// The sheet may have been submitted since the caller checked; hold its submission row until commit
+	var locked bool
+	err := tx.QueryRow(`
+		SELECT is_locked FROM assessment_submissions
+		WHERE course_id = ? AND academic_year = ? AND section = ?
+		FOR UPDATE`, courseID, academicYear, section).Scan(&locked)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println("Error checking marks lock:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return false
+	}
+	if locked {
+		http.Error(w, "Marks for this course and section have been submitted and are locked", http.StatusConflict)
+		return false
+	}
+
+	outside, err := studentsOutsideSection(tx, keys, section)
+	if err != nil {
+		log.Println("Error checking student sections:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return false
+	}
+	if len(outside) > 0 {
+		http.Error(w, fmt.Sprintf("Student %d is not in section %s", outside[0], section), http.StatusForbidden)
+		return false
+	}
+
+	stored, err := lockMarkSections(tx, table, itemColumn, keys, academicYear)
+	if err != nil {
+		log.Println("Error checking existing marks:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return false
+	}
+	if foreign := foreignSectionMarks(keys, stored, section); len(foreign) > 0 {
+		k := foreign[0]
+		http.Error(w, fmt.Sprintf("Marks of student %d for %s %d were entered for section %s",
+			k.studentID, itemName, k.itemID, stored[k]), http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// GetAssessmentComponents handles GET /course/:courseId/assessment-components
+func GetAssessmentComponents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var ciaMarks sql.NullInt64
+	err = db.DB.QueryRow("SELECT cia_marks FROM courses WHERE course_id = ?", courseID).Scan(&ciaMarks)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching course CIA marks:", err)
+		http.Error(w, "Failed to fetch course", http.StatusInternalServerError)
+		return
+	}
+
+	components, err := fetchAssessmentComponents(courseID)
+	if err != nil {
+		log.Println("Error fetching assessment components:", err)
+		http.Error(w, "Failed to fetch assessment components", http.StatusInternalServerError)
+		return
+	}
+
+	var total float64
+	for _, c := range components {
+		total += c.Weightage
+	}
+
+	json.NewEncoder(w).Encode(models.AssessmentComponentsResponse{
+		CourseID:       courseID,
+		CIAMarks:       int(ciaMarks.Int64),
+		TotalWeightage: total,
+		Components:     components,
+	})
+}
+
+// componentLimit is what an existing component's max_marks must still cover: the highest mark
+// entered against it and, when it is marked by questions, the total of its questions
+type componentLimit struct {
+	highestMark   float64
+	questionTotal float64
+}
+
+// lockComponentLimits locks the active components of a course and returns their limits, keyed by component id
+func lockComponentLimits(tx *sql.Tx, courseID int) (map[int]componentLimit, error) {
+	rows, err := tx.Query(`
+		SELECT ac.id,
+		       COALESCE((SELECT MAX(m.marks_obtained) FROM assessment_marks m WHERE m.component_id = ac.id), 0),
+		       COALESCE((SELECT SUM(q.max_marks) FROM assessment_questions q WHERE q.component_id = ac.id AND q.status = 1), 0)
+		FROM assessment_components ac
+		WHERE ac.course_id = ? AND ac.status = 1
+		FOR UPDATE`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := make(map[int]componentLimit)
+	for rows.Next() {
+		var id int
+		var limit componentLimit
+		if err := rows.Scan(&id, &limit.highestMark, &limit.questionTotal); err != nil {
+			return nil, err
+		}
+		limits[id] = limit
+	}
+	return limits, rows.Err()
+}
+
+// checkComponentUpdate returns why a new component list cannot replace the current components,
+// or "" when it can. A max_marks below entered marks would push CIA totals past the component
+// weightage, and one that differs from the question total would break question-based entry
+func checkComponentUpdate(components []models.AssessmentComponent, limits map[int]componentLimit) string {
+	for _, c := range components {
+		limit, ok := limits[c.ID]
+		if c.ID == 0 || !ok {
+			continue
+		}
+		if c.MaxMarks < limit.highestMark {
+			return fmt.Sprintf("Component %q: max_marks %.2f is below marks already entered (%.2f)",
+				c.Name, c.MaxMarks, limit.highestMark)
+		}
+		if limit.questionTotal > 0 && math.Abs(c.MaxMarks-limit.questionTotal) > weightageTolerance {
+			return fmt.Sprintf("Component %q: max_marks %.2f does not match its questions, which add up to %.2f; change the questions first",
+				c.Name, c.MaxMarks, limit.questionTotal)
+		}
+	}
+	return ""
+}
+
+// SaveAssessmentComponents handles POST /course/:courseId/assessment-components
+// The request replaces the full component list; weights must add up to the course cia_marks
+func SaveAssessmentComponents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var request models.AssessmentComponentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var ciaMarks sql.NullInt64
+	var courseName string
+	err = db.DB.QueryRow("SELECT course_name, cia_marks FROM courses WHERE course_id = ?", courseID).Scan(&courseName, &ciaMarks)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching course:", err)
+		http.Error(w, "Failed to fetch course", http.StatusInternalServerError)
+		return
+	}
+
+	// Validate components and their weights
+	var totalWeightage float64
+	for i := range request.Components {
+		c := &request.Components[i]
+		c.Name = strings.TrimSpace(c.Name)
+		c.ComponentType = strings.ToUpper(strings.TrimSpace(c.ComponentType))
+		if c.ComponentType == "" {
+			c.ComponentType = "TEST"
+		}
+		if c.Name == "" {
+			http.Error(w, fmt.Sprintf("Component %d: name is required", i+1), http.StatusBadRequest)
+			return
+		}
+		if !validComponentTypes[c.ComponentType] {
+			http.Error(w, fmt.Sprintf("Component %q: invalid component_type %q", c.Name, c.ComponentType), http.StatusBadRequest)
+			return
+		}
+		if c.MaxMarks <= 0 || c.Weightage <= 0 {
+			http.Error(w, fmt.Sprintf("Component %q: max_marks and weightage must be positive", c.Name), http.StatusBadRequest)
+			return
+		}
+		totalWeightage += c.Weightage
+	}
+	if len(request.Components) > 0 && math.Abs(totalWeightage-float64(ciaMarks.Int64)) > weightageTolerance {
+		http.Error(w, fmt.Sprintf("Component weightages add up to %.2f but course CIA marks are %d",
+			totalWeightage, ciaMarks.Int64), http.StatusBadRequest)
+		return
+	}
+
+	oldComponents, _ := fetchAssessmentComponents(courseID)
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to save assessment components", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Components cannot change once any section has submitted marks against them; the lock keeps
+	// a submission from landing while they change
+	var lockedCount int
+	err = tx.QueryRow("SELECT COUNT(*) FROM assessment_submissions WHERE course_id = ? AND is_locked = 1 FOR UPDATE", courseID).Scan(&lockedCount)
+	if err != nil {
+		log.Println("Error checking marks submissions:", err)
+		http.Error(w, "Failed to save assessment components", http.StatusInternalServerError)
+		return
+	}
+	if lockedCount > 0 {
+		http.Error(w, "Cannot change assessment components after marks have been submitted", http.StatusConflict)
+		return
+	}
+
+	limits, err := lockComponentLimits(tx, courseID)
+	if err != nil {
+		log.Println("Error fetching entered marks:", err)
+		http.Error(w, "Failed to save assessment components", http.StatusInternalServerError)
+		return
+	}
+	if problem := checkComponentUpdate(request.Components, limits); problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
+
+	keep := make(map[int]bool)
+	for i := range request.Components {
+		c := &request.Components[i]
+		c.CourseID = courseID
+		c.Position = i + 1
+		if c.ID > 0 {
+			res, err := tx.Exec(`
+				UPDATE assessment_components
+				SET name = ?, component_type = ?, max_marks = ?, weightage = ?, position = ?
+				WHERE id = ? AND course_id = ? AND status = 1
+			`, c.Name, c.ComponentType, c.MaxMarks, c.Weightage, c.Position, c.ID, courseID)
+			if err != nil {
+				log.Println("Error updating assessment component:", err)
+				http.Error(w, "Failed to save assessment components", http.StatusInternalServerError)
+				return
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				keep[c.ID] = true
+				continue
+			}
+			// Check whether the row exists but was unchanged
+			var exists int
+			tx.QueryRow("SELECT COUNT(*) FROM assessment_components WHERE id = ? AND course_id = ? AND status = 1", c.ID, courseID).Scan(&exists)
+			if exists > 0 {
+				keep[c.ID] = true
+				continue
+			}
+			http.Error(w, fmt.Sprintf("Component %d does not belong to this course", c.ID), http.StatusBadRequest)
+			return
+		}
+
+		result, err := tx.Exec(`
+			INSERT INTO assessment_components (course_id, name, component_type, max_marks, weightage, position, status)
+			VALUES (?, ?, ?, ?, ?, ?, 1)
+		`, courseID, c.Name, c.ComponentType, c.MaxMarks, c.Weightage, c.Position)
+		if err != nil {
+			log.Println("Error inserting assessment component:", err)
+			http.Error(w, "Failed to save assessment components", http.StatusInternalServerError)
+			return
+		}
+		id, _ := result.LastInsertId()
+		c.ID = int(id)
+		keep[c.ID] = true
+	}
+
+	// Soft delete components that were dropped from the list
+	for _, old := range oldComponents {
+		if keep[old.ID] {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE assessment_components SET status = 0 WHERE id = ?", old.ID); err != nil {
+			log.Println("Error removing assessment component:", err)
+			http.Error(w, "Failed to save assessment components", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		http.Error(w, "Failed to save assessment components", http.StatusInternalServerError)
+		return
+	}
+
+	var curriculumID int
+	db.DB.QueryRow("SELECT curriculum_id FROM curriculum_courses WHERE course_id = ? AND status = 1 LIMIT 1", courseID).Scan(&curriculumID)
+	if curriculumID > 0 {
+		diff := map[string]interface{}{
+			"assessment_components": map[string]interface{}{"old": oldComponents, "new": request.Components},
+		}
+		logCurriculumDiff(curriculumID, logEntity{"assessment_components", courseID, opUpdate}, "Assessment Components Saved",
+			"Updated CIA components for course: "+courseName, requestUser(r), diff)
+	}
+
+	json.NewEncoder(w).Encode(models.AssessmentComponentsResponse{
+		CourseID:       courseID,
+		CIAMarks:       int(ciaMarks.Int64),
+		TotalWeightage: totalWeightage,
+		Components:     request.Components,
+	})
+}
+
+// GetMarksSheet handles GET /course/:courseId/marks?academic_year=&section=
+func GetMarksSheet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	academicYear := r.URL.Query().Get("academic_year")
+	section := r.URL.Query().Get("section")
+	if academicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+	if section == "" {
+		section = "A"
+	}
+
+	components, err := fetchAssessmentComponents(courseID)
+	if err != nil {
+		log.Println("Error fetching assessment components:", err)
+		http.Error(w, "Failed to fetch assessment components", http.StatusInternalServerError)
+		return
+	}
+
+	locked, err := isMarksLocked(courseID, academicYear, section)
+	if err != nil {
+		log.Println("Error checking marks lock:", err)
+		http.Error(w, "Failed to fetch marks", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT m.id, m.component_id, m.student_id, COALESCE(s.student_name, ''), m.academic_year, m.section,
+		       m.marks_obtained, m.entered_by, m.updated_at
+		FROM assessment_marks m
+		INNER JOIN assessment_components ac ON ac.id = m.component_id
+		LEFT JOIN students s ON s.student_id = m.student_id
+		WHERE ac.course_id = ? AND ac.status = 1 AND m.academic_year = ? AND m.section = ?
+		ORDER BY m.student_id, ac.position`, courseID, academicYear, section)
+	if err != nil {
+		log.Println("Error fetching marks:", err)
+		http.Error(w, "Failed to fetch marks", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	marks := []models.AssessmentMark{}
+	for rows.Next() {
+		var m models.AssessmentMark
+		if err := rows.Scan(&m.ID, &m.ComponentID, &m.StudentID, &m.StudentName, &m.AcademicYear, &m.Section,
+			&m.MarksObtained, &m.EnteredBy, &m.UpdatedAt); err != nil {
+			log.Println("Error scanning mark:", err)
+			continue
+		}
+		marks = append(marks, m)
+	}
+
+	json.NewEncoder(w).Encode(models.MarksSheetResponse{
+		CourseID:     courseID,
+		AcademicYear: academicYear,
+		Section:      section,
+		IsLocked:     locked,
+		Components:   components,
+		Marks:        marks,
+	})
+}
+
+// questionMarkedComponents returns the active components of a course that have CO-tagged questions;
+// their marks are the sum of the question marks, so they are entered through /question-marks
+func questionMarkedComponents(tx *sql.Tx, courseID int) (map[int]bool, error) {
+	rows, err := tx.Query(`
+		SELECT DISTINCT q.component_id
+		FROM assessment_questions q
+		INNER JOIN assessment_components ac ON ac.id = q.component_id
+		WHERE ac.course_id = ? AND ac.status = 1 AND q.status = 1`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		components[id] = true
+	}
+	return components, rows.Err()
+}
+
+// SaveMarks handles POST /course/:courseId/marks
+// Only a teacher allocated to the course/section may enter marks, and only until the sheet is submitted.
+// Components with CO-tagged questions are rejected; their marks come from /question-marks
+func SaveMarks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var request models.MarksEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.AcademicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+	if request.Section == "" {
+		request.Section = "A"
+	}
+
+	teacherID, err := requestTeacherID(r)
+	if err != nil {
+		writeTeacherError(w, err)
+		return
+	}
+	allocated, err := isTeacherAllocated(teacherID, courseID, request.AcademicYear, request.Section)
+	if err != nil {
+		log.Println("Error checking teacher allocation:", err)
+		http.Error(w, "Failed to verify teacher allocation", http.StatusInternalServerError)
+		return
+	}
+	if !allocated {
+		http.Error(w, "Teacher is not allocated to this course and section", http.StatusForbidden)
+		return
+	}
+
+	locked, err := isMarksLocked(courseID, request.AcademicYear, request.Section)
+	if err != nil {
+		log.Println("Error checking marks lock:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+	if locked {
+		http.Error(w, "Marks for this course and section have been submitted and are locked", http.StatusConflict)
+		return
+	}
+
+	components, err := fetchAssessmentComponents(courseID)
+	if err != nil {
+		log.Println("Error fetching assessment components:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+	maxMarks := make(map[int]float64)
+	for _, c := range components {
+		maxMarks[c.ID] = c.MaxMarks
+	}
+
+	keys := make([]markKey, 0, len(request.Marks))
+	for _, m := range request.Marks {
+		max, ok := maxMarks[m.ComponentID]
+		if !ok {
+			http.Error(w, fmt.Sprintf("Component %d is not an active component of this course", m.ComponentID), http.StatusBadRequest)
+			return
+		}
+		if m.StudentID == 0 {
+			http.Error(w, "student_id is required for every mark", http.StatusBadRequest)
+			return
+		}
+		if m.MarksObtained < 0 || m.MarksObtained > max {
+			http.Error(w, fmt.Sprintf("Marks %.2f for student %d exceed the range 0-%.2f of component %d",
+				m.MarksObtained, m.StudentID, max, m.ComponentID), http.StatusBadRequest)
+			return
+		}
+		keys = append(keys, markKey{m.ComponentID, m.StudentID})
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	questionMarked, err := questionMarkedComponents(tx, courseID)
+	if err != nil {
+		log.Println("Error fetching question-marked components:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+	for _, m := range request.Marks {
+		if questionMarked[m.ComponentID] {
+			http.Error(w, fmt.Sprintf("Component %d is marked question by question; enter its marks through /api/course/%d/question-marks",
+				m.ComponentID, courseID), http.StatusConflict)
+			return
+		}
+	}
+
+	if !checkMarkOwnership(w, tx, courseID, "assessment_marks", "component_id", "component", keys, request.AcademicYear, request.Section) {
+		return
+	}
+
+	for _, m := range request.Marks {
+		_, err := tx.Exec(`
+			INSERT INTO assessment_marks (component_id, student_id, academic_year, section, marks_obtained, entered_by)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE marks_obtained = VALUES(marks_obtained), entered_by = VALUES(entered_by)
+		`, m.ComponentID, m.StudentID, request.AcademicYear, request.Section, m.MarksObtained, teacherID)
+		if err != nil {
+			log.Println("Error saving mark:", err)
+			http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Marks saved successfully",
+		"count":   len(request.Marks),
+	})
+}
+
+// SubmitMarks handles POST /course/:courseId/marks/submit and locks the marks sheet
+func SubmitMarks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var request models.MarksSubmission
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.AcademicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+	if request.Section == "" {
+		request.Section = "A"
+	}
+
+	request.SubmittedBy, err = requestTeacherID(r)
+	if err != nil {
+		writeTeacherError(w, err)
+		return
+	}
+	allocated, err := isTeacherAllocated(request.SubmittedBy, courseID, request.AcademicYear, request.Section)
+	if err != nil {
+		log.Println("Error checking teacher allocation:", err)
+		http.Error(w, "Failed to verify teacher allocation", http.StatusInternalServerError)
+		return
+	}
+	if !allocated {
+		http.Error(w, "Teacher is not allocated to this course and section", http.StatusForbidden)
+		return
+	}
+
+	locked, err := isMarksLocked(courseID, request.AcademicYear, request.Section)
+	if err != nil {
+		log.Println("Error checking marks lock:", err)
+		http.Error(w, "Failed to submit marks", http.StatusInternalServerError)
+		return
+	}
+	if locked {
+		http.Error(w, "Marks have already been submitted", http.StatusConflict)
+		return
+	}
+
+	_, err = db.DB.Exec(`
+		INSERT INTO assessment_submissions (course_id, academic_year, section, submitted_by, is_locked, submitted_at)
+		VALUES (?, ?, ?, ?, 1, NOW())
+		ON DUPLICATE KEY UPDATE submitted_by = VALUES(submitted_by), is_locked = 1, submitted_at = NOW()
+	`, courseID, request.AcademicYear, request.Section, request.SubmittedBy)
+	if err != nil {
+		log.Println("Error submitting marks:", err)
+		http.Error(w, "Failed to submit marks", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Marks submitted and locked successfully"})
+}
+
+// GetCIATotals handles GET /course/:courseId/cia-totals?academic_year=&section=
+// Each component contributes (marks_obtained / max_marks) * weightage to the CIA total
+func GetCIATotals(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	academicYear := r.URL.Query().Get("academic_year")
+	section := r.URL.Query().Get("section")
+	if academicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+	if section == "" {
+		section = "A"
+	}
+
+	var ciaMarks sql.NullInt64
+	db.DB.QueryRow("SELECT cia_marks FROM courses WHERE course_id = ?", courseID).Scan(&ciaMarks)
+
+	rows, err := db.DB.Query(`
+		SELECT m.student_id, COALESCE(s.student_name, ''), ac.id, ac.max_marks, ac.weightage, m.marks_obtained
+		FROM assessment_marks m
+		INNER JOIN assessment_components ac ON ac.id = m.component_id
+		LEFT JOIN students s ON s.student_id = m.student_id
+		WHERE ac.course_id = ? AND ac.status = 1 AND m.academic_year = ? AND m.section = ?
+		ORDER BY m.student_id, ac.position`, courseID, academicYear, section)
+	if err != nil {
+		log.Println("Error fetching marks for CIA totals:", err)
+		http.Error(w, "Failed to compute CIA totals", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	totals := []models.StudentCIATotal{}
+	index := make(map[int]int)
+	for rows.Next() {
+		var studentID, componentID int
+		var studentName string
+		var maxMarks, weightage, obtained float64
+		if err := rows.Scan(&studentID, &studentName, &componentID, &maxMarks, &weightage, &obtained); err != nil {
+			log.Println("Error scanning mark:", err)
+			continue
+		}
+
+		i, ok := index[studentID]
+		if !ok {
+			totals = append(totals, models.StudentCIATotal{
+				StudentID:       studentID,
+				StudentName:     studentName,
+				ComponentScores: make(map[string]float64),
+				CIAMarks:        int(ciaMarks.Int64),
+			})
+			i = len(totals) - 1
+			index[studentID] = i
+		}
+
+		score := 0.0
+		if maxMarks > 0 {
+			score = obtained / maxMarks * weightage
+		}
+		totals[i].ComponentScores[strconv.Itoa(componentID)] = roundTo2(score)
+		totals[i].CIATotal += score
+	}
+
+	for i := range totals {
+		totals[i].CIATotal = roundTo2(totals[i].CIATotal)
+	}
+
+	json.NewEncoder(w).Encode(totals)
+}
+
+// roundTo2 rounds a score to two decimal places
+func roundTo2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
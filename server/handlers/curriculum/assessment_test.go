@@ -0,0 +1,71 @@
+package curriculum
+
+import (
+	"reflect"
+	"server/models"
+	"strings"
+	"testing"
+)
+
+func TestForeignSectionMarks(t *testing.T) {
+	stored := map[markKey]string{
+		{1, 100}: "A",
+		{1, 101}: "B",
+		{2, 100}: "A",
+	}
+
+	tests := []struct {
+		name    string
+		keys    []markKey
+		section string
+		want    []markKey
+	}{
+		{"new marks", []markKey{{3, 100}, {1, 102}}, "A", []markKey{}},
+		{"own section", []markKey{{1, 100}, {2, 100}}, "A", []markKey{}},
+		{"other section's mark", []markKey{{1, 100}, {1, 101}}, "A", []markKey{{1, 101}}},
+		{"all taken by another section", []markKey{{1, 100}, {2, 100}}, "B", []markKey{{1, 100}, {2, 100}}},
+	}
+	for _, tt := range tests {
+		if got := foreignSectionMarks(tt.keys, stored, tt.section); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: foreignSectionMarks = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDistinctMarkIDs(t *testing.T) {
+	items, students := distinctMarkIDs([]markKey{{2, 100}, {1, 100}, {2, 101}, {1, 101}})
+	if !reflect.DeepEqual(items, []int{2, 1}) || !reflect.DeepEqual(students, []int{100, 101}) {
+		t.Errorf("distinctMarkIDs = %v, %v", items, students)
+	}
+}
+
+func TestMissingIDs(t *testing.T) {
+	got := missingIDs([]int{100, 101, 102}, map[int]bool{101: true})
+	if !reflect.DeepEqual(got, []int{100, 102}) {
+		t.Errorf("missingIDs = %v, want [100 102]", got)
+	}
+}
+
+func TestCheckComponentUpdate(t *testing.T) {
+	// 18 marks have been entered for component 1; component 2 is marked by questions worth 20
+	limits := map[int]componentLimit{1: {highestMark: 18}, 2: {highestMark: 12, questionTotal: 20}}
+
+	tests := []struct {
+		name       string
+		components []models.AssessmentComponent
+		problem    string
+	}{
+		{"new component", []models.AssessmentComponent{{Name: "Quiz", MaxMarks: 5}}, ""},
+		{"max marks down to entered marks", []models.AssessmentComponent{{ID: 1, Name: "CAT 1", MaxMarks: 18}}, ""},
+		{"max marks below entered marks", []models.AssessmentComponent{{ID: 1, Name: "CAT 1", MaxMarks: 15}}, "below marks already entered (18.00)"},
+		{"question total kept", []models.AssessmentComponent{{ID: 2, Name: "CAT 2", MaxMarks: 20}}, ""},
+		{"max marks below question total", []models.AssessmentComponent{{ID: 2, Name: "CAT 2", MaxMarks: 15}}, "add up to 20.00"},
+		{"max marks above question total", []models.AssessmentComponent{{ID: 2, Name: "CAT 2", MaxMarks: 25}}, "add up to 20.00"},
+	}
+	for _, tt := range tests {
+		problem := checkComponentUpdate(tt.components, limits)
+		if (tt.problem == "" && problem != "") || !strings.Contains(problem, tt.problem) {
+			t.Errorf("%s: problem = %q, want %q", tt.name, problem, tt.problem)
+		}
+	}
+}
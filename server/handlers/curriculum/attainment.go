@@ -0,0 +1,948 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"server/db"
+	"server/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultAttainmentTargets are used until a course configures its own targets
+var defaultAttainmentTargets = models.AttainmentTargets{
+	StudentThreshold: 60,
+	Level1:           50,
+	Level2:           60,
+	Level3:           70,
+}
+
+// fetchAttainmentTargets returns the attainment targets of a course, falling back to the defaults
+func fetchAttainmentTargets(courseID int) models.AttainmentTargets {
+	targets := defaultAttainmentTargets
+	targets.CourseID = courseID
+	err := db.DB.QueryRow(`
+		SELECT student_threshold, level_1, level_2, level_3
+		FROM co_attainment_targets WHERE course_id = ?
+	`, courseID).Scan(&targets.StudentThreshold, &targets.Level1, &targets.Level2, &targets.Level3)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println("Error fetching attainment targets, using defaults:", err)
+	}
+	return targets
+}
+
+// attainmentLevel converts the percentage of students attaining a CO into a level 0-3
+func attainmentLevel(percentage float64, targets models.AttainmentTargets) int {
+	switch {
+	case percentage >= targets.Level3:
+		return 3
+	case percentage >= targets.Level2:
+		return 2
+	case percentage >= targets.Level1:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// rollupOutcomeAttainment derives PO/PSO attainment as the mapping-weighted average of CO levels:
+// attainment(PO j) = sum_i(level(CO i) * map(i, j)) / sum_i(map(i, j)), over assessed COs only
+func rollupOutcomeAttainment(coLevels map[int]int, matrix map[int]map[int]int) []models.OutcomeAttainment {
+	weighted := make(map[int]float64)
+	weights := make(map[int]float64)
+	for coIndex, row := range matrix {
+		level, assessed := coLevels[coIndex]
+		if !assessed {
+			continue
+		}
+		for outcomeIndex, value := range row {
+			if value <= 0 {
+				continue
+			}
+			weighted[outcomeIndex] += float64(level * value)
+			weights[outcomeIndex] += float64(value)
+		}
+	}
+
+	result := []models.OutcomeAttainment{}
+	for outcomeIndex, weight := range weights {
+		result = append(result, models.OutcomeAttainment{
+			Index:      outcomeIndex,
+			Attainment: roundTo2(weighted[outcomeIndex] / weight),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Index < result[j].Index })
+	return result
+}
+
+// fetchCOMappingMatrix loads co_po_mapping or co_pso_mapping as co_index -> outcome index -> value
+func fetchCOMappingMatrix(courseID int, table, outcomeColumn string) (map[int]map[int]int, error) {
+	query := fmt.Sprintf("SELECT co_index, %s, mapping_value FROM %s WHERE course_id = ?", outcomeColumn, table)
+	rows, err := db.DB.Query(query, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matrix := make(map[int]map[int]int)
+	for rows.Next() {
+		var coIndex, outcomeIndex, value int
+		if err := rows.Scan(&coIndex, &outcomeIndex, &value); err != nil {
+			return nil, err
+		}
+		if matrix[coIndex] == nil {
+			matrix[coIndex] = make(map[int]int)
+		}
+		matrix[coIndex][outcomeIndex] = value
+	}
+	return matrix, rows.Err()
+}
+
+// outcomePositions maps course_outcomes ids to their current co_index
+func outcomePositions(outcomeIDs []int) map[int]int {
+	positionOf := make(map[int]int, len(outcomeIDs))
+	for i, id := range outcomeIDs {
+		positionOf[id] = i
+	}
+	return positionOf
+}
+
+// questionCOIndex returns the co_index a question counts towards now, or -1 and the reason when its
+// outcome is gone. Questions saved before outcome_id was stored fall back to their co_index
+func questionCOIndex(coIndex int, outcomeID sql.NullInt64, positionOf map[int]int) (int, string) {
+	if outcomeID.Valid {
+		if pos, ok := positionOf[int(outcomeID.Int64)]; ok {
+			return pos, ""
+		}
+		return -1, fmt.Sprintf("was tagged to CO%d, which has since been deleted", coIndex+1)
+	}
+	if coIndex < 0 || coIndex >= len(positionOf) {
+		return -1, fmt.Sprintf("is tagged to CO%d, which does not exist (course has %d outcomes)", coIndex+1, len(positionOf))
+	}
+	return coIndex, ""
+}
+
+// fetchQuestionCOs resolves the current co_index of every active question of a course, keyed by
+// question id, and lists the questions whose outcome has been deleted
+func fetchQuestionCOs(courseID int) (map[int]int, []models.OrphanedQuestion, error) {
+	outcomeIDs, err := fetchOutcomeIDs(courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	positionOf := outcomePositions(outcomeIDs)
+
+	rows, err := db.DB.Query(`
+		SELECT q.id, q.component_id, ac.name, q.question_no, q.co_index, q.outcome_id
+		FROM assessment_questions q
+		INNER JOIN assessment_components ac ON ac.id = q.component_id AND ac.status = 1
+		WHERE ac.course_id = ? AND q.status = 1
+		ORDER BY ac.position, ac.id, q.position, q.id`, courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	coIndexes := make(map[int]int)
+	orphaned := []models.OrphanedQuestion{}
+	for rows.Next() {
+		var q models.OrphanedQuestion
+		var coIndex int
+		var outcomeID sql.NullInt64
+		if err := rows.Scan(&q.QuestionID, &q.ComponentID, &q.ComponentName, &q.QuestionNo, &coIndex, &outcomeID); err != nil {
+			return nil, nil, err
+		}
+		current, reason := questionCOIndex(coIndex, outcomeID, positionOf)
+		if current < 0 {
+			q.Message = fmt.Sprintf("%s question %s %s", q.ComponentName, q.QuestionNo, reason)
+			orphaned = append(orphaned, q)
+			continue
+		}
+		coIndexes[q.QuestionID] = current
+	}
+	return coIndexes, orphaned, rows.Err()
+}
+
+// computeCourseAttainment computes direct CO attainment from question marks and rolls it up
+// into PO and PSO attainment. An empty section aggregates every section of the academic year.
+// When viewed through a curriculum pinned to a published version of the course, the outcomes and
+// CO-PO/PSO mappings come from that version
+func computeCourseAttainment(courseID int, academicYear, section string, curriculumID int) (*models.CourseAttainment, error) {
+	result := &models.CourseAttainment{
+		CourseID:      courseID,
+		AcademicYear:  academicYear,
+		Section:       section,
+		COs:           []models.COAttainment{},
+		POAttainment:  []models.OutcomeAttainment{},
+		PSOAttainment: []models.OutcomeAttainment{},
+		Orphaned:      []models.OrphanedQuestion{},
+	}
+
+	err := db.DB.QueryRow("SELECT course_code, course_name FROM courses WHERE course_id = ?", courseID).
+		Scan(&result.CourseCode, &result.CourseName)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Targets = fetchAttainmentTargets(courseID)
+	snapshot, pinned, err := pinnedCourseSnapshot(curriculumID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	var outcomes []string
+	if snapshot != nil {
+		outcomes = snapshot.Outcomes
+		result.PinnedVersion = pinned
+	} else {
+		outcomes, _ = fetchOutcomes(courseID)
+	}
+
+	questionCOs, orphaned, err := fetchQuestionCOs(courseID)
+	if err != nil {
+		return nil, err
+	}
+	result.Orphaned = orphaned
+
+	query := `
+		SELECT qm.question_id, qm.student_id, qm.marks_obtained, q.max_marks
+		FROM assessment_question_marks qm
+		INNER JOIN assessment_questions q ON q.id = qm.question_id AND q.status = 1
+		INNER JOIN assessment_components ac ON ac.id = q.component_id AND ac.status = 1
+		WHERE ac.course_id = ? AND qm.academic_year = ?`
+	args := []interface{}{courseID, academicYear}
+	if section != "" {
+		query += " AND qm.section = ?"
+		args = append(args, section)
+	}
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type coStudent struct{ coIndex, studentID int }
+	obtainedBy := make(map[coStudent]float64)
+	maxBy := make(map[coStudent]float64)
+	for rows.Next() {
+		var questionID, studentID int
+		var obtained, max float64
+		if err := rows.Scan(&questionID, &studentID, &obtained, &max); err != nil {
+			return nil, err
+		}
+		coIndex, ok := questionCOs[questionID]
+		if !ok {
+			continue
+		}
+		key := coStudent{coIndex, studentID}
+		obtainedBy[key] += obtained
+		maxBy[key] += max
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	assessed := make(map[int]int)
+	attained := make(map[int]int)
+	for key, max := range maxBy {
+		if max <= 0 {
+			continue
+		}
+		assessed[key.coIndex]++
+		if obtainedBy[key]/max*100 >= result.Targets.StudentThreshold {
+			attained[key.coIndex]++
+		}
+	}
+
+	coLevels := make(map[int]int)
+	for coIndex, outcome := range outcomes {
+		co := models.COAttainment{
+			COIndex:          coIndex,
+			Outcome:          outcome,
+			StudentsAssessed: assessed[coIndex],
+			StudentsAttained: attained[coIndex],
+		}
+		if co.StudentsAssessed > 0 {
+			co.Percentage = roundTo2(float64(co.StudentsAttained) / float64(co.StudentsAssessed) * 100)
+			co.Level = attainmentLevel(co.Percentage, result.Targets)
+			coLevels[coIndex] = co.Level
+		}
+		result.COs = append(result.COs, co)
+	}
+
+	if snapshot != nil {
+		result.POAttainment = rollupOutcomeAttainment(coLevels, snapshotMappingMatrix(snapshot.COPOMapping))
+		result.PSOAttainment = rollupOutcomeAttainment(coLevels, snapshotMappingMatrix(snapshot.COPSOMapping))
+		return result, nil
+	}
+	poMatrix, err := fetchCOMappingMatrix(courseID, "co_po_mapping", "po_index")
+	if err != nil {
+		return nil, err
+	}
+	psoMatrix, err := fetchCOMappingMatrix(courseID, "co_pso_mapping", "pso_index")
+	if err != nil {
+		return nil, err
+	}
+	result.POAttainment = rollupOutcomeAttainment(coLevels, poMatrix)
+	result.PSOAttainment = rollupOutcomeAttainment(coLevels, psoMatrix)
+
+	return result, nil
+}
+
+// averageOutcomeAttainment averages PO/PSO attainment across courses that address each outcome
+func averageOutcomeAttainment(perCourse [][]models.OutcomeAttainment) []models.OutcomeAttainment {
+	sums := make(map[int]float64)
+	counts := make(map[int]int)
+	for _, list := range perCourse {
+		for _, oa := range list {
+			sums[oa.Index] += oa.Attainment
+			counts[oa.Index]++
+		}
+	}
+
+	result := []models.OutcomeAttainment{}
+	for index, sum := range sums {
+		result = append(result, models.OutcomeAttainment{
+			Index:      index,
+			Attainment: roundTo2(sum / float64(counts[index])),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Index < result[j].Index })
+	return result
+}
+
+// computeCurriculumAttainment computes attainment for every active course of a curriculum and
+// averages PO/PSO attainment across the courses that address each outcome
+func computeCurriculumAttainment(curriculumID int, academicYear string) (*models.CurriculumAttainment, error) {
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT c.course_id
+		FROM courses c
+		INNER JOIN curriculum_courses cc ON c.course_id = cc.course_id AND cc.status = 1
+		WHERE cc.curriculum_id = ? AND c.status = 1
+		ORDER BY c.course_id`, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	courseIDs := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			courseIDs = append(courseIDs, id)
+		}
+	}
+	rows.Close()
+
+	result := &models.CurriculumAttainment{
+		CurriculumID: curriculumID,
+		AcademicYear: academicYear,
+		Courses:      []models.CourseAttainment{},
+	}
+	var poLists, psoLists [][]models.OutcomeAttainment
+	for _, courseID := range courseIDs {
+		attainment, err := computeCourseAttainment(courseID, academicYear, "", curriculumID)
+		if err != nil {
+			log.Printf("Error computing attainment for course %d: %v", courseID, err)
+			continue
+		}
+		result.Courses = append(result.Courses, *attainment)
+		poLists = append(poLists, attainment.POAttainment)
+		psoLists = append(psoLists, attainment.PSOAttainment)
+	}
+	result.POAttainment = averageOutcomeAttainment(poLists)
+	result.PSOAttainment = averageOutcomeAttainment(psoLists)
+
+	return result, nil
+}
+
+// GetAttainmentTargets handles GET /course/:courseId/attainment-targets
+func GetAttainmentTargets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(fetchAttainmentTargets(courseID))
+}
+
+// SaveAttainmentTargets handles PUT /course/:courseId/attainment-targets
+func SaveAttainmentTargets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var targets models.AttainmentTargets
+	if err := json.NewDecoder(r.Body).Decode(&targets); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	inRange := func(v float64) bool { return v >= 0 && v <= 100 }
+	if !inRange(targets.StudentThreshold) || !inRange(targets.Level1) || !inRange(targets.Level2) || !inRange(targets.Level3) {
+		http.Error(w, "Targets must be percentages between 0 and 100", http.StatusBadRequest)
+		return
+	}
+	if !(targets.Level1 <= targets.Level2 && targets.Level2 <= targets.Level3) {
+		http.Error(w, "Level thresholds must satisfy level_1 <= level_2 <= level_3", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec(`
+		INSERT INTO co_attainment_targets (course_id, student_threshold, level_1, level_2, level_3)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE student_threshold = VALUES(student_threshold),
+			level_1 = VALUES(level_1), level_2 = VALUES(level_2), level_3 = VALUES(level_3)
+	`, courseID, targets.StudentThreshold, targets.Level1, targets.Level2, targets.Level3)
+	if err != nil {
+		log.Println("Error saving attainment targets:", err)
+		http.Error(w, "Failed to save attainment targets", http.StatusInternalServerError)
+		return
+	}
+
+	targets.CourseID = courseID
+	json.NewEncoder(w).Encode(targets)
+}
+
+// GetAssessmentQuestions handles GET /assessment-component/:componentId/questions
+func GetAssessmentQuestions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	componentID, err := strconv.Atoi(vars["componentId"])
+	if err != nil {
+		http.Error(w, "Invalid component ID", http.StatusBadRequest)
+		return
+	}
+
+	var courseID int
+	err = db.DB.QueryRow("SELECT course_id FROM assessment_components WHERE id = ?", componentID).Scan(&courseID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Assessment component not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching assessment component:", err)
+		http.Error(w, "Failed to fetch questions", http.StatusInternalServerError)
+		return
+	}
+	outcomeIDs, err := fetchOutcomeIDs(courseID)
+	if err != nil {
+		log.Println("Error fetching course outcomes:", err)
+		http.Error(w, "Failed to fetch questions", http.StatusInternalServerError)
+		return
+	}
+	positionOf := outcomePositions(outcomeIDs)
+
+	rows, err := db.DB.Query(`
+		SELECT id, component_id, question_no, max_marks, co_index, outcome_id, position
+		FROM assessment_questions
+		WHERE component_id = ? AND status = 1
+		ORDER BY position, id`, componentID)
+	if err != nil {
+		log.Println("Error fetching assessment questions:", err)
+		http.Error(w, "Failed to fetch questions", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	questions := []models.AssessmentQuestion{}
+	for rows.Next() {
+		var q models.AssessmentQuestion
+		var outcomeID sql.NullInt64
+		if err := rows.Scan(&q.ID, &q.ComponentID, &q.QuestionNo, &q.MaxMarks, &q.COIndex, &outcomeID, &q.Position); err != nil {
+			log.Println("Error scanning question:", err)
+			continue
+		}
+		// Report the question against where its outcome is now
+		if current, reason := questionCOIndex(q.COIndex, outcomeID, positionOf); current < 0 {
+			q.Orphaned = "Question " + q.QuestionNo + " " + reason
+		} else {
+			q.COIndex = current
+		}
+		if outcomeID.Valid {
+			id := int(outcomeID.Int64)
+			q.OutcomeID = &id
+		}
+		questions = append(questions, q)
+	}
+
+	json.NewEncoder(w).Encode(questions)
+}
+
+// lockComponentQuestions locks the active questions of a component and returns the highest marks
+// entered for each, keyed by question id
+func lockComponentQuestions(tx *sql.Tx, componentID int) (map[int]float64, error) {
+	rows, err := tx.Query(`
+		SELECT q.id, COALESCE(MAX(qm.marks_obtained), 0)
+		FROM assessment_questions q
+		LEFT JOIN assessment_question_marks qm ON qm.question_id = q.id
+		WHERE q.component_id = ? AND q.status = 1
+		GROUP BY q.id
+		FOR UPDATE`, componentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	highest := make(map[int]float64)
+	for rows.Next() {
+		var id int
+		var marks float64
+		if err := rows.Scan(&id, &marks); err != nil {
+			return nil, err
+		}
+		highest[id] = marks
+	}
+	return highest, rows.Err()
+}
+
+// checkQuestionUpdate returns why a component's new question list cannot replace its current
+// questions, or "" when it can. highest holds the current questions and their highest entered marks
+func checkQuestionUpdate(questions []models.AssessmentQuestion, highest map[int]float64) string {
+	seen := make(map[int]bool)
+	for _, q := range questions {
+		if q.ID == 0 {
+			continue
+		}
+		entered, ok := highest[q.ID]
+		if !ok {
+			return fmt.Sprintf("Question %s: question %d is not an active question of this component", q.QuestionNo, q.ID)
+		}
+		if seen[q.ID] {
+			return fmt.Sprintf("Question %s: question %d is listed more than once", q.QuestionNo, q.ID)
+		}
+		seen[q.ID] = true
+		if q.MaxMarks < entered {
+			return fmt.Sprintf("Question %s: max_marks %.2f is below marks already entered (%.2f)",
+				q.QuestionNo, q.MaxMarks, entered)
+		}
+	}
+	return ""
+}
+
+// recomputeComponentMarks sets the component marks of every student with question marks in the
+// component to the sum of their marks for its active questions
+func recomputeComponentMarks(tx *sql.Tx, componentID int) error {
+	_, err := tx.Exec(`
+		UPDATE assessment_marks m
+		INNER JOIN (
+			SELECT qm.student_id, qm.academic_year,
+			       SUM(CASE WHEN q.status = 1 THEN qm.marks_obtained ELSE 0 END) AS total
+			FROM assessment_question_marks qm
+			INNER JOIN assessment_questions q ON q.id = qm.question_id
+			WHERE q.component_id = ?
+			GROUP BY qm.student_id, qm.academic_year
+		) t ON t.student_id = m.student_id AND t.academic_year = m.academic_year
+		SET m.marks_obtained = t.total
+		WHERE m.component_id = ?`, componentID, componentID)
+	return err
+}
+
+// SaveAssessmentQuestions handles POST /assessment-component/:componentId/questions
+// Questions replace the existing set; each must reference an existing course outcome and
+// their marks must add up to the component's max_marks
+func SaveAssessmentQuestions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	componentID, err := strconv.Atoi(vars["componentId"])
+	if err != nil {
+		http.Error(w, "Invalid component ID", http.StatusBadRequest)
+		return
+	}
+
+	var request models.AssessmentQuestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var courseID int
+	var componentMax float64
+	err = db.DB.QueryRow("SELECT course_id, max_marks FROM assessment_components WHERE id = ? AND status = 1", componentID).
+		Scan(&courseID, &componentMax)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Assessment component not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching assessment component:", err)
+		http.Error(w, "Failed to fetch assessment component", http.StatusInternalServerError)
+		return
+	}
+
+	var lockedCount int
+	err = db.DB.QueryRow("SELECT COUNT(*) FROM assessment_submissions WHERE course_id = ? AND is_locked = 1", courseID).Scan(&lockedCount)
+	if err != nil {
+		log.Println("Error checking marks submissions:", err)
+		http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+		return
+	}
+	if lockedCount > 0 {
+		http.Error(w, "Cannot change questions after marks have been submitted", http.StatusConflict)
+		return
+	}
+
+	outcomeIDs, err := fetchOutcomeIDs(courseID)
+	if err != nil {
+		log.Println("Error fetching course outcomes:", err)
+		http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+		return
+	}
+	var total float64
+	for i := range request.Questions {
+		q := &request.Questions[i]
+		q.QuestionNo = strings.TrimSpace(q.QuestionNo)
+		if q.QuestionNo == "" {
+			q.QuestionNo = strconv.Itoa(i + 1)
+		}
+		if q.MaxMarks <= 0 {
+			http.Error(w, fmt.Sprintf("Question %s: max_marks must be positive", q.QuestionNo), http.StatusBadRequest)
+			return
+		}
+		if q.COIndex < 0 || q.COIndex >= len(outcomeIDs) {
+			http.Error(w, fmt.Sprintf("Question %s: co_index %d does not match any of the %d course outcomes",
+				q.QuestionNo, q.COIndex, len(outcomeIDs)), http.StatusBadRequest)
+			return
+		}
+		q.OutcomeID = &outcomeIDs[q.COIndex]
+		q.Orphaned = ""
+		total += q.MaxMarks
+	}
+	if len(request.Questions) > 0 && math.Abs(total-componentMax) > weightageTolerance {
+		http.Error(w, fmt.Sprintf("Question marks add up to %.2f but the component max_marks is %.2f",
+			total, componentMax), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	highest, err := lockComponentQuestions(tx, componentID)
+	if err != nil {
+		log.Println("Error fetching existing questions:", err)
+		http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+		return
+	}
+	if problem := checkQuestionUpdate(request.Questions, highest); problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
+
+	keep := []interface{}{componentID}
+	placeholders := []string{}
+	for i := range request.Questions {
+		q := &request.Questions[i]
+		q.ComponentID = componentID
+		q.Position = i + 1
+		if q.ID > 0 {
+			_, err := tx.Exec(`
+				UPDATE assessment_questions
+				SET question_no = ?, max_marks = ?, co_index = ?, outcome_id = ?, position = ?
+				WHERE id = ? AND component_id = ? AND status = 1
+			`, q.QuestionNo, q.MaxMarks, q.COIndex, *q.OutcomeID, q.Position, q.ID, componentID)
+			if err != nil {
+				log.Println("Error updating question:", err)
+				http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+				return
+			}
+		} else {
+			result, err := tx.Exec(`
+				INSERT INTO assessment_questions (component_id, question_no, max_marks, co_index, outcome_id, position, status)
+				VALUES (?, ?, ?, ?, ?, ?, 1)
+			`, componentID, q.QuestionNo, q.MaxMarks, q.COIndex, *q.OutcomeID, q.Position)
+			if err != nil {
+				log.Println("Error inserting question:", err)
+				http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+				return
+			}
+			id, _ := result.LastInsertId()
+			q.ID = int(id)
+		}
+		keep = append(keep, q.ID)
+		placeholders = append(placeholders, "?")
+	}
+
+	// Soft delete questions dropped from the list
+	removeQuery := "UPDATE assessment_questions SET status = 0 WHERE component_id = ? AND status = 1"
+	if len(placeholders) > 0 {
+		removeQuery += " AND id NOT IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	if _, err := tx.Exec(removeQuery, keep...); err != nil {
+		log.Println("Error removing questions:", err)
+		http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+		return
+	}
+
+	// Component marks entered through questions are their sum; when every question is removed the
+	// component goes back to direct entry and keeps its marks
+	if len(request.Questions) > 0 {
+		if err := recomputeComponentMarks(tx, componentID); err != nil {
+			log.Println("Error recomputing component marks:", err)
+			http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		http.Error(w, "Failed to save questions", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(request.Questions)
+}
+
+// SaveQuestionMarks handles POST /course/:courseId/question-marks
+// Question marks are summed back into assessment_marks so CIA totals stay consistent
+func SaveQuestionMarks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var request models.QuestionMarksRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.AcademicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+	if request.Section == "" {
+		request.Section = "A"
+	}
+
+	teacherID, err := requestTeacherID(r)
+	if err != nil {
+		writeTeacherError(w, err)
+		return
+	}
+	allocated, err := isTeacherAllocated(teacherID, courseID, request.AcademicYear, request.Section)
+	if err != nil {
+		log.Println("Error checking teacher allocation:", err)
+		http.Error(w, "Failed to verify teacher allocation", http.StatusInternalServerError)
+		return
+	}
+	if !allocated {
+		http.Error(w, "Teacher is not allocated to this course and section", http.StatusForbidden)
+		return
+	}
+
+	locked, err := isMarksLocked(courseID, request.AcademicYear, request.Section)
+	if err != nil {
+		log.Println("Error checking marks lock:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+	if locked {
+		http.Error(w, "Marks for this course and section have been submitted and are locked", http.StatusConflict)
+		return
+	}
+
+	// Load the active questions of this course
+	type questionInfo struct {
+		componentID int
+		maxMarks    float64
+	}
+	questions := make(map[int]questionInfo)
+	rows, err := db.DB.Query(`
+		SELECT q.id, q.component_id, q.max_marks
+		FROM assessment_questions q
+		INNER JOIN assessment_components ac ON ac.id = q.component_id
+		WHERE ac.course_id = ? AND ac.status = 1 AND q.status = 1`, courseID)
+	if err != nil {
+		log.Println("Error fetching questions:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+	for rows.Next() {
+		var id int
+		var info questionInfo
+		if err := rows.Scan(&id, &info.componentID, &info.maxMarks); err == nil {
+			questions[id] = info
+		}
+	}
+	rows.Close()
+
+	type componentStudent struct {
+		componentID int
+		studentID   int
+	}
+	touched := make(map[componentStudent]bool)
+	questionKeys := make([]markKey, 0, len(request.Marks))
+	componentKeys := []markKey{}
+	for _, m := range request.Marks {
+		info, ok := questions[m.QuestionID]
+		if !ok {
+			http.Error(w, fmt.Sprintf("Question %d is not an active question of this course", m.QuestionID), http.StatusBadRequest)
+			return
+		}
+		if m.StudentID == 0 {
+			http.Error(w, "student_id is required for every mark", http.StatusBadRequest)
+			return
+		}
+		if m.MarksObtained < 0 || m.MarksObtained > info.maxMarks {
+			http.Error(w, fmt.Sprintf("Marks %.2f for student %d exceed the range 0-%.2f of question %d",
+				m.MarksObtained, m.StudentID, info.maxMarks, m.QuestionID), http.StatusBadRequest)
+			return
+		}
+		questionKeys = append(questionKeys, markKey{m.QuestionID, m.StudentID})
+		key := componentStudent{info.componentID, m.StudentID}
+		if !touched[key] {
+			touched[key] = true
+			componentKeys = append(componentKeys, markKey{info.componentID, m.StudentID})
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// The component totals are rewritten too, so they must belong to this section as well
+	if !checkMarkOwnership(w, tx, courseID, "assessment_question_marks", "question_id", "question", questionKeys,
+		request.AcademicYear, request.Section) {
+		return
+	}
+	if !checkMarkOwnership(w, tx, courseID, "assessment_marks", "component_id", "component", componentKeys,
+		request.AcademicYear, request.Section) {
+		return
+	}
+
+	for _, m := range request.Marks {
+		_, err := tx.Exec(`
+			INSERT INTO assessment_question_marks (question_id, student_id, academic_year, section, marks_obtained, entered_by)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE marks_obtained = VALUES(marks_obtained), entered_by = VALUES(entered_by)
+		`, m.QuestionID, m.StudentID, request.AcademicYear, request.Section, m.MarksObtained, teacherID)
+		if err != nil {
+			log.Println("Error saving question mark:", err)
+			http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Recompute component marks from the question marks
+	for key := range touched {
+		_, err := tx.Exec(`
+			INSERT INTO assessment_marks (component_id, student_id, academic_year, section, marks_obtained, entered_by)
+			SELECT q.component_id, qm.student_id, qm.academic_year, ?, SUM(qm.marks_obtained), ?
+			FROM assessment_question_marks qm
+			INNER JOIN assessment_questions q ON q.id = qm.question_id AND q.status = 1
+			WHERE q.component_id = ? AND qm.student_id = ? AND qm.academic_year = ?
+			GROUP BY q.component_id, qm.student_id, qm.academic_year
+			ON DUPLICATE KEY UPDATE marks_obtained = VALUES(marks_obtained), entered_by = VALUES(entered_by)
+		`, request.Section, teacherID, key.componentID, key.studentID, request.AcademicYear)
+		if err != nil {
+			log.Println("Error recomputing component marks:", err)
+			http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		http.Error(w, "Failed to save marks", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Question marks saved successfully",
+		"count":   len(request.Marks),
+	})
+}
+
+// GetCourseAttainment handles GET /course/:courseId/attainment?academic_year=&section=&curriculum_id=
+func GetCourseAttainment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	academicYear := r.URL.Query().Get("academic_year")
+	if academicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+
+	curriculumID, err := curriculumIDParam(r)
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	attainment, err := computeCourseAttainment(courseID, academicYear, r.URL.Query().Get("section"), curriculumID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error computing course attainment:", err)
+		http.Error(w, "Failed to compute attainment", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(attainment)
+}
+
+// GetCurriculumAttainment handles GET /curriculum/:id/attainment?academic_year=
+func GetCurriculumAttainment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	academicYear := r.URL.Query().Get("academic_year")
+	if academicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := computeCurriculumAttainment(curriculumID, academicYear)
+	if err != nil {
+		log.Println("Error computing curriculum attainment:", err)
+		http.Error(w, "Failed to compute attainment", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,58 @@
+package curriculum
+
+import (
+	"database/sql"
+	"server/models"
+	"strings"
+	"testing"
+)
+
+func TestCheckQuestionUpdate(t *testing.T) {
+	// Questions 1 and 2 belong to the component; 7 marks have been entered for question 2
+	highest := map[int]float64{1: 0, 2: 7}
+
+	tests := []struct {
+		name      string
+		questions []models.AssessmentQuestion
+		problem   string
+	}{
+		{"new questions", []models.AssessmentQuestion{{QuestionNo: "1", MaxMarks: 10}, {QuestionNo: "2", MaxMarks: 5}}, ""},
+		{"update and remove", []models.AssessmentQuestion{{ID: 2, QuestionNo: "1", MaxMarks: 8}}, ""},
+		{"max marks down to entered marks", []models.AssessmentQuestion{{ID: 2, QuestionNo: "2", MaxMarks: 7}}, ""},
+		{"question of another component", []models.AssessmentQuestion{{ID: 1, QuestionNo: "1", MaxMarks: 5}, {ID: 9, QuestionNo: "2", MaxMarks: 5}}, "question 9 is not an active question"},
+		{"listed twice", []models.AssessmentQuestion{{ID: 1, QuestionNo: "1a", MaxMarks: 5}, {ID: 1, QuestionNo: "1b", MaxMarks: 5}}, "listed more than once"},
+		{"max marks below entered marks", []models.AssessmentQuestion{{ID: 2, QuestionNo: "2", MaxMarks: 6.5}}, "below marks already entered (7.00)"},
+	}
+	for _, tt := range tests {
+		problem := checkQuestionUpdate(tt.questions, highest)
+		if (tt.problem == "" && problem != "") || !strings.Contains(problem, tt.problem) {
+			t.Errorf("%s: problem = %q, want %q", tt.name, problem, tt.problem)
+		}
+	}
+}
+
+func TestQuestionCOIndex(t *testing.T) {
+	// Outcomes 30, 10 and 20 are now CO1-CO3; outcome 40 was deleted
+	positionOf := outcomePositions([]int{30, 10, 20})
+	id := func(n int64) sql.NullInt64 { return sql.NullInt64{Int64: n, Valid: true} }
+
+	tests := []struct {
+		name      string
+		coIndex   int
+		outcomeID sql.NullInt64
+		want      int
+		reason    string
+	}{
+		{"outcome in place", 1, id(10), 1, ""},
+		{"outcome moved", 0, id(10), 1, ""},
+		{"outcome deleted", 2, id(40), -1, "CO3, which has since been deleted"},
+		{"saved before outcome ids", 2, sql.NullInt64{}, 2, ""},
+		{"saved before outcome ids, out of range", 3, sql.NullInt64{}, -1, "CO4, which does not exist (course has 3 outcomes)"},
+	}
+	for _, tt := range tests {
+		got, reason := questionCOIndex(tt.coIndex, tt.outcomeID, positionOf)
+		if got != tt.want || (tt.reason == "" && reason != "") || !strings.Contains(reason, tt.reason) {
+			t.Errorf("%s: questionCOIndex = %d, %q, want %d, %q", tt.name, got, reason, tt.want, tt.reason)
+		}
+	}
+}
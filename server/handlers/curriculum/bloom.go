@@ -0,0 +1,430 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// bloomLevelNames are the six levels of the revised Bloom's taxonomy
+var bloomLevelNames = map[string]string{
+	"K1": "Remember",
+	"K2": "Understand",
+	"K3": "Apply",
+	"K4": "Analyze",
+	"K5": "Evaluate",
+	"K6": "Create",
+}
+
+// bloomLevelOrder lists the levels in report order
+var bloomLevelOrder = []string{"K1", "K2", "K3", "K4", "K5", "K6"}
+
+// bloomVerbs maps measurable action verbs to the Bloom's level they usually signal.
+// Verbs that appear under several levels in published lists are placed at their most common level
+var bloomVerbs = map[string]string{
+	// K1 Remember
+	"define": "K1", "list": "K1", "name": "K1", "recall": "K1", "state": "K1", "identify": "K1",
+	"label": "K1", "match": "K1", "recognize": "K1", "recognise": "K1", "reproduce": "K1",
+	"memorize": "K1", "enumerate": "K1", "tell": "K1", "locate": "K1", "quote": "K1", "record": "K1",
+	// K2 Understand
+	"explain": "K2", "describe": "K2", "summarize": "K2", "summarise": "K2", "classify": "K2",
+	"discuss": "K2", "interpret": "K2", "illustrate": "K2", "paraphrase": "K2", "outline": "K2",
+	"infer": "K2", "exemplify": "K2", "extend": "K2", "rewrite": "K2", "translate": "K2",
+	"express": "K2", "restate": "K2", "review": "K2", "report": "K2", "represent": "K2",
+	"relate": "K2", "indicate": "K2", "associate": "K2",
+	// K3 Apply
+	"apply": "K3", "demonstrate": "K3", "use": "K3", "implement": "K3", "solve": "K3",
+	"compute": "K3", "calculate": "K3", "execute": "K3", "construct": "K3", "modify": "K3",
+	"prepare": "K3", "produce": "K3", "show": "K3", "sketch": "K3", "operate": "K3",
+	"employ": "K3", "practice": "K3", "determine": "K3", "perform": "K3", "simulate": "K3",
+	"manipulate": "K3", "utilize": "K3", "utilise": "K3", "make use of": "K3", "measure": "K3",
+	"derive": "K3", "program": "K3", "draw": "K3",
+	// K4 Analyze
+	"analyze": "K4", "analyse": "K4", "differentiate": "K4", "distinguish": "K4",
+	"examine": "K4", "compare": "K4", "contrast": "K4", "categorize": "K4", "categorise": "K4",
+	"break down": "K4", "discriminate": "K4", "investigate": "K4", "organize": "K4",
+	"organise": "K4", "attribute": "K4", "deconstruct": "K4", "diagnose": "K4", "inspect": "K4",
+	"test": "K4", "debug": "K4", "experiment": "K4",
+	// K5 Evaluate
+	"evaluate": "K5", "assess": "K5", "judge": "K5", "justify": "K5", "critique": "K5",
+	"appraise": "K5", "argue": "K5", "defend": "K5", "recommend": "K5", "validate": "K5",
+	"verify": "K5", "prioritize": "K5", "prioritise": "K5", "rate": "K5", "select": "K5",
+	"choose": "K5", "critically evaluate": "K5", "estimate": "K5",
+	"optimize": "K5", "optimise": "K5",
+	// K6 Create
+	"design": "K6", "create": "K6", "develop": "K6", "formulate": "K6", "compose": "K6",
+	"plan": "K6", "propose": "K6", "invent": "K6", "generate": "K6", "devise": "K6",
+	"synthesize": "K6", "synthesise": "K6", "build": "K6", "assemble": "K6", "integrate": "K6",
+	"combine": "K6", "author": "K6", "set up": "K6", "architect": "K6",
+}
+
+// unmeasurableVerbs describe internal states that cannot be assessed directly
+var unmeasurableVerbs = map[string]bool{
+	"understand": true, "know": true, "learn": true, "appreciate": true, "realize": true,
+	"realise": true, "comprehend": true, "believe": true, "grasp": true, "familiarize": true,
+	"familiarise": true, "be aware": true, "become familiar": true, "become aware": true,
+	"gain": true, "acquire": true, "study": true, "master": true, "internalize": true,
+	"recognize the importance": true, "be familiar": true, "get": true, "have": true,
+}
+
+// bloomStatementPrefixes are stripped before looking for the leading action verb
+var bloomStatementPrefixes = []string{
+	"students will be able to", "student will be able to", "the students will be able to",
+	"the student will be able to", "students should be able to", "student should be able to",
+	"the students should be able to", "the student should be able to", "learners will be able to",
+	"learner will be able to", "on completion of the course", "upon completion of the course",
+	"at the end of the course", "students will", "student will", "learners will", "will be able to",
+	"be able to", "able to", "ability to", "to",
+}
+
+var bloomNumberingPattern = regexp.MustCompile(`^(co|clo|cob|obj)?\s*\d+\s*[:.\-)]*\s*`)
+
+// extractLeadingVerb returns the leading action verb (or two-word verb phrase) of a statement
+func extractLeadingVerb(text string) string {
+	s := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	s = bloomNumberingPattern.ReplaceAllString(s, "")
+
+	for changed := true; changed; {
+		changed = false
+		s = strings.TrimLeft(s, " ,:;-")
+		for _, prefix := range bloomStatementPrefixes {
+			if strings.HasPrefix(s, prefix+" ") || strings.HasPrefix(s, prefix+",") {
+				s = strings.TrimPrefix(s, prefix)
+				changed = true
+				break
+			}
+		}
+	}
+
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z')
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	for _, n := range []int{3, 2} {
+		if len(words) >= n {
+			phrase := strings.Join(words[:n], " ")
+			if _, ok := bloomVerbs[phrase]; ok || unmeasurableVerbs[phrase] {
+				return phrase
+			}
+		}
+	}
+	return words[0]
+}
+
+// lookupBloomVerb finds a verb in the tables, also trying its base form ("explains" -> "explain")
+func lookupBloomVerb(verb string) (string, string, bool) {
+	candidates := []string{verb}
+	if strings.HasSuffix(verb, "ies") {
+		candidates = append(candidates, strings.TrimSuffix(verb, "ies")+"y")
+	}
+	if strings.HasSuffix(verb, "es") {
+		candidates = append(candidates, strings.TrimSuffix(verb, "es"))
+	}
+	if strings.HasSuffix(verb, "s") {
+		candidates = append(candidates, strings.TrimSuffix(verb, "s"))
+	}
+	for _, c := range candidates {
+		if level, ok := bloomVerbs[c]; ok {
+			return c, level, true
+		}
+		if unmeasurableVerbs[c] {
+			return c, "", false
+		}
+	}
+	return verb, "", true
+}
+
+// analyzeBloomStatement suggests a Bloom's level from the leading verb of a statement
+func analyzeBloomStatement(text string) models.BloomAnalysis {
+	verb := extractLeadingVerb(text)
+	if verb == "" {
+		return models.BloomAnalysis{Warning: "Statement does not start with an action verb"}
+	}
+
+	verb, level, measurable := lookupBloomVerb(verb)
+	analysis := models.BloomAnalysis{Verb: verb, Measurable: measurable}
+	switch {
+	case !measurable:
+		analysis.Warning = fmt.Sprintf("\"%s\" is not measurable; use an observable action verb such as explain, apply or analyze", verb)
+	case level == "":
+		analysis.Warning = fmt.Sprintf("\"%s\" is not a recognised Bloom's action verb", verb)
+	default:
+		analysis.SuggestedLevel = level
+		analysis.LevelName = bloomLevelNames[level]
+	}
+	return analysis
+}
+
+// suggestBloomLevel returns the suggested level of a statement, or NULL when none can be inferred
+func suggestBloomLevel(text string) sql.NullString {
+	level := analyzeBloomStatement(text).SuggestedLevel
+	return sql.NullString{String: level, Valid: level != ""}
+}
+
+// newBloomDistribution returns a distribution with every level present
+func newBloomDistribution() map[string]int {
+	dist := map[string]int{"UNCLASSIFIED": 0}
+	for _, level := range bloomLevelOrder {
+		dist[level] = 0
+	}
+	return dist
+}
+
+// fetchBloomStatements loads active outcomes or objectives of a course with their Bloom's analysis
+func fetchBloomStatements(courseID int, table, textColumn string) ([]models.BloomStatement, error) {
+	rows, err := db.DB.Query(fmt.Sprintf(`
+		SELECT id, position, %s, COALESCE(bloom_level, ''), COALESCE(bloom_level_manual, 0)
+		FROM %s
+		WHERE course_id = ? AND (status = 1 OR status IS NULL)
+		ORDER BY position, id`, textColumn, table), courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statements := []models.BloomStatement{}
+	for rows.Next() {
+		var st models.BloomStatement
+		if err := rows.Scan(&st.ID, &st.Position, &st.Text, &st.BloomLevel, &st.Manual); err != nil {
+			log.Println("Error scanning statement:", err)
+			continue
+		}
+		st.Analysis = analyzeBloomStatement(st.Text)
+		statements = append(statements, st)
+	}
+	return statements, nil
+}
+
+// buildCourseBloomReport collects a course's statements, distributions and warnings
+func buildCourseBloomReport(courseID int) (*models.CourseBloomReport, error) {
+	outcomes, err := fetchBloomStatements(courseID, "course_outcomes", "outcome")
+	if err != nil {
+		return nil, err
+	}
+	objectives, err := fetchBloomStatements(courseID, "course_objectives", "objective")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CourseBloomReport{
+		CourseID:              courseID,
+		Outcomes:              outcomes,
+		Objectives:            objectives,
+		OutcomeDistribution:   newBloomDistribution(),
+		ObjectiveDistribution: newBloomDistribution(),
+		Warnings:              []string{},
+	}
+
+	for _, group := range []struct {
+		label      string
+		statements []models.BloomStatement
+		dist       map[string]int
+	}{
+		{"CO", outcomes, report.OutcomeDistribution},
+		{"Objective ", objectives, report.ObjectiveDistribution},
+	} {
+		for i, st := range group.statements {
+			label := fmt.Sprintf("%s%d", group.label, i+1)
+			if st.BloomLevel == "" {
+				group.dist["UNCLASSIFIED"]++
+			} else {
+				group.dist[st.BloomLevel]++
+			}
+			if st.Analysis.Warning != "" {
+				report.Warnings = append(report.Warnings, label+": "+st.Analysis.Warning)
+			}
+			if st.BloomLevel != "" && st.Analysis.SuggestedLevel != "" && st.BloomLevel != st.Analysis.SuggestedLevel {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: set to %s but \"%s\" suggests %s",
+					label, st.BloomLevel, st.Analysis.Verb, st.Analysis.SuggestedLevel))
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// SuggestBloomLevel handles POST /bloom/suggest
+func SuggestBloomLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req models.BloomSuggestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(analyzeBloomStatement(req.Text))
+}
+
+// GetCourseBloomLevels handles GET /course/:courseId/bloom
+func GetCourseBloomLevels(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	report, err := buildCourseBloomReport(courseID)
+	if err != nil {
+		log.Println("Error building Bloom's report:", err)
+		http.Error(w, "Failed to fetch Bloom's levels", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+// SaveCourseBloomLevels handles PUT /course/:courseId/bloom
+func SaveCourseBloomLevels(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.BloomLevelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	for _, updates := range [][]models.BloomLevelUpdate{req.Outcomes, req.Objectives} {
+		for _, u := range updates {
+			if _, ok := bloomLevelNames[u.BloomLevel]; u.BloomLevel != "" && !ok {
+				http.Error(w, fmt.Sprintf("Invalid bloom_level %q; use K1 to K6", u.BloomLevel), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to save Bloom's levels", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, group := range []struct {
+		table   string
+		updates []models.BloomLevelUpdate
+	}{
+		{"course_outcomes", req.Outcomes},
+		{"course_objectives", req.Objectives},
+	} {
+		for _, u := range group.updates {
+			result, err := tx.Exec(fmt.Sprintf("UPDATE %s SET bloom_level = ?, bloom_level_manual = ? WHERE id = ? AND course_id = ?", group.table),
+				nullIfEmpty(u.BloomLevel), u.BloomLevel != "", u.ID, courseID)
+			if err != nil {
+				log.Println("Error saving Bloom's level:", err)
+				http.Error(w, "Failed to save Bloom's levels", http.StatusInternalServerError)
+				return
+			}
+			if n, _ := result.RowsAffected(); n == 0 {
+				var exists bool
+				tx.QueryRow(fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE id = ? AND course_id = ?)", group.table),
+					u.ID, courseID).Scan(&exists)
+				if !exists {
+					http.Error(w, fmt.Sprintf("Statement %d does not belong to this course", u.ID), http.StatusBadRequest)
+					return
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing Bloom's levels:", err)
+		http.Error(w, "Failed to save Bloom's levels", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := buildCourseBloomReport(courseID)
+	if err != nil {
+		log.Println("Error building Bloom's report:", err)
+		http.Error(w, "Failed to fetch Bloom's levels", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetCurriculumBloomDistribution handles GET /curriculum/:id/bloom-distribution
+func GetCurriculumBloomDistribution(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT c.course_id, c.course_code, c.course_name
+		FROM courses c
+		INNER JOIN curriculum_courses cc ON c.course_id = cc.course_id
+		WHERE cc.curriculum_id = ? AND cc.status = 1 AND c.status = 1
+		ORDER BY c.course_code`, curriculumID)
+	if err != nil {
+		log.Println("Error fetching curriculum courses:", err)
+		http.Error(w, "Failed to compute Bloom's distribution", http.StatusInternalServerError)
+		return
+	}
+	type courseRef struct {
+		ID   int
+		Code string
+		Name string
+	}
+	courses := []courseRef{}
+	for rows.Next() {
+		var c courseRef
+		if err := rows.Scan(&c.ID, &c.Code, &c.Name); err == nil {
+			courses = append(courses, c)
+		}
+	}
+	rows.Close()
+
+	result := models.CurriculumBloomDistribution{
+		CurriculumID:          curriculumID,
+		Courses:               []models.CourseBloomReport{},
+		OutcomeDistribution:   newBloomDistribution(),
+		ObjectiveDistribution: newBloomDistribution(),
+	}
+	for _, c := range courses {
+		report, err := buildCourseBloomReport(c.ID)
+		if err != nil {
+			log.Printf("Error building Bloom's report for course %d: %v", c.ID, err)
+			continue
+		}
+		report.CourseCode = c.Code
+		report.CourseName = c.Name
+		for level, count := range report.OutcomeDistribution {
+			result.OutcomeDistribution[level] += count
+		}
+		for level, count := range report.ObjectiveDistribution {
+			result.ObjectiveDistribution[level] += count
+		}
+		result.WarningCount += len(report.Warnings)
+		result.Courses = append(result.Courses, *report)
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,44 @@
+package curriculum
+
+import "testing"
+
+func TestAnalyzeBloomStatement(t *testing.T) {
+	tests := []struct {
+		text       string
+		verb       string
+		level      string
+		measurable bool
+		warns      bool
+	}{
+		{"Explain the working of PN junction diodes", "explain", "K2", true, false},
+		{"CO1: Students will be able to design a combinational circuit", "design", "K6", true, false},
+		{"2. Analyses the stability of control systems", "analyse", "K4", true, false},
+		{"Upon completion of the course, students will be able to apply Ohm's law", "apply", "K3", true, false},
+		{"Critically evaluate sorting algorithms", "critically evaluate", "K5", true, false},
+		{"Make use of SQL to query relational data", "make use of", "K3", true, false},
+		{"Identifies the parts of a CPU", "identify", "K1", true, false},
+		{"Understand the basics of thermodynamics", "understand", "", false, true},
+		{"Be aware of ethical issues", "be aware", "", false, true},
+		{"Appreciate good software practice", "appreciate", "", false, true},
+		{"Ponder the meaning of entropy", "ponder", "", true, true},
+		{"", "", "", false, true},
+	}
+	for _, tt := range tests {
+		got := analyzeBloomStatement(tt.text)
+		if got.Verb != tt.verb || got.SuggestedLevel != tt.level || got.Measurable != tt.measurable || (got.Warning != "") != tt.warns {
+			t.Errorf("analyzeBloomStatement(%q) = %+v", tt.text, got)
+		}
+		if tt.level != "" && got.LevelName == "" {
+			t.Errorf("analyzeBloomStatement(%q) has no level name", tt.text)
+		}
+	}
+}
+
+func TestSuggestBloomLevel(t *testing.T) {
+	if got := suggestBloomLevel("Develop a web application"); !got.Valid || got.String != "K6" {
+		t.Errorf("suggestBloomLevel = %+v, want K6", got)
+	}
+	if got := suggestBloomLevel("Learn about compilers"); got.Valid {
+		t.Errorf("suggestBloomLevel = %+v, want NULL", got)
+	}
+}
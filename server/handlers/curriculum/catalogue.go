@@ -0,0 +1,594 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// catalogueCourseColumns is the column list scanned by scanCatalogueCourse
+const catalogueCourseColumns = `c.course_id, c.course_code, c.course_name, COALESCE(c.course_type, ''), COALESCE(c.category, ''),
+	COALESCE(c.credit, 0), COALESCE(c.lecture_hrs, 0), COALESCE(c.tutorial_hrs, 0), COALESCE(c.practical_hrs, 0),
+	COALESCE(c.activity_hrs, 0), COALESCE(c.` + "`tw/sl`" + `, 0), COALESCE(c.theory_total_hrs, 0), COALESCE(c.tutorial_total_hrs, 0),
+	COALESCE(c.practical_total_hrs, 0), COALESCE(c.activity_total_hrs, 0), COALESCE(c.total_hrs, 0),
+	COALESCE(c.cia_marks, 0), COALESCE(c.see_marks, 0), COALESCE(c.total_marks, 0),
+	c.version, c.forked_from_course_id, c.owner_curriculum_id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCatalogueCourse scans a row selected with catalogueCourseColumns
+func scanCatalogueCourse(row rowScanner) (models.CatalogueCourse, error) {
+	var c models.CatalogueCourse
+	var forkedFrom, owner sql.NullInt64
+	err := row.Scan(&c.CourseID, &c.CourseCode, &c.CourseName, &c.CourseType, &c.Category,
+		&c.Credit, &c.LectureHrs, &c.TutorialHrs, &c.PracticalHrs,
+		&c.ActivityHrs, &c.TwSlHrs, &c.TheoryTotalHrs, &c.TutorialTotalHrs,
+		&c.PracticalTotalHrs, &c.ActivityTotalHrs, &c.TotalHrs,
+		&c.CIAMarks, &c.SEEMarks, &c.TotalMarks,
+		&c.Version, &forkedFrom, &owner)
+	if err != nil {
+		return c, err
+	}
+	if forkedFrom.Valid {
+		id := int(forkedFrom.Int64)
+		c.ForkedFromID = &id
+	}
+	if owner.Valid {
+		id := int(owner.Int64)
+		c.OwnerCurriculumID = &id
+	}
+	return c, nil
+}
+
+// fetchCourseUsage lists the semester cards and honour verticals that use a course
+func fetchCourseUsage(courseID int) ([]models.CourseUsage, error) {
+	usage := []models.CourseUsage{}
+
+	rows, err := db.DB.Query(`
+		SELECT cur.id, cur.name, COALESCE(nc.card_type, 'semester'), nc.id, nc.semester_number
+		FROM curriculum_courses cc
+		INNER JOIN curriculum cur ON cur.id = cc.curriculum_id
+		LEFT JOIN normal_cards nc ON nc.id = cc.semester_id
+		WHERE cc.course_id = ? AND cc.status = 1 AND cur.status = 1
+		ORDER BY cur.id, nc.semester_number`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var u models.CourseUsage
+		var semesterID, semesterNumber sql.NullInt64
+		if err := rows.Scan(&u.CurriculumID, &u.CurriculumName, &u.CardType, &semesterID, &semesterNumber); err != nil {
+			log.Println("Error scanning course usage:", err)
+			continue
+		}
+		if semesterID.Valid {
+			id := int(semesterID.Int64)
+			u.SemesterID = &id
+		}
+		if semesterNumber.Valid {
+			n := int(semesterNumber.Int64)
+			u.SemesterNumber = &n
+		}
+		usage = append(usage, u)
+	}
+	rows.Close()
+
+	rows, err = db.DB.Query(`
+		SELECT cur.id, cur.name, hv.id, hv.name
+		FROM honour_vertical_courses hvc
+		INNER JOIN honour_verticals hv ON hv.id = hvc.honour_vertical_id
+		INNER JOIN honour_cards hc ON hc.id = hv.honour_card_id
+		INNER JOIN curriculum cur ON cur.id = hc.curriculum_id
+		WHERE hvc.course_id = ? AND hvc.status = 1 AND cur.status = 1
+		ORDER BY cur.id, hv.id`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		u := models.CourseUsage{CardType: "honour"}
+		var verticalID int
+		if err := rows.Scan(&u.CurriculumID, &u.CurriculumName, &verticalID, &u.VerticalName); err != nil {
+			log.Println("Error scanning course usage:", err)
+			continue
+		}
+		u.VerticalID = &verticalID
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
+
+// courseCurriculumIDs returns the distinct curricula that use a course
+func courseCurriculumIDs(courseID int) []int {
+	usage, err := fetchCourseUsage(courseID)
+	if err != nil {
+		log.Println("Error fetching course usage:", err)
+		return nil
+	}
+	ids := []int{}
+	seen := make(map[int]bool)
+	for _, u := range usage {
+		if !seen[u.CurriculumID] {
+			seen[u.CurriculumID] = true
+			ids = append(ids, u.CurriculumID)
+		}
+	}
+	return ids
+}
+
+// copyCourseContent copies the active syllabus, outcomes, experiments and mappings of one course to another
+func copyCourseContent(tx *sql.Tx, sourceID, targetID int) error {
+	// Flat child tables copied row for row
+	flatCopies := []struct{ name, query string }{
+		{"objectives", `INSERT INTO course_objectives (course_id, objective, position, status, bloom_level, bloom_level_manual)
+			SELECT ?, objective, position, 1, bloom_level, bloom_level_manual FROM course_objectives
+			WHERE course_id = ? AND (status = 1 OR status IS NULL)`},
+		{"outcomes", `INSERT INTO course_outcomes (course_id, outcome, position, status, bloom_level, bloom_level_manual)
+			SELECT ?, outcome, position, 1, bloom_level, bloom_level_manual FROM course_outcomes
+			WHERE course_id = ? AND (status = 1 OR status IS NULL)`},
+		{"references", `INSERT INTO course_references (course_id, reference_text, position, status)
+			SELECT ?, reference_text, position, 1 FROM course_references
+			WHERE course_id = ? AND (status = 1 OR status IS NULL)`},
+		{"prerequisites", `INSERT INTO course_prerequisites (course_id, prerequisite, prerequisite_course_id, position)
+			SELECT ?, prerequisite, prerequisite_course_id, position FROM course_prerequisites WHERE course_id = ?`},
+		{"teamwork", `INSERT INTO course_teamwork (course_id, total_hours)
+			SELECT ?, total_hours FROM course_teamwork WHERE course_id = ?`},
+		{"teamwork activities", `INSERT INTO course_teamwork_activities (course_id, activity, position)
+			SELECT ?, activity, position FROM course_teamwork_activities WHERE course_id = ?`},
+		{"self-learning", `INSERT INTO course_selflearning (course_id, total_hours)
+			SELECT ?, total_hours FROM course_selflearning WHERE course_id = ?`},
+	}
+	for _, c := range flatCopies {
+		if _, err := tx.Exec(c.query, targetID, sourceID); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", c.name, err)
+		}
+	}
+
+	// Self-learning topics and their resources
+	type idPair struct{ source, target int64 }
+	rows, err := tx.Query("SELECT id, main_text, position FROM course_selflearning_topics WHERE course_id = ? ORDER BY position", sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to read self-learning topics: %w", err)
+	}
+	type selfLearningTopic struct {
+		id       int64
+		text     string
+		position int
+	}
+	topics := []selfLearningTopic{}
+	for rows.Next() {
+		var t selfLearningTopic
+		if err := rows.Scan(&t.id, &t.text, &t.position); err == nil {
+			topics = append(topics, t)
+		}
+	}
+	rows.Close()
+	for _, t := range topics {
+		result, err := tx.Exec("INSERT INTO course_selflearning_topics (course_id, main_text, position) VALUES (?, ?, ?)",
+			targetID, t.text, t.position)
+		if err != nil {
+			return fmt.Errorf("failed to copy self-learning topic: %w", err)
+		}
+		newID, _ := result.LastInsertId()
+		if _, err := tx.Exec(`INSERT INTO course_selflearning_resources (main_id, internal_text, position)
+			SELECT ?, internal_text, position FROM course_selflearning_resources WHERE main_id = ?`, newID, t.id); err != nil {
+			return fmt.Errorf("failed to copy self-learning resources: %w", err)
+		}
+	}
+
+	// Syllabus modules -> titles -> topics
+	modelIDs := []idPair{}
+	rows, err = tx.Query(`SELECT id FROM syllabus WHERE course_id = ? AND (status = 1 OR status IS NULL) ORDER BY position, id`, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to read syllabus modules: %w", err)
+	}
+	for rows.Next() {
+		var p idPair
+		if err := rows.Scan(&p.source); err == nil {
+			modelIDs = append(modelIDs, p)
+		}
+	}
+	rows.Close()
+	for _, m := range modelIDs {
+		result, err := tx.Exec(`INSERT INTO syllabus (course_id, name, model_name, position, status)
+			SELECT ?, name, model_name, position, 1 FROM syllabus WHERE id = ?`, targetID, m.source)
+		if err != nil {
+			return fmt.Errorf("failed to copy syllabus module: %w", err)
+		}
+		m.target, _ = result.LastInsertId()
+
+		titleIDs := []idPair{}
+		rows, err := tx.Query(`SELECT id FROM syllabus_titles WHERE model_id = ? AND (status = 1 OR status IS NULL) ORDER BY position, id`, m.source)
+		if err != nil {
+			return fmt.Errorf("failed to read syllabus titles: %w", err)
+		}
+		for rows.Next() {
+			var p idPair
+			if err := rows.Scan(&p.source); err == nil {
+				titleIDs = append(titleIDs, p)
+			}
+		}
+		rows.Close()
+		for _, t := range titleIDs {
+			result, err := tx.Exec(`INSERT INTO syllabus_titles (model_id, title, title_name, hours, position, status)
+				SELECT ?, title, title_name, hours, position, 1 FROM syllabus_titles WHERE id = ?`, m.target, t.source)
+			if err != nil {
+				return fmt.Errorf("failed to copy syllabus title: %w", err)
+			}
+			t.target, _ = result.LastInsertId()
+			if _, err := tx.Exec(`INSERT INTO syllabus_topics (title_id, topic, content, position, status)
+				SELECT ?, topic, content, position, 1 FROM syllabus_topics
+				WHERE title_id = ? AND (status = 1 OR status IS NULL)`, t.target, t.source); err != nil {
+				return fmt.Errorf("failed to copy syllabus topics: %w", err)
+			}
+		}
+	}
+
+	// Experiments and their topics
+	experimentIDs := []idPair{}
+	rows, err = tx.Query("SELECT id FROM course_experiments WHERE course_id = ? AND status = 1 ORDER BY experiment_number", sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to read experiments: %w", err)
+	}
+	for rows.Next() {
+		var p idPair
+		if err := rows.Scan(&p.source); err == nil {
+			experimentIDs = append(experimentIDs, p)
+		}
+	}
+	rows.Close()
+	for _, e := range experimentIDs {
+		result, err := tx.Exec(`INSERT INTO course_experiments (course_id, experiment_number, experiment_name, hours, status)
+			SELECT ?, experiment_number, experiment_name, hours, 1 FROM course_experiments WHERE id = ?`, targetID, e.source)
+		if err != nil {
+			return fmt.Errorf("failed to copy experiment: %w", err)
+		}
+		e.target, _ = result.LastInsertId()
+		if _, err := tx.Exec(`INSERT INTO course_experiment_topics (experiment_id, topic_text, topic_order, status)
+			SELECT ?, topic_text, topic_order, 1 FROM course_experiment_topics
+			WHERE experiment_id = ? AND status = 1`, e.target, e.source); err != nil {
+			return fmt.Errorf("failed to copy experiment topics: %w", err)
+		}
+	}
+
+	// CO-PO/PSO mappings, re-pointing outcome_id at the copied outcome in the same position
+	for _, table := range []struct{ name, column string }{
+		{"co_po_mapping", "po_index"},
+		{"co_pso_mapping", "pso_index"},
+	} {
+		if _, err := tx.Exec(fmt.Sprintf(`
+			INSERT INTO %s (course_id, co_index, %s, mapping_value, justification, outcome_id)
+			SELECT ?, m.co_index, m.%s, m.mapping_value, m.justification, n.id
+			FROM %s m
+			LEFT JOIN course_outcomes o ON o.id = m.outcome_id
+			LEFT JOIN course_outcomes n ON n.course_id = ? AND n.position = o.position AND n.status = 1
+			WHERE m.course_id = ?`, table.name, table.column, table.column, table.name),
+			targetID, targetID, sourceID); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", table.name, err)
+		}
+	}
+
+	return nil
+}
+
+// forkCourse creates a curriculum-specific copy of a course, including its syllabus content
+func forkCourse(tx *sql.Tx, sourceID, curriculumID int) (int, error) {
+	result, err := tx.Exec(`
+		INSERT INTO courses (course_code, course_name, course_type, category, credit,
+		                     lecture_hrs, tutorial_hrs, practical_hrs, activity_hrs, `+"`tw/sl`"+`,
+		                     theory_total_hrs, tutorial_total_hrs, practical_total_hrs, activity_total_hrs,
+		                     cia_marks, see_marks, status, version, forked_from_course_id, owner_curriculum_id)
+		SELECT course_code, course_name, course_type, category, credit,
+		       lecture_hrs, tutorial_hrs, practical_hrs, activity_hrs, `+"`tw/sl`"+`,
+		       theory_total_hrs, tutorial_total_hrs, practical_total_hrs, activity_total_hrs,
+		       cia_marks, see_marks, 1, 1, course_id, ?
+		FROM courses WHERE course_id = ?`, curriculumID, sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy course: %w", err)
+	}
+	newID, _ := result.LastInsertId()
+
+	if err := copyCourseContent(tx, sourceID, int(newID)); err != nil {
+		return 0, err
+	}
+	return int(newID), nil
+}
+
+// GetCatalogueCourses handles GET /catalogue/courses
+// Supports ?search= on code or name; curriculum-specific copies are only listed with ?include_forks=true
+func GetCatalogueCourses(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := "SELECT " + catalogueCourseColumns + " FROM courses c WHERE c.status = 1"
+	args := []interface{}{}
+	if r.URL.Query().Get("include_forks") != "true" {
+		query += " AND c.owner_curriculum_id IS NULL"
+	}
+	if search := strings.TrimSpace(r.URL.Query().Get("search")); search != "" {
+		query += " AND (c.course_code LIKE ? OR c.course_name LIKE ?)"
+		args = append(args, "%"+search+"%", "%"+search+"%")
+	}
+	query += " ORDER BY c.course_code, c.course_id"
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching catalogue courses:", err)
+		http.Error(w, "Failed to fetch catalogue", http.StatusInternalServerError)
+		return
+	}
+
+	courses := []models.CatalogueCourse{}
+	for rows.Next() {
+		course, err := scanCatalogueCourse(rows)
+		if err != nil {
+			log.Println("Error scanning catalogue course:", err)
+			continue
+		}
+		courses = append(courses, course)
+	}
+	rows.Close()
+
+	for i := range courses {
+		usage, err := fetchCourseUsage(courses[i].CourseID)
+		if err != nil {
+			log.Println("Error fetching course usage:", err)
+			usage = []models.CourseUsage{}
+		}
+		courses[i].Usage = usage
+	}
+
+	json.NewEncoder(w).Encode(courses)
+}
+
+// GetCatalogueCourse handles GET /catalogue/courses/:id
+func GetCatalogueCourse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	course, err := scanCatalogueCourse(db.DB.QueryRow(
+		"SELECT "+catalogueCourseColumns+" FROM courses c WHERE c.course_id = ? AND c.status = 1", courseID))
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching catalogue course:", err)
+		http.Error(w, "Failed to fetch course", http.StatusInternalServerError)
+		return
+	}
+
+	course.Usage, err = fetchCourseUsage(courseID)
+	if err != nil {
+		log.Println("Error fetching course usage:", err)
+		http.Error(w, "Failed to fetch course usage", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(course)
+}
+
+// CreateCatalogueCourse handles POST /catalogue/courses
+// Creates a shared course that is not yet placed in any curriculum
+func CreateCatalogueCourse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var course models.Course
+	if err := json.NewDecoder(r.Body).Decode(&course); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	course.CourseCode = strings.TrimSpace(course.CourseCode)
+	course.CourseName = strings.TrimSpace(course.CourseName)
+	if course.CourseCode == "" || course.CourseName == "" {
+		http.Error(w, "Course code and name are required", http.StatusBadRequest)
+		return
+	}
+
+	var exists bool
+	err := db.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM courses
+		WHERE course_code = ? AND owner_curriculum_id IS NULL AND status = 1)`, course.CourseCode).Scan(&exists)
+	if err != nil {
+		log.Println("Error checking catalogue course code:", err)
+		http.Error(w, "Failed to create course", http.StatusInternalServerError)
+		return
+	}
+	if exists {
+		http.Error(w, "A catalogue course with this course code already exists", http.StatusConflict)
+		return
+	}
+
+	theoryTotal, tutorialTotal, practicalTotal, activityTotal := computeCourseTotalHours(course.CurriculumTemplate, course)
+	result, err := db.DB.Exec(`
+		INSERT INTO courses (course_code, course_name, course_type, category, credit,
+		                     lecture_hrs, tutorial_hrs, practical_hrs, activity_hrs, `+"`tw/sl`"+`,
+		                     theory_total_hrs, tutorial_total_hrs, practical_total_hrs, activity_total_hrs,
+		                     cia_marks, see_marks, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)`,
+		course.CourseCode, course.CourseName, course.CourseType, course.Category, course.Credit,
+		course.LectureHrs, course.TutorialHrs, course.PracticalHrs, course.ActivityHrs, course.TwSlHrs,
+		theoryTotal, tutorialTotal, practicalTotal, activityTotal,
+		course.CIAMarks, course.SEEMarks)
+	if err != nil {
+		log.Println("Error creating catalogue course:", err)
+		http.Error(w, "Failed to create course", http.StatusInternalServerError)
+		return
+	}
+	courseID, _ := result.LastInsertId()
+
+	created, err := scanCatalogueCourse(db.DB.QueryRow(
+		"SELECT "+catalogueCourseColumns+" FROM courses c WHERE c.course_id = ?", courseID))
+	if err != nil {
+		log.Println("Error fetching created course:", err)
+		http.Error(w, "Failed to fetch course", http.StatusInternalServerError)
+		return
+	}
+	created.Usage = []models.CourseUsage{}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// DeleteCatalogueCourse handles DELETE /catalogue/courses/:id
+// Courses still used by a curriculum cannot be deleted
+func DeleteCatalogueCourse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	usage, err := fetchCourseUsage(courseID)
+	if err != nil {
+		log.Println("Error fetching course usage:", err)
+		http.Error(w, "Failed to delete course", http.StatusInternalServerError)
+		return
+	}
+	if len(usage) > 0 {
+		http.Error(w, fmt.Sprintf("Course is used in %d semester(s) or vertical(s); remove it from them first", len(usage)),
+			http.StatusConflict)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE courses SET status = 0 WHERE course_id = ? AND status = 1", courseID)
+	if err != nil {
+		log.Println("Error deleting catalogue course:", err)
+		http.Error(w, "Failed to delete course", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err := cascadeSoftDeleteCourse(courseID, tx); err != nil {
+		http.Error(w, "Failed to delete course", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit transaction", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Course deleted successfully"})
+}
+
+// ForkCurriculumCourse handles POST /curriculum/:id/course/:courseId/fork
+// Replaces a shared course in one curriculum with a curriculum-specific copy so later edits stay local
+func ForkCurriculumCourse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var owner sql.NullInt64
+	var courseCode string
+	err = db.DB.QueryRow("SELECT course_code, owner_curriculum_id FROM courses WHERE course_id = ? AND status = 1",
+		courseID).Scan(&courseCode, &owner)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching course:", err)
+		http.Error(w, "Failed to fork course", http.StatusInternalServerError)
+		return
+	}
+	if owner.Valid && int(owner.Int64) == curriculumID {
+		http.Error(w, "Course is already specific to this curriculum", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	newID, err := forkCourse(tx, courseID, curriculumID)
+	if err != nil {
+		log.Println("Error forking course:", err)
+		http.Error(w, "Failed to fork course", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := tx.Exec("UPDATE curriculum_courses SET course_id = ? WHERE curriculum_id = ? AND course_id = ? AND status = 1",
+		newID, curriculumID, courseID)
+	if err != nil {
+		log.Println("Error relinking forked course:", err)
+		http.Error(w, "Failed to fork course", http.StatusInternalServerError)
+		return
+	}
+	semesterLinks, _ := result.RowsAffected()
+
+	result, err = tx.Exec(`
+		UPDATE honour_vertical_courses SET course_id = ?
+		WHERE course_id = ? AND status = 1 AND honour_vertical_id IN (
+			SELECT hv.id FROM honour_verticals hv
+			INNER JOIN honour_cards hc ON hc.id = hv.honour_card_id
+			WHERE hc.curriculum_id = ?)`, newID, courseID, curriculumID)
+	if err != nil {
+		log.Println("Error relinking forked course:", err)
+		http.Error(w, "Failed to fork course", http.StatusInternalServerError)
+		return
+	}
+	verticalLinks, _ := result.RowsAffected()
+
+	if semesterLinks+verticalLinks == 0 {
+		http.Error(w, "Course is not used by this curriculum", http.StatusNotFound)
+		return
+	}
+
+	if err := logCurriculumDiffTx(tx, curriculumID, logEntity{"course", newID, opCreate}, "Course Forked",
+		fmt.Sprintf("Replaced shared course %s with a curriculum-specific copy", courseCode), requestUser(r),
+		map[string]map[string]interface{}{"course_id": {"old": courseID, "new": newID}}); err != nil {
+		log.Println("Error logging course fork:", err)
+		http.Error(w, "Failed to fork course", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit transaction", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Course forked successfully",
+		"course_id": newID,
+	})
+}
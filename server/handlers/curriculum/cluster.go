@@ -257,6 +257,9 @@ func AddDepartmentToCluster(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id, _ := result.LastInsertId()
+	logCurriculumChange(req.DepartmentID, logEntity{"cluster_membership", clusterID, opCreate}, "Cluster Joined",
+		fmt.Sprintf("Added to cluster %d", clusterID), requestUser(r), nil, clusterLogState(clusterID))
+
 	response := models.ClusterDepartment{
 		ID:           int(id),
 		ClusterID:    clusterID,
@@ -286,6 +289,8 @@ func RemoveDepartmentFromCluster(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := clusterLogState(clusterID)
+
 	// Start a transaction to ensure all operations succeed or fail together
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -332,6 +337,15 @@ func RemoveDepartmentFromCluster(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Log the membership change as part of the transaction
+	if err := logCurriculumChangeTx(tx, deptID, logEntity{"cluster_membership", clusterID, opDelete}, "Cluster Left",
+		fmt.Sprintf("Removed from cluster %d; received items are now owned by this curriculum", clusterID), requestUser(r), before, nil); err != nil {
+		log.Println("Error logging cluster removal:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to remove department from cluster"})
+		return
+	}
+
 	// Commit the transaction
 	if err := tx.Commit(); err != nil {
 		log.Println("Error committing transaction:", err)
@@ -357,6 +371,8 @@ func DeleteCluster(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := clusterLogState(clusterID)
+
 	query := "DELETE FROM clusters WHERE id = ?"
 	result, err := db.DB.Exec(query, clusterID)
 	if err != nil {
@@ -373,9 +389,34 @@ func DeleteCluster(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	members, _ := before["members"].([]int)
+	for _, curriculumID := range members {
+		logCurriculumChange(curriculumID, logEntity{"cluster_membership", clusterID, opDelete}, "Cluster Deleted",
+			fmt.Sprintf("Cluster %d was deleted", clusterID), requestUser(r), before, nil)
+	}
+
 	json.NewEncoder(w).Encode(map[string]string{"message": "Cluster deleted successfully"})
 }
 
+// clusterLogState is the name and member curricula of a cluster for the activity log
+func clusterLogState(clusterID int) map[string]interface{} {
+	var name string
+	db.DB.QueryRow("SELECT name FROM clusters WHERE id = ?", clusterID).Scan(&name)
+
+	members := []int{}
+	rows, err := db.DB.Query("SELECT curriculum_id FROM cluster_departments WHERE cluster_id = ? ORDER BY curriculum_id", clusterID)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var curriculumID int
+			if rows.Scan(&curriculumID) == nil {
+				members = append(members, curriculumID)
+			}
+		}
+	}
+	return map[string]interface{}{"cluster_id": clusterID, "cluster": name, "members": members}
+}
+
 // convertReceivedDataToOwned converts all received data to owned data for a department
 // This transfers ownership of all shared items to the receiving department
 func convertReceivedDataToOwned(tx *sql.Tx, deptID int) error {
@@ -0,0 +1,761 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// errCourseVersionUnchanged is returned when publishing a course that has not changed since its latest version
+var errCourseVersionUnchanged = errors.New("course has not changed since its latest version")
+
+// curriculumCourseIDsQuery selects the ids of every course a curriculum uses in a semester card or honour vertical.
+// It takes the curriculum id twice
+const curriculumCourseIDsQuery = `
+	SELECT course_id FROM curriculum_courses WHERE curriculum_id = ? AND status = 1
+	UNION
+	SELECT hvc.course_id FROM honour_vertical_courses hvc
+	INNER JOIN honour_verticals hv ON hv.id = hvc.honour_vertical_id
+	INNER JOIN honour_cards hc ON hc.id = hv.honour_card_id
+	WHERE hc.curriculum_id = ? AND hvc.status = 1`
+
+// fetchExperimentsForSnapshot retrieves the active experiments of a course with their topics
+func fetchExperimentsForSnapshot(courseID int) ([]models.Experiment, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, course_id, experiment_number, experiment_name, COALESCE(hours, 0)
+		FROM course_experiments
+		WHERE course_id = ? AND status = 1
+		ORDER BY experiment_number`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	experiments := []models.Experiment{}
+	for rows.Next() {
+		var exp models.Experiment
+		if err := rows.Scan(&exp.ID, &exp.CourseID, &exp.ExperimentNumber, &exp.ExperimentName, &exp.Hours); err == nil {
+			experiments = append(experiments, exp)
+		}
+	}
+	rows.Close()
+
+	for i := range experiments {
+		experiments[i].Topics = []string{}
+		topicRows, err := db.DB.Query(`
+			SELECT topic_text FROM course_experiment_topics
+			WHERE experiment_id = ? AND status = 1
+			ORDER BY topic_order`, experiments[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		for topicRows.Next() {
+			var topic string
+			if err := topicRows.Scan(&topic); err == nil {
+				experiments[i].Topics = append(experiments[i].Topics, topic)
+			}
+		}
+		topicRows.Close()
+	}
+	return experiments, nil
+}
+
+// buildCourseSnapshot captures the current content of a course
+func buildCourseSnapshot(courseID int) (*models.CourseSnapshot, error) {
+	course, err := scanCatalogueCourse(db.DB.QueryRow(
+		"SELECT "+catalogueCourseColumns+" FROM courses c WHERE c.course_id = ?", courseID))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.CourseSnapshot{Course: course.Course}
+	if snapshot.Objectives, err = fetchObjectives(courseID); err != nil {
+		return nil, err
+	}
+	if snapshot.Outcomes, err = fetchOutcomes(courseID); err != nil {
+		return nil, err
+	}
+	if snapshot.Prerequisites, err = fetchPrerequisites(courseID); err != nil {
+		return nil, err
+	}
+	if snapshot.ReferenceList, err = fetchReferences(courseID); err != nil {
+		return nil, err
+	}
+	snapshot.Teamwork, _ = fetchTeamwork(courseID)
+	snapshot.SelfLearning, _ = fetchSelfLearning(courseID)
+	snapshot.Models = fetchModelsForPDF(courseID)
+	if snapshot.Experiments, err = fetchExperimentsForSnapshot(courseID); err != nil {
+		return nil, err
+	}
+	snapshot.COPOMapping, snapshot.COPSOMapping, snapshot.Justifications = fetchCourseMappingsForPDF(courseID)
+	return snapshot, nil
+}
+
+// latestCourseVersion returns the highest published version of a course, or 0 when none is published
+func latestCourseVersion(courseID int) (int, error) {
+	var version int
+	err := db.DB.QueryRow("SELECT COALESCE(MAX(version), 0) FROM course_versions WHERE course_id = ?", courseID).Scan(&version)
+	return version, err
+}
+
+// loadCourseVersion retrieves a published version of a course with its snapshot
+func loadCourseVersion(courseID, version int) (*models.CourseVersion, error) {
+	v := &models.CourseVersion{}
+	var note, createdBy sql.NullString
+	var snapshotJSON string
+	err := db.DB.QueryRow(`
+		SELECT id, course_id, version, note, created_by, created_at, snapshot
+		FROM course_versions WHERE course_id = ? AND version = ?`, courseID, version).Scan(
+		&v.ID, &v.CourseID, &v.Version, &note, &createdBy, &v.CreatedAt, &snapshotJSON)
+	if err != nil {
+		return nil, err
+	}
+	v.Note = note.String
+	v.CreatedBy = createdBy.String
+
+	v.Snapshot = &models.CourseSnapshot{}
+	if err := json.Unmarshal([]byte(snapshotJSON), v.Snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot of course %d version %d: %w", courseID, version, err)
+	}
+	return v, nil
+}
+
+// publishCourseVersion stores the current state of a course as its next version. The course row is
+// locked so concurrent publishes get consecutive versions. The first publish pins every curriculum
+// already using the course to it, so their content stops following later live edits
+func publishCourseVersion(courseID int, note, createdBy string) (*models.CourseVersion, error) {
+	if createdBy == "" {
+		createdBy = "System"
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the course so a concurrent publish of it waits for this one
+	var courseVersion int
+	if err := tx.QueryRow("SELECT COALESCE(version, 0) FROM courses WHERE course_id = ? FOR UPDATE", courseID).Scan(&courseVersion); err != nil {
+		return nil, err
+	}
+	var latest int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM course_versions WHERE course_id = ?", courseID).Scan(&latest); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := buildCourseSnapshot(courseID)
+	if err != nil {
+		return nil, err
+	}
+	if latest > 0 {
+		previous, err := loadCourseVersion(courseID, latest)
+		if err != nil {
+			return nil, err
+		}
+		if len(diffCourseSnapshots(previous.Snapshot, snapshot)) == 0 {
+			return nil, errCourseVersionUnchanged
+		}
+	}
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	version := latest + 1
+	if _, err := tx.Exec(`
+		INSERT INTO course_versions (course_id, version, snapshot, note, created_by)
+		VALUES (?, ?, ?, ?, ?)`, courseID, version, string(snapshotJSON), nullIfEmpty(note), createdBy); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE courses SET version = ? WHERE course_id = ?", version, courseID); err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Published version %d of course %s", version, snapshot.Course.CourseCode)
+	if latest == 0 {
+		description += " and pinned this curriculum to it"
+	}
+	for _, id := range courseCurriculumIDs(courseID) {
+		if latest == 0 {
+			if _, err := tx.Exec("INSERT IGNORE INTO curriculum_course_versions (curriculum_id, course_id, version) VALUES (?, ?, ?)",
+				id, courseID, version); err != nil {
+				return nil, err
+			}
+		}
+		if err := logCurriculumDiffTx(tx, id, logEntity{}, "Course Version Published", description, createdBy, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return loadCourseVersion(courseID, version)
+}
+
+// toJSONMap round-trips a value through JSON so snapshots can be compared field by field
+func toJSONMap(v interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	if data, err := json.Marshal(v); err == nil {
+		json.Unmarshal(data, &m)
+	}
+	return m
+}
+
+// diffJSONFields records every key whose value differs between old and new, except the skipped ones
+func diffJSONFields(diff map[string]map[string]interface{}, old, new map[string]interface{}, skip map[string]bool) {
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			new[key] = nil
+		}
+	}
+	for key, value := range new {
+		if skip[key] {
+			continue
+		}
+		if !reflect.DeepEqual(old[key], value) {
+			diff[key] = map[string]interface{}{"old": old[key], "new": value}
+		}
+	}
+}
+
+// diffCourseSnapshots compares two snapshots in the {"field": {"old": ..., "new": ...}} form used by curriculum logs.
+// Course detail fields are compared one by one; syllabus sections are compared as a whole
+func diffCourseSnapshots(from, to *models.CourseSnapshot) map[string]map[string]interface{} {
+	diff := make(map[string]map[string]interface{})
+	fromMap, toMapped := toJSONMap(from), toJSONMap(to)
+	fromCourse, _ := fromMap["course"].(map[string]interface{})
+	toCourse, _ := toMapped["course"].(map[string]interface{})
+	if fromCourse == nil {
+		fromCourse = map[string]interface{}{}
+	}
+	if toCourse == nil {
+		toCourse = map[string]interface{}{}
+	}
+	diffJSONFields(diff, fromCourse, toCourse, map[string]bool{"id": true})
+	diffJSONFields(diff, fromMap, toMapped, map[string]bool{"course": true})
+	return diff
+}
+
+// pinnedCourseVersion returns the version of a course a curriculum is pinned to, or 0 when it follows the live course
+func pinnedCourseVersion(curriculumID, courseID int) (int, error) {
+	var version int
+	err := db.DB.QueryRow("SELECT version FROM curriculum_course_versions WHERE curriculum_id = ? AND course_id = ?",
+		curriculumID, courseID).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, err
+}
+
+// courseSnapshotForCurriculum returns the course content a curriculum should show:
+// the pinned version when there is one, otherwise the live course
+func courseSnapshotForCurriculum(curriculumID, courseID int) (*models.CourseSnapshot, int, error) {
+	pinned, err := pinnedCourseVersion(curriculumID, courseID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pinned > 0 {
+		v, err := loadCourseVersion(courseID, pinned)
+		if err == nil {
+			return v.Snapshot, pinned, nil
+		}
+		log.Printf("Error loading pinned version %d of course %d, using live course: %v", pinned, courseID, err)
+	}
+	snapshot, err := buildCourseSnapshot(courseID)
+	return snapshot, 0, err
+}
+
+// pinnedCourseSnapshot returns the version of a course a curriculum is pinned to, or nil when the
+// curriculum follows the live course or curriculumID is 0
+func pinnedCourseSnapshot(curriculumID, courseID int) (*models.CourseSnapshot, int, error) {
+	if curriculumID == 0 {
+		return nil, 0, nil
+	}
+	pinned, err := pinnedCourseVersion(curriculumID, courseID)
+	if err != nil || pinned == 0 {
+		return nil, 0, err
+	}
+	v, err := loadCourseVersion(courseID, pinned)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v.Snapshot, pinned, nil
+}
+
+// curriculumIDParam reads the optional ?curriculum_id a course endpoint is viewed through; 0 when absent
+func curriculumIDParam(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("curriculum_id")
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
+
+// snapshotMappingMatrix turns a snapshot mapping keyed "co_index-outcome_index" into co_index -> outcome index -> value
+func snapshotMappingMatrix(values map[string]int) map[int]map[int]int {
+	matrix := make(map[int]map[int]int)
+	for key, value := range values {
+		var coIndex, outcomeIndex int
+		if _, err := fmt.Sscanf(key, "%d-%d", &coIndex, &outcomeIndex); err != nil {
+			continue
+		}
+		if matrix[coIndex] == nil {
+			matrix[coIndex] = make(map[int]int)
+		}
+		matrix[coIndex][outcomeIndex] = value
+	}
+	return matrix
+}
+
+// snapshotMappingJustifications keys the justifications of a snapshot like the mapping editor does,
+// "co_index-outcome_index", one map for the POs and one for the PSOs
+func snapshotMappingJustifications(notes []models.MappingNotePDF) (map[string]string, map[string]string) {
+	po := make(map[string]string)
+	pso := make(map[string]string)
+	for _, note := range notes {
+		var outcomeIndex int
+		if _, err := fmt.Sscanf(note.Outcome, "PSO%d", &outcomeIndex); err == nil {
+			pso[fmt.Sprintf("%d-%d", note.CO-1, outcomeIndex)] = note.Justification
+		} else if _, err := fmt.Sscanf(note.Outcome, "PO%d", &outcomeIndex); err == nil {
+			po[fmt.Sprintf("%d-%d", note.CO-1, outcomeIndex)] = note.Justification
+		}
+	}
+	return po, pso
+}
+
+// snapshotCourseSyllabus turns the syllabus of a pinned version into the shape of the syllabus editor
+func snapshotCourseSyllabus(courseID int, template string, snapshot *models.CourseSnapshot, pinned int) models.CourseSyllabusResponse {
+	resp := models.CourseSyllabusResponse{CurriculumTemplate: template, PinnedVersion: pinned}
+	resp.Header = models.Syllabus{
+		ID:            courseID,
+		CourseID:      courseID,
+		Objectives:    snapshot.Objectives,
+		Outcomes:      snapshot.Outcomes,
+		ReferenceList: snapshot.ReferenceList,
+		Prerequisites: snapshot.Prerequisites,
+		Teamwork:      snapshot.Teamwork,
+		SelfLearning:  snapshot.SelfLearning,
+	}
+	resp.Models = []models.SyllabusModel{}
+	for _, m := range snapshot.Models {
+		model := models.SyllabusModel{ID: m.ID, CourseID: courseID, ModelName: m.ModelName, Position: m.Position, Titles: []models.SyllabusTitle{}}
+		for _, t := range m.Titles {
+			title := models.SyllabusTitle{ID: t.ID, ModelID: m.ID, TitleName: t.TitleName, Hours: t.Hours, Position: t.Position, Topics: []models.SyllabusTopic{}}
+			for _, topic := range t.Topics {
+				title.Topics = append(title.Topics, models.SyllabusTopic{ID: topic.ID, TitleID: t.ID, Topic: topic.Topic, Position: topic.Position})
+			}
+			model.Titles = append(model.Titles, title)
+		}
+		resp.Models = append(resp.Models, model)
+	}
+	if template == "2022" {
+		resp.Experiments = snapshot.Experiments
+	}
+	return resp
+}
+
+// applyCourseSnapshotDetails overwrites the course details of a semester listing with those of a snapshot
+func applyCourseSnapshotDetails(course *models.CourseWithDetails, snapshot models.Course) {
+	course.CourseCode = snapshot.CourseCode
+	course.CourseName = snapshot.CourseName
+	course.CourseType = snapshot.CourseType
+	course.Category = snapshot.Category
+	course.Credit = snapshot.Credit
+	course.LectureHrs = snapshot.LectureHrs
+	course.TutorialHrs = snapshot.TutorialHrs
+	course.PracticalHrs = snapshot.PracticalHrs
+	course.ActivityHrs = snapshot.ActivityHrs
+	course.TwSlHrs = snapshot.TwSlHrs
+	course.TheoryTotalHrs = snapshot.TheoryTotalHrs
+	course.TutorialTotalHrs = snapshot.TutorialTotalHrs
+	course.PracticalTotalHrs = snapshot.PracticalTotalHrs
+	course.ActivityTotalHrs = snapshot.ActivityTotalHrs
+	course.TotalHrs = snapshot.TotalHrs
+	course.CIAMarks = snapshot.CIAMarks
+	course.SEEMarks = snapshot.SEEMarks
+	course.TotalMarks = snapshot.TotalMarks
+}
+
+// curriculumUsesCourse reports whether a course is placed in one of a curriculum's semesters or honour verticals
+func curriculumUsesCourse(curriculumID, courseID int) (bool, error) {
+	var exists bool
+	err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM ("+curriculumCourseIDsQuery+") used WHERE course_id = ?)",
+		curriculumID, curriculumID, courseID).Scan(&exists)
+	return exists, err
+}
+
+// GetCourseVersions handles GET /course/:courseId/versions
+func GetCourseVersions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, course_id, version, COALESCE(note, ''), COALESCE(created_by, ''), created_at
+		FROM course_versions WHERE course_id = ?
+		ORDER BY version DESC`, courseID)
+	if err != nil {
+		log.Println("Error fetching course versions:", err)
+		http.Error(w, "Failed to fetch course versions", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	versions := []models.CourseVersion{}
+	for rows.Next() {
+		var v models.CourseVersion
+		if err := rows.Scan(&v.ID, &v.CourseID, &v.Version, &v.Note, &v.CreatedBy, &v.CreatedAt); err != nil {
+			log.Println("Error scanning course version:", err)
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	json.NewEncoder(w).Encode(versions)
+}
+
+// PublishCourseVersion handles POST /course/:courseId/versions
+// Snapshots the course's details, syllabus, outcomes, experiments and mappings as its next version
+func PublishCourseVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.PublishCourseVersionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	version, err := publishCourseVersion(courseID, req.Note, requestUser(r))
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err == errCourseVersionUnchanged {
+		http.Error(w, "Course has not changed since its latest version", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error publishing course version:", err)
+		http.Error(w, "Failed to publish course version", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(version)
+}
+
+// GetCourseVersion handles GET /course/:courseId/versions/:version
+func GetCourseVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+
+	v, err := loadCourseVersion(courseID, version)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course version not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching course version:", err)
+		http.Error(w, "Failed to fetch course version", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(v)
+}
+
+// GetCourseVersionDiff handles GET /course/:courseId/versions/diff?from=&to=
+// Without to, the from version is compared against the current state of the course
+func GetCourseVersionDiff(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	fromVersion, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid from version", http.StatusBadRequest)
+		return
+	}
+
+	from, err := loadCourseVersion(courseID, fromVersion)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course version not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching course version:", err)
+		http.Error(w, "Failed to compare course versions", http.StatusInternalServerError)
+		return
+	}
+
+	result := models.CourseVersionDiff{CourseID: courseID, FromVersion: fromVersion}
+	var to *models.CourseSnapshot
+	if toParam := strings.TrimSpace(r.URL.Query().Get("to")); toParam != "" {
+		toVersion, err := strconv.Atoi(toParam)
+		if err != nil {
+			http.Error(w, "Invalid to version", http.StatusBadRequest)
+			return
+		}
+		v, err := loadCourseVersion(courseID, toVersion)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Course version not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Println("Error fetching course version:", err)
+			http.Error(w, "Failed to compare course versions", http.StatusInternalServerError)
+			return
+		}
+		to = v.Snapshot
+		result.ToVersion = &toVersion
+	} else {
+		to, err = buildCourseSnapshot(courseID)
+		if err != nil {
+			log.Println("Error building course snapshot:", err)
+			http.Error(w, "Failed to compare course versions", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	result.Changes = diffCourseSnapshots(from.Snapshot, to)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetCurriculumCourseVersions handles GET /curriculum/:id/course-versions
+func GetCurriculumCourseVersions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT c.course_id, c.course_code, c.course_name, ccv.version,
+		       (SELECT COALESCE(MAX(v.version), 0) FROM course_versions v WHERE v.course_id = c.course_id)
+		FROM courses c
+		LEFT JOIN curriculum_course_versions ccv ON ccv.course_id = c.course_id AND ccv.curriculum_id = ?
+		WHERE c.course_id IN (`+curriculumCourseIDsQuery+`)
+		ORDER BY c.course_code`, curriculumID, curriculumID, curriculumID)
+	if err != nil {
+		log.Println("Error fetching curriculum course versions:", err)
+		http.Error(w, "Failed to fetch course versions", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	results := []models.CurriculumCourseVersion{}
+	for rows.Next() {
+		var v models.CurriculumCourseVersion
+		var pinned sql.NullInt64
+		if err := rows.Scan(&v.CourseID, &v.CourseCode, &v.CourseName, &pinned, &v.LatestVersion); err != nil {
+			log.Println("Error scanning curriculum course version:", err)
+			continue
+		}
+		if pinned.Valid {
+			version := int(pinned.Int64)
+			v.PinnedVersion = &version
+			v.UpgradeAvailable = version < v.LatestVersion
+		}
+		results = append(results, v)
+	}
+
+	json.NewEncoder(w).Encode(results)
+}
+
+// PinCurriculumCourseVersion handles PUT /curriculum/:id/course-versions
+func PinCurriculumCourseVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.PinCourseVersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	used, err := curriculumUsesCourse(curriculumID, req.CourseID)
+	if err != nil {
+		log.Println("Error checking curriculum course:", err)
+		http.Error(w, "Failed to pin course version", http.StatusInternalServerError)
+		return
+	}
+	if !used {
+		http.Error(w, "Course is not used by this curriculum", http.StatusBadRequest)
+		return
+	}
+
+	if req.Version <= 0 {
+		entry := newActivityLogEntry(curriculumID, logEntity{"course_pin", req.CourseID, opDelete}, "Course Version Unpinned",
+			fmt.Sprintf("Course %d now follows the live course", req.CourseID), requestUser(r), nil)
+		if err := execWithActivityLog(entry, "DELETE FROM curriculum_course_versions WHERE curriculum_id = ? AND course_id = ?",
+			curriculumID, req.CourseID); err != nil {
+			log.Println("Error unpinning course version:", err)
+			http.Error(w, "Failed to unpin course version", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"message": "Course version unpinned"})
+		return
+	}
+
+	v, err := loadCourseVersion(req.CourseID, req.Version)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course version not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching course version:", err)
+		http.Error(w, "Failed to pin course version", http.StatusInternalServerError)
+		return
+	}
+
+	previous, _ := pinnedCourseVersion(curriculumID, req.CourseID)
+	entry := newActivityLogEntry(curriculumID, logEntity{"course_pin", req.CourseID, opUpdate}, "Course Version Pinned",
+		fmt.Sprintf("Pinned course %s to version %d", v.Snapshot.Course.CourseCode, req.Version), requestUser(r),
+		map[string]map[string]interface{}{"version": {"old": previous, "new": req.Version}})
+	if err := execWithActivityLog(entry, `
+		INSERT INTO curriculum_course_versions (curriculum_id, course_id, version)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE version = VALUES(version)`, curriculumID, req.CourseID, req.Version); err != nil {
+		log.Println("Error pinning course version:", err)
+		http.Error(w, "Failed to pin course version", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Course version pinned"})
+}
+
+// UpgradeCurriculumCourseVersion handles POST /curriculum/:id/course-versions/:courseId/upgrade
+// Moves the curriculum's pin to a newer version and returns what changed between the two
+func UpgradeCurriculumCourseVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpgradeCourseVersionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	current, err := pinnedCourseVersion(curriculumID, courseID)
+	if err != nil {
+		log.Println("Error fetching pinned course version:", err)
+		http.Error(w, "Failed to upgrade course version", http.StatusInternalServerError)
+		return
+	}
+	if current == 0 {
+		http.Error(w, "Curriculum is not pinned to a version of this course", http.StatusBadRequest)
+		return
+	}
+
+	target := req.Version
+	if target == 0 {
+		if target, err = latestCourseVersion(courseID); err != nil {
+			log.Println("Error fetching latest course version:", err)
+			http.Error(w, "Failed to upgrade course version", http.StatusInternalServerError)
+			return
+		}
+	}
+	if target <= current {
+		http.Error(w, fmt.Sprintf("Curriculum already uses version %d; no newer version to upgrade to", current), http.StatusBadRequest)
+		return
+	}
+
+	from, err := loadCourseVersion(courseID, current)
+	if err != nil {
+		log.Println("Error fetching course version:", err)
+		http.Error(w, "Failed to upgrade course version", http.StatusInternalServerError)
+		return
+	}
+	to, err := loadCourseVersion(courseID, target)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course version not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching course version:", err)
+		http.Error(w, "Failed to upgrade course version", http.StatusInternalServerError)
+		return
+	}
+
+	diff := models.CourseVersionDiff{
+		CourseID:    courseID,
+		FromVersion: current,
+		ToVersion:   &target,
+		Changes:     diffCourseSnapshots(from.Snapshot, to.Snapshot),
+	}
+	if req.DryRun {
+		json.NewEncoder(w).Encode(diff)
+		return
+	}
+
+	entry := newActivityLogEntry(curriculumID, logEntity{"course_pin", courseID, opUpdate}, "Course Version Upgraded",
+		fmt.Sprintf("Upgraded course %s from version %d to %d", to.Snapshot.Course.CourseCode, current, target), requestUser(r), diff.Changes)
+	if err := execWithActivityLog(entry, "UPDATE curriculum_course_versions SET version = ? WHERE curriculum_id = ? AND course_id = ?",
+		target, curriculumID, courseID); err != nil {
+		log.Println("Error upgrading course version:", err)
+		http.Error(w, "Failed to upgrade course version", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(diff)
+}
@@ -0,0 +1,85 @@
+package curriculum
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"server/models"
+	"testing"
+)
+
+func TestSnapshotMappingMatrix(t *testing.T) {
+	got := snapshotMappingMatrix(map[string]int{"0-1": 3, "0-12": 1, "2-4": 2, "bad": 1})
+	want := map[int]map[int]int{0: {1: 3, 12: 1}, 2: {4: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshotMappingMatrix = %v, want %v", got, want)
+	}
+}
+
+func TestSnapshotMappingJustifications(t *testing.T) {
+	po, pso := snapshotMappingJustifications([]models.MappingNotePDF{
+		{CO: 1, Outcome: "PO3", Value: 3, Justification: "Designs circuits"},
+		{CO: 2, Outcome: "PSO1", Value: 2, Justification: "Embedded work"},
+		{CO: 2, Outcome: "PO12", Value: 1, Justification: "Lifelong learning"},
+	})
+	wantPO := map[string]string{"0-3": "Designs circuits", "1-12": "Lifelong learning"}
+	wantPSO := map[string]string{"1-1": "Embedded work"}
+	if !reflect.DeepEqual(po, wantPO) || !reflect.DeepEqual(pso, wantPSO) {
+		t.Errorf("snapshotMappingJustifications = %v, %v, want %v, %v", po, pso, wantPO, wantPSO)
+	}
+}
+
+func TestSnapshotCourseSyllabus(t *testing.T) {
+	snapshot := &models.CourseSnapshot{
+		Outcomes: []string{"Explain transistors"},
+		Models: []models.SyllabusModelPDF{{
+			ID: 4, ModelName: "Module 1", Position: 1,
+			Titles: []models.SyllabusTitlePDF{{
+				ID: 9, TitleName: "Diodes", Hours: 6, Position: 1,
+				Topics: []models.SyllabusTopicPDF{{ID: 21, Topic: "PN junction", Position: 1}},
+			}},
+		}},
+		Experiments: []models.Experiment{{ID: 2, ExperimentName: "Rectifier"}},
+	}
+
+	tests := []struct {
+		template        string
+		wantExperiments int
+	}{
+		{"2026", 0},
+		{"2022", 1},
+	}
+	for _, tt := range tests {
+		resp := snapshotCourseSyllabus(7, tt.template, snapshot, 3)
+		if resp.PinnedVersion != 3 || resp.Header.CourseID != 7 || len(resp.Header.Outcomes) != 1 {
+			t.Errorf("%s: header = %+v, pinned %d", tt.template, resp.Header, resp.PinnedVersion)
+		}
+		if len(resp.Models) != 1 || resp.Models[0].CourseID != 7 || len(resp.Models[0].Titles) != 1 {
+			t.Fatalf("%s: models = %+v", tt.template, resp.Models)
+		}
+		title := resp.Models[0].Titles[0]
+		if title.ModelID != 4 || len(title.Topics) != 1 || title.Topics[0].TitleID != 9 || title.Topics[0].Topic != "PN junction" {
+			t.Errorf("%s: title = %+v", tt.template, title)
+		}
+		if len(resp.Experiments) != tt.wantExperiments {
+			t.Errorf("%s: %d experiments, want %d", tt.template, len(resp.Experiments), tt.wantExperiments)
+		}
+	}
+}
+
+func TestCurriculumIDParam(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    int
+		wantErr bool
+	}{
+		{"/course/1/mapping", 0, false},
+		{"/course/1/mapping?curriculum_id=5", 5, false},
+		{"/course/1/mapping?curriculum_id=abc", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := curriculumIDParam(httptest.NewRequest("GET", tt.url, nil))
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("%s: curriculumIDParam = %d, %v", tt.url, got, err)
+		}
+	}
+}
@@ -90,6 +90,38 @@ func cascadeSoftDeleteCourse(courseID int, tx *sql.Tx) error {
 	return nil
 }
 
+// courseUsedElsewhere is a condition that holds while course is linked to an active semester or honour vertical.
+// otherSemesterLink and otherHonourLink narrow the curriculum_courses (ucc) and honour_vertical_courses (uhvc, uhv)
+// links that count, so a delete can leave out the links it is about to remove
+func courseUsedElsewhere(course, otherSemesterLink, otherHonourLink string) string {
+	return `(EXISTS (
+			SELECT 1 FROM curriculum_courses ucc
+			INNER JOIN normal_cards unc ON unc.id = ucc.semester_id AND (unc.status = 1 OR unc.status IS NULL)
+			WHERE ucc.course_id = ` + course + ` AND ucc.status = 1 AND ` + otherSemesterLink + `
+		) OR EXISTS (
+			SELECT 1 FROM honour_vertical_courses uhvc
+			INNER JOIN honour_verticals uhv ON uhv.id = uhvc.honour_vertical_id AND uhv.status = 1
+			INNER JOIN honour_cards uhc ON uhc.id = uhv.honour_card_id AND uhc.status = 1
+			WHERE uhvc.course_id = ` + course + ` AND uhvc.status = 1 AND ` + otherHonourLink + `
+		))`
+}
+
+// softDeleteCourseIfUnused soft-deletes a course and its children once nothing links it any more.
+// It runs after the caller has removed its own link, so a course shared with other curricula stays active for them
+func softDeleteCourseIfUnused(tx *sql.Tx, courseID int) error {
+	var used bool
+	if err := tx.QueryRow("SELECT "+courseUsedElsewhere("?", "TRUE", "TRUE"), courseID, courseID).Scan(&used); err != nil {
+		return err
+	}
+	if used {
+		return nil
+	}
+	if _, err := tx.Exec("UPDATE courses SET status = 0 WHERE course_id = ? AND status = 1", courseID); err != nil {
+		return err
+	}
+	return cascadeSoftDeleteCourse(courseID, tx)
+}
+
 // GetSemesters retrieves all semesters for a regulation
 func GetSemesters(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -216,8 +248,8 @@ func CreateSemester(w http.ResponseWriter, r *http.Request) {
 	} else {
 		logName = "New Card"
 	}
-	LogCurriculumActivity(curriculumID, "Card Added",
-		"Added "+logName, "System")
+	logCurriculumChange(curriculumID, logEntity{"semester", card.ID, opCreate}, "Card Added",
+		"Added "+logName, requestUser(r), nil, card)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(card)
@@ -242,6 +274,17 @@ func DeleteSemester(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Capture the card as it was for the activity log
+	var card models.Semester
+	err = db.DB.QueryRow("SELECT id, curriculum_id, semester_number, COALESCE(card_type, 'semester') FROM normal_cards WHERE id = ?", semesterID).
+		Scan(&card.ID, &card.CurriculumID, &card.SemesterNumber, &card.CardType)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println("Error fetching semester:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete semester"})
+		return
+	}
+
 	// Start a transaction for soft-delete cascade
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -252,6 +295,14 @@ func DeleteSemester(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
+	// Keep what is about to be deleted in the recycle bin
+	if err := recordDeletion(tx, "semester", semesterID, 0, requestUser(r)); err != nil {
+		log.Println("Error recording deleted semester:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete semester"})
+		return
+	}
+
 	// Soft delete the normal card (semester)
 	query := "UPDATE normal_cards SET status = 0 WHERE id = ? AND status = 1"
 	result, err := tx.Exec(query, semesterID)
@@ -269,9 +320,9 @@ func DeleteSemester(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Soft-delete all courses linked to this semester (keep curriculum_courses mappings intact)
+	// Soft-delete the courses linked to this semester that no other curriculum uses (keep curriculum_courses mappings intact)
 	rows, err := tx.Query(`
-		SELECT course_id FROM curriculum_courses WHERE semester_id = ?
+		SELECT course_id FROM curriculum_courses WHERE semester_id = ? AND status = 1
 	`, semesterID)
 	if err != nil {
 		log.Println("Error fetching semester courses:", err)
@@ -294,22 +345,27 @@ func DeleteSemester(w http.ResponseWriter, r *http.Request) {
 	}
 	rows.Close()
 
-	// Soft-delete each course and cascade to its children
+	// Soft-delete each course no longer used anywhere and cascade to its children
 	for _, courseID := range courseIDs {
-		_, err = tx.Exec("UPDATE courses SET status = 0 WHERE course_id = ? AND status = 1", courseID)
-		if err != nil {
+		if err := softDeleteCourseIfUnused(tx, courseID); err != nil {
 			log.Println("Error soft-deleting course:", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to courses"})
 			return
 		}
-		// Cascade soft-delete to course children (in DeleteSemester transaction)
-		if err := cascadeSoftDeleteCourse(courseID, tx); err != nil {
-			log.Println("Error cascading course soft-delete:", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to course children"})
-			return
-		}
+	}
+
+	// Log the deletion as part of the transaction
+	logName := strings.Title(card.CardType)
+	if card.SemesterNumber != nil {
+		logName += " " + strconv.Itoa(*card.SemesterNumber)
+	}
+	if err := logCurriculumChangeTx(tx, card.CurriculumID, logEntity{"semester", semesterID, opDelete}, "Card Deleted",
+		fmt.Sprintf("Deleted %s with %d course(s)", logName, len(courseIDs)), requestUser(r), card, nil); err != nil {
+		log.Println("Error logging semester deletion:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete semester"})
+		return
 	}
 
 	// Commit the transaction
@@ -355,7 +411,7 @@ func GetSemesterCourses(w http.ResponseWriter, r *http.Request) {
 		       COALESCE(rc.count_towards_limit, 1) as count_towards_limit
 		FROM courses c
 		INNER JOIN curriculum_courses rc ON c.course_id = rc.course_id
-		WHERE rc.curriculum_id = ? AND rc.semester_id = ? AND c.status = 1
+		WHERE rc.curriculum_id = ? AND rc.semester_id = ? AND rc.status = 1 AND c.status = 1
 		ORDER BY c.course_code
 	`
 
@@ -387,11 +443,43 @@ func GetSemesterCourses(w http.ResponseWriter, r *http.Request) {
 		course.CurriculumTemplate = curriculumTemplate
 		courses = append(courses, course)
 	}
+	rows.Close()
+
+	// Courses pinned to a published version show that version's details
+	for i := range courses {
+		pinned, err := pinnedCourseVersion(curriculumID, courses[i].CourseID)
+		if err != nil || pinned == 0 {
+			continue
+		}
+		v, err := loadCourseVersion(courses[i].CourseID, pinned)
+		if err != nil {
+			log.Println("Error loading pinned course version:", err)
+			continue
+		}
+		applyCourseSnapshotDetails(&courses[i], v.Snapshot.Course)
+		courses[i].PinnedVersion = &pinned
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(courses)
 }
 
+// writeCatalogueCourseConflict answers 409 when a course code already exists in the shared catalogue,
+// letting the caller choose between sharing the catalogue course and forking a copy
+func writeCatalogueCourseConflict(w http.ResponseWriter, courseID int, courseCode, courseName string) {
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": fmt.Sprintf("Course %s already exists in the course catalogue. Choose whether to link the shared course or fork a curriculum-specific copy.", courseCode),
+		"existing_course": map[string]interface{}{
+			"id":          courseID,
+			"course_code": courseCode,
+			"course_name": courseName,
+			"curricula":   courseCurriculumIDs(courseID),
+		},
+		"link_modes": []string{"link", "fork"},
+	})
+}
+
 // AddCourseToSemester adds a new course and links it to a semester
 func AddCourseToSemester(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -459,7 +547,7 @@ func AddCourseToSemester(w http.ResponseWriter, r *http.Request) {
 		creditQuery := `SELECT SUM(c.credit) FROM courses c 
 		                INNER JOIN curriculum_courses cc ON c.course_id = cc.course_id 
 		                INNER JOIN normal_cards nc ON cc.semester_id = nc.id
-		                WHERE cc.curriculum_id = ? 
+		                WHERE cc.curriculum_id = ? AND cc.status = 1
 		                AND nc.card_type = 'semester'
 		                AND (cc.count_towards_limit IS NULL OR cc.count_towards_limit = 1)`
 		err = db.DB.QueryRow(creditQuery, curriculumID).Scan(&currentCredits)
@@ -502,16 +590,19 @@ func AddCourseToSemester(w http.ResponseWriter, r *http.Request) {
 	var existingCourseID int
 	checkQuery := `SELECT c.course_id FROM courses c 
 	               INNER JOIN curriculum_courses cc ON c.course_id = cc.course_id 
-	               WHERE c.course_code = ? AND cc.curriculum_id = ?`
+	               WHERE c.course_code = ? AND cc.curriculum_id = ? AND cc.status = 1`
 	err = db.DB.QueryRow(checkQuery, course.CourseCode, curriculumID).Scan(&existingCourseID)
 
 	var courseID int
 	var wasReused bool
 	if err == sql.ErrNoRows {
-		// Course code doesn't exist in this curriculum, check if it exists globally
+		// Course code doesn't exist in this curriculum, check if it exists in the shared catalogue
 		var globalCourseID int
-		globalCheckQuery := "SELECT course_id FROM courses WHERE course_code = ?"
-		globalErr := db.DB.QueryRow(globalCheckQuery, course.CourseCode).Scan(&globalCourseID)
+		var globalCourseName string
+		globalCheckQuery := `SELECT course_id, course_name FROM courses
+		                     WHERE course_code = ? AND owner_curriculum_id IS NULL
+		                     ORDER BY status DESC, course_id LIMIT 1`
+		globalErr := db.DB.QueryRow(globalCheckQuery, course.CourseCode).Scan(&globalCourseID, &globalCourseName)
 
 		if globalErr == sql.ErrNoRows {
 			// Course doesn't exist globally - create new course
@@ -538,13 +629,36 @@ func AddCourseToSemester(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create course"})
 			return
-		} else {
-			// Course exists globally but not in this curriculum - reuse the existing course
+		} else if course.LinkMode == "link" {
+			// Course exists globally but not in this curriculum - share the existing catalogue course
 			courseID = globalCourseID
 			// Reactivate the course if it was soft-deleted
 			db.DB.Exec("UPDATE courses SET status = 1 WHERE course_id = ?", globalCourseID)
 			wasReused = true
 			log.Printf("Reusing existing course %s (ID: %d) for curriculum %d", course.CourseCode, globalCourseID, curriculumID)
+		} else if course.LinkMode == "fork" {
+			// Course exists globally - give this curriculum its own copy so edits stay local
+			tx, err := db.DB.Begin()
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create course"})
+				return
+			}
+			courseID, err = forkCourse(tx, globalCourseID, curriculumID)
+			if err == nil {
+				err = tx.Commit()
+			}
+			if err != nil {
+				tx.Rollback()
+				log.Println("Error forking course:", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create course"})
+				return
+			}
+			log.Printf("Forked course %s (ID: %d) as %d for curriculum %d", course.CourseCode, globalCourseID, courseID, curriculumID)
+		} else {
+			writeCatalogueCourseConflict(w, globalCourseID, course.CourseCode, globalCourseName)
+			return
 		}
 	} else if err != nil {
 		log.Println("Error checking existing course in curriculum:", err)
@@ -559,8 +673,9 @@ func AddCourseToSemester(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Link course to curriculum and semester (countTowardsLimit already set above)
-	linkQuery := "INSERT INTO curriculum_courses (curriculum_id, semester_id, course_id, count_towards_limit) VALUES (?, ?, ?, ?)"
+	// Link course to curriculum and semester (countTowardsLimit already set above), reviving a link removed earlier
+	linkQuery := `INSERT INTO curriculum_courses (curriculum_id, semester_id, course_id, count_towards_limit) VALUES (?, ?, ?, ?)
+	              ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), status = 1, count_towards_limit = VALUES(count_towards_limit)`
 	result, err := db.DB.Exec(linkQuery, curriculumID, semesterID, courseID, countTowardsLimit)
 	if err != nil {
 		log.Println("Error linking course:", err)
@@ -606,8 +721,9 @@ func AddCourseToSemester(w http.ResponseWriter, r *http.Request) {
 	fullCourse.RegCourseID = int(regCourseID)
 
 	// Log the activity
-	LogCurriculumActivity(curriculumID, "Course Added",
-		"Added course "+course.CourseCode+" - "+course.CourseName+" to Semester "+strconv.Itoa(semesterID), "System")
+	logCurriculumChange(curriculumID, logEntity{"course", courseID, opCreate}, "Course Added",
+		"Added course "+course.CourseCode+" - "+course.CourseName+" to Semester "+strconv.Itoa(semesterID), requestUser(r),
+		nil, courseLogState(courseID, "semester_id", semesterID))
 
 	w.WriteHeader(http.StatusCreated)
 
@@ -617,6 +733,8 @@ func AddCourseToSemester(w http.ResponseWriter, r *http.Request) {
 	}
 	if wasReused {
 		response["message"] = fmt.Sprintf("Course %s already exists in another curriculum and has been reused. The course details from the database have been applied.", course.CourseCode)
+	} else if course.LinkMode == "fork" {
+		response["message"] = fmt.Sprintf("A curriculum-specific copy of course %s has been created from the catalogue.", course.CourseCode)
 	}
 	json.NewEncoder(w).Encode(response)
 }
@@ -654,9 +772,15 @@ func RemoveCourseFromSemester(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if mapping exists
-	var mappingExists bool
-	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM curriculum_courses WHERE curriculum_id = ? AND semester_id = ? AND course_id = ?)", curriculumID, semesterID, courseID).Scan(&mappingExists)
+	// Find the link of the course to this semester
+	var linkID int
+	err = db.DB.QueryRow("SELECT id FROM curriculum_courses WHERE curriculum_id = ? AND semester_id = ? AND course_id = ? AND status = 1",
+		curriculumID, semesterID, courseID).Scan(&linkID)
+	if err == sql.ErrNoRows {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Course not found in semester"})
+		return
+	}
 	if err != nil {
 		log.Println("Error checking course mapping:", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -664,20 +788,32 @@ func RemoveCourseFromSemester(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !mappingExists {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Course not found in semester"})
-		return
-	}
-
 	// Get course name for logging
 	var courseName string
 	db.DB.QueryRow("SELECT course_name FROM courses WHERE course_id = ?", courseID).Scan(&courseName)
+	before := courseLogState(courseID, "semester_id", semesterID)
 
-	// Soft-delete the course (keep the mapping intact)
-	result, err := db.DB.Exec("UPDATE courses SET status = 0 WHERE course_id = ? AND status = 1", courseID)
+	tx, err := db.DB.Begin()
 	if err != nil {
-		log.Println("Error soft-deleting course:", err)
+		log.Println("Error starting transaction:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Keep what is about to be deleted in the recycle bin
+	if err := recordDeletion(tx, "curriculum_course", linkID, curriculumID, requestUser(r)); err != nil {
+		log.Println("Error recording removed course:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to remove course"})
+		return
+	}
+
+	// Soft-delete this curriculum's link; other curricula linking the same course keep it
+	result, err := tx.Exec("UPDATE curriculum_courses SET status = 0 WHERE id = ? AND status = 1", linkID)
+	if err != nil {
+		log.Println("Error soft-deleting course mapping:", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to remove course"})
 		return
@@ -685,21 +821,34 @@ func RemoveCourseFromSemester(w http.ResponseWriter, r *http.Request) {
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		// Course might already be soft-deleted, which is fine
-		log.Printf("Course %d was already inactive", courseID)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Course not found in semester"})
+		return
 	}
 
-	// Cascade soft-delete to all course children
-	if err := cascadeSoftDeleteCourse(courseID, nil); err != nil {
-		log.Println("Error cascading course soft-delete:", err)
+	// Retire the course itself only when nothing else uses it
+	if err := softDeleteCourseIfUnused(tx, courseID); err != nil {
+		log.Println("Error soft-deleting course:", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to course children"})
 		return
 	}
 
-	// Log the activity
-	LogCurriculumActivity(curriculumID, "Course Removed",
-		"Removed course "+courseName+" from Semester "+strconv.Itoa(semesterID), "System")
+	// Log the activity as part of the transaction
+	if err := logCurriculumChangeTx(tx, curriculumID, logEntity{"course", courseID, opDelete}, "Course Removed",
+		"Removed course "+courseName+" from Semester "+strconv.Itoa(semesterID), requestUser(r), before, nil); err != nil {
+		log.Println("Error logging removed course:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to remove course"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to commit transaction"})
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Course removed successfully"})
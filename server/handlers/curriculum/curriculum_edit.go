@@ -69,7 +69,7 @@ func UpdateCurriculum(w http.ResponseWriter, r *http.Request) {
 	// Prevent switching template when courses already exist
 	if oldTemplate != "" && oldTemplate != updateData.CurriculumTemplate {
 		var courseCount int
-		_ = db.DB.QueryRow("SELECT COUNT(*) FROM curriculum_courses WHERE curriculum_id = ?", curriculumID).Scan(&courseCount)
+		_ = db.DB.QueryRow("SELECT COUNT(*) FROM curriculum_courses WHERE curriculum_id = ? AND status = 1", curriculumID).Scan(&courseCount)
 		var honourCourseCount int
 		_ = db.DB.QueryRow(`
 			SELECT COUNT(*) FROM honour_vertical_courses hvc
@@ -109,14 +109,202 @@ func UpdateCurriculum(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(diff) > 0 {
-		LogCurriculumActivityWithDiff(curriculumID, "Curriculum Updated",
-			"Updated curriculum details", "System", diff)
+		logCurriculumDiff(curriculumID, logEntity{"curriculum", curriculumID, opUpdate}, "Curriculum Updated",
+			"Updated curriculum details", requestUser(r), diff)
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Curriculum updated successfully"})
 }
 
+// UpdateCurriculumPublishStatus sets whether a curriculum is DRAFT or PUBLISHED; exports of a
+// draft carry a DRAFT watermark
+func UpdateCurriculumPublishStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid curriculum ID"})
+		return
+	}
+
+	var body struct {
+		PublishStatus string `json:"publish_status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || (body.PublishStatus != "DRAFT" && body.PublishStatus != "PUBLISHED") {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "publish_status must be DRAFT or PUBLISHED"})
+		return
+	}
+
+	var oldStatus string
+	err = db.DB.QueryRow("SELECT publish_status FROM curriculum WHERE id = ?", curriculumID).Scan(&oldStatus)
+	if err == sql.ErrNoRows {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Curriculum not found"})
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching curriculum publish status:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch curriculum data"})
+		return
+	}
+
+	if oldStatus != body.PublishStatus {
+		if _, err := db.DB.Exec("UPDATE curriculum SET publish_status = ? WHERE id = ?", body.PublishStatus, curriculumID); err != nil {
+			log.Println("Error updating curriculum publish status:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update curriculum"})
+			return
+		}
+		logCurriculumDiff(curriculumID, logEntity{"curriculum", curriculumID, opUpdate}, "Curriculum Publish Status Changed",
+			fmt.Sprintf("Changed publish status to %s", body.PublishStatus), requestUser(r),
+			map[string]map[string]interface{}{"publish_status": {"old": oldStatus, "new": body.PublishStatus}})
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Publish status updated successfully", "publish_status": body.PublishStatus})
+}
+
+// UpdateCurriculumDepartment assigns a curriculum to a department, whose branding and PDF
+// templates its exports use; a null department_id falls back to the institution's
+func UpdateCurriculumDepartment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid curriculum ID"})
+		return
+	}
+
+	var body struct {
+		DepartmentID *int `json:"department_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+	if body.DepartmentID != nil {
+		var exists int
+		if err := db.DB.QueryRow("SELECT 1 FROM departments WHERE id = ?", *body.DepartmentID).Scan(&exists); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Department not found"})
+			return
+		}
+	}
+
+	var oldDepartment sql.NullInt64
+	err = db.DB.QueryRow("SELECT department_id FROM curriculum WHERE id = ?", curriculumID).Scan(&oldDepartment)
+	if err == sql.ErrNoRows {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Curriculum not found"})
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching curriculum department:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch curriculum data"})
+		return
+	}
+
+	if _, err := db.DB.Exec("UPDATE curriculum SET department_id = ? WHERE id = ?", body.DepartmentID, curriculumID); err != nil {
+		log.Println("Error updating curriculum department:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update curriculum"})
+		return
+	}
+
+	var oldValue, newValue interface{}
+	if oldDepartment.Valid {
+		oldValue = oldDepartment.Int64
+	}
+	if body.DepartmentID != nil {
+		newValue = *body.DepartmentID
+	}
+	if fmt.Sprint(oldValue) != fmt.Sprint(newValue) {
+		logCurriculumDiff(curriculumID, logEntity{"curriculum", curriculumID, opUpdate}, "Curriculum Department Changed",
+			"Changed the department of the curriculum", requestUser(r),
+			map[string]map[string]interface{}{"department_id": {"old": oldValue, "new": newValue}})
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Curriculum department updated successfully"})
+}
+
+// UpdateCurriculumRegulation links a curriculum to the regulation document printed at the start of
+// its book, or unlinks it with a null regulation_id
+func UpdateCurriculumRegulation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid curriculum ID"})
+		return
+	}
+
+	var body struct {
+		RegulationID *int `json:"regulation_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+	if body.RegulationID != nil {
+		var exists int
+		if err := db.DB.QueryRow("SELECT 1 FROM regulations WHERE id = ?", *body.RegulationID).Scan(&exists); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Regulation not found"})
+			return
+		}
+	}
+
+	var oldRegulation sql.NullInt64
+	err = db.DB.QueryRow("SELECT regulation_id FROM curriculum WHERE id = ?", curriculumID).Scan(&oldRegulation)
+	if err == sql.ErrNoRows {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Curriculum not found"})
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching curriculum regulation:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch curriculum data"})
+		return
+	}
+
+	if _, err := db.DB.Exec("UPDATE curriculum SET regulation_id = ? WHERE id = ?", body.RegulationID, curriculumID); err != nil {
+		log.Println("Error updating curriculum regulation:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update curriculum"})
+		return
+	}
+
+	var oldValue, newValue interface{}
+	if oldRegulation.Valid {
+		oldValue = oldRegulation.Int64
+	}
+	if body.RegulationID != nil {
+		newValue = *body.RegulationID
+	}
+	if fmt.Sprint(oldValue) != fmt.Sprint(newValue) {
+		logCurriculumDiff(curriculumID, logEntity{"curriculum", curriculumID, opUpdate}, "Curriculum Regulation Changed",
+			"Changed the regulation document of the curriculum", requestUser(r),
+			map[string]map[string]interface{}{"regulation_id": {"old": oldValue, "new": newValue}})
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Curriculum regulation updated successfully"})
+}
+
 // UpdateSemester updates semester name/number
 func UpdateSemester(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -198,8 +386,8 @@ func UpdateSemester(w http.ResponseWriter, r *http.Request) {
 		diff := map[string]map[string]interface{}{
 			"semester_number": {"old": oldSemesterNumber, "new": updateData.SemesterNumber},
 		}
-		LogCurriculumActivityWithDiff(curriculumID, "Semester Updated",
-			fmt.Sprintf("Updated Semester %d to Semester %d", oldSemesterNumber, updateData.SemesterNumber), "System", diff)
+		logCurriculumDiff(curriculumID, logEntity{"semester", semesterID, opUpdate}, "Semester Updated",
+			fmt.Sprintf("Updated Semester %d to Semester %d", oldSemesterNumber, updateData.SemesterNumber), requestUser(r), diff)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -251,7 +439,7 @@ func UpdateCourse(w http.ResponseWriter, r *http.Request) {
 
 	// Get curriculum_id from curriculum_courses
 	var curriculumID int
-	err = db.DB.QueryRow("SELECT curriculum_id FROM curriculum_courses WHERE course_id = ? LIMIT 1", courseID).Scan(&curriculumID)
+	err = db.DB.QueryRow("SELECT curriculum_id FROM curriculum_courses WHERE course_id = ? AND status = 1 LIMIT 1", courseID).Scan(&curriculumID)
 	if err != nil && err != sql.ErrNoRows {
 		log.Println("Error fetching curriculum_id:", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -273,38 +461,7 @@ func UpdateCourse(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Calculate total hours based on template and course type
-	var theoryTotal, tutorialTotal, practicalTotal, activityTotal int
-	if curriculumTemplate == "2026" {
-		switch course.CourseType {
-		case "Theory":
-			theoryTotal = course.LectureHrs * 15
-			tutorialTotal = course.TutorialHrs * 15
-			activityTotal = course.ActivityHrs * 15
-			practicalTotal = 0
-		case "Lab":
-			theoryTotal = 0
-			tutorialTotal = 0
-			activityTotal = 0
-			practicalTotal = course.PracticalHrs * 15
-		case "Theory&Lab":
-			theoryTotal = course.LectureHrs * 15
-			tutorialTotal = course.TutorialHrs * 15
-			practicalTotal = course.PracticalHrs * 15
-			activityTotal = 0
-		default:
-			// Default to calculating all
-			theoryTotal = course.LectureHrs * 15
-			tutorialTotal = course.TutorialHrs * 15
-			practicalTotal = course.PracticalHrs * 15
-			activityTotal = course.ActivityHrs * 15
-		}
-	} else {
-		// For 2022 or other templates, calculate all as before
-		theoryTotal = course.LectureHrs * 15
-		tutorialTotal = course.TutorialHrs * 15
-		practicalTotal = course.PracticalHrs * 15
-		activityTotal = course.ActivityHrs * 15
-	}
+	theoryTotal, tutorialTotal, practicalTotal, activityTotal := computeCourseTotalHours(curriculumTemplate, course)
 
 	// Update course - calculate total hours (total_hrs and total_marks are GENERATED columns)
 	course.TotalMarks = course.CIAMarks + course.SEEMarks
@@ -360,9 +517,17 @@ func UpdateCourse(w http.ResponseWriter, r *http.Request) {
 		diff["see_marks"] = map[string]interface{}{"old": oldCourse.SEEMarks, "new": course.SEEMarks}
 	}
 
-	if len(diff) > 0 && curriculumID > 0 {
-		LogCurriculumActivityWithDiff(curriculumID, "Course Updated",
-			fmt.Sprintf("Updated course: %s - %s", course.CourseCode, course.CourseName), "System", diff)
+	if oldCourse.TwSlHrs != course.TwSlHrs {
+		diff["tw/sl"] = map[string]interface{}{"old": oldCourse.TwSlHrs, "new": course.TwSlHrs}
+	}
+
+	if len(diff) > 0 {
+		// Record the change in every curriculum sharing the course; curricula pinned to a
+		// published version keep showing that version until they upgrade
+		for _, id := range courseCurriculumIDs(courseID) {
+			logCurriculumDiff(id, logEntity{"course", courseID, opUpdate}, "Course Updated",
+				fmt.Sprintf("Updated course: %s - %s", course.CourseCode, course.CourseName), requestUser(r), diff)
+		}
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -449,3 +614,20 @@ func UpdateCurriculumCourse(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Curriculum course link updated successfully"})
 }
+
+// computeCourseTotalHours derives the semester hour totals of a course from its weekly L-T-P-A hours.
+// The 2026 template only counts the components that apply to the course type
+func computeCourseTotalHours(curriculumTemplate string, course models.Course) (theoryTotal, tutorialTotal, practicalTotal, activityTotal int) {
+	if curriculumTemplate == "2026" {
+		switch course.CourseType {
+		case "Theory":
+			return course.LectureHrs * 15, course.TutorialHrs * 15, 0, course.ActivityHrs * 15
+		case "Lab":
+			return 0, 0, course.PracticalHrs * 15, 0
+		case "Theory&Lab":
+			return course.LectureHrs * 15, course.TutorialHrs * 15, course.PracticalHrs * 15, 0
+		}
+	}
+	// For 2022 or other templates (and other course types), calculate all
+	return course.LectureHrs * 15, course.TutorialHrs * 15, course.PracticalHrs * 15, course.ActivityHrs * 15
+}
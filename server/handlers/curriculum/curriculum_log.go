@@ -1,16 +1,37 @@
 package curriculum
 
 import (
+	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
+	"reflect"
 	"server/db"
 	"server/models"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+// Operations recorded on curriculum logs
+const (
+	opCreate  = "create"
+	opUpdate  = "update"
+	opDelete  = "delete"
+	opShare   = "share"
+	opUnshare = "unshare"
+	opRestore = "restore"
+)
+
+// logEntity identifies the row a logged change touched
+type logEntity struct {
+	Type      string
+	ID        int
+	Operation string
+}
+
 // CreateCurriculumLog handles POST /curriculum/:id/log
 func CreateCurriculumLog(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -33,9 +54,10 @@ func CreateCurriculumLog(w http.ResponseWriter, r *http.Request) {
 
 	// Insert log entry
 	result, err := db.DB.Exec(`
-		INSERT INTO curriculum_logs (curriculum_id, action, description, changed_by)
-		VALUES (?, ?, ?, ?)
-	`, logEntry.CurriculumID, logEntry.Action, logEntry.Description, logEntry.ChangedBy)
+		INSERT INTO curriculum_logs (curriculum_id, action, description, changed_by, entity_type, entity_id, operation)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, logEntry.CurriculumID, logEntry.Action, logEntry.Description, logEntry.ChangedBy,
+		nullIfEmpty(logEntry.EntityType), logEntry.EntityID, nullIfEmpty(logEntry.Operation))
 
 	if err != nil {
 		log.Println("Error creating log entry:", err)
@@ -50,6 +72,7 @@ func CreateCurriculumLog(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetCurriculumLogs handles GET /curriculum/:id/logs
+// Optional filters: entity_type, entity_id, operation, field (a changed field), since, until (RFC 3339 or YYYY-MM-DD) and limit
 func GetCurriculumLogs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -60,12 +83,58 @@ func GetCurriculumLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query(`
-		SELECT id, curriculum_id, action, description, changed_by, diff, created_at
+	query := `
+		SELECT id, curriculum_id, action, description, changed_by, entity_type, entity_id, operation, diff, created_at
 		FROM curriculum_logs
-		WHERE curriculum_id = ?
-		ORDER BY created_at DESC
-	`, curriculumID)
+		WHERE curriculum_id = ?`
+	args := []interface{}{curriculumID}
+
+	params := r.URL.Query()
+	if entityType := params.Get("entity_type"); entityType != "" {
+		query += " AND entity_type = ?"
+		args = append(args, entityType)
+	}
+	if value := params.Get("entity_id"); value != "" {
+		entityID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid entity ID", http.StatusBadRequest)
+			return
+		}
+		query += " AND entity_id = ?"
+		args = append(args, entityID)
+	}
+	if operation := params.Get("operation"); operation != "" {
+		query += " AND operation = ?"
+		args = append(args, operation)
+	}
+	if field := params.Get("field"); field != "" {
+		query += " AND EXISTS (SELECT 1 FROM curriculum_log_changes c WHERE c.log_id = curriculum_logs.id AND c.field = ?)"
+		args = append(args, field)
+	}
+	for _, bound := range []struct{ param, cond string }{{"since", " AND created_at >= ?"}, {"until", " AND created_at <= ?"}} {
+		value := params.Get(bound.param)
+		if value == "" {
+			continue
+		}
+		at, err := parseLogTime(value)
+		if err != nil {
+			http.Error(w, "Invalid "+bound.param+" time", http.StatusBadRequest)
+			return
+		}
+		query += bound.cond
+		args = append(args, at)
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+
+	rows, err := db.DB.Query(query, args...)
 
 	if err != nil {
 		log.Println("Error fetching logs:", err)
@@ -77,13 +146,21 @@ func GetCurriculumLogs(w http.ResponseWriter, r *http.Request) {
 	var logs []models.CurriculumLog
 	for rows.Next() {
 		var logEntry models.CurriculumLog
+		var entityType, operation sql.NullString
+		var entityID sql.NullInt64
 		var diffData []byte
 		err := rows.Scan(&logEntry.ID, &logEntry.CurriculumID, &logEntry.Action,
-			&logEntry.Description, &logEntry.ChangedBy, &diffData, &logEntry.CreatedAt)
+			&logEntry.Description, &logEntry.ChangedBy, &entityType, &entityID, &operation, &diffData, &logEntry.CreatedAt)
 		if err != nil {
 			log.Println("Error scanning log entry:", err)
 			continue
 		}
+		logEntry.EntityType = entityType.String
+		logEntry.Operation = operation.String
+		if entityID.Valid {
+			id := int(entityID.Int64)
+			logEntry.EntityID = &id
+		}
 		if len(diffData) > 0 {
 			logEntry.Diff = json.RawMessage(diffData)
 		}
@@ -97,34 +174,286 @@ func GetCurriculumLogs(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(logs)
 }
 
-// Helper function to create log entries (non-blocking)
+// parseLogTime accepts an RFC 3339 timestamp or a plain date
+func parseLogTime(value string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// Helper function to create log entries (queued)
 func LogCurriculumActivity(curriculumID int, action, description, changedBy string) {
 	LogCurriculumActivityWithDiff(curriculumID, action, description, changedBy, nil)
 }
 
-// Helper function to create log entries with diff (non-blocking)
+// Helper function to create log entries with diff (queued)
 func LogCurriculumActivityWithDiff(curriculumID int, action, description, changedBy string, diff interface{}) {
-	go func() {
-		if changedBy == "" {
-			changedBy = "System"
+	logCurriculumDiff(curriculumID, logEntity{}, action, description, changedBy, diff)
+}
+
+// logCurriculumChange queues the field-by-field difference between the before and after state of an entity.
+// A nil before means the entity was created, a nil after that it was deleted; updates that change nothing are not logged
+func logCurriculumChange(curriculumID int, entity logEntity, action, description, changedBy string, before, after interface{}) {
+	if diff, ok := changeDiff(entity, before, after); ok {
+		logCurriculumDiff(curriculumID, entity, action, description, changedBy, diff)
+	}
+}
+
+// logCourseChange queues a course change on every curriculum that uses the course
+func logCourseChange(courseID int, entity logEntity, action, description, changedBy string, before, after interface{}) {
+	if diff, ok := changeDiff(entity, before, after); ok {
+		for _, curriculumID := range courseCurriculumIDs(courseID) {
+			logCurriculumDiff(curriculumID, entity, action, description, changedBy, diff)
 		}
+	}
+}
 
-		var diffJSON []byte
-		var err error
-		if diff != nil {
-			diffJSON, err = json.Marshal(diff)
-			if err != nil {
-				log.Printf("Warning: Failed to marshal diff: %v", err)
-				diffJSON = nil
-			}
+// logCurriculumChangeTx is logCurriculumChange inside the caller's transaction, so the entry
+// is committed or rolled back together with the change it describes
+func logCurriculumChangeTx(tx *sql.Tx, curriculumID int, entity logEntity, action, description, changedBy string, before, after interface{}) error {
+	diff, ok := changeDiff(entity, before, after)
+	if !ok {
+		return nil
+	}
+	return logCurriculumDiffTx(tx, curriculumID, entity, action, description, changedBy, diff)
+}
+
+// logCourseChangeTx is logCourseChange inside the caller's transaction
+func logCourseChangeTx(tx *sql.Tx, courseID int, entity logEntity, action, description, changedBy string, before, after interface{}) error {
+	diff, ok := changeDiff(entity, before, after)
+	if !ok {
+		return nil
+	}
+	for _, curriculumID := range courseCurriculumIDs(courseID) {
+		if err := logCurriculumDiffTx(tx, curriculumID, entity, action, description, changedBy, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeDiff diffs two states; ok is false for updates that change nothing
+func changeDiff(entity logEntity, before, after interface{}) (map[string]map[string]interface{}, bool) {
+	diff := diffChange(before, after)
+	return diff, entity.Operation != opUpdate || len(diff) > 0
+}
+
+// courseLogState is the logged state of a course placed in a semester card or an honour vertical;
+// placement is the column naming the card ("semester_id" or "honour_vertical_id")
+func courseLogState(courseID int, placement string, placementID int) map[string]interface{} {
+	state := map[string]interface{}{}
+	course, err := scanCatalogueCourse(db.DB.QueryRow(
+		"SELECT "+catalogueCourseColumns+" FROM courses c WHERE c.course_id = ?", courseID))
+	if err == nil {
+		state = toJSONMap(course.Course)
+	}
+	state[placement] = placementID
+	var countTowardsLimit bool
+	if placement == "semester_id" && db.DB.QueryRow("SELECT count_towards_limit FROM curriculum_courses WHERE semester_id = ? AND course_id = ? AND status = 1 LIMIT 1",
+		placementID, courseID).Scan(&countTowardsLimit) == nil {
+		state["count_towards_limit"] = countTowardsLimit
+	}
+	return state
+}
+
+// courseItemLogQueries load a course-level row for the activity log; the first column is the owning course
+var courseItemLogQueries = map[string]string{
+	"syllabus_model": `SELECT course_id, model_name, position FROM syllabus WHERE id = ?`,
+	"syllabus_title": `
+		SELECT s.course_id, t.model_id, t.title, t.hours, t.position
+		FROM syllabus_titles t INNER JOIN syllabus s ON t.model_id = s.id
+		WHERE t.id = ?`,
+	"syllabus_topic": `
+		SELECT s.course_id, tp.title_id, tp.topic, tp.position
+		FROM syllabus_topics tp
+		INNER JOIN syllabus_titles t ON tp.title_id = t.id
+		INNER JOIN syllabus s ON t.model_id = s.id
+		WHERE tp.id = ?`,
+	"experiment": `
+		SELECT e.course_id, e.experiment_number, e.experiment_name, e.hours,
+			(SELECT GROUP_CONCAT(topic_text ORDER BY topic_order SEPARATOR '\n')
+			 FROM course_experiment_topics WHERE experiment_id = e.id AND status = 1) AS topics
+		FROM course_experiments e WHERE e.id = ?`,
+}
+
+// courseItemLogState loads a course-level row by entity type; the course id is 0 when the row is missing
+func courseItemLogState(entityType string, id int) (int, map[string]interface{}) {
+	rows, err := db.DB.Query(courseItemLogQueries[entityType], id)
+	if err != nil {
+		log.Printf("Warning: Failed to load %s %d for the activity log: %v", entityType, id, err)
+		return 0, nil
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, nil
+	}
+
+	columns, _ := rows.Columns()
+	values := make([]sql.NullString, len(columns))
+	targets := make([]interface{}, len(columns))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return 0, nil
+	}
+
+	courseID, _ := strconv.Atoi(values[0].String)
+	state := make(map[string]interface{}, len(columns)-1)
+	for i, column := range columns[1:] {
+		if values[i+1].Valid {
+			state[column] = values[i+1].String
+		} else {
+			state[column] = nil
 		}
+	}
+	return courseID, state
+}
 
-		_, err = db.DB.Exec(`
-			INSERT INTO curriculum_logs (curriculum_id, action, description, changed_by, diff)
-			VALUES (?, ?, ?, ?, ?)
-		`, curriculumID, action, description, changedBy, diffJSON)
+// logCurriculumDiff queues a log entry and its per-field changes for the activity log writer
+func logCurriculumDiff(curriculumID int, entity logEntity, action, description, changedBy string, diff interface{}) {
+	enqueueActivityLog(newActivityLogEntry(curriculumID, entity, action, description, changedBy, diff))
+}
+
+// logCurriculumDiffTx writes a log entry and its per-field changes inside the caller's transaction
+func logCurriculumDiffTx(tx *sql.Tx, curriculumID int, entity logEntity, action, description, changedBy string, diff interface{}) error {
+	return insertActivityLog(tx, newActivityLogEntry(curriculumID, entity, action, description, changedBy, diff))
+}
+
+// execWithActivityLog runs a single-statement change and writes its log entry in one transaction
+func execWithActivityLog(entry activityLogEntry, query string, args ...interface{}) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+	if err := insertActivityLog(tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// newActivityLogEntry captures a log entry; the diff is encoded right away so later changes to it are not logged
+func newActivityLogEntry(curriculumID int, entity logEntity, action, description, changedBy string, diff interface{}) activityLogEntry {
+	if changedBy == "" {
+		changedBy = "System"
+	}
+	entry := activityLogEntry{
+		CurriculumID: curriculumID,
+		EntityType:   entity.Type,
+		EntityID:     entity.ID,
+		Operation:    entity.Operation,
+		Action:       action,
+		Description:  description,
+		ChangedBy:    changedBy,
+		CreatedAt:    time.Now(),
+	}
+	if diff != nil {
+		diffJSON, err := json.Marshal(diff)
 		if err != nil {
-			log.Printf("Warning: Failed to log activity for curriculum %d: %v", curriculumID, err)
+			log.Printf("Warning: Failed to marshal diff: %v", err)
+		} else {
+			entry.Diff = diffJSON
+		}
+	}
+	return entry
+}
+
+// insertActivityLog inserts a log entry and one curriculum_log_changes row per field of its diff
+func insertActivityLog(tx *sql.Tx, entry activityLogEntry) error {
+	var entityID interface{}
+	if entry.EntityType != "" {
+		entityID = entry.EntityID
+	}
+	var diffJSON interface{}
+	if len(entry.Diff) > 0 {
+		diffJSON = string(entry.Diff)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO curriculum_logs (curriculum_id, action, description, changed_by, diff, entity_type, entity_id, operation, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.CurriculumID, entry.Action, entry.Description, entry.ChangedBy, diffJSON,
+		nullIfEmpty(entry.EntityType), entityID, nullIfEmpty(entry.Operation), entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+	logID, _ := result.LastInsertId()
+
+	// Diffs that are not in the {"field": {"old": ..., "new": ...}} form are kept only as a whole
+	var fields map[string]map[string]json.RawMessage
+	if len(entry.Diff) > 0 && json.Unmarshal(entry.Diff, &fields) == nil {
+		for field, change := range fields {
+			if _, err := tx.Exec(`
+				INSERT INTO curriculum_log_changes (log_id, field, old_value, new_value)
+				VALUES (?, ?, ?, ?)
+			`, logID, field, jsonValue(change["old"]), jsonValue(change["new"])); err != nil {
+				return err
+			}
 		}
-	}()
+	}
+	return nil
+}
+
+// jsonValue turns a raw JSON value into a column value, keeping SQL NULL for missing values
+func jsonValue(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+// diffChange compares the JSON form of two states in the {"field": {"old": ..., "new": ...}} form.
+// Values that are not JSON objects are compared as a whole under the "value" field
+func diffChange(before, after interface{}) map[string]map[string]interface{} {
+	diff := make(map[string]map[string]interface{})
+	oldMap, oldIsObject := jsonObject(before)
+	newMap, newIsObject := jsonObject(after)
+	if oldIsObject && newIsObject {
+		diffJSONFields(diff, oldMap, newMap, nil)
+		return diff
+	}
+
+	oldValue, newValue := jsonGeneric(before), jsonGeneric(after)
+	if !reflect.DeepEqual(oldValue, newValue) {
+		diff["value"] = map[string]interface{}{"old": oldValue, "new": newValue}
+	}
+	return diff
+}
+
+// jsonObject returns the JSON object form of v; nil (or a nil pointer) counts as an empty object
+func jsonObject(v interface{}) (map[string]interface{}, bool) {
+	if v == nil {
+		return map[string]interface{}{}, true
+	}
+	data, err := json.Marshal(v)
+	if err == nil && string(data) == "null" {
+		return map[string]interface{}{}, true
+	}
+	if err != nil || !strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		return nil, false
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+// jsonGeneric converts v to its plain JSON representation so values of different Go types compare equal
+func jsonGeneric(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	json.Unmarshal(data, &out)
+	return out
 }
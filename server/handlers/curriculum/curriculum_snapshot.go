@@ -0,0 +1,961 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"server/db"
+	"server/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// departmentListTables are the ordered overview lists captured in a curriculum snapshot
+var departmentListTables = []struct{ table, column string }{
+	{"curriculum_mission", "mission_text"},
+	{"curriculum_peos", "peo_text"},
+	{"curriculum_pos", "po_text"},
+	{"curriculum_psos", "pso_text"},
+}
+
+// snapshotDepartmentLists returns the overview lists in the order of departmentListTables
+func snapshotDepartmentLists(overview *models.DepartmentOverview) []*[]models.DepartmentListItem {
+	return []*[]models.DepartmentListItem{&overview.Mission, &overview.PEOs, &overview.POs, &overview.PSOs}
+}
+
+// buildCurriculumSnapshot captures the overview, semesters, honour cards and course content of a curriculum
+func buildCurriculumSnapshot(curriculumID int) (*models.CurriculumSnapshotData, error) {
+	data := &models.CurriculumSnapshotData{
+		FormatVersion: models.CurriculumSnapshotFormat,
+		PEOPOMapping:  []models.PEOPOMapping{},
+		Semesters:     []models.SnapshotSemester{},
+		CourseLinks:   []models.SnapshotCourseLink{},
+		HonourCards:   []models.SnapshotHonourCard{},
+		Courses:       make(map[string]*models.CourseSnapshot),
+		Pins:          make(map[string]int),
+	}
+	err := db.DB.QueryRow(`
+		SELECT name, COALESCE(academic_year, ''), COALESCE(curriculum_template, ''), COALESCE(max_credits, 0)
+		FROM curriculum WHERE id = ?`, curriculumID).Scan(&data.Name, &data.AcademicYear, &data.Template, &data.MaxCredits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Department overview; row ids are dropped so that snapshots only differ by content
+	data.Overview.CurriculumID = curriculumID
+	err = db.DB.QueryRow(`
+		SELECT vision FROM curriculum_vision
+		WHERE curriculum_id = ? AND (status = 1 OR status IS NULL)
+		ORDER BY id DESC LIMIT 1`, curriculumID).Scan(&data.Overview.Vision)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	lists := snapshotDepartmentLists(&data.Overview)
+	for i, source := range departmentListTables {
+		items := fetchDepartmentList(curriculumID, source.table, source.column)
+		for j := range items {
+			items[j].ID = 0
+		}
+		*lists[i] = items
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT peo_index, po_index, mapping_value FROM peo_po_mapping
+		WHERE curriculum_id = ? ORDER BY peo_index, po_index`, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var m models.PEOPOMapping
+		if err := rows.Scan(&m.PEOIndex, &m.POIndex, &m.MappingValue); err == nil {
+			data.PEOPOMapping = append(data.PEOPOMapping, m)
+		}
+	}
+	rows.Close()
+
+	// Semester cards and the courses placed in them
+	rows, err = db.DB.Query(`
+		SELECT id, semester_number, COALESCE(card_type, 'semester') FROM normal_cards
+		WHERE curriculum_id = ? AND (status = 1 OR status IS NULL)
+		ORDER BY COALESCE(semester_number, 999), id`, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var s models.SnapshotSemester
+		var number sql.NullInt64
+		if err := rows.Scan(&s.ID, &number, &s.CardType); err == nil {
+			if number.Valid {
+				n := int(number.Int64)
+				s.SemesterNumber = &n
+			}
+			data.Semesters = append(data.Semesters, s)
+		}
+	}
+	rows.Close()
+
+	rows, err = db.DB.Query(`
+		SELECT cc.semester_id, cc.course_id, COALESCE(cc.count_towards_limit, 1) FROM curriculum_courses cc
+		INNER JOIN normal_cards nc ON nc.id = cc.semester_id AND (nc.status = 1 OR nc.status IS NULL)
+		WHERE cc.curriculum_id = ? AND cc.status = 1
+		ORDER BY cc.semester_id, cc.id`, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var l models.SnapshotCourseLink
+		if err := rows.Scan(&l.SemesterID, &l.CourseID, &l.CountTowardsLimit); err == nil {
+			data.CourseLinks = append(data.CourseLinks, l)
+		}
+	}
+	rows.Close()
+
+	// Honour cards -> verticals -> courses
+	rows, err = db.DB.Query("SELECT id, title FROM honour_cards WHERE curriculum_id = ? AND status = 1 ORDER BY id", curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var card models.SnapshotHonourCard
+		if err := rows.Scan(&card.ID, &card.Title); err == nil {
+			card.Verticals = []models.SnapshotHonourVertical{}
+			data.HonourCards = append(data.HonourCards, card)
+		}
+	}
+	rows.Close()
+	for i := range data.HonourCards {
+		card := &data.HonourCards[i]
+		rows, err := db.DB.Query("SELECT id, name FROM honour_verticals WHERE honour_card_id = ? AND status = 1 ORDER BY id", card.ID)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var v models.SnapshotHonourVertical
+			if err := rows.Scan(&v.ID, &v.Name); err == nil {
+				v.CourseIDs = []int{}
+				card.Verticals = append(card.Verticals, v)
+			}
+		}
+		rows.Close()
+		for j := range card.Verticals {
+			vertical := &card.Verticals[j]
+			vertical.CourseIDs, err = queryCourseIDs(`
+				SELECT course_id FROM honour_vertical_courses
+				WHERE honour_vertical_id = ? AND status = 1 ORDER BY id`, vertical.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Course content as the curriculum sees it, with the version it is pinned to
+	courseIDs, err := queryCourseIDs(curriculumCourseIDsQuery, curriculumID, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	for _, courseID := range courseIDs {
+		snapshot, pinned, err := courseSnapshotForCurriculum(curriculumID, courseID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot course %d: %w", courseID, err)
+		}
+		key := strconv.Itoa(courseID)
+		data.Courses[key] = snapshot
+		if pinned > 0 {
+			data.Pins[key] = pinned
+		}
+	}
+	return data, nil
+}
+
+// loadCurriculumSnapshot retrieves a snapshot of a curriculum with its data
+func loadCurriculumSnapshot(curriculumID, snapshotID int) (*models.CurriculumSnapshot, error) {
+	s := &models.CurriculumSnapshot{}
+	var createdBy sql.NullString
+	var snapshotJSON string
+	err := db.DB.QueryRow(`
+		SELECT id, curriculum_id, version, name, created_by, created_at, snapshot
+		FROM curriculum_snapshots WHERE id = ? AND curriculum_id = ?`, snapshotID, curriculumID).Scan(
+		&s.ID, &s.CurriculumID, &s.Version, &s.Name, &createdBy, &s.CreatedAt, &snapshotJSON)
+	if err != nil {
+		return nil, err
+	}
+	s.CreatedBy = createdBy.String
+
+	s.Data = &models.CurriculumSnapshotData{}
+	if err := json.Unmarshal([]byte(snapshotJSON), s.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode curriculum snapshot %d: %w", snapshotID, err)
+	}
+	return s, nil
+}
+
+// createCurriculumSnapshot stores the current state of a curriculum as its next snapshot
+func createCurriculumSnapshot(curriculumID int, name, createdBy string) (*models.CurriculumSnapshot, error) {
+	data, err := buildCurriculumSnapshot(curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	snapshotJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if createdBy == "" {
+		createdBy = "System"
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) + 1 FROM curriculum_snapshots WHERE curriculum_id = ? FOR UPDATE",
+		curriculumID).Scan(&version); err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fmt.Sprintf("Snapshot %d", version)
+	}
+	result, err := tx.Exec(`
+		INSERT INTO curriculum_snapshots (curriculum_id, version, name, snapshot, created_by)
+		VALUES (?, ?, ?, ?, ?)`, curriculumID, version, name, string(snapshotJSON), createdBy)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := result.LastInsertId()
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return loadCurriculumSnapshot(curriculumID, int(id))
+}
+
+// diffCurriculumSnapshots compares two snapshots of a curriculum.
+// Curriculum-level sections use the {"field": {"old": ..., "new": ...}} form; courses are compared one by one
+func diffCurriculumSnapshots(from, to *models.CurriculumSnapshotData) models.CurriculumSnapshotDiff {
+	diff := models.CurriculumSnapshotDiff{
+		Changes:        make(map[string]map[string]interface{}),
+		AddedCourses:   []int{},
+		RemovedCourses: []int{},
+		CourseChanges:  make(map[string]map[string]map[string]interface{}),
+	}
+	diffJSONFields(diff.Changes, toJSONMap(from), toJSONMap(to),
+		map[string]bool{"format_version": true, "courses": true})
+
+	for key, old := range from.Courses {
+		courseID, _ := strconv.Atoi(key)
+		new, ok := to.Courses[key]
+		if !ok {
+			diff.RemovedCourses = append(diff.RemovedCourses, courseID)
+			continue
+		}
+		if changes := diffCourseSnapshots(old, new); len(changes) > 0 {
+			diff.CourseChanges[key] = changes
+		}
+	}
+	for key := range to.Courses {
+		if _, ok := from.Courses[key]; !ok {
+			courseID, _ := strconv.Atoi(key)
+			diff.AddedCourses = append(diff.AddedCourses, courseID)
+		}
+	}
+	sort.Ints(diff.AddedCourses)
+	sort.Ints(diff.RemovedCourses)
+	return diff
+}
+
+// writeCourseSnapshot replaces the details and syllabus content of a course with those of a snapshot.
+// Current rows are soft-deleted; their positions are moved out of the way of the unique (course_id, position) keys
+func writeCourseSnapshot(tx *sql.Tx, courseID int, snapshot *models.CourseSnapshot) error {
+	c := snapshot.Course
+	if _, err := tx.Exec(`
+		UPDATE courses SET course_code = ?, course_name = ?, course_type = ?, category = ?, credit = ?,
+		       lecture_hrs = ?, tutorial_hrs = ?, practical_hrs = ?, activity_hrs = ?, `+"`tw/sl`"+` = ?,
+		       theory_total_hrs = ?, tutorial_total_hrs = ?, practical_total_hrs = ?, activity_total_hrs = ?,
+		       cia_marks = ?, see_marks = ?, status = 1
+		WHERE course_id = ?`,
+		c.CourseCode, c.CourseName, c.CourseType, c.Category, c.Credit,
+		c.LectureHrs, c.TutorialHrs, c.PracticalHrs, c.ActivityHrs, c.TwSlHrs,
+		c.TheoryTotalHrs, c.TutorialTotalHrs, c.PracticalTotalHrs, c.ActivityTotalHrs,
+		c.CIAMarks, c.SEEMarks, courseID); err != nil {
+		return fmt.Errorf("failed to restore course details: %w", err)
+	}
+
+	// Objectives, outcomes and references
+	outcomeIDs := []int64{}
+	for _, list := range []struct {
+		table, column string
+		items         []string
+		bloom         bool
+	}{
+		{"course_objectives", "objective", snapshot.Objectives, true},
+		{"course_outcomes", "outcome", snapshot.Outcomes, true},
+		{"course_references", "reference_text", snapshot.ReferenceList, false},
+	} {
+		if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET status = 0, position = -id WHERE course_id = ?", list.table), courseID); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", list.table, err)
+		}
+		for i, text := range list.items {
+			var result sql.Result
+			var err error
+			if list.bloom {
+				result, err = tx.Exec(fmt.Sprintf("INSERT INTO %s (course_id, %s, position, status, bloom_level) VALUES (?, ?, ?, 1, ?)",
+					list.table, list.column), courseID, text, i, suggestBloomLevel(text))
+			} else {
+				result, err = tx.Exec(fmt.Sprintf("INSERT INTO %s (course_id, %s, position, status) VALUES (?, ?, ?, 1)",
+					list.table, list.column), courseID, text, i)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to restore %s: %w", list.table, err)
+			}
+			if list.table == "course_outcomes" {
+				id, _ := result.LastInsertId()
+				outcomeIDs = append(outcomeIDs, id)
+			}
+		}
+	}
+
+	// Prerequisites, re-linked to courses by their leading course code
+	if _, err := tx.Exec("DELETE FROM course_prerequisites WHERE course_id = ?", courseID); err != nil {
+		return fmt.Errorf("failed to clear prerequisites: %w", err)
+	}
+	for i, text := range snapshot.Prerequisites {
+		if _, err := tx.Exec(`
+			INSERT INTO course_prerequisites (course_id, prerequisite, prerequisite_course_id, position)
+			VALUES (?, ?, ?, ?)`, courseID, text, resolvePrerequisiteCourse(courseID, text), i); err != nil {
+			return fmt.Errorf("failed to restore prerequisites: %w", err)
+		}
+	}
+
+	// Teamwork and self-learning
+	for _, query := range []string{
+		"DELETE FROM course_teamwork_activities WHERE course_id = ?",
+		"DELETE FROM course_teamwork WHERE course_id = ?",
+		"DELETE FROM course_selflearning_resources WHERE main_id IN (SELECT id FROM course_selflearning_topics WHERE course_id = ?)",
+		"DELETE FROM course_selflearning_topics WHERE course_id = ?",
+		"DELETE FROM course_selflearning WHERE course_id = ?",
+	} {
+		if _, err := tx.Exec(query, courseID); err != nil {
+			return fmt.Errorf("failed to clear teamwork and self-learning: %w", err)
+		}
+	}
+	if tw := snapshot.Teamwork; tw != nil {
+		if _, err := tx.Exec("INSERT INTO course_teamwork (course_id, total_hours) VALUES (?, ?)", courseID, tw.Hours); err != nil {
+			return fmt.Errorf("failed to restore teamwork: %w", err)
+		}
+		for i, activity := range tw.Activities {
+			if _, err := tx.Exec("INSERT INTO course_teamwork_activities (course_id, activity, position) VALUES (?, ?, ?)",
+				courseID, activity, i); err != nil {
+				return fmt.Errorf("failed to restore teamwork activities: %w", err)
+			}
+		}
+	}
+	if sl := snapshot.SelfLearning; sl != nil {
+		if _, err := tx.Exec("INSERT INTO course_selflearning (course_id, total_hours) VALUES (?, ?)", courseID, sl.Hours); err != nil {
+			return fmt.Errorf("failed to restore self-learning: %w", err)
+		}
+		for i, main := range sl.MainInputs {
+			result, err := tx.Exec("INSERT INTO course_selflearning_topics (course_id, main_text, position) VALUES (?, ?, ?)",
+				courseID, main.Main, i)
+			if err != nil {
+				return fmt.Errorf("failed to restore self-learning topics: %w", err)
+			}
+			mainID, _ := result.LastInsertId()
+			for j, text := range main.Internal {
+				if _, err := tx.Exec("INSERT INTO course_selflearning_resources (main_id, internal_text, position) VALUES (?, ?, ?)",
+					mainID, text, j); err != nil {
+					return fmt.Errorf("failed to restore self-learning resources: %w", err)
+				}
+			}
+		}
+	}
+
+	// Syllabus modules -> titles -> topics
+	for _, query := range []string{
+		`UPDATE syllabus_topics SET status = 0 WHERE title_id IN (
+			SELECT st.id FROM syllabus_titles st INNER JOIN syllabus s ON s.id = st.model_id WHERE s.course_id = ?)`,
+		"UPDATE syllabus_titles SET status = 0 WHERE model_id IN (SELECT id FROM syllabus WHERE course_id = ?)",
+		"UPDATE syllabus SET status = 0 WHERE course_id = ?",
+	} {
+		if _, err := tx.Exec(query, courseID); err != nil {
+			return fmt.Errorf("failed to clear syllabus: %w", err)
+		}
+	}
+	for _, model := range snapshot.Models {
+		result, err := tx.Exec("INSERT INTO syllabus (course_id, model_name, name, position, status) VALUES (?, ?, ?, ?, 1)",
+			courseID, model.ModelName, model.ModelName, model.Position)
+		if err != nil {
+			return fmt.Errorf("failed to restore syllabus module: %w", err)
+		}
+		modelID, _ := result.LastInsertId()
+		for _, title := range model.Titles {
+			result, err := tx.Exec(`
+				INSERT INTO syllabus_titles (model_id, title, title_name, hours, position, status)
+				VALUES (?, ?, ?, ?, ?, 1)`, modelID, title.TitleName, title.TitleName, title.Hours, title.Position)
+			if err != nil {
+				return fmt.Errorf("failed to restore syllabus title: %w", err)
+			}
+			titleID, _ := result.LastInsertId()
+			for _, topic := range title.Topics {
+				if _, err := tx.Exec(`
+					INSERT INTO syllabus_topics (title_id, topic, content, position, status)
+					VALUES (?, ?, '', ?, 1)`, titleID, topic.Topic, topic.Position); err != nil {
+					return fmt.Errorf("failed to restore syllabus topic: %w", err)
+				}
+			}
+		}
+	}
+
+	// Experiments and their topics
+	if _, err := tx.Exec(`UPDATE course_experiment_topics SET status = 0
+		WHERE experiment_id IN (SELECT id FROM course_experiments WHERE course_id = ?)`, courseID); err != nil {
+		return fmt.Errorf("failed to clear experiment topics: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE course_experiments SET status = 0 WHERE course_id = ?", courseID); err != nil {
+		return fmt.Errorf("failed to clear experiments: %w", err)
+	}
+	for _, exp := range snapshot.Experiments {
+		result, err := tx.Exec(`
+			INSERT INTO course_experiments (course_id, experiment_number, experiment_name, hours, status)
+			VALUES (?, ?, ?, ?, 1)`, courseID, exp.ExperimentNumber, exp.ExperimentName, exp.Hours)
+		if err != nil {
+			return fmt.Errorf("failed to restore experiment: %w", err)
+		}
+		expID, _ := result.LastInsertId()
+		for i, topic := range exp.Topics {
+			if _, err := tx.Exec(`
+				INSERT INTO course_experiment_topics (experiment_id, topic_text, topic_order, status)
+				VALUES (?, ?, ?, 1)`, expID, topic, i); err != nil {
+				return fmt.Errorf("failed to restore experiment topics: %w", err)
+			}
+		}
+	}
+
+	// CO-PO/PSO mappings, pointed at the restored outcome in the same position
+	justifications := make(map[string]string)
+	for _, note := range snapshot.Justifications {
+		justifications[fmt.Sprintf("%d-%s", note.CO-1, note.Outcome)] = note.Justification
+	}
+	for _, matrix := range []struct {
+		prefix, table, column string
+		values                map[string]int
+	}{
+		{"PO", "co_po_mapping", "po_index", snapshot.COPOMapping},
+		{"PSO", "co_pso_mapping", "pso_index", snapshot.COPSOMapping},
+	} {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE course_id = ?", matrix.table), courseID); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", matrix.table, err)
+		}
+		for key, value := range matrix.values {
+			var coIdx, outcomeIdx int
+			if _, err := fmt.Sscanf(key, "%d-%d", &coIdx, &outcomeIdx); err != nil {
+				continue
+			}
+			var outcomeID sql.NullInt64
+			if coIdx >= 0 && coIdx < len(outcomeIDs) {
+				outcomeID = sql.NullInt64{Int64: outcomeIDs[coIdx], Valid: true}
+			}
+			justification := justifications[fmt.Sprintf("%d-%s%d", coIdx, matrix.prefix, outcomeIdx)]
+			if _, err := tx.Exec(fmt.Sprintf(`
+				INSERT INTO %s (course_id, co_index, %s, mapping_value, justification, outcome_id)
+				VALUES (?, ?, ?, ?, ?, ?)`, matrix.table, matrix.column),
+				courseID, coIdx, outcomeIdx, value, nullIfEmpty(justification), outcomeID); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", matrix.table, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// restoreCurriculumSnapshot rolls a curriculum back to a snapshot in a single transaction, which also
+// writes logEntry. Courses that other curricula also use are not rewritten; the curriculum gets its own copy instead
+func restoreCurriculumSnapshot(curriculumID int, data *models.CurriculumSnapshotData, logEntry activityLogEntry) (*models.RestoreCurriculumSnapshotResult, error) {
+	if data.FormatVersion > models.CurriculumSnapshotFormat {
+		return nil, fmt.Errorf("snapshot format %d is newer than supported format %d", data.FormatVersion, models.CurriculumSnapshotFormat)
+	}
+	result := &models.RestoreCurriculumSnapshotResult{
+		CurriculumID:    curriculumID,
+		RestoredCourses: []int{},
+		ForkedCourses:   make(map[int]int),
+	}
+
+	// Decide per course, before writing anything, whether its content has to be rewritten and where.
+	// Pinned courses take their content from the pinned version, so only the pin is restored
+	const (
+		restoreInPlace = iota + 1
+		restoreFork
+		restoreRecreate
+	)
+	plans := make(map[int]int)
+	courseIDs := []int{}
+	for key, snapshot := range data.Courses {
+		courseID, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid course key %q in snapshot", key)
+		}
+		courseIDs = append(courseIDs, courseID)
+		if _, pinned := data.Pins[key]; pinned {
+			continue
+		}
+		live, err := buildCourseSnapshot(courseID)
+		if err == sql.ErrNoRows {
+			plans[courseID] = restoreRecreate
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(diffCourseSnapshots(snapshot, live)) == 0 {
+			continue
+		}
+		plans[courseID] = restoreInPlace
+		for _, id := range courseCurriculumIDs(courseID) {
+			if id != curriculumID {
+				plans[courseID] = restoreFork
+				break
+			}
+		}
+	}
+	sort.Ints(courseIDs)
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE curriculum SET name = ?, academic_year = ?, curriculum_template = ?, max_credits = ?
+		WHERE id = ?`, data.Name, data.AcademicYear, data.Template, data.MaxCredits, curriculumID); err != nil {
+		return nil, fmt.Errorf("failed to restore curriculum: %w", err)
+	}
+
+	// Department overview
+	var visionID int
+	err = tx.QueryRow(`
+		SELECT id FROM curriculum_vision WHERE curriculum_id = ?
+		ORDER BY (status = 1 OR status IS NULL) DESC, id DESC LIMIT 1`, curriculumID).Scan(&visionID)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.Exec("INSERT INTO curriculum_vision (curriculum_id, vision, status) VALUES (?, ?, 1)", curriculumID, data.Overview.Vision)
+	case err == nil:
+		_, err = tx.Exec("UPDATE curriculum_vision SET vision = ?, status = 1 WHERE id = ?", data.Overview.Vision, visionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore vision: %w", err)
+	}
+	lists := snapshotDepartmentLists(&data.Overview)
+	for i, target := range departmentListTables {
+		if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET status = 0 WHERE curriculum_id = ? AND (status = 1 OR status IS NULL)", target.table),
+			curriculumID); err != nil {
+			return nil, fmt.Errorf("failed to clear %s: %w", target.table, err)
+		}
+		for position, item := range *lists[i] {
+			visibility := item.Visibility
+			if visibility == "" {
+				visibility = "UNIQUE"
+			}
+			source := sql.NullInt64{Int64: int64(item.SourceCurriculumID), Valid: item.SourceCurriculumID != 0}
+			if _, err := tx.Exec(fmt.Sprintf(`
+				INSERT INTO %s (curriculum_id, %s, position, visibility, source_curriculum_id, status)
+				VALUES (?, ?, ?, ?, ?, 1)`, target.table, target.column),
+				curriculumID, item.Text, position, visibility, source); err != nil {
+				return nil, fmt.Errorf("failed to restore %s: %w", target.table, err)
+			}
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM peo_po_mapping WHERE curriculum_id = ?", curriculumID); err != nil {
+		return nil, fmt.Errorf("failed to clear PEO-PO mapping: %w", err)
+	}
+	for _, m := range data.PEOPOMapping {
+		if _, err := tx.Exec("INSERT INTO peo_po_mapping (curriculum_id, peo_index, po_index, mapping_value) VALUES (?, ?, ?, ?)",
+			curriculumID, m.PEOIndex, m.POIndex, m.MappingValue); err != nil {
+			return nil, fmt.Errorf("failed to restore PEO-PO mapping: %w", err)
+		}
+	}
+
+	// Course content
+	courseMap := make(map[int]int)
+	for _, courseID := range courseIDs {
+		courseMap[courseID] = courseID
+		snapshot := data.Courses[strconv.Itoa(courseID)]
+		switch plans[courseID] {
+		case restoreInPlace:
+			if err := writeCourseSnapshot(tx, courseID, snapshot); err != nil {
+				return nil, fmt.Errorf("failed to restore course %d: %w", courseID, err)
+			}
+			result.RestoredCourses = append(result.RestoredCourses, courseID)
+		case restoreFork, restoreRecreate:
+			forkedFrom := sql.NullInt64{Int64: int64(courseID), Valid: plans[courseID] == restoreFork}
+			res, err := tx.Exec(`
+				INSERT INTO courses (course_code, course_name, status, version, forked_from_course_id, owner_curriculum_id)
+				VALUES (?, ?, 1, 1, ?, ?)`, snapshot.Course.CourseCode, snapshot.Course.CourseName, forkedFrom, curriculumID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy course %d: %w", courseID, err)
+			}
+			newID, _ := res.LastInsertId()
+			if err := writeCourseSnapshot(tx, int(newID), snapshot); err != nil {
+				return nil, fmt.Errorf("failed to restore course %d: %w", courseID, err)
+			}
+			courseMap[courseID] = int(newID)
+			result.ForkedCourses[courseID] = int(newID)
+			result.RestoredCourses = append(result.RestoredCourses, int(newID))
+		default:
+			if _, err := tx.Exec("UPDATE courses SET status = 1 WHERE course_id = ?", courseID); err != nil {
+				return nil, fmt.Errorf("failed to reactivate course %d: %w", courseID, err)
+			}
+		}
+	}
+	mapCourse := func(courseID int) int {
+		if id, ok := courseMap[courseID]; ok {
+			return id
+		}
+		return courseID
+	}
+
+	// Semester cards, reusing the original rows where they still exist
+	if _, err := tx.Exec("UPDATE normal_cards SET status = 0 WHERE curriculum_id = ? AND (status = 1 OR status IS NULL)", curriculumID); err != nil {
+		return nil, fmt.Errorf("failed to clear semesters: %w", err)
+	}
+	semesterMap := make(map[int]int)
+	for _, s := range data.Semesters {
+		var number sql.NullInt64
+		if s.SemesterNumber != nil {
+			number = sql.NullInt64{Int64: int64(*s.SemesterNumber), Valid: true}
+		}
+		res, err := tx.Exec("UPDATE normal_cards SET semester_number = ?, card_type = ?, status = 1 WHERE id = ? AND curriculum_id = ?",
+			number, s.CardType, s.ID, curriculumID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore semester: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			semesterMap[s.ID] = s.ID
+			continue
+		}
+		res, err = tx.Exec("INSERT INTO normal_cards (curriculum_id, semester_number, card_type, status) VALUES (?, ?, ?, 1)",
+			curriculumID, number, s.CardType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recreate semester: %w", err)
+		}
+		newID, _ := res.LastInsertId()
+		semesterMap[s.ID] = int(newID)
+	}
+
+	if _, err := tx.Exec("UPDATE curriculum_courses SET status = 0 WHERE curriculum_id = ? AND status = 1", curriculumID); err != nil {
+		return nil, fmt.Errorf("failed to clear semester courses: %w", err)
+	}
+	for _, l := range data.CourseLinks {
+		semesterID, ok := semesterMap[l.SemesterID]
+		if !ok {
+			continue
+		}
+		courseID := mapCourse(l.CourseID)
+		res, err := tx.Exec(`
+			UPDATE curriculum_courses SET status = 1, count_towards_limit = ?
+			WHERE curriculum_id = ? AND semester_id = ? AND course_id = ? AND status = 0
+			LIMIT 1`, l.CountTowardsLimit, curriculumID, semesterID, courseID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore semester course: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO curriculum_courses (curriculum_id, semester_id, course_id, status, count_towards_limit)
+			VALUES (?, ?, ?, 1, ?)`, curriculumID, semesterID, courseID, l.CountTowardsLimit); err != nil {
+			return nil, fmt.Errorf("failed to relink semester course: %w", err)
+		}
+	}
+
+	// Honour cards -> verticals -> courses
+	for _, query := range []string{
+		`UPDATE honour_vertical_courses SET status = 0 WHERE honour_vertical_id IN (
+			SELECT hv.id FROM honour_verticals hv INNER JOIN honour_cards hc ON hc.id = hv.honour_card_id WHERE hc.curriculum_id = ?)`,
+		"UPDATE honour_verticals SET status = 0 WHERE honour_card_id IN (SELECT id FROM honour_cards WHERE curriculum_id = ?)",
+		"UPDATE honour_cards SET status = 0 WHERE curriculum_id = ?",
+	} {
+		if _, err := tx.Exec(query, curriculumID); err != nil {
+			return nil, fmt.Errorf("failed to clear honour cards: %w", err)
+		}
+	}
+	for _, card := range data.HonourCards {
+		cardID := card.ID
+		res, err := tx.Exec("UPDATE honour_cards SET title = ?, status = 1 WHERE id = ? AND curriculum_id = ?", card.Title, card.ID, curriculumID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore honour card: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			res, err := tx.Exec("INSERT INTO honour_cards (curriculum_id, title, status) VALUES (?, ?, 1)", curriculumID, card.Title)
+			if err != nil {
+				return nil, fmt.Errorf("failed to recreate honour card: %w", err)
+			}
+			id, _ := res.LastInsertId()
+			cardID = int(id)
+		}
+		for _, vertical := range card.Verticals {
+			verticalID := vertical.ID
+			res, err := tx.Exec("UPDATE honour_verticals SET name = ?, status = 1 WHERE id = ? AND honour_card_id = ?",
+				vertical.Name, vertical.ID, cardID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore honour vertical: %w", err)
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				res, err := tx.Exec("INSERT INTO honour_verticals (honour_card_id, name, status) VALUES (?, ?, 1)", cardID, vertical.Name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to recreate honour vertical: %w", err)
+				}
+				id, _ := res.LastInsertId()
+				verticalID = int(id)
+			}
+			for _, courseID := range vertical.CourseIDs {
+				if _, err := tx.Exec(`
+					INSERT INTO honour_vertical_courses (honour_vertical_id, course_id, status) VALUES (?, ?, 1)
+					ON DUPLICATE KEY UPDATE status = 1`, verticalID, mapCourse(courseID)); err != nil {
+					return nil, fmt.Errorf("failed to relink honour course: %w", err)
+				}
+			}
+		}
+	}
+
+	// Course version pins
+	if _, err := tx.Exec("DELETE FROM curriculum_course_versions WHERE curriculum_id = ?", curriculumID); err != nil {
+		return nil, fmt.Errorf("failed to clear course version pins: %w", err)
+	}
+	for key, version := range data.Pins {
+		courseID, _ := strconv.Atoi(key)
+		if _, err := tx.Exec("INSERT INTO curriculum_course_versions (curriculum_id, course_id, version) VALUES (?, ?, ?)",
+			curriculumID, courseID, version); err != nil {
+			return nil, fmt.Errorf("failed to restore course version pin: %w", err)
+		}
+	}
+
+	if err := insertActivityLog(tx, logEntry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseCurriculumSnapshotVars reads the curriculum and snapshot ids of a snapshot route
+func parseCurriculumSnapshotVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	snapshotID, err := strconv.Atoi(vars["snapshotId"])
+	if err != nil {
+		http.Error(w, "Invalid snapshot ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return curriculumID, snapshotID, true
+}
+
+// GetCurriculumSnapshots handles GET /curriculum/:id/snapshots
+func GetCurriculumSnapshots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, curriculum_id, version, name, COALESCE(created_by, ''), created_at
+		FROM curriculum_snapshots WHERE curriculum_id = ?
+		ORDER BY version DESC`, curriculumID)
+	if err != nil {
+		log.Println("Error fetching curriculum snapshots:", err)
+		http.Error(w, "Failed to fetch curriculum snapshots", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	snapshots := []models.CurriculumSnapshot{}
+	for rows.Next() {
+		var s models.CurriculumSnapshot
+		if err := rows.Scan(&s.ID, &s.CurriculumID, &s.Version, &s.Name, &s.CreatedBy, &s.CreatedAt); err != nil {
+			log.Println("Error scanning curriculum snapshot:", err)
+			continue
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	json.NewEncoder(w).Encode(snapshots)
+}
+
+// CreateCurriculumSnapshot handles POST /curriculum/:id/snapshots
+// Stores the overview, semesters, honour cards and full course content of the curriculum under a name
+func CreateCurriculumSnapshot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CreateCurriculumSnapshotRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	snapshot, err := createCurriculumSnapshot(curriculumID, req.Name, req.CreatedBy)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Curriculum not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error creating curriculum snapshot:", err)
+		http.Error(w, "Failed to create curriculum snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	logCurriculumDiff(curriculumID, logEntity{"curriculum_snapshot", snapshot.ID, opCreate}, "Snapshot Created",
+		fmt.Sprintf("Created snapshot %q (#%d)", snapshot.Name, snapshot.Version), snapshot.CreatedBy, nil)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// GetCurriculumSnapshot handles GET /curriculum/:id/snapshots/:snapshotId
+func GetCurriculumSnapshot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	curriculumID, snapshotID, ok := parseCurriculumSnapshotVars(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot, err := loadCurriculumSnapshot(curriculumID, snapshotID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Snapshot not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching curriculum snapshot:", err)
+		http.Error(w, "Failed to fetch curriculum snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// CompareCurriculumSnapshots handles GET /curriculum/:id/snapshots/compare?from=&to=
+// Without ?to= the snapshot is compared against the live curriculum
+func CompareCurriculumSnapshots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+	fromID, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid from snapshot", http.StatusBadRequest)
+		return
+	}
+
+	from, err := loadCurriculumSnapshot(curriculumID, fromID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Snapshot not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching curriculum snapshot:", err)
+		http.Error(w, "Failed to compare curriculum snapshots", http.StatusInternalServerError)
+		return
+	}
+
+	var toData *models.CurriculumSnapshotData
+	var toID *int
+	if toParam := r.URL.Query().Get("to"); toParam != "" {
+		id, err := strconv.Atoi(toParam)
+		if err != nil {
+			http.Error(w, "Invalid to snapshot", http.StatusBadRequest)
+			return
+		}
+		to, err := loadCurriculumSnapshot(curriculumID, id)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Snapshot not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Println("Error fetching curriculum snapshot:", err)
+			http.Error(w, "Failed to compare curriculum snapshots", http.StatusInternalServerError)
+			return
+		}
+		toData, toID = to.Data, &id
+	} else if toData, err = buildCurriculumSnapshot(curriculumID); err != nil {
+		log.Println("Error building curriculum snapshot:", err)
+		http.Error(w, "Failed to compare curriculum snapshots", http.StatusInternalServerError)
+		return
+	}
+
+	diff := diffCurriculumSnapshots(from.Data, toData)
+	diff.CurriculumID = curriculumID
+	diff.FromSnapshotID = fromID
+	diff.ToSnapshotID = toID
+	json.NewEncoder(w).Encode(diff)
+}
+
+// RestoreCurriculumSnapshot handles POST /curriculum/:id/snapshots/:snapshotId/restore
+// The current state is saved as a snapshot first so that the restore itself can be undone
+func RestoreCurriculumSnapshot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	curriculumID, snapshotID, ok := parseCurriculumSnapshotVars(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot, err := loadCurriculumSnapshot(curriculumID, snapshotID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Snapshot not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching curriculum snapshot:", err)
+		http.Error(w, "Failed to restore curriculum snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	backup, err := createCurriculumSnapshot(curriculumID, fmt.Sprintf("Before restoring %q", snapshot.Name), "System")
+	if err != nil {
+		log.Println("Error saving curriculum before restore:", err)
+		http.Error(w, "Failed to restore curriculum snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	diff := diffCurriculumSnapshots(backup.Data, snapshot.Data)
+	logEntry := newActivityLogEntry(curriculumID, logEntity{"curriculum_snapshot", snapshotID, opRestore}, "Snapshot Restored",
+		fmt.Sprintf("Restored snapshot %q (#%d)", snapshot.Name, snapshot.Version), requestUser(r), diff.Changes)
+	result, err := restoreCurriculumSnapshot(curriculumID, snapshot.Data, logEntry)
+	if err != nil {
+		log.Println("Error restoring curriculum snapshot:", err)
+		http.Error(w, "Failed to restore curriculum snapshot", http.StatusInternalServerError)
+		return
+	}
+	result.SnapshotID = snapshotID
+	result.Message = fmt.Sprintf("Curriculum restored to snapshot %q; previous state saved as snapshot #%d", snapshot.Name, backup.Version)
+
+	json.NewEncoder(w).Encode(result)
+}
@@ -203,24 +203,24 @@ func SaveDepartmentOverview(w http.ResponseWriter, r *http.Request) {
 			diff := map[string]map[string]interface{}{
 				"vision": {"old": oldOverview.Vision, "new": overview.Vision},
 			}
-			LogCurriculumActivityWithDiff(curriculumID, "Vision Updated",
-				"Updated department vision", "System", diff)
+			logCurriculumDiff(curriculumID, logEntity{"department_overview", curriculumID, opUpdate}, "Vision Updated",
+				"Updated department vision", requestUser(r), diff)
 		}
 
 		// Mission changes (per index)
-		detectArrayChanges(curriculumID, "Mission", oldOverview.Mission, overview.Mission)
+		detectArrayChanges(curriculumID, requestUser(r), "Mission", oldOverview.Mission, overview.Mission)
 
 		// PEO changes (per index)
-		detectArrayChanges(curriculumID, "PEO", oldOverview.PEOs, overview.PEOs)
+		detectArrayChanges(curriculumID, requestUser(r), "PEO", oldOverview.PEOs, overview.PEOs)
 
 		// PO changes (per index)
-		detectArrayChanges(curriculumID, "PO", oldOverview.POs, overview.POs)
+		detectArrayChanges(curriculumID, requestUser(r), "PO", oldOverview.POs, overview.POs)
 
 		// PSO changes (per index)
-		detectArrayChanges(curriculumID, "PSO", oldOverview.PSOs, overview.PSOs)
+		detectArrayChanges(curriculumID, requestUser(r), "PSO", oldOverview.PSOs, overview.PSOs)
 	} else {
-		LogCurriculumActivity(curriculumID, "Department Overview Created",
-			"Created department vision, mission, PEOs, POs, and PSOs", "System")
+		logCurriculumChange(curriculumID, logEntity{"department_overview", curriculumID, opCreate}, "Department Overview Created",
+			"Created department vision, mission, PEOs, POs, and PSOs", requestUser(r), nil, overview)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -493,7 +493,7 @@ func listItemArraysEqual(a, b []models.DepartmentListItem) bool {
 }
 
 // detectArrayChanges compares two DepartmentListItem arrays and logs individual item changes
-func detectArrayChanges(regulationID int, label string, oldArray, newArray []models.DepartmentListItem) {
+func detectArrayChanges(regulationID int, changedBy, label string, oldArray, newArray []models.DepartmentListItem) {
 	maxLen := len(oldArray)
 	if len(newArray) > maxLen {
 		maxLen = len(newArray)
@@ -514,19 +514,22 @@ func detectArrayChanges(regulationID int, label string, oldArray, newArray []mod
 				fmt.Sprintf("%s[%d]", label, i): {"old": oldVal, "new": newVal},
 			}
 
-			var action, description string
+			var action, description, operation string
 			if oldVal == "" {
 				action = fmt.Sprintf("%s[%d] Added", label, i)
 				description = fmt.Sprintf("Added %s item at index %d", label, i)
+				operation = opCreate
 			} else if newVal == "" {
 				action = fmt.Sprintf("%s[%d] Deleted", label, i)
 				description = fmt.Sprintf("Deleted %s item at index %d", label, i)
+				operation = opDelete
 			} else {
 				action = fmt.Sprintf("%s[%d] Updated", label, i)
 				description = fmt.Sprintf("Updated %s item at index %d", label, i)
+				operation = opUpdate
 			}
 
-			LogCurriculumActivityWithDiff(regulationID, action, description, "System", diff)
+			logCurriculumDiff(regulationID, logEntity{"department_overview", regulationID, operation}, action, description, changedBy, diff)
 		}
 	}
 }
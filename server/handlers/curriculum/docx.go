@@ -0,0 +1,246 @@
+package curriculum
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// docxDocument builds a minimal WordprocessingML (.docx) document: headings, paragraphs,
+// lists, bordered tables and page breaks, using the built-in Word heading styles
+type docxDocument struct {
+	body bytes.Buffer
+	// bookmarks are contents entries waiting for the next paragraph to mark
+	bookmarks []int
+}
+
+func newDocxDocument() *docxDocument {
+	return &docxDocument{}
+}
+
+// docxEscape escapes text for use inside a w:t element
+func docxEscape(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+// docxRun is one run of text; line breaks in the text become w:br
+func docxRun(text string, bold bool) string {
+	var b strings.Builder
+	b.WriteString("<w:r>")
+	if bold {
+		b.WriteString("<w:rPr><w:b/></w:rPr>")
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			b.WriteString("<w:br/>")
+		}
+		fmt.Fprintf(&b, `<w:t xml:space="preserve">%s</w:t>`, docxEscape(line))
+	}
+	b.WriteString("</w:r>")
+	return b.String()
+}
+
+func (d *docxDocument) paragraph(props string, runs ...string) {
+	d.body.WriteString("<w:p>")
+	if props != "" {
+		fmt.Fprintf(&d.body, "<w:pPr>%s</w:pPr>", props)
+	}
+	for _, index := range d.bookmarks {
+		fmt.Fprintf(&d.body, `<w:bookmarkStart w:id="%d" w:name="%s"/><w:bookmarkEnd w:id="%d"/>`, index, docxContentsBookmark(index), index)
+	}
+	d.bookmarks = nil
+	for _, run := range runs {
+		d.body.WriteString(run)
+	}
+	d.body.WriteString("</w:p>")
+}
+
+// Title adds a centred document title
+func (d *docxDocument) Title(text string) {
+	d.paragraph(`<w:pStyle w:val="Title"/><w:jc w:val="center"/>`, docxRun(text, false))
+}
+
+// Heading adds a heading of level 1-4
+func (d *docxDocument) Heading(level int, text string) {
+	if level < 1 {
+		level = 1
+	}
+	if level > 4 {
+		level = 4
+	}
+	d.paragraph(fmt.Sprintf(`<w:pStyle w:val="Heading%d"/>`, level), docxRun(text, false))
+}
+
+// Paragraph adds a plain paragraph
+func (d *docxDocument) Paragraph(text string) {
+	d.paragraph("", docxRun(text, false))
+}
+
+// Centered adds a centred paragraph
+func (d *docxDocument) Centered(text string, bold bool) {
+	d.paragraph(`<w:jc w:val="center"/>`, docxRun(text, bold))
+}
+
+// Bold adds a paragraph set in bold
+func (d *docxDocument) Bold(text string) {
+	d.paragraph("", docxRun(text, true))
+}
+
+// List adds one indented paragraph per item, numbered when ordered is set and bulleted otherwise
+func (d *docxDocument) List(items []string, ordered bool) {
+	d.indentedList(items, ordered, 360)
+}
+
+// SubList adds a bulleted list indented one level deeper than List
+func (d *docxDocument) SubList(items []string) {
+	d.indentedList(items, false, 720)
+}
+
+func (d *docxDocument) indentedList(items []string, ordered bool, indent int) {
+	props := fmt.Sprintf(`<w:ind w:left="%d" w:hanging="360"/>`, indent)
+	for i, item := range items {
+		marker := "•\t"
+		if ordered {
+			marker = fmt.Sprintf("%d.\t", i+1)
+		}
+		d.paragraph(props, docxRun(marker+item, false))
+	}
+}
+
+// Table adds a bordered table; the first row is the header and is set in bold
+func (d *docxDocument) Table(rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+	d.body.WriteString(`<w:tbl><w:tblPr><w:tblW w:w="5000" w:type="pct"/><w:tblBorders>` +
+		`<w:top w:val="single" w:sz="4" w:space="0" w:color="000000"/>` +
+		`<w:left w:val="single" w:sz="4" w:space="0" w:color="000000"/>` +
+		`<w:bottom w:val="single" w:sz="4" w:space="0" w:color="000000"/>` +
+		`<w:right w:val="single" w:sz="4" w:space="0" w:color="000000"/>` +
+		`<w:insideH w:val="single" w:sz="4" w:space="0" w:color="000000"/>` +
+		`<w:insideV w:val="single" w:sz="4" w:space="0" w:color="000000"/>` +
+		`</w:tblBorders></w:tblPr>`)
+	for r, row := range rows {
+		d.body.WriteString("<w:tr>")
+		if r == 0 {
+			d.body.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+		}
+		for _, cell := range row {
+			fmt.Fprintf(&d.body, "<w:tc><w:p>%s</w:p></w:tc>", docxRun(cell, r == 0))
+		}
+		d.body.WriteString("</w:tr>")
+	}
+	d.body.WriteString("</w:tbl>")
+	// Word needs a paragraph between consecutive tables
+	d.paragraph("")
+}
+
+// Contents writes a table of contents with a PAGEREF field per entry; Word fills in the page
+// numbers when it updates the fields on opening the document
+func (d *docxDocument) Contents(titles []string) {
+	d.Heading(1, "CONTENTS")
+	props := `<w:tabs><w:tab w:val="right" w:leader="dot" w:pos="10200"/></w:tabs>`
+	for i, title := range titles {
+		d.paragraph(props,
+			docxRun(title, false),
+			"<w:r><w:tab/></w:r>",
+			`<w:r><w:fldChar w:fldCharType="begin"/></w:r>`,
+			fmt.Sprintf(`<w:r><w:instrText xml:space="preserve"> PAGEREF %s \h </w:instrText></w:r>`, docxContentsBookmark(i)),
+			`<w:r><w:fldChar w:fldCharType="separate"/></w:r>`,
+			`<w:r><w:t></w:t></w:r>`,
+			`<w:r><w:fldChar w:fldCharType="end"/></w:r>`)
+	}
+}
+
+// ContentsEntry bookmarks the next paragraph as the start of a contents entry
+func (d *docxDocument) ContentsEntry(index int) {
+	d.bookmarks = append(d.bookmarks, index)
+}
+
+func docxContentsBookmark(index int) string {
+	return fmt.Sprintf("_Toc%d", index)
+}
+
+// PageBreak starts a new page
+func (d *docxDocument) PageBreak() {
+	d.body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+}
+
+// Bytes packages the document as a .docx file
+func (d *docxDocument) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		d.body.String() +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/>` +
+		`<w:pgMar w:top="567" w:right="850" w:bottom="567" w:left="850" w:header="0" w:footer="0" w:gutter="0"/>` +
+		`</w:sectPr></w:body></w:document>`
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles},
+		{"word/settings.xml", docxSettings},
+		{"word/document.xml", document},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+<Override PartName="/word/settings.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"/>
+</Types>`
+
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings" Target="settings.xml"/>
+</Relationships>`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="Times New Roman" w:hAnsi="Times New Roman" w:cs="Times New Roman"/><w:sz w:val="22"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="80"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="240"/></w:pPr><w:rPr><w:b/><w:sz w:val="36"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="200" w:after="100"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:sz w:val="28"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading3"><w:name w:val="heading 3"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="160" w:after="80"/><w:outlineLvl w:val="2"/></w:pPr><w:rPr><w:b/><w:sz w:val="24"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading4"><w:name w:val="heading 4"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="120" w:after="60"/><w:outlineLvl w:val="3"/></w:pPr><w:rPr><w:b/><w:i/><w:sz w:val="22"/></w:rPr></w:style>
+</w:styles>`
+
+// docxSettings asks Word to update fields, such as the contents page numbers, when the document is opened
+const docxSettings = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:updateFields w:val="true"/>
+</w:settings>`
@@ -2,6 +2,7 @@ package curriculum
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"server/db"
@@ -138,6 +139,10 @@ func CreateExperiment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("DEBUG CreateExperiment: completed successfully, returning experiment ID=%d", expID)
+
+	_, after := courseItemLogState("experiment", int(expID))
+	logCourseChange(courseID, logEntity{"experiment", int(expID), opCreate}, "Experiment Added",
+		fmt.Sprintf("Added experiment %d: %s", req.ExperimentNumber, req.ExperimentName), requestUser(r), nil, after)
 	json.NewEncoder(w).Encode(map[string]int64{"id": expID})
 }
 
@@ -162,6 +167,8 @@ func UpdateExperiment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	courseID, before := courseItemLogState("experiment", expID)
+
 	tx, err := db.DB.Begin()
 	if err != nil {
 		log.Println("ERROR UpdateExperiment: Failed to begin transaction:", err)
@@ -327,6 +334,12 @@ func UpdateExperiment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if courseID > 0 {
+		_, after := courseItemLogState("experiment", expID)
+		logCourseChange(courseID, logEntity{"experiment", expID, opUpdate}, "Experiment Updated",
+			fmt.Sprintf("Updated experiment %d: %s", req.ExperimentNumber, req.ExperimentName), requestUser(r), before, after)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -343,14 +356,31 @@ func DeleteExperiment(w http.ResponseWriter, r *http.Request) {
 	// Soft delete experiment and its topics
 	log.Printf("DEBUG DeleteExperiment: Soft deleting experiment ID=%d", expID)
 
+	courseID, before := courseItemLogState("experiment", expID)
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("ERROR DeleteExperiment: Failed to begin transaction:", err)
+		http.Error(w, "Failed to delete experiment", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Keep what is about to be deleted in the recycle bin
+	if err := recordDeletion(tx, "experiment", expID, 0, requestUser(r)); err != nil {
+		log.Println("ERROR DeleteExperiment: Failed to record deletion:", err)
+		http.Error(w, "Failed to delete experiment", http.StatusInternalServerError)
+		return
+	}
+
 	// Soft delete topics first
-	_, err = db.DB.Exec("UPDATE course_experiment_topics SET status = 0 WHERE experiment_id = ?", expID)
+	_, err = tx.Exec("UPDATE course_experiment_topics SET status = 0 WHERE experiment_id = ?", expID)
 	if err != nil {
 		log.Printf("ERROR DeleteExperiment: Failed to soft delete topics: %v", err)
 	}
 
 	// Soft delete experiment
-	result, err := db.DB.Exec("UPDATE course_experiments SET status = 0 WHERE id = ?", expID)
+	result, err := tx.Exec("UPDATE course_experiments SET status = 0 WHERE id = ?", expID)
 	if err != nil {
 		log.Println("ERROR DeleteExperiment: Failed to soft delete experiment:", err)
 		http.Error(w, "Failed to delete experiment", http.StatusInternalServerError)
@@ -358,6 +388,21 @@ func DeleteExperiment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	if courseID > 0 && rowsAffected > 0 {
+		if err := logCourseChangeTx(tx, courseID, logEntity{"experiment", expID, opDelete}, "Experiment Deleted",
+			fmt.Sprintf("Deleted experiment %v: %v", before["experiment_number"], before["experiment_name"]), requestUser(r), before, nil); err != nil {
+			log.Println("ERROR DeleteExperiment: Failed to log deletion:", err)
+			http.Error(w, "Failed to delete experiment", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("ERROR DeleteExperiment: Failed to commit:", err)
+		http.Error(w, "Failed to delete experiment", http.StatusInternalServerError)
+		return
+	}
+
 	log.Printf("DEBUG DeleteExperiment: Soft deleted experiment ID=%d, rows affected: %d", expID, rowsAffected)
 
 	w.WriteHeader(http.StatusNoContent)
@@ -3,6 +3,7 @@ package curriculum
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
@@ -164,8 +165,8 @@ func CreateHonourCard(w http.ResponseWriter, r *http.Request) {
 	card.ID = int(id)
 
 	// Log the activity
-	LogCurriculumActivity(curriculumID, "Honour Card Added",
-		"Added Honour Card: "+card.Title, "System")
+	logCurriculumChange(curriculumID, logEntity{"honour_card", card.ID, opCreate}, "Honour Card Added",
+		"Added Honour Card: "+card.Title, requestUser(r), nil, card)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(card)
@@ -213,6 +214,12 @@ func CreateHonourVertical(w http.ResponseWriter, r *http.Request) {
 	id, _ := result.LastInsertId()
 	vertical.ID = int(id)
 
+	// Log the activity
+	if curriculumID, state := honourVerticalLogState(vertical.ID); curriculumID > 0 {
+		logCurriculumChange(curriculumID, logEntity{"honour_vertical", vertical.ID, opCreate}, "Honour Vertical Added",
+			"Added vertical "+vertical.Name+" to "+fmt.Sprint(state["honour_card"]), requestUser(r), nil, state)
+	}
+
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(vertical)
 }
@@ -259,6 +266,7 @@ func AddCourseToVertical(w http.ResponseWriter, r *http.Request) {
 		CIAMarks          int    `json:"cia_marks,omitempty"`
 		SEEMarks          int    `json:"see_marks,omitempty"`
 		TotalMarks        int    `json:"total_marks,omitempty"`
+		LinkMode          string `json:"link_mode,omitempty"` // "link" or "fork" when the code exists in the catalogue
 	}
 
 	err = json.NewDecoder(r.Body).Decode(&payload)
@@ -289,14 +297,19 @@ func AddCourseToVertical(w http.ResponseWriter, r *http.Request) {
 
 	if payload.CourseID != nil && *payload.CourseID > 0 {
 		// Legacy path: link an existing course by ID
-		var exists bool
-		checkQuery := "SELECT EXISTS(SELECT 1 FROM courses WHERE course_id = ?)"
-		err = db.DB.QueryRow(checkQuery, *payload.CourseID).Scan(&exists)
-		if err != nil || !exists {
+		var owner sql.NullInt64
+		err = db.DB.QueryRow("SELECT owner_curriculum_id FROM courses WHERE course_id = ?", *payload.CourseID).Scan(&owner)
+		if err != nil {
 			w.WriteHeader(http.StatusNotFound)
 			json.NewEncoder(w).Encode(map[string]string{"error": "Course not found"})
 			return
 		}
+		// Another curriculum's fork stays with that curriculum
+		if owner.Valid && int(owner.Int64) != curriculumID {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": "This course is a copy owned by another curriculum and cannot be added here"})
+			return
+		}
 		courseID = *payload.CourseID
 	} else {
 		// New path: create or reuse a course based on course_code (similar to AddCourseToSemester)
@@ -312,18 +325,22 @@ func AddCourseToVertical(w http.ResponseWriter, r *http.Request) {
 		practicalTotal := payload.PracticalTotalHrs
 		activityTotal := payload.ActivityTotalHrs
 
-		// Check if course code already exists in this curriculum
+		// Check if course code already exists in this curriculum, either linked to it or as its own copy
 		var existingCourseID int
 		checkQuery := `SELECT c.course_id FROM courses c 
-		               INNER JOIN curriculum_courses cc ON c.course_id = cc.course_id 
-		               WHERE c.course_code = ? AND cc.curriculum_id = ?`
-		err = db.DB.QueryRow(checkQuery, payload.CourseCode, curriculumID).Scan(&existingCourseID)
+		               LEFT JOIN curriculum_courses cc ON c.course_id = cc.course_id AND cc.curriculum_id = ? AND cc.status = 1
+		               WHERE c.course_code = ? AND (cc.id IS NOT NULL OR c.owner_curriculum_id = ?)
+		               ORDER BY c.status DESC, c.course_id LIMIT 1`
+		err = db.DB.QueryRow(checkQuery, curriculumID, payload.CourseCode, curriculumID).Scan(&existingCourseID)
 
 		if err == sql.ErrNoRows {
-			// Course code doesn't exist in this curriculum, check if it exists globally
+			// Course code doesn't exist in this curriculum, check if it exists in the shared catalogue
 			var globalCourseID int
-			globalCheckQuery := "SELECT course_id FROM courses WHERE course_code = ?"
-			globalErr := db.DB.QueryRow(globalCheckQuery, payload.CourseCode).Scan(&globalCourseID)
+			var globalCourseName string
+			globalCheckQuery := `SELECT course_id, course_name FROM courses
+			                     WHERE course_code = ? AND owner_curriculum_id IS NULL
+			                     ORDER BY status DESC, course_id LIMIT 1`
+			globalErr := db.DB.QueryRow(globalCheckQuery, payload.CourseCode).Scan(&globalCourseID, &globalCourseName)
 
 			if globalErr == sql.ErrNoRows {
 				// Course doesn't exist globally - create new course
@@ -363,13 +380,36 @@ func AddCourseToVertical(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(http.StatusInternalServerError)
 				json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create course"})
 				return
-			} else {
-				// Course exists globally but not in this curriculum - reuse the existing course
+			} else if payload.LinkMode == "link" {
+				// Course exists globally but not in this curriculum - share the existing catalogue course
 				courseID = globalCourseID
 				wasReused = true
 				// Reactivate the course if it was soft-deleted
 				db.DB.Exec("UPDATE courses SET status = 1 WHERE course_id = ?", globalCourseID)
 				log.Printf("Reusing existing course %s (ID: %d) for honour vertical curriculum %d", payload.CourseCode, globalCourseID, curriculumID)
+			} else if payload.LinkMode == "fork" {
+				// Course exists globally - give this curriculum its own copy so edits stay local
+				tx, err := db.DB.Begin()
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create course"})
+					return
+				}
+				courseID, err = forkCourse(tx, globalCourseID, curriculumID)
+				if err == nil {
+					err = tx.Commit()
+				}
+				if err != nil {
+					tx.Rollback()
+					log.Println("Error forking course for honour vertical:", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create course"})
+					return
+				}
+				log.Printf("Forked course %s (ID: %d) as %d for honour vertical curriculum %d", payload.CourseCode, globalCourseID, courseID, curriculumID)
+			} else {
+				writeCatalogueCourseConflict(w, globalCourseID, payload.CourseCode, globalCourseName)
+				return
 			}
 		} else if err != nil {
 			log.Println("Error checking existing course in curriculum for honour vertical:", err)
@@ -398,6 +438,13 @@ func AddCourseToVertical(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Log the activity
+	if curriculumID > 0 {
+		after := courseLogState(courseID, "honour_vertical_id", verticalID)
+		logCurriculumChange(curriculumID, logEntity{"honour_course", courseID, opCreate}, "Honour Course Added",
+			fmt.Sprintf("Added course %v - %v to honour vertical %d", after["course_code"], after["course_name"], verticalID), requestUser(r), nil, after)
+	}
+
 	// Fetch the complete course details including computed fields (matching normal card behavior)
 	var fullCourse models.CourseWithDetails
 	fetchQuery := `SELECT course_id, course_code, course_name, course_type, category, credit, 
@@ -512,8 +559,34 @@ func RemoveCourseFromVertical(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	curriculumID, _ := honourVerticalLogState(verticalID)
+	before := courseLogState(courseID, "honour_vertical_id", verticalID)
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Keep what is about to be deleted in the recycle bin
+	var mappingID int
+	err = tx.QueryRow("SELECT id FROM honour_vertical_courses WHERE honour_vertical_id = ? AND course_id = ? AND status = 1 ORDER BY id LIMIT 1",
+		verticalID, courseID).Scan(&mappingID)
+	if err == nil {
+		err = recordDeletion(tx, "honour_course", mappingID, 0, requestUser(r))
+	}
+	if err != nil {
+		log.Println("Error recording removed honour course:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to remove course"})
+		return
+	}
+
 	// Soft-delete honour_vertical_courses mapping (keep the record)
-	_, err = db.DB.Exec("UPDATE honour_vertical_courses SET status = 0 WHERE honour_vertical_id = ? AND course_id = ? AND status = 1", verticalID, courseID)
+	_, err = tx.Exec("UPDATE honour_vertical_courses SET status = 0 WHERE honour_vertical_id = ? AND course_id = ? AND status = 1", verticalID, courseID)
 	if err != nil {
 		log.Println("Error soft-deleting vertical course mapping:", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -521,20 +594,28 @@ func RemoveCourseFromVertical(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Soft-delete the course itself
-	_, err = db.DB.Exec("UPDATE courses SET status = 0 WHERE course_id = ? AND status = 1", courseID)
-	if err != nil {
+	// Soft-delete the course itself once nothing else uses it
+	if err := softDeleteCourseIfUnused(tx, courseID); err != nil {
 		log.Println("Error soft-deleting course:", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete course"})
 		return
 	}
 
-	// Cascade soft-delete to all course children
-	if err := cascadeSoftDeleteCourse(courseID, nil); err != nil {
-		log.Println("Error cascading course soft-delete:", err)
+	if curriculumID > 0 {
+		if err := logCurriculumChangeTx(tx, curriculumID, logEntity{"honour_course", courseID, opDelete}, "Honour Course Removed",
+			fmt.Sprintf("Removed course %v - %v from honour vertical %d", before["course_code"], before["course_name"], verticalID), requestUser(r), before, nil); err != nil {
+			log.Println("Error logging removed honour course:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to remove course"})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
 		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to course children"})
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to commit transaction"})
 		return
 	}
 
@@ -561,6 +642,8 @@ func DeleteHonourVertical(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	curriculumID, before := honourVerticalLogState(verticalID)
+
 	// Start a transaction for soft-delete cascade
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -571,6 +654,14 @@ func DeleteHonourVertical(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
+	// Keep what is about to be deleted in the recycle bin
+	if err := recordDeletion(tx, "honour_vertical", verticalID, 0, requestUser(r)); err != nil {
+		log.Println("Error recording deleted vertical:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete vertical"})
+		return
+	}
+
 	// Soft delete the honour vertical
 	query := "UPDATE honour_verticals SET status = 0 WHERE id = ? AND status = 1"
 	result, err := tx.Exec(query, verticalID)
@@ -621,18 +712,21 @@ func DeleteHonourVertical(w http.ResponseWriter, r *http.Request) {
 	rows.Close()
 
 	for _, courseID := range courseIDs {
-		_, err = tx.Exec("UPDATE courses SET status = 0 WHERE course_id = ? AND status = 1", courseID)
-		if err != nil {
+		// Courses other curricula still use stay active (DeleteHonourVertical)
+		if err := softDeleteCourseIfUnused(tx, courseID); err != nil {
 			log.Println("Error soft-deleting course:", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to courses"})
 			return
 		}
-		// Cascade soft-delete to course children (DeleteHonourVertical)
-		if err := cascadeSoftDeleteCourse(courseID, tx); err != nil {
-			log.Println("Error cascading course soft-delete:", err)
+	}
+
+	if curriculumID > 0 {
+		if err := logCurriculumChangeTx(tx, curriculumID, logEntity{"honour_vertical", verticalID, opDelete}, "Honour Vertical Deleted",
+			fmt.Sprintf("Deleted vertical %v with %d course(s)", before["name"], len(courseIDs)), requestUser(r), before, nil); err != nil {
+			log.Println("Error logging deleted vertical:", err)
 			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to course children"})
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete vertical"})
 			return
 		}
 	}
@@ -668,6 +762,10 @@ func DeleteHonourCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Capture the card as it was for the activity log
+	var card models.HonourCard
+	db.DB.QueryRow("SELECT id, curriculum_id, title FROM honour_cards WHERE id = ?", cardID).Scan(&card.ID, &card.CurriculumID, &card.Title)
+
 	// Start a transaction for soft-delete cascade
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -678,6 +776,14 @@ func DeleteHonourCard(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
+	// Keep what is about to be deleted in the recycle bin
+	if err := recordDeletion(tx, "honour_card", cardID, 0, requestUser(r)); err != nil {
+		log.Println("Error recording deleted honour card:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete honour card"})
+		return
+	}
+
 	// Soft delete the honour card
 	query := "UPDATE honour_cards SET status = 0 WHERE id = ? AND status = 1"
 	result, err := tx.Exec(query, cardID)
@@ -748,18 +854,21 @@ func DeleteHonourCard(w http.ResponseWriter, r *http.Request) {
 	rows.Close()
 
 	for _, courseID := range courseIDs {
-		_, err = tx.Exec("UPDATE courses SET status = 0 WHERE course_id = ? AND status = 1", courseID)
-		if err != nil {
+		// Courses other curricula still use stay active (DeleteHonourCard)
+		if err := softDeleteCourseIfUnused(tx, courseID); err != nil {
 			log.Println("Error soft-deleting course:", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to courses"})
 			return
 		}
-		// Cascade soft-delete to course children (DeleteHonourCard)
-		if err := cascadeSoftDeleteCourse(courseID, tx); err != nil {
-			log.Println("Error cascading course soft-delete:", err)
+	}
+
+	if card.CurriculumID > 0 {
+		if err := logCurriculumChangeTx(tx, card.CurriculumID, logEntity{"honour_card", cardID, opDelete}, "Honour Card Deleted",
+			fmt.Sprintf("Deleted Honour Card: %s with %d course(s)", card.Title, len(courseIDs)), requestUser(r), card, nil); err != nil {
+			log.Println("Error logging deleted honour card:", err)
 			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to course children"})
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to delete honour card"})
 			return
 		}
 	}
@@ -775,3 +884,18 @@ func DeleteHonourCard(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Honour card deleted successfully"})
 }
+
+// honourVerticalLogState returns the curriculum of an honour vertical and its state for the activity log
+func honourVerticalLogState(verticalID int) (int, map[string]interface{}) {
+	var curriculumID, cardID int
+	var cardTitle, name string
+	err := db.DB.QueryRow(`
+		SELECT hc.curriculum_id, hc.id, hc.title, hv.name
+		FROM honour_verticals hv
+		INNER JOIN honour_cards hc ON hv.honour_card_id = hc.id
+		WHERE hv.id = ?`, verticalID).Scan(&curriculumID, &cardID, &cardTitle, &name)
+	if err != nil {
+		return 0, nil
+	}
+	return curriculumID, map[string]interface{}{"honour_card_id": cardID, "honour_card": cardTitle, "name": name}
+}
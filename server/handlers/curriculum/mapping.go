@@ -9,6 +9,7 @@ import (
 	"server/db"
 	"server/models"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -24,9 +25,35 @@ func GetCourseMapping(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A curriculum pinned to a published version of the course sees that version's mapping
+	curriculumID, err := curriculumIDParam(r)
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+	snapshot, pinned, err := pinnedCourseSnapshot(curriculumID, courseID)
+	if err != nil {
+		log.Println("Error loading pinned course version:", err)
+		http.Error(w, "Failed to load pinned course version", http.StatusInternalServerError)
+		return
+	}
+	if snapshot != nil {
+		coPoJustifications, coPsoJustifications := snapshotMappingJustifications(snapshot.Justifications)
+		json.NewEncoder(w).Encode(models.MappingResponse{
+			COs:                 append([]string{}, snapshot.Outcomes...),
+			COPOMatrix:          snapshot.COPOMapping,
+			COPSOMatrix:         snapshot.COPSOMapping,
+			COPOJustifications:  coPoJustifications,
+			COPSOJustifications: coPsoJustifications,
+			Issues:              []models.MappingIssue{},
+			PinnedVersion:       pinned,
+		})
+		return
+	}
+
 	// Fetch COs from normalized course_outcomes table
 	var cos []string
-	outcomeRows, err := db.DB.Query("SELECT outcome FROM course_outcomes WHERE course_id = ? ORDER BY position", courseID)
+	outcomeRows, err := db.DB.Query("SELECT outcome FROM course_outcomes WHERE course_id = ? AND (status = 1 OR status IS NULL) ORDER BY position", courseID)
 	if err != nil && err != sql.ErrNoRows {
 		log.Println("Error fetching course outcomes:", err)
 		http.Error(w, "Failed to fetch course outcomes", http.StatusInternalServerError)
@@ -49,40 +76,61 @@ func GetCourseMapping(w http.ResponseWriter, r *http.Request) {
 
 	// Fetch existing CO-PO mappings
 	coPoMatrix := make(map[string]int)
-	rows, err := db.DB.Query("SELECT co_index, po_index, mapping_value FROM co_po_mapping WHERE course_id = ?", courseID)
+	coPoJustifications := make(map[string]string)
+	rows, err := db.DB.Query("SELECT co_index, po_index, mapping_value, COALESCE(justification, '') FROM co_po_mapping WHERE course_id = ?", courseID)
 	if err != nil {
 		log.Println("Error fetching CO-PO mappings:", err)
 	} else {
 		defer rows.Close()
 		for rows.Next() {
 			var coIndex, poIndex, value int
-			if err := rows.Scan(&coIndex, &poIndex, &value); err == nil {
+			var justification string
+			if err := rows.Scan(&coIndex, &poIndex, &value, &justification); err == nil {
 				key := fmt.Sprintf("%d-%d", coIndex, poIndex)
 				coPoMatrix[key] = value
+				if justification != "" {
+					coPoJustifications[key] = justification
+				}
 			}
 		}
 	}
 
 	// Fetch existing CO-PSO mappings
 	coPsoMatrix := make(map[string]int)
-	rows, err = db.DB.Query("SELECT co_index, pso_index, mapping_value FROM co_pso_mapping WHERE course_id = ?", courseID)
+	coPsoJustifications := make(map[string]string)
+	rows, err = db.DB.Query("SELECT co_index, pso_index, mapping_value, COALESCE(justification, '') FROM co_pso_mapping WHERE course_id = ?", courseID)
 	if err != nil {
 		log.Println("Error fetching CO-PSO mappings:", err)
 	} else {
 		defer rows.Close()
 		for rows.Next() {
 			var coIndex, psoIndex, value int
-			if err := rows.Scan(&coIndex, &psoIndex, &value); err == nil {
+			var justification string
+			if err := rows.Scan(&coIndex, &psoIndex, &value, &justification); err == nil {
 				key := fmt.Sprintf("%d-%d", coIndex, psoIndex)
 				coPsoMatrix[key] = value
+				if justification != "" {
+					coPsoJustifications[key] = justification
+				}
 			}
 		}
 	}
 
+	// Report orphaned or inconsistent cells so the editor can flag them
+	issues := []models.MappingIssue{}
+	if validation, err := validateCourseMappings(courseID); err != nil {
+		log.Println("Error validating course mappings:", err)
+	} else {
+		issues = validation.Issues
+	}
+
 	response := models.MappingResponse{
-		COs:         cos,
-		COPOMatrix:  coPoMatrix,
-		COPSOMatrix: coPsoMatrix,
+		COs:                 cos,
+		COPOMatrix:          coPoMatrix,
+		COPSOMatrix:         coPsoMatrix,
+		COPOJustifications:  coPoJustifications,
+		COPSOJustifications: coPsoJustifications,
+		Issues:              issues,
 	}
 
 	json.NewEncoder(w).Encode(response)
@@ -106,30 +154,30 @@ func SaveCourseMapping(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fetch existing mappings for diff
-	oldCOPO := make(map[string]int)
-	oldCOPSO := make(map[string]int)
-	oldRows, _ := db.DB.Query("SELECT co_index, po_index, mapping_value FROM co_po_mapping WHERE course_id = ?", courseID)
-	if oldRows != nil {
-		for oldRows.Next() {
-			var coIndex, poIndex, value int
-			if oldRows.Scan(&coIndex, &poIndex, &value) == nil {
-				key := fmt.Sprintf("CO%d-PO%d", coIndex, poIndex)
-				oldCOPO[key] = value
-			}
-		}
-		oldRows.Close()
+	// Validate against the course's outcomes and the curriculum's POs/PSOs
+	bounds, err := fetchMappingBounds(courseID)
+	if err != nil {
+		log.Println("Error fetching mapping bounds:", err)
+		http.Error(w, "Failed to save mappings", http.StatusInternalServerError)
+		return
 	}
-	oldRows2, _ := db.DB.Query("SELECT co_index, pso_index, mapping_value FROM co_pso_mapping WHERE course_id = ?", courseID)
-	if oldRows2 != nil {
-		for oldRows2.Next() {
-			var coIndex, psoIndex, value int
-			if oldRows2.Scan(&coIndex, &psoIndex, &value) == nil {
-				key := fmt.Sprintf("CO%d-PSO%d", coIndex, psoIndex)
-				oldCOPSO[key] = value
-			}
-		}
-		oldRows2.Close()
+	if problems := validateMappingRequest(request, bounds); len(problems) > 0 {
+		http.Error(w, "Invalid mappings: "+strings.Join(problems, "; "), http.StatusBadRequest)
+		return
+	}
+
+	// Fetch existing mappings for the diff and to keep justifications the client did not send
+	oldCOPO, oldPONotes, err := fetchMappingCells(courseID, "co_po_mapping", "po_index", "PO")
+	if err != nil {
+		log.Println("Error fetching existing CO-PO mappings:", err)
+		http.Error(w, "Failed to save mappings", http.StatusInternalServerError)
+		return
+	}
+	oldCOPSO, oldPSONotes, err := fetchMappingCells(courseID, "co_pso_mapping", "pso_index", "PSO")
+	if err != nil {
+		log.Println("Error fetching existing CO-PSO mappings:", err)
+		http.Error(w, "Failed to save mappings", http.StatusInternalServerError)
+		return
 	}
 
 	// Start transaction
@@ -152,9 +200,11 @@ func SaveCourseMapping(w http.ResponseWriter, r *http.Request) {
 	// Insert new CO-PO mappings
 	for _, mapping := range request.COPOMatrix {
 		_, err = tx.Exec(`
-			INSERT INTO co_po_mapping (course_id, co_index, po_index, mapping_value)
-			VALUES (?, ?, ?, ?)
-		`, courseID, mapping.COIndex, mapping.POIndex, mapping.MappingValue)
+			INSERT INTO co_po_mapping (course_id, co_index, po_index, mapping_value, justification, outcome_id)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, courseID, mapping.COIndex, mapping.POIndex, mapping.MappingValue,
+			mappingJustification(mapping.Justification, oldPONotes, fmt.Sprintf("CO%d-PO%d", mapping.COIndex, mapping.POIndex)),
+			bounds.OutcomeIDs[mapping.COIndex])
 		if err != nil {
 			log.Println("Error inserting CO-PO mapping:", err)
 			http.Error(w, "Failed to save CO-PO mappings", http.StatusInternalServerError)
@@ -173,9 +223,11 @@ func SaveCourseMapping(w http.ResponseWriter, r *http.Request) {
 	// Insert new CO-PSO mappings
 	for _, mapping := range request.COPSOMatrix {
 		_, err = tx.Exec(`
-			INSERT INTO co_pso_mapping (course_id, co_index, pso_index, mapping_value)
-			VALUES (?, ?, ?, ?)
-		`, courseID, mapping.COIndex, mapping.PSOIndex, mapping.MappingValue)
+			INSERT INTO co_pso_mapping (course_id, co_index, pso_index, mapping_value, justification, outcome_id)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, courseID, mapping.COIndex, mapping.PSOIndex, mapping.MappingValue,
+			mappingJustification(mapping.Justification, oldPSONotes, fmt.Sprintf("CO%d-PSO%d", mapping.COIndex, mapping.PSOIndex)),
+			bounds.OutcomeIDs[mapping.COIndex])
 		if err != nil {
 			log.Println("Error inserting CO-PSO mapping:", err)
 			http.Error(w, "Failed to save CO-PSO mappings", http.StatusInternalServerError)
@@ -183,21 +235,14 @@ func SaveCourseMapping(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		log.Println("Error committing transaction:", err)
-		http.Error(w, "Failed to save mappings", http.StatusInternalServerError)
-		return
-	}
-
 	// Get curriculum ID and course name for logging
 	var curriculumID int
 	var courseName string
-	db.DB.QueryRow(`
+	tx.QueryRow(`
 		SELECT rc.curriculum_id, c.course_name 
 		FROM curriculum_courses rc 
 		JOIN courses c ON rc.course_id = c.course_id 
-		WHERE rc.course_id = ? LIMIT 1
+		WHERE rc.course_id = ? AND rc.status = 1 LIMIT 1
 	`, courseID).Scan(&curriculumID, &courseName)
 
 	if curriculumID > 0 {
@@ -217,8 +262,20 @@ func SaveCourseMapping(w http.ResponseWriter, r *http.Request) {
 		diff["co_po_mappings"] = map[string]interface{}{"old": oldCOPO, "new": newCOPO}
 		diff["co_pso_mappings"] = map[string]interface{}{"old": oldCOPSO, "new": newCOPSO}
 
-		LogCurriculumActivityWithDiff(curriculumID, "CO-PO/PSO Mapping Saved",
-			"Updated CO-PO and CO-PSO mappings for course: "+courseName, "System", diff)
+		// Logged in the same transaction so the saved mappings never lose their log entry
+		if err := logCurriculumDiffTx(tx, curriculumID, logEntity{"course_mapping", courseID, opUpdate}, "CO-PO/PSO Mapping Saved",
+			"Updated CO-PO and CO-PSO mappings for course: "+courseName, requestUser(r), diff); err != nil {
+			log.Println("Error logging mapping change:", err)
+			http.Error(w, "Failed to save mappings", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		http.Error(w, "Failed to save mappings", http.StatusInternalServerError)
+		return
 	}
 
 	json.NewEncoder(w).Encode(map[string]string{"message": "Mappings saved successfully"})
@@ -0,0 +1,344 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxMappingValue is the strongest correlation level of a CO-PO/PSO mapping (1 low, 2 medium, 3 high)
+const maxMappingValue = 3
+
+// mappingBounds holds what a course's mapping cells may refer to
+// OutcomeIDs[i] is the course_outcomes id of CO at co_index i
+type mappingBounds struct {
+	OutcomeIDs    []int
+	POCount       int
+	PSOCount      int
+	HasCurriculum bool
+}
+
+// nullIfEmpty stores blank text as NULL
+func nullIfEmpty(s string) sql.NullString {
+	s = strings.TrimSpace(s)
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// mappingJustification is the justification to store for a saved cell: the one sent with it, or
+// when the client left it out, the one the cell already had
+func mappingJustification(sent *string, previous map[string]string, cell string) sql.NullString {
+	if sent != nil {
+		return nullIfEmpty(*sent)
+	}
+	return nullIfEmpty(previous[cell])
+}
+
+// fetchMappingCells loads the stored values and justifications of one mapping matrix of a course,
+// keyed by cell as "CO<co_index>-<kind><outcome_index>"
+func fetchMappingCells(courseID int, table, column, kind string) (map[string]int, map[string]string, error) {
+	rows, err := db.DB.Query(fmt.Sprintf(`
+		SELECT co_index, %s, mapping_value, COALESCE(justification, '')
+		FROM %s WHERE course_id = ?`, column, table), courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]int)
+	notes := make(map[string]string)
+	for rows.Next() {
+		var coIndex, outcomeIndex, value int
+		var justification string
+		if err := rows.Scan(&coIndex, &outcomeIndex, &value, &justification); err != nil {
+			return nil, nil, err
+		}
+		key := fmt.Sprintf("CO%d-%s%d", coIndex, kind, outcomeIndex)
+		values[key] = value
+		if justification != "" {
+			notes[key] = justification
+		}
+	}
+	return values, notes, rows.Err()
+}
+
+// fetchOutcomeIDs returns the course_outcomes ids of a course's active COs, indexed by co_index
+func fetchOutcomeIDs(courseID int) ([]int, error) {
+	rows, err := db.DB.Query(`
+		SELECT id FROM course_outcomes
+		WHERE course_id = ? AND (status = 1 OR status IS NULL)
+		ORDER BY position`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// fetchMappingBounds loads the active COs of a course and the PO/PSO counts of its curriculum
+func fetchMappingBounds(courseID int) (*mappingBounds, error) {
+	outcomeIDs, err := fetchOutcomeIDs(courseID)
+	if err != nil {
+		return nil, err
+	}
+	bounds := &mappingBounds{OutcomeIDs: outcomeIDs}
+
+	var curriculumID int
+	err = db.DB.QueryRow(`
+		SELECT curriculum_id FROM curriculum_courses
+		WHERE course_id = ? AND status = 1
+		ORDER BY curriculum_id LIMIT 1`, courseID).Scan(&curriculumID)
+	if err == sql.ErrNoRows {
+		return bounds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bounds.HasCurriculum = true
+	bounds.POCount = len(fetchDepartmentList(curriculumID, "curriculum_pos", "po_text"))
+	bounds.PSOCount = len(fetchDepartmentList(curriculumID, "curriculum_psos", "pso_text"))
+	return bounds, nil
+}
+
+// checkMappingCell returns a message describing why a mapping cell is invalid, or "" when it is valid
+func checkMappingCell(bounds *mappingBounds, kind string, coIndex, outcomeIndex, value int) string {
+	if coIndex < 0 || coIndex >= len(bounds.OutcomeIDs) {
+		return fmt.Sprintf("CO%d does not exist (course has %d outcomes)", coIndex+1, len(bounds.OutcomeIDs))
+	}
+	outcomeCount := bounds.POCount
+	if kind == "PSO" {
+		outcomeCount = bounds.PSOCount
+	}
+	if outcomeIndex < 1 || (bounds.HasCurriculum && outcomeIndex > outcomeCount) {
+		return fmt.Sprintf("%s%d does not exist (curriculum has %d %ss)", kind, outcomeIndex, outcomeCount, kind)
+	}
+	if value < 0 || value > maxMappingValue {
+		return fmt.Sprintf("CO%d-%s%d has mapping value %d; values must be between 0 and %d",
+			coIndex+1, kind, outcomeIndex, value, maxMappingValue)
+	}
+	return ""
+}
+
+// validateMappingRequest checks every cell of a mapping request against the course's COs and curriculum POs/PSOs
+func validateMappingRequest(request models.MappingRequest, bounds *mappingBounds) []string {
+	problems := []string{}
+	seen := make(map[string]bool)
+
+	for _, m := range request.COPOMatrix {
+		if msg := checkMappingCell(bounds, "PO", m.COIndex, m.POIndex, m.MappingValue); msg != "" {
+			problems = append(problems, msg)
+		}
+		key := fmt.Sprintf("PO-%d-%d", m.COIndex, m.POIndex)
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("CO%d-PO%d is mapped more than once", m.COIndex+1, m.POIndex))
+		}
+		seen[key] = true
+	}
+	for _, m := range request.COPSOMatrix {
+		if msg := checkMappingCell(bounds, "PSO", m.COIndex, m.PSOIndex, m.MappingValue); msg != "" {
+			problems = append(problems, msg)
+		}
+		key := fmt.Sprintf("PSO-%d-%d", m.COIndex, m.PSOIndex)
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("CO%d-PSO%d is mapped more than once", m.COIndex+1, m.PSOIndex))
+		}
+		seen[key] = true
+	}
+
+	return problems
+}
+
+// detectMappingIssues inspects the stored mappings of a course for orphaned, out-of-range,
+// invalid or unjustified cells. A cell is orphaned when the outcome it was saved against has
+// since been deleted or moved to another position in course_outcomes
+func detectMappingIssues(courseID int, bounds *mappingBounds) ([]models.MappingIssue, error) {
+	positionOf := make(map[int]int)
+	for i, id := range bounds.OutcomeIDs {
+		positionOf[id] = i
+	}
+
+	issues := []models.MappingIssue{}
+	for _, source := range []struct{ kind, table, column string }{
+		{"PO", "co_po_mapping", "po_index"},
+		{"PSO", "co_pso_mapping", "pso_index"},
+	} {
+		rows, err := db.DB.Query(fmt.Sprintf(`
+			SELECT co_index, %s, mapping_value, outcome_id, COALESCE(justification, '')
+			FROM %s WHERE course_id = ?
+			ORDER BY co_index, %s`, source.column, source.table, source.column), courseID)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var coIndex, outcomeIndex, value int
+			var outcomeID sql.NullInt64
+			var justification string
+			if err := rows.Scan(&coIndex, &outcomeIndex, &value, &outcomeID, &justification); err != nil {
+				log.Println("Error scanning mapping:", err)
+				continue
+			}
+
+			if issue := checkStoredMappingCell(bounds, positionOf, source.kind, coIndex, outcomeIndex, value, outcomeID, justification); issue != nil {
+				issues = append(issues, *issue)
+			}
+		}
+		rows.Close()
+	}
+
+	return issues, nil
+}
+
+// checkStoredMappingCell returns the issue of one stored mapping cell, or nil when it has none.
+// positionOf maps a course_outcomes id to its current co_index
+func checkStoredMappingCell(bounds *mappingBounds, positionOf map[int]int, kind string, coIndex, outcomeIndex, value int,
+	outcomeID sql.NullInt64, justification string) *models.MappingIssue {
+	issue := &models.MappingIssue{
+		Matrix:       "CO-" + kind,
+		COIndex:      coIndex,
+		OutcomeIndex: outcomeIndex,
+	}
+	cell := fmt.Sprintf("CO%d-%s%d", coIndex+1, kind, outcomeIndex)
+
+	if outcomeID.Valid {
+		if pos, ok := positionOf[int(outcomeID.Int64)]; !ok {
+			issue.IssueType = "ORPHANED"
+			issue.Message = fmt.Sprintf("%s was mapped to an outcome that has since been deleted", cell)
+			return issue
+		} else if pos != coIndex {
+			issue.IssueType = "ORPHANED"
+			issue.Message = fmt.Sprintf("%s was mapped to an outcome that is now CO%d", cell, pos+1)
+			return issue
+		}
+	}
+
+	if msg := checkMappingCell(bounds, kind, coIndex, outcomeIndex, value); msg != "" {
+		issue.IssueType = "OUT_OF_RANGE"
+		if value < 0 || value > maxMappingValue {
+			issue.IssueType = "INVALID_VALUE"
+		}
+		issue.Message = msg
+		return issue
+	}
+
+	if value > 0 && strings.TrimSpace(justification) == "" {
+		issue.IssueType = "MISSING_JUSTIFICATION"
+		issue.Message = fmt.Sprintf("%s (level %d) has no justification", cell, value)
+		return issue
+	}
+	return nil
+}
+
+// validateCourseMappings builds the mapping validation report of a course
+func validateCourseMappings(courseID int) (*models.MappingValidation, error) {
+	bounds, err := fetchMappingBounds(courseID)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := detectMappingIssues(courseID, bounds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MappingValidation{
+		CourseID: courseID,
+		COCount:  len(bounds.OutcomeIDs),
+		POCount:  bounds.POCount,
+		PSOCount: bounds.PSOCount,
+		Valid:    len(issues) == 0,
+		Issues:   issues,
+	}, nil
+}
+
+// GetCourseMappingValidation handles GET /course/:courseId/mapping/validation
+func GetCourseMappingValidation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	validation, err := validateCourseMappings(courseID)
+	if err != nil {
+		log.Println("Error validating course mappings:", err)
+		http.Error(w, "Failed to validate mappings", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(validation)
+}
+
+// GetCurriculumMappingIssues handles GET /curriculum/:id/mapping-issues
+// Only courses with at least one issue are returned
+func GetCurriculumMappingIssues(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT c.course_id, c.course_code, c.course_name
+		FROM courses c
+		INNER JOIN curriculum_courses cc ON c.course_id = cc.course_id
+		WHERE cc.curriculum_id = ? AND cc.status = 1 AND c.status = 1
+		ORDER BY c.course_code`, curriculumID)
+	if err != nil {
+		log.Println("Error fetching curriculum courses:", err)
+		http.Error(w, "Failed to validate mappings", http.StatusInternalServerError)
+		return
+	}
+
+	type courseRef struct {
+		ID   int
+		Code string
+		Name string
+	}
+	courses := []courseRef{}
+	for rows.Next() {
+		var c courseRef
+		if err := rows.Scan(&c.ID, &c.Code, &c.Name); err == nil {
+			courses = append(courses, c)
+		}
+	}
+	rows.Close()
+
+	results := []models.MappingValidation{}
+	for _, c := range courses {
+		validation, err := validateCourseMappings(c.ID)
+		if err != nil {
+			log.Printf("Error validating mappings for course %d: %v", c.ID, err)
+			continue
+		}
+		if validation.Valid {
+			continue
+		}
+		validation.CourseCode = c.Code
+		validation.CourseName = c.Name
+		results = append(results, *validation)
+	}
+
+	json.NewEncoder(w).Encode(results)
+}
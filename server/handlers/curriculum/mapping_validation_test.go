@@ -0,0 +1,123 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"server/models"
+	"strings"
+	"testing"
+)
+
+func TestValidateMappingRequest(t *testing.T) {
+	bounds := &mappingBounds{OutcomeIDs: []int{11, 12, 13}, POCount: 12, PSOCount: 2, HasCurriculum: true}
+
+	tests := []struct {
+		name    string
+		body    string
+		problem string
+	}{
+		{"valid", `{"co_po_matrix":[{"co_index":0,"po_index":1,"mapping_value":3}],"co_pso_matrix":[{"co_index":2,"pso_index":2,"mapping_value":1}]}`, ""},
+		{"unknown CO", `{"co_po_matrix":[{"co_index":3,"po_index":1,"mapping_value":2}]}`, "CO4 does not exist"},
+		{"negative CO", `{"co_po_matrix":[{"co_index":-1,"po_index":1,"mapping_value":2}]}`, "CO0 does not exist"},
+		{"PO out of range", `{"co_po_matrix":[{"co_index":0,"po_index":13,"mapping_value":2}]}`, "PO13 does not exist"},
+		{"PO zero", `{"co_po_matrix":[{"co_index":0,"po_index":0,"mapping_value":2}]}`, "PO0 does not exist"},
+		{"PSO out of range", `{"co_pso_matrix":[{"co_index":0,"pso_index":3,"mapping_value":2}]}`, "PSO3 does not exist"},
+		{"value too high", `{"co_po_matrix":[{"co_index":0,"po_index":1,"mapping_value":4}]}`, "values must be between 0 and 3"},
+		{"duplicate cell", `{"co_po_matrix":[{"co_index":1,"po_index":2,"mapping_value":1},{"co_index":1,"po_index":2,"mapping_value":2}]}`, "CO2-PO2 is mapped more than once"},
+		{"same cell in both matrices", `{"co_po_matrix":[{"co_index":0,"po_index":1,"mapping_value":1}],"co_pso_matrix":[{"co_index":0,"pso_index":1,"mapping_value":1}]}`, ""},
+	}
+	for _, tt := range tests {
+		var request models.MappingRequest
+		if err := json.Unmarshal([]byte(tt.body), &request); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		problems := validateMappingRequest(request, bounds)
+		if tt.problem == "" {
+			if len(problems) > 0 {
+				t.Errorf("%s: unexpected problems %v", tt.name, problems)
+			}
+			continue
+		}
+		if len(problems) != 1 || !strings.Contains(problems[0], tt.problem) {
+			t.Errorf("%s: problems = %v, want one containing %q", tt.name, problems, tt.problem)
+		}
+	}
+}
+
+func TestValidateMappingRequestWithoutCurriculum(t *testing.T) {
+	// Without a curriculum the PO/PSO counts are unknown, so only the lower bound is checked
+	bounds := &mappingBounds{OutcomeIDs: []int{11}}
+	request := models.MappingRequest{COPOMatrix: []models.COPOMapping{{COIndex: 0, POIndex: 20, MappingValue: 1}}}
+	if problems := validateMappingRequest(request, bounds); len(problems) > 0 {
+		t.Errorf("unexpected problems %v", problems)
+	}
+}
+
+func TestCheckStoredMappingCell(t *testing.T) {
+	bounds := &mappingBounds{OutcomeIDs: []int{11, 12}, POCount: 12, PSOCount: 2, HasCurriculum: true}
+	positionOf := map[int]int{11: 0, 12: 1}
+	outcome := func(id int64) sql.NullInt64 { return sql.NullInt64{Int64: id, Valid: true} }
+
+	tests := []struct {
+		name          string
+		kind          string
+		coIndex       int
+		outcomeIndex  int
+		value         int
+		outcomeID     sql.NullInt64
+		justification string
+		issueType     string
+	}{
+		{"justified", "PO", 0, 1, 3, outcome(11), "Designs circuits", ""},
+		{"unmapped needs no justification", "PO", 1, 2, 0, outcome(12), "", ""},
+		{"legacy row without outcome id", "PO", 0, 1, 2, sql.NullInt64{}, "Because", ""},
+		{"deleted outcome", "PO", 0, 1, 2, outcome(99), "Because", "ORPHANED"},
+		{"moved outcome", "PO", 0, 1, 2, outcome(12), "Because", "ORPHANED"},
+		{"CO beyond outcomes", "PO", 2, 1, 2, sql.NullInt64{}, "Because", "OUT_OF_RANGE"},
+		{"PSO beyond curriculum", "PSO", 0, 3, 2, outcome(11), "Because", "OUT_OF_RANGE"},
+		{"invalid value", "PO", 0, 1, 5, outcome(11), "Because", "INVALID_VALUE"},
+		{"missing justification", "PSO", 1, 2, 1, outcome(12), "  ", "MISSING_JUSTIFICATION"},
+	}
+	for _, tt := range tests {
+		issue := checkStoredMappingCell(bounds, positionOf, tt.kind, tt.coIndex, tt.outcomeIndex, tt.value, tt.outcomeID, tt.justification)
+		got := ""
+		if issue != nil {
+			got = issue.IssueType
+			if issue.Matrix != "CO-"+tt.kind || issue.COIndex != tt.coIndex || issue.OutcomeIndex != tt.outcomeIndex {
+				t.Errorf("%s: issue located at %s CO%d/%d", tt.name, issue.Matrix, issue.COIndex, issue.OutcomeIndex)
+			}
+		}
+		if got != tt.issueType {
+			t.Errorf("%s: issue = %q, want %q", tt.name, got, tt.issueType)
+		}
+	}
+}
+
+func TestMappingJustificationKeepsOmittedJustification(t *testing.T) {
+	// The mapping page saves only co_index, po_index and mapping_value
+	var request models.MappingRequest
+	body := `{"co_po_matrix":[
+		{"co_index":0,"po_index":1,"mapping_value":3},
+		{"co_index":0,"po_index":2,"mapping_value":2,"justification":"Rewritten"},
+		{"co_index":1,"po_index":1,"mapping_value":1,"justification":""},
+		{"co_index":1,"po_index":3,"mapping_value":2}
+	]}`
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		t.Fatal(err)
+	}
+	previous := map[string]string{"CO0-PO1": "Kept", "CO0-PO2": "Old", "CO1-PO1": "Cleared"}
+
+	want := []sql.NullString{
+		{String: "Kept", Valid: true},
+		{String: "Rewritten", Valid: true},
+		{},
+		{},
+	}
+	for i, m := range request.COPOMatrix {
+		cell := fmt.Sprintf("CO%d-PO%d", m.COIndex, m.POIndex)
+		if got := mappingJustification(m.Justification, previous, cell); got != want[i] {
+			t.Errorf("%s: justification = %+v, want %+v", cell, got, want[i])
+		}
+	}
+}
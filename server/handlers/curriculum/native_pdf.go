@@ -0,0 +1,320 @@
+package curriculum
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	nativePDFFont       = "Times"
+	nativePDFLineHeight = 5.5
+	nativePDFCellHeight = 4.5
+)
+
+// nativePDFDocument writes documents straight to PDF with the built-in PDF fonts, so it needs
+// no browser or LaTeX. Text outside Windows-1252 cannot be shown by those fonts
+type nativePDFDocument struct {
+	pdf *fpdf.Fpdf
+	tr  func(string) string
+	// fresh is set while nothing has been written on the current page
+	fresh bool
+}
+
+func newNativePDFDocument(setup pdfPageSetup) *nativePDFDocument {
+	orientation := "P"
+	if setup.Landscape {
+		orientation = "L"
+	}
+	pdf := fpdf.New(orientation, "mm", setup.PaperSize, "")
+	top, right, bottom, left := setup.Margins[0], setup.Margins[1], setup.Margins[2], setup.Margins[3]
+	pdf.SetMargins(left, top, right)
+	pdf.SetAutoPageBreak(true, bottom+2)
+	pdf.AliasNbPages("")
+	d := &nativePDFDocument{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor(""), fresh: true}
+
+	pdf.SetHeaderFunc(func() {
+		if setup.Draft {
+			d.watermark("DRAFT")
+		}
+		if setup.HeaderText != "" {
+			pdf.SetY(top * 0.4)
+			pdf.SetFont(nativePDFFont, "I", 8)
+			pdf.CellFormat(0, 4, d.tr(setup.HeaderText), "", 0, "C", false, 0, "")
+			pdf.SetY(top)
+		}
+	})
+	pdf.SetFooterFunc(func() {
+		footer := setup.footerLine(setup.FooterText, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()))
+		if footer == "" {
+			return
+		}
+		pdf.SetY(-bottom)
+		pdf.SetFont(nativePDFFont, "I", 8)
+		pdf.CellFormat(0, 5, d.tr(footer), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	return d
+}
+
+// watermark draws large, faint diagonal text across the page, beneath its content
+func (d *nativePDFDocument) watermark(text string) {
+	pageWidth, pageHeight := d.pdf.GetPageSize()
+	d.pdf.SetFont(nativePDFFont, "B", 100)
+	d.pdf.SetTextColor(200, 0, 0)
+	d.pdf.SetAlpha(0.12, "Normal")
+	d.pdf.TransformBegin()
+	d.pdf.TransformRotate(35, pageWidth/2, pageHeight/2)
+	d.pdf.Text(pageWidth/2-d.pdf.GetStringWidth(text)/2, pageHeight/2+12, text)
+	d.pdf.TransformEnd()
+	d.pdf.SetAlpha(1, "Normal")
+	d.pdf.SetTextColor(0, 0, 0)
+}
+
+// Logo places an image centred on the page, 30mm wide; images that cannot be read are skipped
+func (d *nativePDFDocument) Logo(path string) {
+	const width = 30.0
+	opts := fpdf.ImageOptions{ReadDpi: true}
+	info := d.pdf.RegisterImageOptions(path, opts)
+	if d.pdf.Err() {
+		log.Println("Error reading PDF logo:", d.pdf.Error())
+		d.pdf.ClearError()
+		return
+	}
+	height := width * info.Height() / info.Width()
+	pageWidth, _ := d.pdf.GetPageSize()
+	d.pdf.ImageOptions(path, (pageWidth-width)/2, d.pdf.GetY(), width, height, false, opts, 0, "")
+	d.pdf.SetY(d.pdf.GetY() + height + 4)
+	d.fresh = false
+}
+
+func (d *nativePDFDocument) contentWidth() float64 {
+	pageWidth, _ := d.pdf.GetPageSize()
+	left, _, right, _ := d.pdf.GetMargins()
+	return pageWidth - left - right
+}
+
+func (d *nativePDFDocument) text(style string, size float64, align, text string) {
+	d.pdf.SetFont(nativePDFFont, style, size)
+	d.pdf.MultiCell(0, size*0.5, d.tr(text), "", align, false)
+	d.fresh = false
+}
+
+// Title adds a centred document title
+func (d *nativePDFDocument) Title(text string) {
+	d.pdf.Ln(4)
+	d.text("B", 18, "C", text)
+	d.pdf.Ln(3)
+}
+
+// Heading adds a heading of level 1-4
+func (d *nativePDFDocument) Heading(level int, text string) {
+	sizes := map[int]float64{1: 15, 2: 13, 3: 12, 4: 11}
+	size, ok := sizes[level]
+	if !ok {
+		size = 11
+	}
+	// Keep a heading on the same page as the first lines below it
+	_, pageHeight := d.pdf.GetPageSize()
+	_, _, _, bottom := d.pdf.GetMargins()
+	if d.pdf.GetY()+size+3*nativePDFLineHeight > pageHeight-bottom {
+		d.PageBreak()
+	}
+	if !d.fresh {
+		d.pdf.Ln(3)
+	}
+	style := "B"
+	if level >= 4 {
+		style = "BI"
+	}
+	d.text(style, size, "L", text)
+	d.pdf.Ln(1)
+}
+
+// Paragraph adds a plain paragraph
+func (d *nativePDFDocument) Paragraph(text string) {
+	d.text("", 11, "L", text)
+	d.pdf.Ln(1)
+}
+
+// Centered adds a centred paragraph
+func (d *nativePDFDocument) Centered(text string, bold bool) {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	d.text(style, 11, "C", text)
+	d.pdf.Ln(1)
+}
+
+// Bold adds a paragraph set in bold
+func (d *nativePDFDocument) Bold(text string) {
+	d.text("B", 11, "L", text)
+}
+
+// List adds one indented item per line, numbered when ordered is set and bulleted otherwise
+func (d *nativePDFDocument) List(items []string, ordered bool) {
+	d.list(items, ordered, 6)
+}
+
+// SubList adds a bulleted list indented one level deeper than List
+func (d *nativePDFDocument) SubList(items []string) {
+	d.list(items, false, 12)
+}
+
+func (d *nativePDFDocument) list(items []string, ordered bool, indent float64) {
+	left, _, _, _ := d.pdf.GetMargins()
+	const markerWidth = 7.0
+	d.pdf.SetFont(nativePDFFont, "", 11)
+	for i, item := range items {
+		marker := "•"
+		if ordered {
+			marker = fmt.Sprintf("%d.", i+1)
+		}
+		d.pdf.SetX(left + indent)
+		d.pdf.CellFormat(markerWidth, nativePDFLineHeight, d.tr(marker), "", 0, "L", false, 0, "")
+		d.pdf.MultiCell(d.contentWidth()-indent-markerWidth, nativePDFLineHeight, d.tr(item), "", "L", false)
+		d.fresh = false
+	}
+	d.pdf.Ln(1)
+}
+
+// Table adds a bordered table; the first row is the header, set in bold and repeated on every page
+func (d *nativePDFDocument) Table(rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+	widths := d.columnWidths(rows)
+	left, _, _, bottom := d.pdf.GetMargins()
+	_, pageHeight := d.pdf.GetPageSize()
+
+	// Rows are broken across pages here, not by MultiCell
+	d.pdf.SetAutoPageBreak(false, bottom)
+	defer d.pdf.SetAutoPageBreak(true, bottom)
+
+	for r, row := range rows {
+		style := ""
+		if r == 0 {
+			style = "B"
+		}
+		height := d.rowHeight(row, widths, style)
+		if r > 0 && d.pdf.GetY()+height > pageHeight-bottom {
+			d.PageBreak()
+			d.tableRow(rows[0], widths, "B", d.rowHeight(rows[0], widths, "B"), left)
+		}
+		d.tableRow(row, widths, style, height, left)
+	}
+	d.pdf.Ln(3)
+}
+
+func (d *nativePDFDocument) tableRow(row []string, widths []float64, style string, height, left float64) {
+	d.pdf.SetFont(nativePDFFont, style, 9)
+	y := d.pdf.GetY()
+	x := left
+	for c, cell := range row {
+		if c >= len(widths) {
+			break
+		}
+		d.pdf.Rect(x, y, widths[c], height, "D")
+		d.pdf.SetXY(x+1, y+1)
+		d.pdf.MultiCell(widths[c]-2, nativePDFCellHeight, d.tr(cell), "", "L", false)
+		x += widths[c]
+	}
+	d.pdf.SetXY(left, y+height)
+	d.fresh = false
+}
+
+func (d *nativePDFDocument) rowHeight(row []string, widths []float64, style string) float64 {
+	d.pdf.SetFont(nativePDFFont, style, 9)
+	lines := 1
+	for c, cell := range row {
+		if c >= len(widths) {
+			break
+		}
+		if n := len(d.pdf.SplitLines([]byte(d.tr(cell)), widths[c]-2)); n > lines {
+			lines = n
+		}
+	}
+	return float64(lines)*nativePDFCellHeight + 2
+}
+
+// columnWidths sizes columns by their widest cell, shrinking them to fit the page when needed
+func (d *nativePDFDocument) columnWidths(rows [][]string) []float64 {
+	available := d.contentWidth()
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	widths := make([]float64, columns)
+	total := 0.0
+	for c := range widths {
+		widest := 0.0
+		for r, row := range rows {
+			if c >= len(row) {
+				continue
+			}
+			style := ""
+			if r == 0 {
+				style = "B"
+			}
+			d.pdf.SetFont(nativePDFFont, style, 9)
+			if w := d.pdf.GetStringWidth(d.tr(row[c])) + 3; w > widest {
+				widest = w
+			}
+		}
+		widths[c] = min(max(widest, 8), available/2)
+		total += widths[c]
+	}
+	scale := available / total
+	for c := range widths {
+		widths[c] *= scale
+	}
+	return widths
+}
+
+// Contents writes a table of contents whose page numbers are filled in as ContentsEntry is called
+func (d *nativePDFDocument) Contents(titles []string) {
+	d.Heading(1, "CONTENTS")
+	left, _, _, _ := d.pdf.GetMargins()
+	// The page numbers are left aligned, as they are only known when the alias is replaced
+	const pageColumn = 12.0
+	d.pdf.SetFont(nativePDFFont, "", 12)
+	for i, title := range titles {
+		d.pdf.SetX(left)
+		d.pdf.CellFormat(d.contentWidth()-pageColumn, nativePDFLineHeight+2, d.tr(title), "", 0, "L", false, 0, "")
+		d.pdf.CellFormat(pageColumn, nativePDFLineHeight+2, nativeContentsAlias(i), "", 1, "L", false, 0, "")
+		d.fresh = false
+	}
+}
+
+// ContentsEntry sets the page number of a contents entry to the current page
+func (d *nativePDFDocument) ContentsEntry(index int) {
+	d.pdf.RegisterAlias(nativeContentsAlias(index), fmt.Sprint(d.pdf.PageNo()))
+}
+
+// nativeContentsAlias is the placeholder replaced by a contents entry's page number on output
+func nativeContentsAlias(index int) string {
+	return fmt.Sprintf("{toc%d}", index)
+}
+
+// PageBreak starts a new page unless the current one is still empty
+func (d *nativePDFDocument) PageBreak() {
+	if d.fresh {
+		return
+	}
+	d.pdf.AddPage()
+	d.fresh = true
+}
+
+// Bytes returns the finished PDF
+func (d *nativePDFDocument) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
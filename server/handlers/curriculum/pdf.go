@@ -116,7 +116,7 @@ func fetchRegulationData(curriculumID int) (*models.RegulationPDF, error) {
 			       c.cia_marks, c.see_marks, c.total_marks
 			FROM courses c
 			INNER JOIN curriculum_courses rc ON c.course_id = rc.course_id
-			WHERE rc.curriculum_id = ? AND rc.semester_id = ?
+			WHERE rc.curriculum_id = ? AND rc.semester_id = ? AND rc.status = 1
 			ORDER BY c.course_code`, curriculumID, semID)
 
 		if courseRows != nil {
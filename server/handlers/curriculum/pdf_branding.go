@@ -0,0 +1,353 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultLogoPath is the logo used until an institution uploads its own
+const defaultLogoPath = "assets/Bannari_Amman_Institute_of_Technology_logo.png"
+
+// brandingUploadDir holds uploaded logos, which are stored as "/uploads/branding/<file>"
+const brandingUploadDir = "uploads/branding"
+
+// pdfPaperSizes are the supported paper sizes as portrait width and height in mm
+var pdfPaperSizes = map[string][2]float64{
+	"A3":     {297, 420},
+	"A4":     {210, 297},
+	"Letter": {215.9, 279.4},
+	"Legal":  {215.9, 355.6},
+}
+
+// defaultPDFBranding is the branding used when the institution has not saved its own
+func defaultPDFBranding() models.PDFBranding {
+	return models.PDFBranding{
+		InstitutionName: "BANNARI AMMAN INSTITUTE OF TECHNOLOGY",
+		InfoLines: []string{
+			"An Autonomous Institution",
+			"Affiliated to Anna University – Chennai",
+			"Approved by AICTE • Accredited by NAAC with \"A+\" Grade",
+		},
+		AddressLines: []string{"SATHYAMANGALAM – 638401", "ERODE DISTRICT, TAMILNADU, INDIA"},
+		ContactLines: []string{
+			"Ph: 04295-226000 / 221289 | Fax: 04295-226666",
+			"E-mail: stayahead@bitsathy.ac.in | Web: www.bitsathy.ac.in",
+		},
+		LogoPath:     defaultLogoPath,
+		PaperSize:    "A4",
+		MarginTop:    10,
+		MarginBottom: 10,
+		MarginLeft:   15,
+		MarginRight:  15,
+		PageNumbers:  true,
+	}
+}
+
+const pdfBrandingColumns = `department_id, institution_name, COALESCE(info_lines, ''), COALESCE(address_lines, ''),
+	COALESCE(contact_lines, ''), logo_path, header_text, footer_text, paper_size, landscape,
+	margin_top, margin_bottom, margin_left, margin_right, page_numbers`
+
+// fetchPDFBranding returns the branding saved for exactly this department (0 for the institution)
+func fetchPDFBranding(departmentID int) (models.PDFBranding, error) {
+	var b models.PDFBranding
+	var info, address, contact string
+	err := db.DB.QueryRow("SELECT "+pdfBrandingColumns+" FROM pdf_brandings WHERE department_id = ?", departmentID).
+		Scan(&b.DepartmentID, &b.InstitutionName, &info, &address, &contact, &b.LogoPath, &b.HeaderText, &b.FooterText,
+			&b.PaperSize, &b.Landscape, &b.MarginTop, &b.MarginBottom, &b.MarginLeft, &b.MarginRight, &b.PageNumbers)
+	b.InfoLines = splitBrandingLines(info)
+	b.AddressLines = splitBrandingLines(address)
+	b.ContactLines = splitBrandingLines(contact)
+	return b, err
+}
+
+// loadPDFBranding returns a department's branding, falling back to the institution's and then to the defaults
+func loadPDFBranding(departmentID int) models.PDFBranding {
+	for _, id := range []int{departmentID, 0} {
+		b, err := fetchPDFBranding(id)
+		if err == nil {
+			return b
+		}
+		if err != sql.ErrNoRows {
+			log.Println("Error fetching PDF branding:", err)
+		}
+		if id == 0 {
+			break
+		}
+	}
+	b := defaultPDFBranding()
+	b.DepartmentID = departmentID
+	return b
+}
+
+// savePDFBranding creates or replaces the branding of b.DepartmentID
+func savePDFBranding(b models.PDFBranding, changedBy string) error {
+	_, err := db.DB.Exec(`
+		INSERT INTO pdf_brandings (department_id, institution_name, info_lines, address_lines, contact_lines, logo_path,
+			header_text, footer_text, paper_size, landscape, margin_top, margin_bottom, margin_left, margin_right,
+			page_numbers, updated_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE institution_name = VALUES(institution_name), info_lines = VALUES(info_lines),
+			address_lines = VALUES(address_lines), contact_lines = VALUES(contact_lines), logo_path = VALUES(logo_path),
+			header_text = VALUES(header_text), footer_text = VALUES(footer_text), paper_size = VALUES(paper_size),
+			landscape = VALUES(landscape), margin_top = VALUES(margin_top), margin_bottom = VALUES(margin_bottom),
+			margin_left = VALUES(margin_left), margin_right = VALUES(margin_right), page_numbers = VALUES(page_numbers),
+			updated_by = VALUES(updated_by)`,
+		b.DepartmentID, b.InstitutionName, strings.Join(b.InfoLines, "\n"), strings.Join(b.AddressLines, "\n"),
+		strings.Join(b.ContactLines, "\n"), b.LogoPath, b.HeaderText, b.FooterText, b.PaperSize, b.Landscape,
+		b.MarginTop, b.MarginBottom, b.MarginLeft, b.MarginRight, b.PageNumbers, changedBy)
+	return err
+}
+
+func splitBrandingLines(text string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// validatePDFBranding checks the page setup; an empty paper size defaults to A4
+func validatePDFBranding(b *models.PDFBranding) error {
+	if strings.TrimSpace(b.InstitutionName) == "" {
+		return fmt.Errorf("institution_name is required")
+	}
+	if b.PaperSize == "" {
+		b.PaperSize = "A4"
+	}
+	if _, ok := pdfPaperSizes[b.PaperSize]; !ok {
+		return fmt.Errorf("paper_size must be one of A3, A4, Letter or Legal")
+	}
+	for _, m := range []float64{b.MarginTop, b.MarginBottom, b.MarginLeft, b.MarginRight} {
+		if m < 0 || m > 50 {
+			return fmt.Errorf("margins must be between 0 and 50 mm")
+		}
+	}
+	return nil
+}
+
+// brandingLogoFile is the file on disk behind a logo path. Only the bundled default and files
+// directly in the upload directory are logos; ok is false for any other path
+func brandingLogoFile(logoPath string) (string, bool) {
+	if logoPath == defaultLogoPath {
+		return defaultLogoPath, true
+	}
+	name, found := strings.CutPrefix(logoPath, "/"+brandingUploadDir+"/")
+	if !found || name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	return filepath.Join(brandingUploadDir, name), true
+}
+
+// brandingLogoDataURI inlines the logo for the HTML templates, or returns "" when it cannot be read.
+// It is a template.URL so html/template keeps the data: URL in the img src
+func brandingLogoDataURI(logoPath string) template.URL {
+	if logoPath == "" {
+		return ""
+	}
+	logoFile, ok := brandingLogoFile(logoPath)
+	if !ok {
+		log.Println("Ignoring PDF logo outside the logo directory:", logoPath)
+		return ""
+	}
+	logoData, err := os.ReadFile(logoFile)
+	if err != nil {
+		log.Println("Error reading PDF logo:", err)
+		return ""
+	}
+	mimeType := "image/png"
+	if ext := strings.ToLower(filepath.Ext(logoPath)); ext == ".jpg" || ext == ".jpeg" {
+		mimeType = "image/jpeg"
+	}
+	return template.URL(fmt.Sprintf("data:%s;base64,%s", mimeType, encodeBase64(logoData)))
+}
+
+// curriculumExportInfo returns the department a curriculum belongs to and whether it is still a draft
+func curriculumExportInfo(curriculumID int) (departmentID int, draft bool) {
+	var dept sql.NullInt64
+	var publishStatus string
+	err := db.DB.QueryRow("SELECT department_id, publish_status FROM curriculum WHERE id = ?", curriculumID).
+		Scan(&dept, &publishStatus)
+	if err != nil {
+		log.Println("Error fetching curriculum publish status:", err)
+		return 0, false
+	}
+	return int(dept.Int64), publishStatus != "PUBLISHED"
+}
+
+// departmentIDParam parses ?department_id, where a missing value means the institution (0)
+func departmentIDParam(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("department_id")
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid department_id")
+	}
+	return id, nil
+}
+
+// pdfBrandingUpdate is the body of PUT /api/pdf/branding; fields left out keep their current value.
+// The logo is not part of it, so it can only be set by uploading one
+type pdfBrandingUpdate struct {
+	InstitutionName *string   `json:"institution_name"`
+	InfoLines       *[]string `json:"info_lines"`
+	AddressLines    *[]string `json:"address_lines"`
+	ContactLines    *[]string `json:"contact_lines"`
+	HeaderText      *string   `json:"header_text"`
+	FooterText      *string   `json:"footer_text"`
+	PaperSize       *string   `json:"paper_size"`
+	Landscape       *bool     `json:"landscape"`
+	MarginTop       *float64  `json:"margin_top"`
+	MarginBottom    *float64  `json:"margin_bottom"`
+	MarginLeft      *float64  `json:"margin_left"`
+	MarginRight     *float64  `json:"margin_right"`
+	PageNumbers     *bool     `json:"page_numbers"`
+}
+
+// apply copies the fields that were sent onto b
+func (u pdfBrandingUpdate) apply(b *models.PDFBranding) {
+	setIfSent(&b.InstitutionName, u.InstitutionName)
+	setIfSent(&b.InfoLines, u.InfoLines)
+	setIfSent(&b.AddressLines, u.AddressLines)
+	setIfSent(&b.ContactLines, u.ContactLines)
+	setIfSent(&b.HeaderText, u.HeaderText)
+	setIfSent(&b.FooterText, u.FooterText)
+	setIfSent(&b.PaperSize, u.PaperSize)
+	setIfSent(&b.Landscape, u.Landscape)
+	setIfSent(&b.MarginTop, u.MarginTop)
+	setIfSent(&b.MarginBottom, u.MarginBottom)
+	setIfSent(&b.MarginLeft, u.MarginLeft)
+	setIfSent(&b.MarginRight, u.MarginRight)
+	setIfSent(&b.PageNumbers, u.PageNumbers)
+}
+
+// setIfSent sets *dst to *src when the field was present in the request
+func setIfSent[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// GetPDFBranding handles GET /api/pdf/branding?department_id=: the branding exports of that
+// department use, which is the institution's or the defaults when it has none of its own
+func GetPDFBranding(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	departmentID, err := departmentIDParam(r)
+	if err != nil {
+		http.Error(w, "Invalid department ID", http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(loadPDFBranding(departmentID))
+}
+
+// UpdatePDFBranding handles PUT /api/pdf/branding?department_id=, saving the branding of the
+// institution (no department) or of one department
+func UpdatePDFBranding(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	departmentID, err := departmentIDParam(r)
+	if err != nil {
+		http.Error(w, "Invalid department ID", http.StatusBadRequest)
+		return
+	}
+
+	var update pdfBrandingUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	branding := loadPDFBranding(departmentID)
+	update.apply(&branding)
+	branding.DepartmentID = departmentID
+	if err := validatePDFBranding(&branding); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := savePDFBranding(branding, requestUser(r)); err != nil {
+		log.Println("Error saving PDF branding:", err)
+		http.Error(w, "Failed to save branding", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(branding)
+}
+
+// UploadPDFBrandingLogo handles POST /api/pdf/branding/logo?department_id= with a PNG or JPEG
+// in the "logo" form field, and makes it the logo of that department's (or the institution's) branding
+func UploadPDFBrandingLogo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	departmentID, err := departmentIDParam(r)
+	if err != nil {
+		http.Error(w, "Invalid department ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		log.Printf("Error parsing multipart form: %v", err)
+		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("logo")
+	if err != nil {
+		http.Error(w, "A logo file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+		http.Error(w, "The logo must be a PNG or JPEG image", http.StatusBadRequest)
+		return
+	}
+
+	if err := os.MkdirAll(brandingUploadDir, 0755); err != nil {
+		log.Printf("Error creating upload directory: %v", err)
+		http.Error(w, "Failed to create upload directory", http.StatusInternalServerError)
+		return
+	}
+
+	// A new name per upload, so exports cached with the old logo are not reused
+	filename := fmt.Sprintf("logo_%d_%d%s", departmentID, time.Now().UnixNano(), ext)
+	dst, err := os.Create(filepath.Join(brandingUploadDir, filename))
+	if err != nil {
+		log.Printf("Error creating file: %v", err)
+		http.Error(w, "Failed to save file", http.StatusInternalServerError)
+		return
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, file); err != nil {
+		log.Printf("Error copying file: %v", err)
+		http.Error(w, "Failed to save file", http.StatusInternalServerError)
+		return
+	}
+
+	branding := loadPDFBranding(departmentID)
+	branding.DepartmentID = departmentID
+	branding.LogoPath = "/" + brandingUploadDir + "/" + filename
+	if err := savePDFBranding(branding, requestUser(r)); err != nil {
+		log.Println("Error saving PDF branding:", err)
+		http.Error(w, "Failed to save branding", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(branding)
+}
@@ -0,0 +1,64 @@
+package curriculum
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"server/models"
+	"testing"
+)
+
+func TestBrandingLogoFile(t *testing.T) {
+	tests := []struct {
+		logoPath string
+		want     string
+		ok       bool
+	}{
+		{defaultLogoPath, defaultLogoPath, true},
+		{"/uploads/branding/logo_0_123.png", filepath.Join("uploads", "branding", "logo_0_123.png"), true},
+		{".env", "", false},
+		{"/etc/passwd", "", false},
+		{"uploads/branding/logo.png", "", false},
+		{"/uploads/branding/", "", false},
+		{"/uploads/branding/../../.env", "", false},
+		{"/uploads/branding/sub/logo.png", "", false},
+		{"/uploads/branding/..", "", false},
+		{"/uploads/branding/.env", "", false},
+		{"/uploads/other/logo.png", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := brandingLogoFile(tt.logoPath)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("brandingLogoFile(%q) = %q, %v; want %q, %v", tt.logoPath, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPDFBrandingUpdateKeepsLogoAndUnsentFields(t *testing.T) {
+	branding := defaultPDFBranding()
+	branding.LogoPath = "/uploads/branding/logo_0_1.png"
+
+	var update pdfBrandingUpdate
+	body := `{"logo_path": ".env", "header_text": "Regulations 2026", "landscape": true, "margin_top": 0}`
+	if err := json.Unmarshal([]byte(body), &update); err != nil {
+		t.Fatal(err)
+	}
+	update.apply(&branding)
+
+	want := defaultPDFBranding()
+	want.LogoPath = "/uploads/branding/logo_0_1.png"
+	want.HeaderText = "Regulations 2026"
+	want.Landscape = true
+	want.MarginTop = 0
+	if got, _ := json.Marshal(branding); string(got) != string(mustJSON(t, want)) {
+		t.Errorf("branding after update = %s; want %s", got, mustJSON(t, want))
+	}
+}
+
+func mustJSON(t *testing.T, v models.PDFBranding) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -7,10 +7,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"html/template"
-	"io/ioutil"
 	"log"
 	"net/http"
-	"os"
 	"server/db"
 	"server/models"
 	"strconv"
@@ -31,8 +29,14 @@ func GenerateRegulationPDFHTML(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := regulationBookOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Fetch all data for the regulation
-	pdfData, err := fetchCompleteRegulationData(regulationID)
+	pdfData, err := buildRegulationPDFData(regulationID, opts)
 	if err != nil {
 		log.Println("Error fetching regulation data:", err)
 		http.Error(w, "Failed to fetch regulation data", http.StatusInternalServerError)
@@ -45,69 +49,102 @@ func GenerateRegulationPDFHTML(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate PDF from HTML
-	pdfBytes, err := generateHTMLPDF(pdfData)
+	// Serve unchanged curricula straight from the cache
+	hash, err := regulationPDFHash(pdfData)
+	if err != nil {
+		log.Println("Error hashing regulation data:", err)
+		http.Error(w, "Failed to generate PDF", http.StatusInternalServerError)
+		return
+	}
+	if pdfBytes, ok := cachedPDF(hash); ok {
+		writeRegulationPDF(w, pdfData, pdfBytes)
+		return
+	}
+
+	// Generate PDF with the configured renderer on the PDF workers
+	pdfBytes, err := renderPDFOnWorkers(r.Context(), regulationPDFFilename(pdfData), hash, regulationPDFRender(pdfData))
 	if err != nil {
 		log.Println("Error generating PDF:", err)
+		if status := pdfQueueErrorStatus(err); status != http.StatusInternalServerError {
+			http.Error(w, err.Error(), status)
+			return
+		}
 		// Provide helpful error message about Chrome requirement
 		errorMsg := fmt.Sprintf("Failed to generate PDF: %v\n\nChrome/Chromium is required for PDF generation.\n"+
-			"Install it with: brew install --cask google-chrome\n\n"+
+			"Install it with: brew install --cask google-chrome\n"+
+			"Or set PDF_RENDERER=native (or auto) to render without Chrome\n\n"+
 			"Or download the HTML preview by adding ?preview=html to the URL", err)
 		http.Error(w, errorMsg, http.StatusInternalServerError)
 		return
 	}
 
-	// Set headers and stream PDF
-	w.Header().Set("Content-Type", "application/pdf")
-	filename := fmt.Sprintf("Regulation_%s_%s.pdf",
-		strings.ReplaceAll(pdfData.RegulationName, " ", "_"),
-		strings.ReplaceAll(pdfData.AcademicYear, " ", "_"))
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	w.Header().Set("Content-Length", strconv.Itoa(len(pdfBytes)))
-	w.Write(pdfBytes)
+	writeRegulationPDF(w, pdfData, pdfBytes)
 }
 
-// generateHTMLPreview generates an HTML preview of the curriculum
-func generateHTMLPreview(w http.ResponseWriter, data *models.RegulationPDF) {
-	// Read and encode logo
-	logoBase64 := ""
-	logoPath := "assets/Bannari_Amman_Institute_of_Technology_logo.png"
-	if logoData, err := ioutil.ReadFile(logoPath); err == nil {
-		logoBase64 = fmt.Sprintf("data:image/png;base64,%s", encodeBase64(logoData))
+// buildRegulationPDFData fetches the regulation along with its articulation matrix, its linked
+// regulation document, its department's branding and template and, when an academic year is given, its program outcome attainment.
+// Only the sections and semesters chosen in opts are kept
+func buildRegulationPDFData(regulationID int, opts regulationBookOptions) (*models.RegulationPDF, error) {
+	pdfData, err := fetchCompleteRegulationData(regulationID)
+	if err != nil {
+		return nil, err
 	}
+	pdfData.Sections = opts.Sections
+	pdfData.Semesters = applySemesterRange(pdfData.Semesters, opts.SemesterFrom, opts.SemesterTo)
 
-	type PDFDataWithDate struct {
-		*models.RegulationPDF
-		GeneratedDate string
-		LogoBase64    string
+	// Include the course x PO/PSO articulation matrix
+	if articulation, err := computeArticulationMatrix(regulationID, defaultWeakCoverageThreshold); err != nil {
+		log.Println("Error computing articulation matrix for PDF:", err)
+	} else {
+		pdfData.Articulation = articulation
 	}
-	dataWithDate := &PDFDataWithDate{
-		RegulationPDF: data,
-		GeneratedDate: time.Now().Format("January 2, 2006"),
-		LogoBase64:    logoBase64,
+
+	// Include program outcome attainment when an academic year is requested
+	if opts.AttainmentYear != "" {
+		attainment, err := computeProgramAttainment(regulationID, opts.AttainmentYear)
+		if err != nil {
+			log.Println("Error computing program attainment for PDF:", err)
+		} else {
+			pdfData.ProgramAttainment = attainment
+		}
 	}
 
-	tmpl, err := template.New("regulation").Funcs(template.FuncMap{
-		"add":        func(a, b int) int { return a + b },
-		"sub":        func(a, b int) int { return a - b },
-		"totalHours": func(l, t, p int) int { return l + t + p },
-		"iterate": func(n int) []int {
-			result := make([]int, n)
-			for i := 0; i < n; i++ {
-				result[i] = i
-			}
-			return result
-		},
-		"isTheory": func(courseType string) bool {
-			ct := strings.ToLower(courseType)
-			return strings.Contains(ct, "theory") || (!strings.Contains(ct, "lab") && !strings.Contains(ct, "practical"))
-		},
-		"isLab": func(courseType string) bool {
-			ct := strings.ToLower(courseType)
-			return strings.Contains(ct, "lab") || strings.Contains(ct, "practical") || strings.Contains(ct, "experiment")
-		},
-	}).Parse(htmlTemplate)
+	// Include the academic rules of the linked regulation document
+	if document, err := fetchCurriculumRegulationDocument(regulationID); err != nil {
+		log.Println("Error fetching regulation document for PDF:", err)
+	} else {
+		pdfData.RegulationDocument = document
+	}
+
+	// Brand and lay out the book for the curriculum's department; a draft regulation document
+	// makes the book a draft as well
+	departmentID, draft := curriculumExportInfo(regulationID)
+	pdfData.Branding = loadPDFBranding(departmentID)
+	pdfData.Draft = draft || (pdfData.RegulationDocument != nil && pdfData.RegulationDocument.Regulation.Status == "DRAFT")
+	pdfData.TemplateHTML = resolvePDFTemplate(pdfTemplateRegulation, departmentID, pdfData.CurriculumTemplate)
 
+	return pdfData, nil
+}
+
+// regulationPDFFilename is the download name for a regulation PDF
+func regulationPDFFilename(data *models.RegulationPDF) string {
+	return fmt.Sprintf("Regulation_%s_%s.pdf",
+		strings.ReplaceAll(data.RegulationName, " ", "_"),
+		strings.ReplaceAll(data.AcademicYear, " ", "_"))
+}
+
+// writeRegulationPDF sets the download headers and streams the PDF
+func writeRegulationPDF(w http.ResponseWriter, data *models.RegulationPDF, pdfBytes []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", regulationPDFFilename(data)))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdfBytes)))
+	w.Write(pdfBytes)
+}
+
+// generateHTMLPreview generates an HTML preview of the curriculum
+func generateHTMLPreview(w http.ResponseWriter, data *models.RegulationPDF) {
+	// Page numbers are only known once printed, so the preview's contents have none
+	htmlContent, err := renderRegulationHTML(data, fullRegulationView(regulationBookContents(data)))
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
 		return
@@ -115,12 +152,13 @@ func generateHTMLPreview(w http.ResponseWriter, data *models.RegulationPDF) {
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Header().Set("Content-Disposition", "inline; filename=curriculum_preview.html")
-	tmpl.Execute(w, dataWithDate)
+	w.Write([]byte(htmlContent))
 }
 
 func fetchCompleteRegulationData(regulationID int) (*models.RegulationPDF, error) {
 	pdfData := &models.RegulationPDF{
-		CurriculumID: regulationID,
+		CurriculumID:  regulationID,
+		GeneratedDate: pdfGeneratedDate(),
 	}
 
 	// Fetch regulation basic info including curriculum_template
@@ -203,102 +241,13 @@ func fetchCompleteRegulationData(regulationID int) (*models.RegulationPDF, error
 		}
 
 		// Fetch courses for semester - maintain database order
-		courseRows, _ := db.DB.Query(`
-			SELECT c.course_id, c.course_code, c.course_name, c.course_type, c.category, c.credit,
-			       c.lecture_hours, c.tutorial_hours, c.practical_hours, 
-			       c.theory_hours, c.activity_hours, c.total_hours,
-			       c.cia_marks, c.see_marks, c.total_marks
-			FROM courses c
-			INNER JOIN curriculum_courses rc ON c.course_id = rc.course_id
-			WHERE rc.curriculum_id = ? AND rc.semester_id = ?
-			ORDER BY rc.id`, regulationID, semID) // Order by junction table ID to preserve insertion order
-
-		if courseRows != nil {
-			defer courseRows.Close()
-			for courseRows.Next() {
-				var course models.CoursePDF
-				courseRows.Scan(&course.CourseID, &course.CourseCode, &course.CourseName, &course.CourseType,
-					&course.Category, &course.Credit, &course.LectureHours, &course.TutorialHours,
-					&course.PracticalHours, &course.TheoryHours, &course.ActivityHours, &course.TotalHours,
-					&course.CIAMarks, &course.SEEMarks, &course.TotalMarks)
-
-				// Fetch syllabus from normalized tables
-				course.Syllabus.Objectives, _ = fetchObjectives(course.CourseID)
-				course.Syllabus.Outcomes, _ = fetchOutcomes(course.CourseID)
-				course.Syllabus.Prerequisites, _ = fetchPrerequisites(course.CourseID)
-				course.Syllabus.ReferenceList, _ = fetchReferences(course.CourseID)
-				course.Syllabus.Teamwork, _ = fetchTeamwork(course.CourseID)
-				course.Syllabus.SelfLearning, _ = fetchSelfLearning(course.CourseID)
-
-				// Fetch models/modules
-				course.Models = fetchModelsForPDF(course.CourseID)
-
-				// Fetch experiments for 2022 template
-				if pdfData.CurriculumTemplate == "2022" {
-					experiments := []models.Experiment{}
-					expRows, _ := db.DB.Query(`
-						SELECT id, course_id, experiment_number, experiment_name
-						FROM course_experiments
-						WHERE course_id = ?
-						ORDER BY experiment_number`, course.CourseID)
-					if expRows != nil {
-						defer expRows.Close()
-						for expRows.Next() {
-							var exp models.Experiment
-							if err := expRows.Scan(&exp.ID, &exp.CourseID, &exp.ExperimentNumber, &exp.ExperimentName); err != nil {
-								continue
-							}
-							// Fetch topics
-							topicRows, _ := db.DB.Query(`
-								SELECT topic_text
-								FROM course_experiment_topics
-								WHERE experiment_id = ?
-								ORDER BY topic_order`, exp.ID)
-							if topicRows != nil {
-								topics := []string{}
-								for topicRows.Next() {
-									var topic string
-									if err := topicRows.Scan(&topic); err == nil {
-										topics = append(topics, topic)
-									}
-								}
-								topicRows.Close()
-								exp.Topics = topics
-							}
-							experiments = append(experiments, exp)
-						}
-					}
-					course.Experiments = experiments
-				}
-
-				// Fetch CO-PO mapping
-				course.COPOMapping = make(map[string]int)
-				copoRows, _ := db.DB.Query("SELECT co_index, po_index, mapping_value FROM co_po_mapping WHERE course_id = ?", course.CourseID)
-				if copoRows != nil {
-					defer copoRows.Close()
-					for copoRows.Next() {
-						var coIdx, poIdx, val int
-						copoRows.Scan(&coIdx, &poIdx, &val)
-						key := fmt.Sprintf("%d-%d", coIdx, poIdx)
-						course.COPOMapping[key] = val
-					}
-				}
-
-				// Fetch CO-PSO mapping
-				course.COPSOMapping = make(map[string]int)
-				copsoRows, _ := db.DB.Query("SELECT co_index, pso_index, mapping_value FROM co_pso_mapping WHERE course_id = ?", course.CourseID)
-				if copsoRows != nil {
-					defer copsoRows.Close()
-					for copsoRows.Next() {
-						var coIdx, psoIdx, val int
-						copoRows.Scan(&coIdx, &psoIdx, &val)
-						key := fmt.Sprintf("%d-%d", coIdx, psoIdx)
-						course.COPSOMapping[key] = val
-					}
-				}
-
-				semData.Courses = append(semData.Courses, course)
-			}
+		for _, courseID := range fetchCourseIDs(`
+			SELECT rc.course_id
+			FROM curriculum_courses rc
+			INNER JOIN courses c ON c.course_id = rc.course_id
+			WHERE rc.curriculum_id = ? AND rc.semester_id = ? AND rc.status = 1 AND c.status = 1
+			ORDER BY rc.id`, regulationID, semID) { // Order by junction table ID to preserve insertion order
+			semData.Courses = append(semData.Courses, loadCoursePDF(regulationID, courseID, pdfData.CurriculumTemplate))
 		}
 
 		pdfData.Semesters = append(pdfData.Semesters, semData)
@@ -345,39 +294,13 @@ func fetchCompleteRegulationData(regulationID int) (*models.RegulationPDF, error
 					}
 
 					// Fetch courses for this vertical
-					courseRows, err := db.DB.Query(`
-						SELECT c.course_id, c.course_code, c.course_name, c.course_type, c.category, c.credit,
-							   c.lecture_hours, c.tutorial_hours, c.practical_hours, 
-							   c.theory_hours, c.activity_hours, c.total_hours,
-							   c.cia_marks, c.see_marks, c.total_marks
-						FROM courses c
-						INNER JOIN honour_vertical_courses hvc ON c.course_id = hvc.course_id
-						WHERE hvc.vertical_id = ?
-						ORDER BY hvc.position`, verticalID)
-					if err == nil {
-						defer courseRows.Close()
-
-						for courseRows.Next() {
-							var course models.CoursePDF
-							courseRows.Scan(&course.CourseID, &course.CourseCode, &course.CourseName,
-								&course.CourseType, &course.Category, &course.Credit,
-								&course.LectureHours, &course.TutorialHours, &course.PracticalHours,
-								&course.TheoryHours, &course.ActivityHours, &course.TotalHours,
-								&course.CIAMarks, &course.SEEMarks, &course.TotalMarks)
-
-							// Fetch syllabus data (same as regular courses)
-							course.Syllabus.Objectives, _ = fetchObjectives(course.CourseID)
-							course.Syllabus.Outcomes, _ = fetchOutcomes(course.CourseID)
-							course.Syllabus.Prerequisites, _ = fetchPrerequisites(course.CourseID)
-							course.Syllabus.ReferenceList, _ = fetchReferences(course.CourseID)
-							course.Syllabus.Teamwork, _ = fetchTeamwork(course.CourseID)
-							course.Syllabus.SelfLearning, _ = fetchSelfLearning(course.CourseID)
-							course.Models = fetchModelsForPDF(course.CourseID)
-							course.COPOMapping = make(map[string]int)
-							course.COPSOMapping = make(map[string]int)
-
-							vertical.Courses = append(vertical.Courses, course)
-						}
+					for _, courseID := range fetchCourseIDs(`
+						SELECT hvc.course_id
+						FROM honour_vertical_courses hvc
+						INNER JOIN courses c ON c.course_id = hvc.course_id
+						WHERE hvc.honour_vertical_id = ? AND hvc.status = 1 AND c.status = 1
+						ORDER BY hvc.id`, verticalID) {
+						vertical.Courses = append(vertical.Courses, loadCoursePDF(regulationID, courseID, pdfData.CurriculumTemplate))
 					}
 
 					honourCard.Verticals = append(honourCard.Verticals, vertical)
@@ -391,13 +314,86 @@ func fetchCompleteRegulationData(regulationID int) (*models.RegulationPDF, error
 	return pdfData, nil
 }
 
+// fetchCourseIDs runs a query selecting course ids and returns them in order
+func fetchCourseIDs(query string, args ...interface{}) []int {
+	ids, err := queryCourseIDs(query, args...)
+	if err != nil {
+		log.Println("Error fetching courses for PDF:", err)
+	}
+	return ids
+}
+
+// queryCourseIDs runs a query selecting course ids and returns them in order
+func queryCourseIDs(query string, args ...interface{}) ([]int, error) {
+	ids := []int{}
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return ids, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// loadCoursePDF builds the PDF data of a course from the version the curriculum is pinned to,
+// or from the live course when it is not pinned
+func loadCoursePDF(curriculumID, courseID int, curriculumTemplate string) models.CoursePDF {
+	snapshot, _, err := courseSnapshotForCurriculum(curriculumID, courseID)
+	if err != nil {
+		log.Printf("Error loading course %d for PDF: %v", courseID, err)
+		return models.CoursePDF{CourseID: courseID, COPOMapping: map[string]int{}, COPSOMapping: map[string]int{}}
+	}
+
+	c := snapshot.Course
+	course := models.CoursePDF{
+		CourseID:       courseID,
+		CourseCode:     c.CourseCode,
+		CourseName:     c.CourseName,
+		CourseType:     c.CourseType,
+		Category:       c.Category,
+		Credit:         c.Credit,
+		LectureHours:   c.LectureHrs,
+		TutorialHours:  c.TutorialHrs,
+		PracticalHours: c.PracticalHrs,
+		TheoryHours:    c.TheoryTotalHrs,
+		ActivityHours:  c.ActivityHrs,
+		TotalHours:     c.TotalHrs,
+		CIAMarks:       c.CIAMarks,
+		SEEMarks:       c.SEEMarks,
+		TotalMarks:     c.TotalMarks,
+		Syllabus: models.SyllabusPDF{
+			Objectives:    snapshot.Objectives,
+			Outcomes:      snapshot.Outcomes,
+			Prerequisites: snapshot.Prerequisites,
+			ReferenceList: snapshot.ReferenceList,
+			Teamwork:      snapshot.Teamwork,
+			SelfLearning:  snapshot.SelfLearning,
+		},
+		Models:         snapshot.Models,
+		COPOMapping:    snapshot.COPOMapping,
+		COPSOMapping:   snapshot.COPSOMapping,
+		Justifications: snapshot.Justifications,
+	}
+
+	// Experiments are only part of the 2022 template
+	if curriculumTemplate == "2022" {
+		course.Experiments = snapshot.Experiments
+	}
+	return course
+}
+
 func fetchModelsForPDF(courseID int) []models.SyllabusModelPDF {
 	modelsList := []models.SyllabusModelPDF{}
 
 	modelRows, err := db.DB.Query(`
 		SELECT id, model_name, position 
 		FROM syllabus 
-		WHERE course_id = ? 
+		WHERE course_id = ? AND (status = 1 OR status IS NULL)
 		ORDER BY position, id`, courseID)
 
 	if err != nil {
@@ -415,7 +411,7 @@ func fetchModelsForPDF(courseID int) []models.SyllabusModelPDF {
 		titleRows, err := db.DB.Query(`
 			SELECT id, title, hours, position 
 			FROM syllabus_titles 
-			WHERE model_id = ? 
+			WHERE model_id = ? AND (status = 1 OR status IS NULL)
 			ORDER BY position, id`, model.ID)
 
 		if err != nil {
@@ -435,7 +431,7 @@ func fetchModelsForPDF(courseID int) []models.SyllabusModelPDF {
 			topicRows, err := db.DB.Query(`
 				SELECT id, topic, position 
 				FROM syllabus_topics 
-				WHERE title_id = ? 
+				WHERE title_id = ? AND (status = 1 OR status IS NULL)
 				ORDER BY position, id`, title.ID)
 
 			if err != nil {
@@ -466,35 +462,95 @@ func fetchModelsForPDF(courseID int) []models.SyllabusModelPDF {
 	return modelsList
 }
 
-func generateHTMLPDF(data *models.RegulationPDF) ([]byte, error) {
-	// Create temp directory
-	tmpDir, err := ioutil.TempDir("", "regulation_pdf_")
-	if err != nil {
-		return nil, err
+// fetchCourseMappingsForPDF loads CO-PO and CO-PSO matrices keyed "co_index-po_index" (co_index is
+// 0-based, PO/PSO numbers 1-based) together with the justification of every mapped cell
+func fetchCourseMappingsForPDF(courseID int) (map[string]int, map[string]int, []models.MappingNotePDF) {
+	copo := make(map[string]int)
+	copso := make(map[string]int)
+	notes := []models.MappingNotePDF{}
+
+	for _, source := range []struct {
+		prefix, table, column string
+		matrix                map[string]int
+	}{
+		{"PO", "co_po_mapping", "po_index", copo},
+		{"PSO", "co_pso_mapping", "pso_index", copso},
+	} {
+		rows, err := db.DB.Query(fmt.Sprintf(`
+			SELECT co_index, %s, mapping_value, COALESCE(justification, '')
+			FROM %s WHERE course_id = ?
+			ORDER BY co_index, %s`, source.column, source.table, source.column), courseID)
+		if err != nil {
+			log.Printf("Error fetching %s for course %d: %v", source.table, courseID, err)
+			continue
+		}
+		for rows.Next() {
+			var coIdx, outcomeIdx, val int
+			var justification string
+			if err := rows.Scan(&coIdx, &outcomeIdx, &val, &justification); err != nil {
+				continue
+			}
+			source.matrix[fmt.Sprintf("%d-%d", coIdx, outcomeIdx)] = val
+			if val > 0 && justification != "" {
+				notes = append(notes, models.MappingNotePDF{
+					CO:            coIdx + 1,
+					Outcome:       fmt.Sprintf("%s%d", source.prefix, outcomeIdx),
+					Value:         val,
+					Justification: justification,
+				})
+			}
+		}
+		rows.Close()
 	}
-	defer os.RemoveAll(tmpDir)
 
-	// Read and encode logo
-	logoBase64 := ""
-	logoPath := "assets/Bannari_Amman_Institute_of_Technology_logo.png"
-	if logoData, err := ioutil.ReadFile(logoPath); err == nil {
-		logoBase64 = fmt.Sprintf("data:image/png;base64,%s", encodeBase64(logoData))
-	}
+	return copo, copso, notes
+}
 
-	// Add current date and logo to data
+// renderRegulationHTML renders the parts of the regulation in view into the printable HTML document
+func renderRegulationHTML(data *models.RegulationPDF, view regulationHTMLView) (string, error) {
+	// Add the logo, page layout and the parts to show to data
 	type PDFDataWithDate struct {
 		*models.RegulationPDF
-		GeneratedDate string
-		LogoBase64    string
+		LogoBase64 template.URL
+		PageStyles template.CSS
+		Cover      bool
+		Contents   []contentsEntry
+		Render     map[string]bool
+		PageMarker bool
 	}
 	dataWithDate := &PDFDataWithDate{
 		RegulationPDF: data,
-		GeneratedDate: time.Now().Format("January 2, 2006"),
-		LogoBase64:    logoBase64,
+		LogoBase64:    brandingLogoDataURI(data.Branding.LogoPath),
+		PageStyles:    pageSetupFor(data.Branding, data.Draft).pageStyles(),
+		Cover:         view.Cover,
+		Contents:      view.Contents,
+		Render:        view.Render,
+		PageMarker:    view.PageMarker,
+	}
+
+	// Load the resolved template with helper functions
+	templateHTML := data.TemplateHTML
+	if templateHTML == "" {
+		templateHTML = htmlTemplate
+	}
+	tmpl, err := parsePDFTemplate("regulation", templateHTML)
+	if err != nil {
+		return "", err
 	}
 
-	// Load template with helper functions
-	tmpl, err := template.New("regulation").Funcs(template.FuncMap{
+	// Render template
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, dataWithDate)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template: %v", err)
+	}
+
+	return buf.String(), nil
+}
+
+// pdfTemplateFuncs are the helper functions available to the PDF HTML templates
+func pdfTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
 		"add":        func(a, b int) int { return a + b },
 		"sub":        func(a, b int) int { return a - b },
 		"totalHours": func(l, t, p int) int { return l + t + p },
@@ -509,10 +565,8 @@ func generateHTMLPDF(data *models.RegulationPDF) ([]byte, error) {
 			ct := strings.ToLower(courseType)
 			return strings.Contains(ct, "theory") || (!strings.Contains(ct, "lab") && !strings.Contains(ct, "practical"))
 		},
-		"isLab": func(courseType string) bool {
-			ct := strings.ToLower(courseType)
-			return strings.Contains(ct, "lab") || strings.Contains(ct, "practical") || strings.Contains(ct, "experiment")
-		},
+		"isLab": isLabCourse,
+		"score": formatScore,
 		"dict": func(values ...interface{}) map[string]interface{} {
 			dict := make(map[string]interface{})
 			for i := 0; i < len(values); i += 2 {
@@ -521,30 +575,47 @@ func generateHTMLPDF(data *models.RegulationPDF) ([]byte, error) {
 			}
 			return dict
 		},
-	}).Parse(htmlTemplate)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse template: %v", err)
 	}
+}
 
-	// Render template
-	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, dataWithDate)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute template: %v", err)
+// formatScore formats an optional score to two decimals, or "-" when there is none
+func formatScore(v *float64) string {
+	if v == nil {
+		return "-"
 	}
+	return fmt.Sprintf("%.2f", *v)
+}
 
-	htmlContent := buf.String()
+// isLabCourse reports whether a course type is practical, which changes how its syllabus is titled
+func isLabCourse(courseType string) bool {
+	ct := strings.ToLower(courseType)
+	return strings.Contains(ct, "lab") || strings.Contains(ct, "practical") || strings.Contains(ct, "experiment")
+}
 
-	// Use chromedp to convert HTML to PDF with proper error handling
-	ctx, cancel := chromedp.NewContext(context.Background())
-	defer cancel()
+// renderRegulationPDF renders the regulation book with the configured renderer, printing HTML through
+// print. It reports whether the native renderer wrote it
+func renderRegulationPDF(print htmlPrinter, data *models.RegulationPDF) ([]byte, bool, error) {
+	setup := pageSetupFor(data.Branding, data.Draft)
+	return renderPDF(
+		setup,
+		func() (string, error) {
+			return renderPaginatedRegulationHTML(data, func(htmlContent string) ([]byte, error) {
+				return print(htmlContent, setup)
+			})
+		},
+		print,
+		func(doc documentWriter) { writeRegulationBook(doc, data) },
+	)
+}
 
+// htmlToPDF prints the HTML through the browser behind ctx
+func htmlToPDF(ctx context.Context, htmlContent string, setup pdfPageSetup) ([]byte, error) {
 	// Set timeout
-	ctx, cancel = context.WithTimeout(ctx, 60*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
 	var pdfBuf []byte
-	err = chromedp.Run(ctx, printToPDF(htmlContent, &pdfBuf))
+	err := chromedp.Run(ctx, printToPDF(htmlContent, setup, &pdfBuf))
 	if err != nil {
 		// Check if the error is due to Chrome not being found
 		errMsg := err.Error()
@@ -559,7 +630,10 @@ func generateHTMLPDF(data *models.RegulationPDF) ([]byte, error) {
 	return pdfBuf, nil
 }
 
-func printToPDF(html string, res *[]byte) chromedp.Tasks {
+func printToPDF(html string, setup pdfPageSetup, res *[]byte) chromedp.Tasks {
+	const mmPerInch = 25.4
+	width, height := setup.paperSize()
+	header, footer, headerFooter := setup.chromeHeaderFooter()
 	return chromedp.Tasks{
 		chromedp.Navigate("about:blank"),
 		chromedp.ActionFunc(func(ctx context.Context) error {
@@ -577,12 +651,15 @@ func printToPDF(html string, res *[]byte) chromedp.Tasks {
 				buf, _, err = page.PrintToPDF().
 					WithPrintBackground(true).
 					WithScale(1).
-					WithPaperWidth(8.27).   // A4 width in inches
-					WithPaperHeight(11.69). // A4 height in inches
-					WithMarginTop(0.39).    // 10mm
-					WithMarginBottom(0.39).
-					WithMarginLeft(0.59). // 15mm
-					WithMarginRight(0.59).
+					WithPaperWidth(width / mmPerInch).
+					WithPaperHeight(height / mmPerInch).
+					WithMarginTop(setup.Margins[0] / mmPerInch).
+					WithMarginRight(setup.Margins[1] / mmPerInch).
+					WithMarginBottom(setup.Margins[2] / mmPerInch).
+					WithMarginLeft(setup.Margins[3] / mmPerInch).
+					WithDisplayHeaderFooter(headerFooter).
+					WithHeaderTemplate(header).
+					WithFooterTemplate(footer).
 					Do(ctx)
 				return err
 			}))
@@ -629,11 +706,6 @@ const htmlTemplate = `<!DOCTYPE html>
 	<meta charset="UTF-8">
 	<title>{{.RegulationName}}</title>
 	<style>
-		@page {
-			size: A4;
-			margin: 10mm 15mm;
-		}
-		
 		* {
 			margin: 0;
 			padding: 0;
@@ -719,6 +791,39 @@ const htmlTemplate = `<!DOCTYPE html>
 		text-align: center;
 	}
 	
+	.contents-page {
+		page-break-after: always;
+	}
+	
+	.contents-entry {
+		display: flex;
+		justify-content: space-between;
+		margin: 8px 0;
+		font-size: 12pt;
+	}
+	
+	.contents-entry a {
+		color: #000;
+		text-decoration: none;
+	}
+	
+	.page-marker {
+		page-break-before: always;
+	}
+	
+	.regulation-clause {
+		margin: 8px 0;
+		text-align: justify;
+	}
+	
+	.regulation-clause .clause-content {
+		white-space: pre-line;
+	}
+	
+	.regulation-clause.sub-clause {
+		margin-left: 24px;
+	}
+	
 	.centered-content {
 		text-align: center;
 	}
@@ -846,31 +951,81 @@ const htmlTemplate = `<!DOCTYPE html>
 	.mapping-table td {
 		padding: 2px 4px;
 	}
+	
+	.mapping-table td.weak {
+		background-color: #fde2e2;
+		font-weight: bold;
+	}
 	</style>
+	<style>{{.PageStyles}}</style>
 </head>
 <body>
+{{if .Draft}}<div class="draft-watermark">DRAFT</div>{{end}}
 
+{{if .Cover}}
 <!-- Cover Page -->
 <div class="cover-page">
 	{{if .LogoBase64}}
 	<img src="{{.LogoBase64}}" alt="College Logo" class="logo">
 	{{end}}
-	<div class="college-name">BANNARI AMMAN INSTITUTE OF TECHNOLOGY</div>
-	<div class="college-info">An Autonomous Institution</div>
-	<div class="college-info">Affiliated to Anna University – Chennai</div>
-	<div class="college-info">Approved by AICTE • Accredited by NAAC with "A+" Grade</div>
-	<div class="college-info" style="margin-top: 10px; font-weight: bold;">SATHYAMANGALAM – 638401</div>
-	<div class="college-info">ERODE DISTRICT, TAMILNADU, INDIA</div>
+	<div class="college-name">{{.Branding.InstitutionName}}</div>
+	{{range .Branding.InfoLines}}
+	<div class="college-info">{{.}}</div>
+	{{end}}
+	{{range $i, $line := .Branding.AddressLines}}
+	{{if eq $i 0}}
+	<div class="college-info" style="margin-top: 10px; font-weight: bold;">{{$line}}</div>
+	{{else}}
+	<div class="college-info">{{$line}}</div>
+	{{end}}
+	{{end}}
+	{{if .Branding.ContactLines}}
 	<div class="contact-info">
-		<div>Ph: 04295-226000 / 221289 | Fax: 04295-226666</div>
-		<div>E-mail: stayahead@bitsathy.ac.in | Web: www.bitsathy.ac.in</div>
+		{{range .Branding.ContactLines}}
+		<div>{{.}}</div>
+		{{end}}
 	</div>
+	{{end}}
 	<div class="divider"></div>
 	<div class="program-title">{{.RegulationName}}</div>
 	<div class="regulation-year">{{.AcademicYear}}</div>
 	<div class="date">Generated on {{.GeneratedDate}}</div>
 </div>
 
+{{if .Contents}}
+<!-- Table of Contents -->
+<div class="contents-page">
+<h1>CONTENTS</h1>
+{{range .Contents}}
+<div class="contents-entry">
+	<a href="#section-{{.Key}}">{{.Title}}</a>
+	<span>{{if .Page}}{{.Page}}{{end}}</span>
+</div>
+{{end}}
+</div>
+{{end}}
+{{end}}
+
+{{if .Render.clauses}}
+<div id="section-clauses">
+<!-- Academic Regulations -->
+<h1>{{.RegulationDocument.Regulation.Name}}</h1>
+{{range .RegulationDocument.Sections}}
+<h2>{{.SectionNo}}. {{.Title}}</h2>
+{{range .Clauses}}
+<div class="regulation-clause{{if .ParentClauseID}} sub-clause{{end}}">
+	<strong>{{.ClauseNo}}{{if .Title}} {{.Title}}{{end}}</strong>
+	<div class="clause-content">{{.Content}}</div>
+</div>
+{{end}}
+{{end}}
+
+<div class="page-break"></div>
+</div>
+{{end}}
+
+{{if .Render.overview}}
+<div id="section-overview">
 <!-- Vision and Mission -->
 <h1>VISION</h1>
 <div class="centered-content">
@@ -919,7 +1074,11 @@ const htmlTemplate = `<!DOCTYPE html>
 {{end}}
 
 <div class="page-break"></div>
+</div>
+{{end}}
 
+{{if .Render.peo_po}}
+<div id="section-peo_po">
 <!-- PEO-PO Mapping -->
 <h1>PEO-PO MAPPING</h1>
 <table class="mapping-table">
@@ -944,14 +1103,115 @@ const htmlTemplate = `<!DOCTYPE html>
 </table>
 
 <div class="page-break"></div>
+</div>
+{{end}}
 
+{{if .Render.mappings}}
+<div id="section-mappings">
+{{if and .Articulation .Articulation.Courses}}
+<!-- Articulation Matrix -->
+<h1>ARTICULATION MATRIX</h1>
+<table class="mapping-table">
+	<thead>
+		<tr>
+			<th>Course Code</th>
+			<th>Course Name</th>
+			{{range $i := iterate .Articulation.POCount}}
+			<th>PO{{add $i 1}}</th>
+			{{end}}
+			{{range $i := iterate .Articulation.PSOCount}}
+			<th>PSO{{add $i 1}}</th>
+			{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Articulation.Courses}}
+		<tr>
+			<td>{{.CourseCode}}</td>
+			<td>{{.CourseName}}</td>
+			{{range .POValues}}
+			<td class="center">{{score .}}</td>
+			{{end}}
+			{{range .PSOValues}}
+			<td class="center">{{score .}}</td>
+			{{end}}
+		</tr>
+		{{end}}
+		<tr>
+			<th colspan="2">Average</th>
+			{{range .Articulation.POCoverage}}
+			<td class="center{{if .Weak}} weak{{end}}">{{score .Average}}</td>
+			{{end}}
+			{{range .Articulation.PSOCoverage}}
+			<td class="center{{if .Weak}} weak{{end}}">{{score .Average}}</td>
+			{{end}}
+		</tr>
+	</tbody>
+</table>
+{{if .Articulation.WeakOutcomes}}
+<p>Outcomes with weak coverage (average below {{printf "%.1f" .Articulation.WeakThreshold}} or not addressed by any course):
+{{range $i, $o := .Articulation.WeakOutcomes}}{{if $i}}, {{end}}{{$o}}{{end}}</p>
+{{end}}
+
+<div class="page-break"></div>
+{{end}}
+
+{{if .ProgramAttainment}}
+<!-- Program Outcome Attainment -->
+<h1>PROGRAM OUTCOME ATTAINMENT ({{.ProgramAttainment.AcademicYear}})</h1>
+<p>Final attainment combines direct (course) attainment weighted {{printf "%.0f" .ProgramAttainment.DirectWeight}}% and indirect (survey) attainment weighted {{printf "%.0f" .ProgramAttainment.IndirectWeight}}%.</p>
+<table class="mapping-table">
+	<thead>
+		<tr>
+			<th>Outcome</th>
+			<th>Courses</th>
+			<th>Direct</th>
+			<th>Indirect</th>
+			<th>Final</th>
+			<th>Target</th>
+			<th>Status</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .ProgramAttainment.POs}}
+		<tr>
+			<th>PO{{.Index}}</th>
+			<td class="center">{{.CoursesMapped}}</td>
+			<td class="center">{{score .Direct}}</td>
+			<td class="center">{{score .Indirect}}</td>
+			<td class="center">{{score .Final}}</td>
+			<td class="center">{{printf "%.2f" .Target}}</td>
+			<td class="center">{{if .TargetMet}}Attained{{else}}Not Attained{{end}}</td>
+		</tr>
+		{{end}}
+		{{range .ProgramAttainment.PSOs}}
+		<tr>
+			<th>PSO{{.Index}}</th>
+			<td class="center">{{.CoursesMapped}}</td>
+			<td class="center">{{score .Direct}}</td>
+			<td class="center">{{score .Indirect}}</td>
+			<td class="center">{{score .Final}}</td>
+			<td class="center">{{printf "%.2f" .Target}}</td>
+			<td class="center">{{if .TargetMet}}Attained{{else}}Not Attained{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+
+<div class="page-break"></div>
+{{end}}
+</div>
+{{end}}
+
+{{if .Render.semesters}}
+<div id="section-semesters">
 <!-- Summary of Credit Distribution -->
 <h1>SUMMARY OF CREDIT DISTRIBUTION</h1>
 {{range .Semesters}}
 {{if eq .CardType "semester"}}
-<h2>SEMESTER {{.Number}}</h2>
+<h2>SEMESTER {{.SemesterNumber}}</h2>
 {{else if eq .CardType "vertical"}}
-<h2>VERTICAL {{.Number}}</h2>
+<h2>VERTICAL {{.SemesterNumber}}</h2>
 {{else if eq .CardType "elective"}}
 <h2>ELECTIVE COURSES</h2>
 {{else if eq .CardType "open_elective"}}
@@ -1000,7 +1260,11 @@ const htmlTemplate = `<!DOCTYPE html>
 {{end}}
 
 <div class="page-break"></div>
+</div>
+{{end}}
 
+{{if .Render.syllabi}}
+<div id="section-syllabi">
 <!-- Course Details -->
 <h1>COURSE DESCRIPTIONS</h1>
 
@@ -1067,6 +1331,7 @@ const htmlTemplate = `<!DOCTYPE html>
 	</ol>
 	{{end}}
 	
+	{{if $.Sections.mappings}}
 	<!-- CO-PO Mapping -->
 	{{if and $course.Syllabus.Outcomes (gt (len $.Overview.POs) 0)}}
 	<h3>CO-PO Mapping</h3>
@@ -1084,7 +1349,7 @@ const htmlTemplate = `<!DOCTYPE html>
 			<tr>
 				<th>CO{{add $coIdx 1}}</th>
 				{{range $poIdx := iterate (len $.Overview.POs)}}
-				<td class="center">{{index $course.COPOMapping (printf "%d-%d" (add $coIdx 1) (add $poIdx 1))}}</td>
+				<td class="center">{{index $course.COPOMapping (printf "%d-%d" $coIdx (add $poIdx 1))}}</td>
 				{{end}}
 			</tr>
 			{{end}}
@@ -1109,7 +1374,7 @@ const htmlTemplate = `<!DOCTYPE html>
 			<tr>
 				<th>CO{{add $coIdx 1}}</th>
 				{{range $psoIdx := iterate (len $.Overview.PSOs)}}
-				<td class="center">{{index $course.COPSOMapping (printf "%d-%d" (add $coIdx 1) (add $psoIdx 1))}}</td>
+				<td class="center">{{index $course.COPSOMapping (printf "%d-%d" $coIdx (add $psoIdx 1))}}</td>
 				{{end}}
 			</tr>
 			{{end}}
@@ -1117,6 +1382,32 @@ const htmlTemplate = `<!DOCTYPE html>
 	</table>
 	{{end}}
 	
+	<!-- Mapping Justification -->
+	{{if $course.Justifications}}
+	<h3>Justification for Mapping</h3>
+	<table class="mapping-table">
+		<thead>
+			<tr>
+				<th>CO</th>
+				<th>PO/PSO</th>
+				<th>Level</th>
+				<th>Justification</th>
+			</tr>
+		</thead>
+		<tbody>
+			{{range $course.Justifications}}
+			<tr>
+				<td class="center">CO{{.CO}}</td>
+				<td class="center">{{.Outcome}}</td>
+				<td class="center">{{.Value}}</td>
+				<td>{{.Justification}}</td>
+			</tr>
+			{{end}}
+		</tbody>
+	</table>
+	{{end}}
+	{{end}}
+	
 	<!-- Course Content / Modules -->
 	{{if $course.Models}}
 	<h3>{{if isLab $course.CourseType}}List of Experiments{{else}}Course Content{{end}}</h3>
@@ -1202,11 +1493,14 @@ const htmlTemplate = `<!DOCTYPE html>
 
 {{end}}
 {{end}}
+</div>
+{{end}}
 
+{{if .Render.honour}}
+<div id="section-honour">
 <!-- Honour Cards -->
 {{range $honourIdx, $honour := .HonourCards}}
-<div class="page-break"></div>
-<h1>{{$honour.Title}} - SEMESTER {{$honour.Number}}</h1>
+<h1>{{$honour.Title}}</h1>
 
 {{range $verticalIdx, $vertical := $honour.Verticals}}
 <h2>{{$vertical.Name}}</h2>
@@ -1250,6 +1544,7 @@ const htmlTemplate = `<!DOCTYPE html>
 </table>
 
 <!-- Detailed Course Descriptions for Vertical -->
+{{if $.Sections.syllabi}}
 {{range $courseIdx, $course := $vertical.Courses}}
 <div class="course-section">
 	<div class="course-header">
@@ -1395,6 +1690,16 @@ const htmlTemplate = `<!DOCTYPE html>
 {{end}}
 {{end}}
 {{end}}
+{{if not $.Sections.syllabi}}
+<div class="page-break"></div>
+{{end}}
+{{end}}
+</div>
+{{end}}
+
+{{if .PageMarker}}
+<div class="page-marker">&nbsp;</div>
+{{end}}
 
 </body>
 </html>
@@ -0,0 +1,640 @@
+package curriculum
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"server/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/gorilla/mux"
+)
+
+const (
+	pdfJobQueued  = "queued"
+	pdfJobRunning = "running"
+	pdfJobDone    = "done"
+	pdfJobFailed  = "failed"
+)
+
+const (
+	defaultPDFWorkers = 2
+	pdfJobQueueSize   = 64
+	pdfJobRetention   = time.Hour
+)
+
+const (
+	defaultPDFCacheMaxAgeDays = 30
+	defaultPDFCacheMaxMB      = 500
+)
+
+// errPDFWorkersUnavailable and errPDFQueueFull are why a render could not be queued
+var (
+	errPDFWorkersUnavailable = errors.New("PDF generation is not available")
+	errPDFQueueFull          = errors.New("Too many PDF jobs are pending, try again later")
+)
+
+// pdfRender renders a PDF, printing HTML through print, and returns it with the cache key to store it under
+type pdfRender func(print htmlPrinter) ([]byte, string, error)
+
+// pdfJob is one PDF render on the workers: a background regulation PDF, or a download a
+// request is waiting for
+type pdfJob struct {
+	ID           string    `json:"id"`
+	CurriculumID int       `json:"curriculum_id"`
+	Status       string    `json:"status"`
+	Cached       bool      `json:"cached"`
+	Error        string    `json:"error,omitempty"`
+	Filename     string    `json:"filename"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	hash       string
+	render     pdfRender
+	keepResult bool   // a waiting request takes the PDF from result
+	result     []byte // the rendered PDF when keepResult is set
+	changed    chan struct{}
+}
+
+func (job *pdfJob) finished() bool {
+	return job.Status == pdfJobDone || job.Status == pdfJobFailed
+}
+
+// pdfJobs holds the submitted jobs and the queue the render workers take them from
+var pdfJobs struct {
+	mu      sync.Mutex
+	jobs    map[string]*pdfJob
+	queue   chan *pdfJob
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// pdfCacheDir is where finished PDFs are kept, named by the hash of the data they were rendered from
+func pdfCacheDir() string {
+	if dir := os.Getenv("PDF_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	return "pdf_cache"
+}
+
+// pdfCacheLimits returns how long an unused PDF stays cached and how large the cache may grow,
+// from PDF_CACHE_MAX_AGE_DAYS and PDF_CACHE_MAX_MB
+func pdfCacheLimits() (time.Duration, int64) {
+	days := defaultPDFCacheMaxAgeDays
+	if n, err := strconv.Atoi(os.Getenv("PDF_CACHE_MAX_AGE_DAYS")); err == nil && n > 0 {
+		days = n
+	}
+	mb := defaultPDFCacheMaxMB
+	if n, err := strconv.Atoi(os.Getenv("PDF_CACHE_MAX_MB")); err == nil && n > 0 {
+		mb = n
+	}
+	return time.Duration(days) * 24 * time.Hour, int64(mb) << 20
+}
+
+// pdfGeneratedDate is the date printed on documents generated now
+func pdfGeneratedDate() string {
+	return time.Now().Format("January 2, 2006")
+}
+
+// StartPDFWorkers starts the render workers; PDF_WORKERS bounds how many Chrome instances run at once
+func StartPDFWorkers() {
+	workers := defaultPDFWorkers
+	if n, err := strconv.Atoi(os.Getenv("PDF_WORKERS")); err == nil && n > 0 {
+		workers = n
+	}
+
+	pdfJobs.mu.Lock()
+	defer pdfJobs.mu.Unlock()
+	if pdfJobs.queue != nil {
+		return
+	}
+	pdfJobs.jobs = make(map[string]*pdfJob)
+	pdfJobs.queue = make(chan *pdfJob, pdfJobQueueSize)
+	pdfJobs.ctx, pdfJobs.cancel = context.WithCancel(context.Background())
+	for i := 0; i < workers; i++ {
+		pdfJobs.wg.Add(1)
+		go runPDFWorker(pdfJobs.ctx, pdfJobs.queue)
+	}
+	log.Printf("Started %d PDF workers", workers)
+}
+
+// StopPDFWorkers stops accepting jobs and waits for the queued ones to finish.
+// When ctx ends first the running renders are cancelled and the remaining jobs fail
+func StopPDFWorkers(ctx context.Context) error {
+	pdfJobs.mu.Lock()
+	if pdfJobs.queue == nil || pdfJobs.stopped {
+		pdfJobs.mu.Unlock()
+		return nil
+	}
+	pdfJobs.stopped = true
+	close(pdfJobs.queue)
+	pdfJobs.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		pdfJobs.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		pdfJobs.cancel()
+		return nil
+	case <-ctx.Done():
+		pdfJobs.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// runPDFWorker renders jobs with one long-lived browser, opening a tab per job.
+// The browser is started on the first Chrome render and relaunched after a failed print
+func runPDFWorker(ctx context.Context, queue <-chan *pdfJob) {
+	defer pdfJobs.wg.Done()
+
+	var browserCtx context.Context
+	cancelBrowser := func() {}
+	defer func() { cancelBrowser() }()
+
+	for job := range queue {
+		if ctx.Err() != nil {
+			updatePDFJob(job, pdfJobFailed, "PDF generation was cancelled by server shutdown")
+			continue
+		}
+		// Another job may have rendered the same data while this one was queued
+		if _, ok := cachedPDF(job.hash); ok {
+			updatePDFJob(job, pdfJobDone, "")
+			continue
+		}
+		updatePDFJob(job, pdfJobRunning, "")
+
+		printFailed := false
+		pdfBytes, hash, err := job.render(func(htmlContent string, setup pdfPageSetup) ([]byte, error) {
+			if browserCtx == nil {
+				browserCtx, cancelBrowser = chromedp.NewContext(ctx)
+			}
+			pdfBytes, err := printInBrowser(browserCtx, htmlContent, setup)
+			printFailed = err != nil
+			return pdfBytes, err
+		})
+		if printFailed {
+			cancelBrowser()
+			browserCtx, cancelBrowser = nil, func() {}
+		}
+		if err != nil {
+			log.Printf("Error generating %s: %v", job.Filename, err)
+			updatePDFJob(job, pdfJobFailed, err.Error())
+			continue
+		}
+		// A fallback render is cached under the native layout; point the job at it so it can be downloaded
+		cachePDF(hash, pdfBytes)
+		pdfJobs.mu.Lock()
+		job.hash = hash
+		if job.keepResult {
+			job.result = pdfBytes
+		}
+		pdfJobs.mu.Unlock()
+		updatePDFJob(job, pdfJobDone, "")
+	}
+}
+
+// printInBrowser prints HTML in a new tab of a long-lived browser
+func printInBrowser(browserCtx context.Context, htmlContent string, setup pdfPageSetup) ([]byte, error) {
+	// Make sure the browser itself is running before opening a tab in it
+	if err := chromedp.Run(browserCtx); err != nil {
+		return nil, fmt.Errorf("failed to start Chrome/Chromium: %v", err)
+	}
+	tabCtx, cancel := chromedp.NewContext(browserCtx)
+	defer cancel()
+	return htmlToPDF(tabCtx, htmlContent, setup)
+}
+
+// updatePDFJob moves a job to a new status and wakes everyone watching it
+func updatePDFJob(job *pdfJob, status, errMsg string) {
+	pdfJobs.mu.Lock()
+	defer pdfJobs.mu.Unlock()
+	job.Status = status
+	job.Error = errMsg
+	job.UpdatedAt = time.Now()
+	if job.finished() {
+		job.render = nil
+	}
+	close(job.changed)
+	job.changed = make(chan struct{})
+}
+
+// pdfJobSnapshot returns a copy of the job safe to encode, and a channel closed on its next change
+func pdfJobSnapshot(id string) (pdfJob, <-chan struct{}, bool) {
+	pdfJobs.mu.Lock()
+	defer pdfJobs.mu.Unlock()
+	job, ok := pdfJobs.jobs[id]
+	if !ok {
+		return pdfJob{}, nil, false
+	}
+	return *job, job.changed, true
+}
+
+// pruneFinishedPDFJobs forgets jobs that finished more than pdfJobRetention ago; callers hold pdfJobs.mu
+func pruneFinishedPDFJobs() {
+	cutoff := time.Now().Add(-pdfJobRetention)
+	for id, job := range pdfJobs.jobs {
+		if job.finished() && job.UpdatedAt.Before(cutoff) {
+			delete(pdfJobs.jobs, id)
+		}
+	}
+}
+
+func newPDFJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// regulationPDFRender renders a regulation book, cached under the layout it was rendered with
+func regulationPDFRender(data *models.RegulationPDF) pdfRender {
+	return func(print htmlPrinter) ([]byte, string, error) {
+		pdfBytes, native, err := renderRegulationPDF(print, data)
+		if err != nil {
+			return nil, "", err
+		}
+		hash, err := renderedRegulationPDFHash(data, native)
+		return pdfBytes, hash, err
+	}
+}
+
+// renderPDFOnWorkers queues a render for a request that waits for the PDF, so synchronous
+// downloads print in the workers' browsers and PDF_WORKERS bounds them too. hash is the cache
+// key of the data, checked again by the worker before it renders
+func renderPDFOnWorkers(ctx context.Context, filename, hash string, render pdfRender) ([]byte, error) {
+	now := time.Now()
+	job := &pdfJob{
+		Status:     pdfJobQueued,
+		Filename:   filename,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		hash:       hash,
+		render:     render,
+		keepResult: true,
+		changed:    make(chan struct{}),
+	}
+
+	pdfJobs.mu.Lock()
+	if pdfJobs.queue == nil || pdfJobs.stopped {
+		pdfJobs.mu.Unlock()
+		return nil, errPDFWorkersUnavailable
+	}
+	select {
+	case pdfJobs.queue <- job:
+	default:
+		pdfJobs.mu.Unlock()
+		return nil, errPDFQueueFull
+	}
+	pdfJobs.mu.Unlock()
+
+	for {
+		pdfJobs.mu.Lock()
+		status, errMsg, result, changed := job.Status, job.Error, job.result, job.changed
+		hash = job.hash
+		pdfJobs.mu.Unlock()
+
+		switch status {
+		case pdfJobFailed:
+			return nil, errors.New(errMsg)
+		case pdfJobDone:
+			if result != nil {
+				return result, nil
+			}
+			// Rendered by another job while this one was queued
+			if pdfBytes, ok := cachedPDF(hash); ok {
+				return pdfBytes, nil
+			}
+			return nil, fmt.Errorf("rendered PDF is no longer cached")
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// pdfQueueErrorStatus is the status for a render that failed with err: 503 when it could not be queued
+func pdfQueueErrorStatus(err error) int {
+	if errors.Is(err, errPDFWorkersUnavailable) || errors.Is(err, errPDFQueueFull) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// regulationPDFHash is the cache key of a regulation PDF
+func regulationPDFHash(data *models.RegulationPDF) (string, error) {
+	return pdfContentHash(data, pdfLayoutKey(data.TemplateHTML))
+}
+
+// renderedRegulationPDFHash is the cache key of a rendered regulation PDF, which differs from
+// regulationPDFHash when auto mode fell back to the native renderer
+func renderedRegulationPDFHash(data *models.RegulationPDF, native bool) (string, error) {
+	return pdfContentHash(data, renderedLayoutKey(data.TemplateHTML, native))
+}
+
+// pdfContentHash is the cache key of a rendered PDF: a hash of its data and the template rendering it
+func pdfContentHash(data interface{}, tmpl string) (string, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	sum.Write(payload)
+	sum.Write([]byte(tmpl))
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// cachedPDF returns a cached PDF, marking it used so eviction keeps it
+func cachedPDF(hash string) ([]byte, bool) {
+	path := filepath.Join(pdfCacheDir(), hash+".pdf")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data, true
+}
+
+// pdfCacheFile is a file in the PDF cache with its size and when it was last used
+type pdfCacheFile struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// pdfCacheEvictions picks the files to remove from the cache: those unused for longer than maxAge,
+// then the least recently used until the rest fit in maxBytes. The most recently used file is
+// always kept, so a PDF larger than the cache can still be downloaded once
+func pdfCacheEvictions(files []pdfCacheFile, now time.Time, maxAge time.Duration, maxBytes int64) []string {
+	sorted := append([]pdfCacheFile{}, files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].modTime.Before(sorted[j].modTime) })
+
+	var total int64
+	for _, f := range sorted {
+		total += f.size
+	}
+	evict := []string{}
+	for i, f := range sorted {
+		if i == len(sorted)-1 || (now.Sub(f.modTime) <= maxAge && total <= maxBytes) {
+			break
+		}
+		evict = append(evict, f.name)
+		total -= f.size
+	}
+	return evict
+}
+
+// prunePDFCache removes stale and least recently used PDFs so the cache stays within its limits
+func prunePDFCache() {
+	dir := pdfCacheDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Printf("Warning: Failed to read PDF cache directory: %v", err)
+		return
+	}
+	files := []pdfCacheFile{}
+	for _, entry := range entries {
+		// Temp files belong to writes in progress
+		if !strings.HasSuffix(entry.Name(), ".pdf") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, pdfCacheFile{name: entry.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+	maxAge, maxBytes := pdfCacheLimits()
+	for _, name := range pdfCacheEvictions(files, time.Now(), maxAge, maxBytes) {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: Failed to evict cached PDF %s: %v", name, err)
+		}
+	}
+}
+
+// cachePDF stores a rendered PDF, writing to a temp file first so readers never see a partial file
+func cachePDF(hash string, pdfBytes []byte) {
+	dir := pdfCacheDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("Warning: Failed to create PDF cache directory: %v", err)
+		return
+	}
+	tmp, err := os.CreateTemp(dir, hash+".*.tmp")
+	if err != nil {
+		log.Printf("Warning: Failed to cache PDF: %v", err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(pdfBytes); err != nil {
+		tmp.Close()
+		log.Printf("Warning: Failed to cache PDF: %v", err)
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		log.Printf("Warning: Failed to cache PDF: %v", err)
+		return
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, hash+".pdf")); err != nil {
+		log.Printf("Warning: Failed to cache PDF: %v", err)
+		return
+	}
+	prunePDFCache()
+}
+
+// SubmitRegulationPDFJob handles POST /api/curriculum/{id}/pdf/jobs.
+// The job is done at once when the PDF for unchanged data is cached; an identical job
+// already queued or running is returned instead of starting another render
+func SubmitRegulationPDFJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	regulationID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid regulation ID", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := regulationBookOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pdfData, err := buildRegulationPDFData(regulationID, opts)
+	if err != nil {
+		log.Println("Error fetching regulation data:", err)
+		http.Error(w, "Failed to fetch regulation data", http.StatusInternalServerError)
+		return
+	}
+	hash, err := regulationPDFHash(pdfData)
+	if err != nil {
+		log.Println("Error hashing regulation data:", err)
+		http.Error(w, "Failed to submit PDF job", http.StatusInternalServerError)
+		return
+	}
+	id, err := newPDFJobID()
+	if err != nil {
+		log.Println("Error creating PDF job ID:", err)
+		http.Error(w, "Failed to submit PDF job", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	job := &pdfJob{
+		ID:           id,
+		CurriculumID: regulationID,
+		Status:       pdfJobQueued,
+		Filename:     regulationPDFFilename(pdfData),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		hash:         hash,
+		render:       regulationPDFRender(pdfData),
+		changed:      make(chan struct{}),
+	}
+	if _, ok := cachedPDF(hash); ok {
+		job.Status = pdfJobDone
+		job.Cached = true
+		job.render = nil
+	}
+
+	pdfJobs.mu.Lock()
+	if pdfJobs.queue == nil || pdfJobs.stopped {
+		pdfJobs.mu.Unlock()
+		http.Error(w, errPDFWorkersUnavailable.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	pruneFinishedPDFJobs()
+	if job.Status == pdfJobQueued {
+		for _, existing := range pdfJobs.jobs {
+			if existing.hash == hash && !existing.finished() {
+				snapshot := *existing
+				pdfJobs.mu.Unlock()
+				w.WriteHeader(http.StatusAccepted)
+				json.NewEncoder(w).Encode(snapshot)
+				return
+			}
+		}
+		select {
+		case pdfJobs.queue <- job:
+		default:
+			pdfJobs.mu.Unlock()
+			http.Error(w, errPDFQueueFull.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	pdfJobs.jobs[job.ID] = job
+	snapshot := *job
+	pdfJobs.mu.Unlock()
+
+	if snapshot.Status == pdfJobDone {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// GetPDFJob handles GET /api/pdf/jobs/{jobId}
+func GetPDFJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	job, _, ok := pdfJobSnapshot(mux.Vars(r)["jobId"])
+	if !ok {
+		http.Error(w, "PDF job not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(job)
+}
+
+// StreamPDFJob handles GET /api/pdf/jobs/{jobId}/events, sending the job as a server-sent
+// "status" event now and on every change until it is done or failed
+func StreamPDFJob(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	jobID := mux.Vars(r)["jobId"]
+	if _, _, ok := pdfJobSnapshot(jobID); !ok {
+		http.Error(w, "PDF job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for {
+		job, changed, ok := pdfJobSnapshot(jobID)
+		if !ok {
+			return
+		}
+		payload, err := json.Marshal(job)
+		if err != nil {
+			log.Println("Error encoding PDF job:", err)
+			return
+		}
+		fmt.Fprintf(w, "event: status\ndata: %s\n\n", payload)
+		flusher.Flush()
+		if job.finished() {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// DownloadPDFJob handles GET /api/pdf/jobs/{jobId}/download
+func DownloadPDFJob(w http.ResponseWriter, r *http.Request) {
+	job, _, ok := pdfJobSnapshot(mux.Vars(r)["jobId"])
+	if !ok {
+		http.Error(w, "PDF job not found", http.StatusNotFound)
+		return
+	}
+	switch job.Status {
+	case pdfJobFailed:
+		http.Error(w, "PDF generation failed: "+job.Error, http.StatusConflict)
+		return
+	case pdfJobQueued, pdfJobRunning:
+		http.Error(w, "PDF is not ready yet", http.StatusConflict)
+		return
+	}
+
+	pdfBytes, ok := cachedPDF(job.hash)
+	if !ok {
+		http.Error(w, "PDF is no longer cached, submit the job again", http.StatusGone)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", job.Filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdfBytes)))
+	w.Write(pdfBytes)
+}
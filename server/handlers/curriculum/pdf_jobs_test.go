@@ -0,0 +1,100 @@
+package curriculum
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"server/models"
+	"testing"
+	"time"
+)
+
+func TestRegulationPDFHashIncludesGeneratedDate(t *testing.T) {
+	data := &models.RegulationPDF{CurriculumID: 1, RegulationName: "R2026", GeneratedDate: "January 2, 2026"}
+	before, err := regulationPDFHash(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.GeneratedDate = "January 3, 2026"
+	after, err := regulationPDFHash(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == after {
+		t.Error("a PDF generated on another day should not be served from the cache")
+	}
+}
+
+func TestPDFCacheEvictions(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	file := func(name string, mb int64, age time.Duration) pdfCacheFile {
+		return pdfCacheFile{name: name, size: mb << 20, modTime: now.Add(-age)}
+	}
+
+	tests := []struct {
+		name     string
+		files    []pdfCacheFile
+		maxBytes int64
+		want     []string
+	}{
+		{"within limits", []pdfCacheFile{file("a.pdf", 10, day), file("b.pdf", 10, 2*day)}, 100 << 20, []string{}},
+		{"stale files", []pdfCacheFile{file("a.pdf", 10, day), file("old.pdf", 10, 40*day), file("older.pdf", 10, 50*day)}, 100 << 20, []string{"older.pdf", "old.pdf"}},
+		{"over size", []pdfCacheFile{file("new.pdf", 40, time.Hour), file("mid.pdf", 40, day), file("lru.pdf", 40, 2*day)}, 100 << 20, []string{"lru.pdf"}},
+		{"newest kept when larger than the cache", []pdfCacheFile{file("huge.pdf", 200, 0), file("a.pdf", 10, day)}, 100 << 20, []string{"a.pdf"}},
+		{"empty cache", nil, 100 << 20, []string{}},
+	}
+	for _, tt := range tests {
+		got := pdfCacheEvictions(tt.files, now, 30*day, tt.maxBytes)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: pdfCacheEvictions = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPDFOnWorkers(t *testing.T) {
+	t.Setenv("PDF_CACHE_DIR", t.TempDir())
+	t.Setenv("PDF_WORKERS", "1")
+	ctx := context.Background()
+	rendered := func(print htmlPrinter) ([]byte, string, error) {
+		return []byte("%PDF-1.4 syllabus"), "rendered", nil
+	}
+
+	if _, err := renderPDFOnWorkers(ctx, "a.pdf", "data", rendered); !errors.Is(err, errPDFWorkersUnavailable) {
+		t.Fatalf("before the workers start: error = %v", err)
+	}
+
+	StartPDFWorkers()
+	pdfBytes, err := renderPDFOnWorkers(ctx, "a.pdf", "data", rendered)
+	if err != nil || string(pdfBytes) != "%PDF-1.4 syllabus" {
+		t.Errorf("render = %q, %v", pdfBytes, err)
+	}
+	if cached, ok := cachedPDF("rendered"); !ok || string(cached) != "%PDF-1.4 syllabus" {
+		t.Errorf("rendered PDF was not cached")
+	}
+
+	// The worker serves data rendered in the meantime from the cache
+	cachePDF("data", []byte("%PDF-1.4 cached"))
+	pdfBytes, err = renderPDFOnWorkers(ctx, "a.pdf", "data", func(print htmlPrinter) ([]byte, string, error) {
+		t.Error("cached data was rendered again")
+		return nil, "", nil
+	})
+	if err != nil || string(pdfBytes) != "%PDF-1.4 cached" {
+		t.Errorf("cached render = %q, %v", pdfBytes, err)
+	}
+
+	_, err = renderPDFOnWorkers(ctx, "b.pdf", "other", func(print htmlPrinter) ([]byte, string, error) {
+		return nil, "", errors.New("Chrome/Chromium not found")
+	})
+	if err == nil || err.Error() != "Chrome/Chromium not found" || pdfQueueErrorStatus(err) != http.StatusInternalServerError {
+		t.Errorf("failed render: error = %v", err)
+	}
+
+	if err := StopPDFWorkers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renderPDFOnWorkers(ctx, "a.pdf", "data", rendered); pdfQueueErrorStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("after the workers stop: error = %v", err)
+	}
+}
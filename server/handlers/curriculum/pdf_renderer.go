@@ -0,0 +1,148 @@
+package curriculum
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"os"
+	"server/models"
+	"strings"
+)
+
+// PDF_RENDERER selects how PDFs are produced: "chrome" (default) prints the HTML templates
+// through headless Chrome, "native" writes them in Go without any external binary, and
+// "auto" uses Chrome and falls back to the native renderer when Chrome fails
+const (
+	pdfRendererChrome = "chrome"
+	pdfRendererNative = "native"
+	pdfRendererAuto   = "auto"
+)
+
+// nativePDFLayoutVersion identifies the native layout in cache keys; bump it when the layout changes
+const nativePDFLayoutVersion = "native-pdf-2"
+
+func configuredPDFRenderer() string {
+	switch mode := strings.ToLower(os.Getenv("PDF_RENDERER")); mode {
+	case pdfRendererNative, pdfRendererAuto:
+		return mode
+	}
+	return pdfRendererChrome
+}
+
+// pdfLayoutKey is what decides a PDF's look besides its data: the HTML template, or the native layout
+func pdfLayoutKey(htmlTemplate string) string {
+	if configuredPDFRenderer() == pdfRendererNative {
+		return nativePDFLayoutVersion
+	}
+	return htmlTemplate
+}
+
+// renderedLayoutKey is the layout key a finished PDF is cached under. A PDF the native renderer
+// wrote, including an auto-mode fallback, is cached under the native layout rather than the template
+func renderedLayoutKey(htmlTemplate string, native bool) string {
+	if native {
+		return nativePDFLayoutVersion
+	}
+	return htmlTemplate
+}
+
+// pdfPageSetup is the paper, margins and running header and footer of an exported PDF
+type pdfPageSetup struct {
+	PaperSize   string
+	Landscape   bool
+	Margins     [4]float64 // top, right, bottom, left in mm
+	HeaderText  string
+	FooterText  string
+	PageNumbers bool
+	Draft       bool
+}
+
+// pageSetupFor is the page setup of a document with the given branding
+func pageSetupFor(b models.PDFBranding, draft bool) pdfPageSetup {
+	setup := pdfPageSetup{
+		PaperSize:   b.PaperSize,
+		Landscape:   b.Landscape,
+		Margins:     [4]float64{b.MarginTop, b.MarginRight, b.MarginBottom, b.MarginLeft},
+		HeaderText:  b.HeaderText,
+		FooterText:  b.FooterText,
+		PageNumbers: b.PageNumbers,
+		Draft:       draft,
+	}
+	if _, ok := pdfPaperSizes[setup.PaperSize]; !ok {
+		setup.PaperSize = "A4"
+	}
+	return setup
+}
+
+// paperSize returns the page width and height in mm
+func (s pdfPageSetup) paperSize() (float64, float64) {
+	size := pdfPaperSizes[s.PaperSize]
+	if s.Landscape {
+		return size[1], size[0]
+	}
+	return size[0], size[1]
+}
+
+// footerLine joins the footer text and, when page numbers are on, the page number
+func (s pdfPageSetup) footerLine(footerText, pageNumber string) string {
+	parts := []string{}
+	if footerText != "" {
+		parts = append(parts, footerText)
+	}
+	if s.PageNumbers {
+		parts = append(parts, pageNumber)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// pageStyles is the @page rule and the DRAFT watermark style for the HTML templates
+func (s pdfPageSetup) pageStyles() template.CSS {
+	width, height := s.paperSize()
+	return template.CSS(fmt.Sprintf(`
+		@page { size: %gmm %gmm; margin: %gmm %gmm %gmm %gmm; }
+		.draft-watermark {
+			position: fixed; top: 40%%; left: 0; width: 100%%;
+			text-align: center; font-size: 120px; font-weight: bold;
+			color: rgba(200, 0, 0, 0.12); transform: rotate(-35deg);
+			z-index: 1000; pointer-events: none;
+		}`, width, height, s.Margins[0], s.Margins[1], s.Margins[2], s.Margins[3]))
+}
+
+// chromeHeaderFooter returns Chrome's header and footer templates, or ok false when there are none
+func (s pdfPageSetup) chromeHeaderFooter() (header, footer string, ok bool) {
+	const style = `<div style="font-size: 8px; width: 100%%; text-align: center; font-family: 'Times New Roman', serif;">%s</div>`
+	footerLine := s.footerLine(html.EscapeString(s.FooterText), `Page <span class="pageNumber"></span> of <span class="totalPages"></span>`)
+	if s.HeaderText == "" && footerLine == "" {
+		return "", "", false
+	}
+	return fmt.Sprintf(style, html.EscapeString(s.HeaderText)), fmt.Sprintf(style, footerLine), true
+}
+
+// htmlPrinter prints an HTML document to PDF through Chrome
+type htmlPrinter func(htmlContent string, setup pdfPageSetup) ([]byte, error)
+
+// renderPDF produces a PDF with the configured renderer, from the HTML renderHTML returns or by
+// handing a native writer to writeNative. It reports whether the native renderer wrote it, so the
+// caller can cache it under renderedLayoutKey
+func renderPDF(setup pdfPageSetup, renderHTML func() (string, error), print htmlPrinter, writeNative func(doc documentWriter)) ([]byte, bool, error) {
+	mode := configuredPDFRenderer()
+	if mode != pdfRendererNative {
+		htmlContent, err := renderHTML()
+		if err == nil {
+			var pdfBytes []byte
+			if pdfBytes, err = print(htmlContent, setup); err == nil {
+				return pdfBytes, false, nil
+			}
+		}
+		if mode == pdfRendererChrome {
+			return nil, false, err
+		}
+		log.Println("Chrome PDF rendering failed, using the native renderer:", err)
+	}
+
+	doc := newNativePDFDocument(setup)
+	writeNative(doc)
+	pdfBytes, err := doc.Bytes()
+	return pdfBytes, true, err
+}
@@ -0,0 +1,50 @@
+package curriculum
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRenderPDFReportsNativeRenders(t *testing.T) {
+	setup := pageSetupFor(defaultPDFBranding(), false)
+	renderHTML := func() (string, error) { return "<p>Regulations</p>", nil }
+	chromeOK := func(string, pdfPageSetup) ([]byte, error) { return []byte("%PDF-chrome"), nil }
+	chromeMissing := func(string, pdfPageSetup) ([]byte, error) { return nil, errors.New("chrome not found") }
+	writeNative := func(doc documentWriter) { doc.Title("Regulations") }
+
+	tests := []struct {
+		mode    string
+		print   htmlPrinter
+		native  bool
+		wantErr bool
+	}{
+		{"chrome", chromeOK, false, false},
+		{"chrome", chromeMissing, false, true},
+		{"native", chromeMissing, true, false},
+		{"auto", chromeOK, false, false},
+		{"auto", chromeMissing, true, false},
+	}
+	for _, tt := range tests {
+		t.Setenv("PDF_RENDERER", tt.mode)
+		pdfBytes, native, err := renderPDF(setup, renderHTML, tt.print, writeNative)
+		if (err != nil) != tt.wantErr || native != tt.native {
+			t.Errorf("%s: native = %v, err = %v; want native %v, error %v", tt.mode, native, err, tt.native, tt.wantErr)
+			continue
+		}
+		if native && !bytes.HasPrefix(pdfBytes, []byte("%PDF")) {
+			t.Errorf("%s: native render did not produce a PDF", tt.mode)
+		}
+	}
+}
+
+func TestRenderedLayoutKeyOfAutoFallback(t *testing.T) {
+	// A fallback must be cached where a later download of the job looks for it, not under the template
+	t.Setenv("PDF_RENDERER", "auto")
+	if got := renderedLayoutKey("<html>", true); got != nativePDFLayoutVersion {
+		t.Errorf("fallback layout key = %q, want %q", got, nativePDFLayoutVersion)
+	}
+	if got := renderedLayoutKey("<html>", false); got != pdfLayoutKey("<html>") {
+		t.Errorf("chrome layout key = %q, want the template", got)
+	}
+}
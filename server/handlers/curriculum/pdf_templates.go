@@ -0,0 +1,278 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// PDF template kinds
+const (
+	pdfTemplateRegulation = "regulation"
+	pdfTemplateSyllabus   = "syllabus"
+)
+
+// builtInPDFTemplate is the template used when none is registered for a kind
+func builtInPDFTemplate(kind string) string {
+	if kind == pdfTemplateSyllabus {
+		return courseSyllabusTemplate
+	}
+	return htmlTemplate
+}
+
+// resolvePDFTemplate picks the registered template of a kind for a department and curriculum template.
+// A department's own templates win over the institution's, and a template for the exact
+// curriculum template wins over one for any; the built-in template is used when none match
+func resolvePDFTemplate(kind string, departmentID int, curriculumTemplate string) string {
+	var html string
+	err := db.DB.QueryRow(`
+		SELECT html FROM pdf_templates
+		WHERE kind = ? AND status = 1 AND department_id IN (?, 0) AND curriculum_template IN (?, '')
+		ORDER BY department_id DESC, curriculum_template DESC, updated_at DESC, id DESC
+		LIMIT 1`, kind, departmentID, curriculumTemplate).Scan(&html)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Println("Error resolving PDF template:", err)
+		}
+		return builtInPDFTemplate(kind)
+	}
+	return html
+}
+
+// parsePDFTemplate parses an HTML template with the helper functions every PDF template can use
+func parsePDFTemplate(name, html string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(pdfTemplateFuncs()).Parse(html)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// validatePDFTemplate checks a template before it is stored
+func validatePDFTemplate(t *models.PDFTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if t.Kind != pdfTemplateRegulation && t.Kind != pdfTemplateSyllabus {
+		return fmt.Errorf("kind must be regulation or syllabus")
+	}
+	if t.DepartmentID < 0 {
+		return fmt.Errorf("invalid department_id")
+	}
+	if strings.TrimSpace(t.HTML) == "" {
+		return fmt.Errorf("html is required")
+	}
+	_, err := parsePDFTemplate(t.Kind, t.HTML)
+	return err
+}
+
+func fetchPDFTemplate(id int) (models.PDFTemplate, error) {
+	var t models.PDFTemplate
+	err := db.DB.QueryRow(`
+		SELECT id, name, kind, department_id, curriculum_template, html, COALESCE(created_by, ''), created_at, updated_at
+		FROM pdf_templates WHERE id = ? AND status = 1`, id).
+		Scan(&t.ID, &t.Name, &t.Kind, &t.DepartmentID, &t.CurriculumTemplate, &t.HTML, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	return t, err
+}
+
+// ListPDFTemplates handles GET /api/pdf/templates, optionally filtered by ?kind and ?department_id.
+// The HTML itself is left out of the list
+func ListPDFTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := `SELECT id, name, kind, department_id, curriculum_template, COALESCE(created_by, ''), created_at, updated_at
+		FROM pdf_templates WHERE status = 1`
+	args := []interface{}{}
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		query += " AND kind = ?"
+		args = append(args, kind)
+	}
+	if r.URL.Query().Get("department_id") != "" {
+		departmentID, err := departmentIDParam(r)
+		if err != nil {
+			http.Error(w, "Invalid department ID", http.StatusBadRequest)
+			return
+		}
+		query += " AND department_id = ?"
+		args = append(args, departmentID)
+	}
+	query += " ORDER BY kind, department_id, curriculum_template, name"
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching PDF templates:", err)
+		http.Error(w, "Failed to fetch templates", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	templates := []models.PDFTemplate{}
+	for rows.Next() {
+		var t models.PDFTemplate
+		if err := rows.Scan(&t.ID, &t.Name, &t.Kind, &t.DepartmentID, &t.CurriculumTemplate, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			log.Println("Error scanning PDF template:", err)
+			continue
+		}
+		templates = append(templates, t)
+	}
+
+	json.NewEncoder(w).Encode(templates)
+}
+
+// GetPDFTemplate handles GET /api/pdf/templates/{templateId}
+func GetPDFTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	templateID, err := strconv.Atoi(mux.Vars(r)["templateId"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	t, err := fetchPDFTemplate(templateID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching PDF template:", err)
+		http.Error(w, "Failed to fetch template", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(t)
+}
+
+// GetBuiltInPDFTemplate handles GET /api/pdf/templates/default?kind=: the built-in template,
+// as a starting point for a custom one
+func GetBuiltInPDFTemplate(w http.ResponseWriter, r *http.Request) {
+	kind := r.URL.Query().Get("kind")
+	if kind == "" {
+		kind = pdfTemplateRegulation
+	}
+	if kind != pdfTemplateRegulation && kind != pdfTemplateSyllabus {
+		http.Error(w, "kind must be regulation or syllabus", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(builtInPDFTemplate(kind)))
+}
+
+// CreatePDFTemplate handles POST /api/pdf/templates
+func CreatePDFTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var t models.PDFTemplate
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validatePDFTemplate(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.DB.Exec(`
+		INSERT INTO pdf_templates (name, kind, department_id, curriculum_template, html, created_by)
+		VALUES (?, ?, ?, ?, ?, ?)`, t.Name, t.Kind, t.DepartmentID, t.CurriculumTemplate, t.HTML, requestUser(r))
+	if err != nil {
+		log.Println("Error creating PDF template:", err)
+		http.Error(w, "Failed to create template", http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	created, err := fetchPDFTemplate(int(id))
+	if err != nil {
+		log.Println("Error fetching created PDF template:", err)
+		http.Error(w, "Failed to fetch template", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// UpdatePDFTemplate handles PUT /api/pdf/templates/{templateId}
+func UpdatePDFTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	templateID, err := strconv.Atoi(mux.Vars(r)["templateId"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	// Fields left out of the request keep their current value
+	t, err := fetchPDFTemplate(templateID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching PDF template:", err)
+		http.Error(w, "Failed to fetch template", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validatePDFTemplate(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec(`
+		UPDATE pdf_templates SET name = ?, kind = ?, department_id = ?, curriculum_template = ?, html = ?
+		WHERE id = ? AND status = 1`, t.Name, t.Kind, t.DepartmentID, t.CurriculumTemplate, t.HTML, templateID)
+	if err != nil {
+		log.Println("Error updating PDF template:", err)
+		http.Error(w, "Failed to update template", http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := fetchPDFTemplate(templateID)
+	if err != nil {
+		log.Println("Error fetching updated PDF template:", err)
+		http.Error(w, "Failed to fetch template", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeletePDFTemplate handles DELETE /api/pdf/templates/{templateId}; exports fall back to the
+// next matching template
+func DeletePDFTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	templateID, err := strconv.Atoi(mux.Vars(r)["templateId"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.DB.Exec("UPDATE pdf_templates SET status = 0 WHERE id = ? AND status = 1", templateID)
+	if err != nil {
+		log.Println("Error deleting PDF template:", err)
+		http.Error(w, "Failed to delete template", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Template deleted successfully"})
+}
@@ -24,23 +24,12 @@ func GetPEOPOMapping(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch existing PEO-PO mappings
-	matrix := make(map[string]int)
-	rows, err := db.DB.Query("SELECT peo_index, po_index, mapping_value FROM peo_po_mapping WHERE curriculum_id = ?", curriculumID)
+	matrix, err := fetchPEOPOMatrix(curriculumID)
 	if err != nil {
 		log.Println("Error fetching PEO-PO mappings:", err)
 		http.Error(w, "Failed to fetch mappings", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var peoIndex, poIndex, value int
-		if err := rows.Scan(&peoIndex, &poIndex, &value); err == nil {
-			// Convert from 1-based (database) to 0-based (frontend)
-			key := fmt.Sprintf("%d-%d", peoIndex-1, poIndex-1)
-			matrix[key] = value
-		}
-	}
 
 	response := models.PEOPOMappingResponse{
 		Matrix: matrix,
@@ -67,6 +56,13 @@ func SavePEOPOMapping(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before, err := fetchPEOPOMatrix(curriculumID)
+	if err != nil {
+		log.Println("Error fetching PEO-PO mappings:", err)
+		http.Error(w, "Failed to save mappings", http.StatusInternalServerError)
+		return
+	}
+
 	// Start transaction
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -97,6 +93,18 @@ func SavePEOPOMapping(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Log the activity as part of the transaction
+	after := make(map[string]int)
+	for _, mapping := range request.Mappings {
+		after[fmt.Sprintf("%d-%d", mapping.PEOIndex-1, mapping.POIndex-1)] = mapping.MappingValue
+	}
+	if err := logCurriculumChangeTx(tx, curriculumID, logEntity{"peo_po_mapping", curriculumID, opUpdate}, "PEO-PO Mapping Saved",
+		"Updated PEO-PO mappings for the curriculum", requestUser(r), before, after); err != nil {
+		log.Println("Error logging PEO-PO mappings:", err)
+		http.Error(w, "Failed to save mappings", http.StatusInternalServerError)
+		return
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		log.Println("Error committing transaction:", err)
@@ -104,9 +112,25 @@ func SavePEOPOMapping(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Log the activity
-	LogCurriculumActivity(curriculumID, "PEO-PO Mapping Saved",
-		"Updated PEO-PO mappings for the curriculum", "System")
-
 	json.NewEncoder(w).Encode(map[string]string{"message": "PEO-PO mappings saved successfully"})
 }
+
+// fetchPEOPOMatrix loads the PEO-PO matrix of a curriculum keyed by 0-based "peo-po" indexes
+func fetchPEOPOMatrix(curriculumID int) (map[string]int, error) {
+	matrix := make(map[string]int)
+	rows, err := db.DB.Query("SELECT peo_index, po_index, mapping_value FROM peo_po_mapping WHERE curriculum_id = ?", curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var peoIndex, poIndex, value int
+		if err := rows.Scan(&peoIndex, &poIndex, &value); err == nil {
+			// Convert from 1-based (database) to 0-based (frontend)
+			key := fmt.Sprintf("%d-%d", peoIndex-1, poIndex-1)
+			matrix[key] = value
+		}
+	}
+	return matrix, rows.Err()
+}
@@ -0,0 +1,605 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// prerequisiteCodePattern picks the leading course code out of a prerequisite line such as "22CS101 - Data Structures"
+var prerequisiteCodePattern = regexp.MustCompile(`^\s*([A-Za-z0-9]+)`)
+
+// findCourseByCode looks up an active course by code, preferring one that shares a curriculum with courseID
+// since the same code may exist in several curricula
+func findCourseByCode(courseID int, code string) (sql.NullInt64, error) {
+	var id sql.NullInt64
+	err := db.DB.QueryRow(`
+		SELECT c.course_id FROM courses c
+		WHERE UPPER(c.course_code) = UPPER(?) AND c.status = 1
+		ORDER BY EXISTS (
+			SELECT 1 FROM curriculum_courses a
+			INNER JOIN curriculum_courses b ON a.curriculum_id = b.curriculum_id
+			WHERE a.course_id = c.course_id AND b.course_id = ? AND a.status = 1 AND b.status = 1
+		) DESC, c.course_id
+		LIMIT 1`, strings.TrimSpace(code), courseID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return sql.NullInt64{}, nil
+	}
+	return id, err
+}
+
+// resolvePrerequisiteCourse links a free-text prerequisite to a course when it starts with a known course code
+func resolvePrerequisiteCourse(courseID int, text string) sql.NullInt64 {
+	match := prerequisiteCodePattern.FindStringSubmatch(text)
+	if match == nil {
+		return sql.NullInt64{}
+	}
+	id, err := findCourseByCode(courseID, match[1])
+	if err != nil {
+		log.Println("Error resolving prerequisite course:", err)
+		return sql.NullInt64{}
+	}
+	if id.Valid && int(id.Int64) == courseID {
+		return sql.NullInt64{}
+	}
+	return id
+}
+
+// prerequisiteOfferedTooLate reports whether, in a curriculum both courses belong to, the prerequisite
+// is offered in the same or a later semester than the course
+func prerequisiteOfferedTooLate(q rowQuerier, courseID, prerequisiteID int) (bool, error) {
+	var tooLate bool
+	err := q.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM curriculum_courses a
+			INNER JOIN normal_cards na ON na.id = a.semester_id
+			INNER JOIN curriculum_courses b ON b.curriculum_id = a.curriculum_id AND b.status = 1
+			INNER JOIN normal_cards nb ON nb.id = b.semester_id
+			WHERE a.course_id = ? AND b.course_id = ? AND a.status = 1
+			  AND na.semester_number > 0 AND nb.semester_number >= na.semester_number
+		)`, courseID, prerequisiteID).Scan(&tooLate)
+	return tooLate, err
+}
+
+// prerequisiteOutsideCurriculum reports whether a curriculum the course belongs to does not include the prerequisite
+func prerequisiteOutsideCurriculum(q rowQuerier, courseID, prerequisiteID int) (bool, error) {
+	var outside bool
+	err := q.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM curriculum_courses a
+			INNER JOIN curriculum cur ON cur.id = a.curriculum_id AND cur.status = 1
+			WHERE a.course_id = ? AND a.status = 1
+			  AND NOT EXISTS (
+				SELECT 1 FROM curriculum_courses b
+				WHERE b.curriculum_id = a.curriculum_id AND b.course_id = ? AND b.status = 1
+			  )
+		)`, courseID, prerequisiteID).Scan(&outside)
+	return outside, err
+}
+
+// autoLinkPrerequisite links a free-text prerequisite line to the course its code names, unless the
+// link would create a cycle or point at a course that is not offered in an earlier semester of the same curriculum.
+// Lines that fail those checks stay free text for the prerequisite editor to resolve
+func autoLinkPrerequisite(requires map[int][]int, courseID int, text string) sql.NullInt64 {
+	link := resolvePrerequisiteCourse(courseID, text)
+	if !link.Valid {
+		return link
+	}
+	prerequisiteID := int(link.Int64)
+	if prerequisiteReaches(requires, prerequisiteID, courseID) {
+		log.Printf("Not linking prerequisite %q of course %d: course %d already requires it", text, courseID, prerequisiteID)
+		return sql.NullInt64{}
+	}
+	outside, err := prerequisiteOutsideCurriculum(db.DB, courseID, prerequisiteID)
+	if err != nil {
+		log.Println("Error checking prerequisite curriculum:", err)
+		return sql.NullInt64{}
+	}
+	if outside {
+		log.Printf("Not linking prerequisite %q of course %d: course %d is not in the same curriculum", text, courseID, prerequisiteID)
+		return sql.NullInt64{}
+	}
+	tooLate, err := prerequisiteOfferedTooLate(db.DB, courseID, prerequisiteID)
+	if err != nil {
+		log.Println("Error checking prerequisite semester:", err)
+		return sql.NullInt64{}
+	}
+	if tooLate {
+		log.Printf("Not linking prerequisite %q of course %d: course %d is not offered in an earlier semester", text, courseID, prerequisiteID)
+		return sql.NullInt64{}
+	}
+	return link
+}
+
+// fetchPrerequisiteLinks returns the course each existing prerequisite line of a course is linked to
+func fetchPrerequisiteLinks(courseID int) map[string]sql.NullInt64 {
+	links := make(map[string]sql.NullInt64)
+	rows, err := db.DB.Query(`
+		SELECT prerequisite, prerequisite_course_id
+		FROM course_prerequisites
+		WHERE course_id = ? AND prerequisite_course_id IS NOT NULL`, courseID)
+	if err != nil {
+		return links
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var text string
+		var id sql.NullInt64
+		if err := rows.Scan(&text, &id); err == nil {
+			links[text] = id
+		}
+	}
+	return links
+}
+
+// fetchCoursePrerequisites retrieves the prerequisites of a course with the courses they reference
+func fetchCoursePrerequisites(courseID int) ([]models.CoursePrerequisite, error) {
+	rows, err := db.DB.Query(`
+		SELECT p.prerequisite, c.course_id, COALESCE(c.course_code, ''), COALESCE(c.course_name, '')
+		FROM course_prerequisites p
+		LEFT JOIN courses c ON c.course_id = p.prerequisite_course_id
+		WHERE p.course_id = ?
+		ORDER BY p.position`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prerequisites := []models.CoursePrerequisite{}
+	for rows.Next() {
+		var p models.CoursePrerequisite
+		var linkedID sql.NullInt64
+		if err := rows.Scan(&p.Text, &linkedID, &p.CourseCode, &p.CourseName); err != nil {
+			log.Println("Error scanning prerequisite:", err)
+			continue
+		}
+		if linkedID.Valid {
+			id := int(linkedID.Int64)
+			p.CourseID = &id
+		}
+		prerequisites = append(prerequisites, p)
+	}
+	return prerequisites, nil
+}
+
+// rowsQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowsQuerier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// prerequisiteEdges loads every course -> prerequisite course link. Inside a transaction the links
+// stay locked until it ends, so two saves cannot close a cycle between them
+func prerequisiteEdges(q rowsQuerier) (map[int][]int, error) {
+	rows, err := q.Query(`
+		SELECT course_id, prerequisite_course_id
+		FROM course_prerequisites
+		WHERE prerequisite_course_id IS NOT NULL
+		FOR UPDATE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requires := make(map[int][]int)
+	for rows.Next() {
+		var courseID, prerequisiteID int
+		if err := rows.Scan(&courseID, &prerequisiteID); err == nil {
+			requires[courseID] = append(requires[courseID], prerequisiteID)
+		}
+	}
+	return requires, nil
+}
+
+// prerequisiteReaches reports whether course from (transitively) requires course target
+func prerequisiteReaches(requires map[int][]int, from, target int) bool {
+	visited := make(map[int]bool)
+	stack := []int{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == target {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		stack = append(stack, requires[current]...)
+	}
+	return false
+}
+
+// findPrerequisiteCycles returns the cycles among the courses a topological sort could not order.
+// Every such course has a predecessor among them, so walking predecessors always ends in a cycle
+func findPrerequisiteCycles(remaining map[int]bool, requires map[int][]int) [][]int {
+	cycles := [][]int{}
+	done := make(map[int]bool)
+
+	for start := range remaining {
+		if done[start] {
+			continue
+		}
+		path := []int{}
+		position := make(map[int]int)
+		current := start
+		for !done[current] {
+			if pos, seen := position[current]; seen {
+				cycle := append([]int{}, path[pos:]...)
+				cycles = append(cycles, cycle)
+				break
+			}
+			position[current] = len(path)
+			path = append(path, current)
+
+			next := -1
+			for _, p := range requires[current] {
+				if remaining[p] {
+					next = p
+					break
+				}
+			}
+			if next == -1 {
+				break
+			}
+			current = next
+		}
+		for _, id := range path {
+			done[id] = true
+		}
+	}
+	return cycles
+}
+
+// buildPrerequisiteGraph builds the prerequisite DAG of a curriculum and checks it for
+// prerequisites outside the curriculum, prerequisites not in an earlier semester, and cycles
+func buildPrerequisiteGraph(curriculumID int) (*models.PrerequisiteGraph, error) {
+	graph := &models.PrerequisiteGraph{
+		CurriculumID: curriculumID,
+		Nodes:        []models.PrerequisiteNode{},
+		Edges:        []models.PrerequisiteEdge{},
+		Order:        []int{},
+		Issues:       []models.PrerequisiteIssue{},
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT c.course_id, c.course_code, c.course_name, COALESCE(MIN(nc.semester_number), 0)
+		FROM curriculum_courses cc
+		INNER JOIN courses c ON c.course_id = cc.course_id
+		LEFT JOIN normal_cards nc ON nc.id = cc.semester_id
+		WHERE cc.curriculum_id = ? AND cc.status = 1 AND c.status = 1
+		GROUP BY c.course_id, c.course_code, c.course_name
+		ORDER BY COALESCE(MIN(nc.semester_number), 0), c.course_code`, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+	nodeIndex := make(map[int]int)
+	for rows.Next() {
+		var node models.PrerequisiteNode
+		if err := rows.Scan(&node.CourseID, &node.CourseCode, &node.CourseName, &node.Semester); err == nil {
+			nodeIndex[node.CourseID] = len(graph.Nodes)
+			graph.Nodes = append(graph.Nodes, node)
+		}
+	}
+	rows.Close()
+
+	label := func(id int) string {
+		if i, ok := nodeIndex[id]; ok {
+			return graph.Nodes[i].CourseCode
+		}
+		return fmt.Sprintf("course %d", id)
+	}
+
+	rows, err = db.DB.Query(`
+		SELECT p.course_id, p.prerequisite_course_id, COALESCE(c.course_code, '')
+		FROM course_prerequisites p
+		LEFT JOIN courses c ON c.course_id = p.prerequisite_course_id
+		WHERE p.course_id IN (SELECT course_id FROM curriculum_courses WHERE curriculum_id = ? AND status = 1)
+		ORDER BY p.course_id, p.position`, curriculumID)
+	if err != nil {
+		return nil, err
+	}
+
+	requires := make(map[int][]int)
+	seen := make(map[models.PrerequisiteEdge]bool)
+	for rows.Next() {
+		var courseID int
+		var prerequisiteID sql.NullInt64
+		var prerequisiteCode string
+		if err := rows.Scan(&courseID, &prerequisiteID, &prerequisiteCode); err != nil {
+			log.Println("Error scanning prerequisite link:", err)
+			continue
+		}
+		course, ok := nodeIndex[courseID]
+		if !ok {
+			continue
+		}
+		if !prerequisiteID.Valid {
+			graph.Nodes[course].FreeText++
+			continue
+		}
+
+		from := int(prerequisiteID.Int64)
+		prerequisite, inCurriculum := nodeIndex[from]
+		if !inCurriculum {
+			graph.Issues = append(graph.Issues, models.PrerequisiteIssue{
+				IssueType: "NOT_IN_CURRICULUM",
+				CourseIDs: []int{courseID, from},
+				Message:   fmt.Sprintf("%s requires %s, which is not part of this curriculum", label(courseID), prerequisiteCode),
+			})
+			continue
+		}
+
+		edge := models.PrerequisiteEdge{From: from, To: courseID}
+		if seen[edge] {
+			continue
+		}
+		seen[edge] = true
+		graph.Edges = append(graph.Edges, edge)
+		requires[courseID] = append(requires[courseID], from)
+
+		courseSemester := graph.Nodes[course].Semester
+		prerequisiteSemester := graph.Nodes[prerequisite].Semester
+		if courseSemester > 0 && prerequisiteSemester > 0 && prerequisiteSemester >= courseSemester {
+			graph.Issues = append(graph.Issues, models.PrerequisiteIssue{
+				IssueType: "SEMESTER_ORDER",
+				CourseIDs: []int{courseID, from},
+				Message: fmt.Sprintf("%s (semester %d) requires %s, which is offered in semester %d",
+					label(courseID), courseSemester, label(from), prerequisiteSemester),
+			})
+		}
+	}
+	rows.Close()
+
+	// Kahn's algorithm in node order, so courses come out by semester where the edges allow
+	inDegree := make(map[int]int)
+	dependents := make(map[int][]int)
+	for _, edge := range graph.Edges {
+		inDegree[edge.To]++
+		dependents[edge.From] = append(dependents[edge.From], edge.To)
+	}
+	queue := []int{}
+	for _, node := range graph.Nodes {
+		if inDegree[node.CourseID] == 0 {
+			queue = append(queue, node.CourseID)
+		}
+	}
+	order := []int{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		order = append(order, current)
+		for _, next := range dependents[current] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if len(order) == len(graph.Nodes) {
+		graph.Order = order
+	} else {
+		remaining := make(map[int]bool)
+		for _, node := range graph.Nodes {
+			remaining[node.CourseID] = true
+		}
+		for _, id := range order {
+			delete(remaining, id)
+		}
+		for _, cycle := range findPrerequisiteCycles(remaining, requires) {
+			codes := make([]string, 0, len(cycle)+1)
+			for _, id := range cycle {
+				codes = append(codes, label(id))
+			}
+			codes = append(codes, label(cycle[0]))
+			graph.Issues = append(graph.Issues, models.PrerequisiteIssue{
+				IssueType: "CYCLE",
+				CourseIDs: cycle,
+				Message:   "Circular prerequisites: " + strings.Join(codes, " requires "),
+			})
+		}
+	}
+
+	graph.Valid = len(graph.Issues) == 0
+	return graph, nil
+}
+
+// GetCoursePrerequisites handles GET /course/:courseId/prerequisites
+func GetCoursePrerequisites(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	prerequisites, err := fetchCoursePrerequisites(courseID)
+	if err != nil {
+		log.Println("Error fetching prerequisites:", err)
+		http.Error(w, "Failed to fetch prerequisites", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(prerequisites)
+}
+
+// SaveCoursePrerequisites handles PUT /course/:courseId/prerequisites
+// Items may reference a course by course_id or course_code; links that would create a cycle, or whose
+// course is not in an earlier semester of every curriculum this course belongs to, are rejected
+func SaveCoursePrerequisites(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.PrerequisitesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	before, _ := fetchCoursePrerequisites(courseID)
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	requires, err := prerequisiteEdges(tx)
+	if err != nil {
+		log.Println("Error fetching prerequisite links:", err)
+		http.Error(w, "Failed to save prerequisites", http.StatusInternalServerError)
+		return
+	}
+
+	type prerequisiteRow struct {
+		text     string
+		courseID sql.NullInt64
+	}
+	toSave := []prerequisiteRow{}
+	for _, item := range req.Prerequisites {
+		row := prerequisiteRow{text: strings.TrimSpace(item.Text)}
+
+		if item.CourseID != nil || strings.TrimSpace(item.CourseCode) != "" {
+			var code, name string
+			if item.CourseID != nil {
+				err = db.DB.QueryRow("SELECT course_code, course_name FROM courses WHERE course_id = ? AND status = 1",
+					*item.CourseID).Scan(&code, &name)
+				row.courseID = sql.NullInt64{Int64: int64(*item.CourseID), Valid: true}
+			} else {
+				row.courseID, err = findCourseByCode(courseID, item.CourseCode)
+				if err == nil && !row.courseID.Valid {
+					err = sql.ErrNoRows
+				}
+				if err == nil {
+					err = db.DB.QueryRow("SELECT course_code, course_name FROM courses WHERE course_id = ?",
+						row.courseID.Int64).Scan(&code, &name)
+				}
+			}
+			if err == sql.ErrNoRows {
+				ref := strings.TrimSpace(item.CourseCode)
+				if item.CourseID != nil {
+					ref = strconv.Itoa(*item.CourseID)
+				}
+				http.Error(w, fmt.Sprintf("Prerequisite course %s not found", ref), http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				log.Println("Error resolving prerequisite course:", err)
+				http.Error(w, "Failed to save prerequisites", http.StatusInternalServerError)
+				return
+			}
+
+			prerequisiteID := int(row.courseID.Int64)
+			if prerequisiteID == courseID {
+				http.Error(w, "A course cannot be its own prerequisite", http.StatusBadRequest)
+				return
+			}
+			if prerequisiteReaches(requires, prerequisiteID, courseID) {
+				http.Error(w, fmt.Sprintf("%s already requires this course; linking it would create a cycle", code), http.StatusBadRequest)
+				return
+			}
+			// The same checks the prerequisite graph reports, so the editor cannot save a link it flags
+			outside, err := prerequisiteOutsideCurriculum(tx, courseID, prerequisiteID)
+			if err != nil {
+				log.Println("Error checking prerequisite curriculum:", err)
+				http.Error(w, "Failed to save prerequisites", http.StatusInternalServerError)
+				return
+			}
+			if outside {
+				http.Error(w, fmt.Sprintf("%s is not part of every curriculum this course belongs to", code), http.StatusBadRequest)
+				return
+			}
+			tooLate, err := prerequisiteOfferedTooLate(tx, courseID, prerequisiteID)
+			if err != nil {
+				log.Println("Error checking prerequisite semester:", err)
+				http.Error(w, "Failed to save prerequisites", http.StatusInternalServerError)
+				return
+			}
+			if tooLate {
+				http.Error(w, fmt.Sprintf("%s is not offered in a semester before this course", code), http.StatusBadRequest)
+				return
+			}
+			if row.text == "" {
+				row.text = code + " - " + name
+			}
+		}
+
+		if row.text == "" {
+			continue
+		}
+		toSave = append(toSave, row)
+	}
+
+	if _, err := tx.Exec("DELETE FROM course_prerequisites WHERE course_id = ?", courseID); err != nil {
+		log.Println("Error clearing prerequisites:", err)
+		http.Error(w, "Failed to save prerequisites", http.StatusInternalServerError)
+		return
+	}
+	for i, row := range toSave {
+		if _, err := tx.Exec(`
+			INSERT INTO course_prerequisites (course_id, prerequisite, prerequisite_course_id, position)
+			VALUES (?, ?, ?, ?)`, courseID, row.text, row.courseID, i); err != nil {
+			log.Println("Error saving prerequisite:", err)
+			http.Error(w, "Failed to save prerequisites", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit transaction", http.StatusInternalServerError)
+		return
+	}
+
+	prerequisites, err := fetchCoursePrerequisites(courseID)
+	if err != nil {
+		log.Println("Error fetching prerequisites:", err)
+		http.Error(w, "Failed to fetch prerequisites", http.StatusInternalServerError)
+		return
+	}
+	logCourseChange(courseID, logEntity{"prerequisites", courseID, opUpdate}, "Prerequisites Saved",
+		fmt.Sprintf("Updated prerequisites of course %d", courseID), requestUser(r),
+		map[string]interface{}{"prerequisites": before}, map[string]interface{}{"prerequisites": prerequisites})
+	json.NewEncoder(w).Encode(prerequisites)
+}
+
+// GetPrerequisiteGraph handles GET /curriculum/:id/prerequisite-graph
+func GetPrerequisiteGraph(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	graph, err := buildPrerequisiteGraph(curriculumID)
+	if err != nil {
+		log.Println("Error building prerequisite graph:", err)
+		http.Error(w, "Failed to build prerequisite graph", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(graph)
+}
@@ -0,0 +1,60 @@
+package curriculum
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPrerequisiteReaches(t *testing.T) {
+	// 4 requires 3 and 2, 3 requires 1, 2 requires 1; 5 stands alone
+	requires := map[int][]int{4: {3, 2}, 3: {1}, 2: {1}}
+
+	tests := []struct {
+		name         string
+		from, target int
+		want         bool
+	}{
+		{"direct", 4, 3, true},
+		{"transitive", 4, 1, true},
+		{"itself", 2, 2, true},
+		{"reverse direction", 1, 4, false},
+		{"siblings", 3, 2, false},
+		{"unconnected", 5, 1, false},
+	}
+	for _, tt := range tests {
+		if got := prerequisiteReaches(requires, tt.from, tt.target); got != tt.want {
+			t.Errorf("%s: prerequisiteReaches(%d, %d) = %v, want %v", tt.name, tt.from, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestFindPrerequisiteCycles(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining []int
+		requires  map[int][]int
+		want      [][]int
+	}{
+		{"two-course loop", []int{1, 2}, map[int][]int{1: {2}, 2: {1}}, [][]int{{1, 2}}},
+		{"three-course loop", []int{1, 2, 3}, map[int][]int{1: {2}, 2: {3}, 3: {1}}, [][]int{{1, 2, 3}}},
+		{"self loop", []int{7}, map[int][]int{7: {7}}, [][]int{{7}}},
+		{"course hanging off a loop", []int{1, 2, 3}, map[int][]int{3: {1}, 1: {2}, 2: {1}}, [][]int{{1, 2}}},
+		{"two separate loops", []int{1, 2, 5, 6}, map[int][]int{1: {2}, 2: {1}, 5: {6}, 6: {5}}, [][]int{{1, 2}, {5, 6}}},
+	}
+	for _, tt := range tests {
+		remaining := make(map[int]bool)
+		for _, id := range tt.remaining {
+			remaining[id] = true
+		}
+		got := findPrerequisiteCycles(remaining, tt.requires)
+		// Map iteration picks the starting course, so compare cycles as sorted course sets
+		for _, cycle := range got {
+			sort.Ints(cycle)
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i][0] < got[j][0] })
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: findPrerequisiteCycles = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultProgramAttainmentConfig is used until a curriculum configures its own weights and targets
+var defaultProgramAttainmentConfig = models.ProgramAttainmentConfig{
+	DirectWeight:   80,
+	IndirectWeight: 20,
+	DefaultTarget:  2,
+}
+
+// fetchProgramAttainmentConfig returns the attainment weights and targets of a curriculum
+func fetchProgramAttainmentConfig(curriculumID int) models.ProgramAttainmentConfig {
+	config := defaultProgramAttainmentConfig
+	config.CurriculumID = curriculumID
+	config.Targets = []models.OutcomeTarget{}
+
+	err := db.DB.QueryRow(`
+		SELECT direct_weight, indirect_weight, default_target
+		FROM program_attainment_config WHERE curriculum_id = ?`, curriculumID).
+		Scan(&config.DirectWeight, &config.IndirectWeight, &config.DefaultTarget)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println("Error fetching program attainment config:", err)
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT outcome_type, outcome_index, target
+		FROM program_outcome_targets
+		WHERE curriculum_id = ?
+		ORDER BY outcome_type, outcome_index`, curriculumID)
+	if err != nil {
+		log.Println("Error fetching program outcome targets:", err)
+		return config
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.OutcomeTarget
+		if err := rows.Scan(&t.OutcomeType, &t.Index, &t.Target); err == nil {
+			config.Targets = append(config.Targets, t)
+		}
+	}
+
+	return config
+}
+
+// fetchIndirectAttainment returns indirect attainment values of a curriculum keyed by outcome type and index
+func fetchIndirectAttainment(curriculumID int, academicYear string) (map[string]map[int]float64, error) {
+	rows, err := db.DB.Query(`
+		SELECT outcome_type, outcome_index, value
+		FROM indirect_attainment
+		WHERE curriculum_id = ? AND academic_year = ?`, curriculumID, academicYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := map[string]map[int]float64{"PO": {}, "PSO": {}}
+	for rows.Next() {
+		var outcomeType string
+		var index int
+		var value float64
+		if err := rows.Scan(&outcomeType, &index, &value); err == nil {
+			values[outcomeType][index] = value
+		}
+	}
+	return values, nil
+}
+
+// buildProgramOutcomes combines direct and indirect attainment for each PO or PSO statement.
+// When only one component is available its value is used as-is rather than being scaled down
+func buildProgramOutcomes(outcomeType string, statements []models.DepartmentListItem, direct []models.OutcomeAttainment,
+	indirect map[int]float64, mapped map[int]int, config models.ProgramAttainmentConfig) []models.ProgramOutcomeAttainment {
+
+	directByIndex := make(map[int]float64)
+	for _, oa := range direct {
+		directByIndex[oa.Index] = oa.Attainment
+	}
+	targets := make(map[int]float64)
+	for _, t := range config.Targets {
+		if t.OutcomeType == outcomeType {
+			targets[t.Index] = t.Target
+		}
+	}
+
+	result := []models.ProgramOutcomeAttainment{}
+	for i, statement := range statements {
+		index := i + 1
+		outcome := models.ProgramOutcomeAttainment{
+			OutcomeType:   outcomeType,
+			Index:         index,
+			Statement:     statement.Text,
+			Target:        config.DefaultTarget,
+			CoursesMapped: mapped[index],
+		}
+		if target, ok := targets[index]; ok {
+			outcome.Target = target
+		}
+
+		var weighted, weights float64
+		if value, ok := directByIndex[index]; ok {
+			v := value
+			outcome.Direct = &v
+			weighted += v * config.DirectWeight
+			weights += config.DirectWeight
+		}
+		if value, ok := indirect[index]; ok {
+			v := value
+			outcome.Indirect = &v
+			weighted += v * config.IndirectWeight
+			weights += config.IndirectWeight
+		}
+		if weights > 0 {
+			final := roundTo2(weighted / weights)
+			outcome.Final = &final
+			outcome.TargetMet = final >= outcome.Target
+		}
+
+		result = append(result, outcome)
+	}
+	return result
+}
+
+// computeProgramAttainment computes program-level PO/PSO attainment of a curriculum for an academic year
+func computeProgramAttainment(curriculumID int, academicYear string) (*models.ProgramAttainment, error) {
+	direct, err := computeCurriculumAttainment(curriculumID, academicYear)
+	if err != nil {
+		return nil, err
+	}
+	indirect, err := fetchIndirectAttainment(curriculumID, academicYear)
+	if err != nil {
+		return nil, err
+	}
+	config := fetchProgramAttainmentConfig(curriculumID)
+
+	poMapped := make(map[int]int)
+	psoMapped := make(map[int]int)
+	for _, course := range direct.Courses {
+		for _, oa := range course.POAttainment {
+			poMapped[oa.Index]++
+		}
+		for _, oa := range course.PSOAttainment {
+			psoMapped[oa.Index]++
+		}
+	}
+
+	return &models.ProgramAttainment{
+		CurriculumID:   curriculumID,
+		AcademicYear:   academicYear,
+		DirectWeight:   config.DirectWeight,
+		IndirectWeight: config.IndirectWeight,
+		POs: buildProgramOutcomes("PO", fetchDepartmentList(curriculumID, "curriculum_pos", "po_text"),
+			direct.POAttainment, indirect["PO"], poMapped, config),
+		PSOs: buildProgramOutcomes("PSO", fetchDepartmentList(curriculumID, "curriculum_psos", "pso_text"),
+			direct.PSOAttainment, indirect["PSO"], psoMapped, config),
+	}, nil
+}
+
+// GetProgramAttainmentConfig handles GET /curriculum/:id/attainment-config
+func GetProgramAttainmentConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(fetchProgramAttainmentConfig(curriculumID))
+}
+
+// SaveProgramAttainmentConfig handles PUT /curriculum/:id/attainment-config
+func SaveProgramAttainmentConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	var config models.ProgramAttainmentConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if config.DirectWeight < 0 || config.IndirectWeight < 0 {
+		http.Error(w, "Weights cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if math.Abs(config.DirectWeight+config.IndirectWeight-100) > weightageTolerance {
+		http.Error(w, "direct_weight and indirect_weight must add up to 100", http.StatusBadRequest)
+		return
+	}
+	if config.DefaultTarget < 0 || config.DefaultTarget > 3 {
+		http.Error(w, "default_target must be between 0 and 3", http.StatusBadRequest)
+		return
+	}
+	for _, t := range config.Targets {
+		if t.OutcomeType != "PO" && t.OutcomeType != "PSO" {
+			http.Error(w, "outcome_type must be PO or PSO", http.StatusBadRequest)
+			return
+		}
+		if t.Index < 1 || t.Target < 0 || t.Target > 3 {
+			http.Error(w, "Targets must have a positive index and a value between 0 and 3", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to save attainment config", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO program_attainment_config (curriculum_id, direct_weight, indirect_weight, default_target)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE direct_weight = VALUES(direct_weight),
+			indirect_weight = VALUES(indirect_weight), default_target = VALUES(default_target)
+	`, curriculumID, config.DirectWeight, config.IndirectWeight, config.DefaultTarget)
+	if err != nil {
+		log.Println("Error saving attainment config:", err)
+		http.Error(w, "Failed to save attainment config", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec("DELETE FROM program_outcome_targets WHERE curriculum_id = ?", curriculumID); err != nil {
+		log.Println("Error clearing outcome targets:", err)
+		http.Error(w, "Failed to save attainment config", http.StatusInternalServerError)
+		return
+	}
+	for _, t := range config.Targets {
+		_, err := tx.Exec(`
+			INSERT INTO program_outcome_targets (curriculum_id, outcome_type, outcome_index, target)
+			VALUES (?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE target = VALUES(target)
+		`, curriculumID, t.OutcomeType, t.Index, t.Target)
+		if err != nil {
+			log.Println("Error saving outcome target:", err)
+			http.Error(w, "Failed to save attainment config", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing attainment config:", err)
+		http.Error(w, "Failed to save attainment config", http.StatusInternalServerError)
+		return
+	}
+
+	LogCurriculumActivity(curriculumID, "Attainment Config Updated",
+		fmt.Sprintf("Updated program attainment weights (direct %.2f%%, indirect %.2f%%) and %d outcome targets",
+			config.DirectWeight, config.IndirectWeight, len(config.Targets)), requestUser(r))
+
+	json.NewEncoder(w).Encode(fetchProgramAttainmentConfig(curriculumID))
+}
+
+// GetIndirectAttainment handles GET /curriculum/:id/indirect-attainment?academic_year=
+func GetIndirectAttainment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	academicYear := r.URL.Query().Get("academic_year")
+	if academicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT outcome_type, outcome_index, value, source
+		FROM indirect_attainment
+		WHERE curriculum_id = ? AND academic_year = ?
+		ORDER BY outcome_type, outcome_index`, curriculumID, academicYear)
+	if err != nil {
+		log.Println("Error fetching indirect attainment:", err)
+		http.Error(w, "Failed to fetch indirect attainment", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	values := []models.IndirectAttainmentValue{}
+	for rows.Next() {
+		var v models.IndirectAttainmentValue
+		if err := rows.Scan(&v.OutcomeType, &v.Index, &v.Value, &v.Source); err == nil {
+			values = append(values, v)
+		}
+	}
+
+	json.NewEncoder(w).Encode(models.IndirectAttainmentRequest{
+		AcademicYear: academicYear,
+		Values:       values,
+	})
+}
+
+// SaveIndirectAttainment handles PUT /curriculum/:id/indirect-attainment
+func SaveIndirectAttainment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.IndirectAttainmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.AcademicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+	for _, v := range req.Values {
+		if v.OutcomeType != "PO" && v.OutcomeType != "PSO" {
+			http.Error(w, "outcome_type must be PO or PSO", http.StatusBadRequest)
+			return
+		}
+		if v.Index < 1 || v.Value < 0 || v.Value > 3 {
+			http.Error(w, "Values must have a positive index and lie between 0 and 3", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to save indirect attainment", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, v := range req.Values {
+		source := v.Source
+		if source == "" {
+			source = "MANUAL"
+		}
+		_, err := tx.Exec(`
+			INSERT INTO indirect_attainment (curriculum_id, academic_year, outcome_type, outcome_index, value, source)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE value = VALUES(value), source = VALUES(source)
+		`, curriculumID, req.AcademicYear, v.OutcomeType, v.Index, v.Value, source)
+		if err != nil {
+			log.Println("Error saving indirect attainment:", err)
+			http.Error(w, "Failed to save indirect attainment", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing indirect attainment:", err)
+		http.Error(w, "Failed to save indirect attainment", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Indirect attainment saved successfully",
+		"count":   len(req.Values),
+	})
+}
+
+// GetProgramAttainment handles GET /curriculum/:id/program-attainment?academic_year=
+func GetProgramAttainment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	academicYear := r.URL.Query().Get("academic_year")
+	if academicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := computeProgramAttainment(curriculumID, academicYear)
+	if err != nil {
+		log.Println("Error computing program attainment:", err)
+		http.Error(w, "Failed to compute program attainment", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,625 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultRecycleBinRetentionDays is how long deleted items stay restorable unless RECYCLE_BIN_RETENTION_DAYS is set
+const defaultRecycleBinRetentionDays = 30
+
+// errRecycleParentDeleted is returned when restoring an item whose parent is itself still deleted
+var errRecycleParentDeleted = errors.New("the item's parent is deleted; restore the parent first")
+
+// errRecycleCourseInUse is returned when purging would remove a course another curriculum still uses
+var errRecycleCourseInUse = errors.New("a deleted course is still used by another curriculum")
+
+// recycleScope selects the rows of one table that a delete soft-deletes; where takes the item id once
+type recycleScope struct {
+	table, idColumn, where string
+}
+
+// captureQuery selects the ids of the scope's rows that are still active
+func (s recycleScope) captureQuery() string {
+	return fmt.Sprintf("SELECT %s FROM %s WHERE %s AND (status = 1 OR status IS NULL)", s.idColumn, s.table, s.where)
+}
+
+// recycleSiblings re-opens a gap in the positions of the item's siblings when it is restored
+type recycleSiblings struct {
+	table, parentColumn string
+}
+
+// recycleItemType describes a deletable item: where its context comes from and which rows its delete cascades to.
+// info selects (curriculum_id, course_id, label); parent, when set, selects the status of the item's parent
+type recycleItemType struct {
+	info     string
+	parent   string
+	siblings *recycleSiblings
+	scopes   []recycleScope
+}
+
+// courseRecycleScopes lists a course and its syllabus content; courses is a query selecting the course ids
+func courseRecycleScopes(courses string) []recycleScope {
+	in := "course_id IN (" + courses + ")"
+	return []recycleScope{
+		{"courses", "course_id", in},
+		{"course_objectives", "id", in},
+		{"course_outcomes", "id", in},
+		{"course_references", "id", in},
+		{"syllabus", "id", in},
+		{"syllabus_titles", "id", "model_id IN (SELECT id FROM syllabus WHERE " + in + ")"},
+		{"syllabus_topics", "id", "title_id IN (SELECT st.id FROM syllabus_titles st INNER JOIN syllabus s ON s.id = st.model_id WHERE s." + in + ")"},
+		{"course_experiments", "id", in},
+		{"course_experiment_topics", "id", "experiment_id IN (SELECT id FROM course_experiments WHERE " + in + ")"},
+	}
+}
+
+// recycleItemTypes are the deletes the recycle bin can undo, keyed by item type
+var recycleItemTypes = map[string]recycleItemType{
+	"semester": {
+		info: `SELECT curriculum_id, NULL, CONCAT(COALESCE(card_type, 'semester'), ' ', COALESCE(semester_number, id))
+			FROM normal_cards WHERE id = ?`,
+		scopes: append([]recycleScope{{"normal_cards", "id", "id = ?"}},
+			courseRecycleScopes(`SELECT cc.course_id FROM curriculum_courses cc WHERE cc.semester_id = ? AND cc.status = 1
+				AND NOT `+courseUsedElsewhere("cc.course_id", "ucc.semester_id <> cc.semester_id", "TRUE"))...),
+	},
+	// course entries were recorded when removing a course from a semester still soft-deleted the course itself
+	"course": {
+		info:   "SELECT NULL, course_id, CONCAT(course_code, ' - ', course_name) FROM courses WHERE course_id = ?",
+		scopes: courseRecycleScopes("?"),
+	},
+	"curriculum_course": {
+		info: `SELECT cc.curriculum_id, cc.course_id, CONCAT(c.course_code, ' - ', c.course_name) FROM curriculum_courses cc
+			INNER JOIN courses c ON c.course_id = cc.course_id WHERE cc.id = ?`,
+		parent: "SELECT status FROM normal_cards WHERE id = (SELECT semester_id FROM curriculum_courses WHERE id = ?)",
+		scopes: append([]recycleScope{{"curriculum_courses", "id", "id = ?"}},
+			courseRecycleScopes(`SELECT cc.course_id FROM curriculum_courses cc WHERE cc.id = ?
+				AND NOT `+courseUsedElsewhere("cc.course_id", "ucc.id <> cc.id", "TRUE"))...),
+	},
+	"honour_card": {
+		info: "SELECT curriculum_id, NULL, title FROM honour_cards WHERE id = ?",
+		scopes: append([]recycleScope{
+			{"honour_cards", "id", "id = ?"},
+			{"honour_verticals", "id", "honour_card_id = ?"},
+			{"honour_vertical_courses", "id", "honour_vertical_id IN (SELECT id FROM honour_verticals WHERE honour_card_id = ?)"},
+		}, courseRecycleScopes(`SELECT hvc.course_id FROM honour_vertical_courses hvc
+			INNER JOIN honour_verticals hv ON hv.id = hvc.honour_vertical_id
+			WHERE hv.honour_card_id = ? AND hvc.status = 1
+			AND NOT `+courseUsedElsewhere("hvc.course_id", "TRUE", "uhv.honour_card_id <> hv.honour_card_id"))...),
+	},
+	"honour_vertical": {
+		info: `SELECT hc.curriculum_id, NULL, hv.name FROM honour_verticals hv
+			INNER JOIN honour_cards hc ON hc.id = hv.honour_card_id WHERE hv.id = ?`,
+		parent: "SELECT status FROM honour_cards WHERE id = (SELECT honour_card_id FROM honour_verticals WHERE id = ?)",
+		scopes: append([]recycleScope{
+			{"honour_verticals", "id", "id = ?"},
+			{"honour_vertical_courses", "id", "honour_vertical_id = ?"},
+		}, courseRecycleScopes(`SELECT hvc.course_id FROM honour_vertical_courses hvc WHERE hvc.honour_vertical_id = ? AND hvc.status = 1
+			AND NOT `+courseUsedElsewhere("hvc.course_id", "TRUE", "uhvc.honour_vertical_id <> hvc.honour_vertical_id"))...),
+	},
+	"honour_course": {
+		info: `SELECT hc.curriculum_id, c.course_id, CONCAT(c.course_code, ' - ', c.course_name) FROM honour_vertical_courses hvc
+			INNER JOIN courses c ON c.course_id = hvc.course_id
+			INNER JOIN honour_verticals hv ON hv.id = hvc.honour_vertical_id
+			INNER JOIN honour_cards hc ON hc.id = hv.honour_card_id
+			WHERE hvc.id = ?`,
+		parent: "SELECT status FROM honour_verticals WHERE id = (SELECT honour_vertical_id FROM honour_vertical_courses WHERE id = ?)",
+		scopes: append([]recycleScope{{"honour_vertical_courses", "id", "id = ?"}},
+			courseRecycleScopes(`SELECT hvc.course_id FROM honour_vertical_courses hvc WHERE hvc.id = ?
+				AND NOT `+courseUsedElsewhere("hvc.course_id", "TRUE", "uhvc.id <> hvc.id"))...),
+	},
+	"syllabus_model": {
+		info:     "SELECT NULL, course_id, model_name FROM syllabus WHERE id = ?",
+		parent:   "SELECT status FROM courses WHERE course_id = (SELECT course_id FROM syllabus WHERE id = ?)",
+		siblings: &recycleSiblings{"syllabus", "course_id"},
+		scopes: []recycleScope{
+			{"syllabus", "id", "id = ?"},
+			{"syllabus_titles", "id", "model_id = ?"},
+			{"syllabus_topics", "id", "title_id IN (SELECT id FROM syllabus_titles WHERE model_id = ?)"},
+		},
+	},
+	"syllabus_title": {
+		info: `SELECT NULL, s.course_id, st.title FROM syllabus_titles st
+			INNER JOIN syllabus s ON s.id = st.model_id WHERE st.id = ?`,
+		parent:   "SELECT status FROM syllabus WHERE id = (SELECT model_id FROM syllabus_titles WHERE id = ?)",
+		siblings: &recycleSiblings{"syllabus_titles", "model_id"},
+		scopes: []recycleScope{
+			{"syllabus_titles", "id", "id = ?"},
+			{"syllabus_topics", "id", "title_id = ?"},
+		},
+	},
+	"syllabus_topic": {
+		info: `SELECT NULL, s.course_id, LEFT(tp.topic, 255) FROM syllabus_topics tp
+			INNER JOIN syllabus_titles st ON st.id = tp.title_id
+			INNER JOIN syllabus s ON s.id = st.model_id WHERE tp.id = ?`,
+		parent:   "SELECT status FROM syllabus_titles WHERE id = (SELECT title_id FROM syllabus_topics WHERE id = ?)",
+		siblings: &recycleSiblings{"syllabus_topics", "title_id"},
+		scopes:   []recycleScope{{"syllabus_topics", "id", "id = ?"}},
+	},
+	"experiment": {
+		info:   "SELECT NULL, course_id, CONCAT('Experiment ', experiment_number, ': ', experiment_name) FROM course_experiments WHERE id = ?",
+		parent: "SELECT status FROM courses WHERE course_id = (SELECT course_id FROM course_experiments WHERE id = ?)",
+		scopes: []recycleScope{
+			{"course_experiments", "id", "id = ?"},
+			{"course_experiment_topics", "id", "experiment_id = ?"},
+		},
+	},
+}
+
+// recycleBinRetentionDays returns how many days deleted items stay restorable before they are purged
+func recycleBinRetentionDays() int {
+	if days, err := strconv.Atoi(os.Getenv("RECYCLE_BIN_RETENTION_DAYS")); err == nil && days > 0 {
+		return days
+	}
+	return defaultRecycleBinRetentionDays
+}
+
+// requestUser returns who made a request, from the X-User-Email header
+func requestUser(r *http.Request) string {
+	if user := r.Header.Get("X-User-Email"); user != "" {
+		return user
+	}
+	return "System"
+}
+
+// idPlaceholders returns "?, ?, ..." and the matching arguments for an IN clause
+func idPlaceholders(ids []int) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
+
+// recordDeletion adds a recycle bin entry for an item that is about to be soft-deleted.
+// It must run in the delete's transaction before the delete, so that only the rows that were active are captured.
+// curriculumID is used when the item type cannot tell which curriculum the delete happened in; 0 leaves it to the item
+func recordDeletion(tx *sql.Tx, itemType string, itemID, curriculumID int, deletedBy string) error {
+	kind, ok := recycleItemTypes[itemType]
+	if !ok {
+		return fmt.Errorf("unknown recycle bin item type %q", itemType)
+	}
+
+	var entryCurriculum, entryCourse sql.NullInt64
+	var label sql.NullString
+	err := tx.QueryRow(kind.info, itemID).Scan(&entryCurriculum, &entryCourse, &label)
+	if err == sql.ErrNoRows {
+		// Nothing to delete; the caller reports the missing item
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to describe deleted %s: %w", itemType, err)
+	}
+	if curriculumID > 0 {
+		entryCurriculum = sql.NullInt64{Int64: int64(curriculumID), Valid: true}
+	}
+
+	deleted := make(map[string][]int)
+	for _, scope := range kind.scopes {
+		rows, err := tx.Query(scope.captureQuery(), itemID)
+		if err != nil {
+			return fmt.Errorf("failed to capture deleted %s: %w", scope.table, err)
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err == nil {
+				deleted[scope.table] = append(deleted[scope.table], id)
+			}
+		}
+		rows.Close()
+	}
+	data, err := json.Marshal(deleted)
+	if err != nil {
+		return err
+	}
+
+	if deletedBy == "" {
+		deletedBy = "System"
+	}
+	_, err = tx.Exec(`
+		INSERT INTO recycle_bin (curriculum_id, course_id, item_type, item_id, label, deleted_rows, deleted_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entryCurriculum, entryCourse, itemType, itemID, label.String, string(data), deletedBy)
+	return err
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// recycleBinEntry is a stored entry with the rows its delete soft-deleted
+type recycleBinEntry struct {
+	models.RecycleBinEntry
+	rows map[string][]int
+}
+
+// loadRecycleBinEntry retrieves an entry that has not been restored yet
+func loadRecycleBinEntry(q rowQuerier, entryID int) (*recycleBinEntry, error) {
+	entry := &recycleBinEntry{}
+	var curriculumID, courseID sql.NullInt64
+	var rowsJSON string
+	err := q.QueryRow(`
+		SELECT id, curriculum_id, course_id, item_type, item_id, label, deleted_rows, COALESCE(deleted_by, ''), deleted_at,
+		       DATE_ADD(deleted_at, INTERVAL ? DAY)
+		FROM recycle_bin WHERE id = ? AND restored_at IS NULL`, recycleBinRetentionDays(), entryID).Scan(
+		&entry.ID, &curriculumID, &courseID, &entry.ItemType, &entry.ItemID, &entry.Label, &rowsJSON,
+		&entry.DeletedBy, &entry.DeletedAt, &entry.PurgeAfter)
+	if err != nil {
+		return nil, err
+	}
+	if curriculumID.Valid {
+		id := int(curriculumID.Int64)
+		entry.CurriculumID = &id
+	}
+	if courseID.Valid {
+		id := int(courseID.Int64)
+		entry.CourseID = &id
+	}
+	if err := json.Unmarshal([]byte(rowsJSON), &entry.rows); err != nil {
+		return nil, fmt.Errorf("failed to decode recycle bin entry %d: %w", entryID, err)
+	}
+	entry.RowCounts = make(map[string]int)
+	for table, ids := range entry.rows {
+		entry.RowCounts[table] = len(ids)
+	}
+	return entry, nil
+}
+
+// recycleBinEntryBelongs reports whether an entry was deleted from a curriculum, directly or through one of its courses
+func recycleBinEntryBelongs(entry *recycleBinEntry, curriculumID int) (bool, error) {
+	if entry.CurriculumID != nil {
+		return *entry.CurriculumID == curriculumID, nil
+	}
+	if entry.CourseID == nil {
+		return false, nil
+	}
+	return curriculumUsesCourse(curriculumID, *entry.CourseID)
+}
+
+// recycleBinLogger writes the activity log entry of a restore or purge inside its transaction
+type recycleBinLogger func(tx *sql.Tx, entry *recycleBinEntry) error
+
+// restoreRecycleBinEntry reactivates exactly the rows a delete soft-deleted, parents before children
+func restoreRecycleBinEntry(entryID int, restoredBy string, logRestore recycleBinLogger) (*recycleBinEntry, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entry, err := loadRecycleBinEntry(tx, entryID)
+	if err != nil {
+		return nil, err
+	}
+	kind, ok := recycleItemTypes[entry.ItemType]
+	if !ok {
+		return nil, fmt.Errorf("unknown recycle bin item type %q", entry.ItemType)
+	}
+
+	if kind.parent != "" {
+		var status sql.NullInt64
+		err := tx.QueryRow(kind.parent, entry.ItemID).Scan(&status)
+		if err == sql.ErrNoRows || (err == nil && status.Valid && status.Int64 == 0) {
+			return nil, errRecycleParentDeleted
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Syllabus deletes close the gap in their siblings' positions, so open it again
+	if kind.siblings != nil {
+		var parentID, position int
+		if err := tx.QueryRow(fmt.Sprintf("SELECT %s, position FROM %s WHERE id = ?", kind.siblings.parentColumn, kind.siblings.table),
+			entry.ItemID).Scan(&parentID, &position); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(fmt.Sprintf(
+			"UPDATE %s SET position = position + 1 WHERE %s = ? AND position >= ? AND (status = 1 OR status IS NULL)",
+			kind.siblings.table, kind.siblings.parentColumn), parentID, position); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, scope := range kind.scopes {
+		ids := entry.rows[scope.table]
+		if len(ids) == 0 {
+			continue
+		}
+		placeholders, args := idPlaceholders(ids)
+		if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET status = 1 WHERE %s IN (%s) AND status = 0",
+			scope.table, scope.idColumn, placeholders), args...); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", scope.table, err)
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE recycle_bin SET restored_at = CURRENT_TIMESTAMP, restored_by = ? WHERE id = ?",
+		restoredBy, entryID); err != nil {
+		return nil, err
+	}
+	if err := logRestore(tx, entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// purgeRecycleBinEntry permanently deletes the rows of an entry that are still soft-deleted, children before parents.
+// Courses still used by another curriculum's active semester or honour vertical are never purged
+func purgeRecycleBinEntry(entryID int, logPurge recycleBinLogger) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	entry, err := loadRecycleBinEntry(tx, entryID)
+	if err != nil {
+		return err
+	}
+	kind, ok := recycleItemTypes[entry.ItemType]
+	if !ok {
+		return fmt.Errorf("unknown recycle bin item type %q", entry.ItemType)
+	}
+
+	if courseIDs := entry.rows["courses"]; len(courseIDs) > 0 {
+		curriculumID := 0
+		if entry.CurriculumID != nil {
+			curriculumID = *entry.CurriculumID
+		}
+		placeholders, args := idPlaceholders(courseIDs)
+		var inUse bool
+		if err := tx.QueryRow(`
+			SELECT EXISTS(
+				SELECT 1 FROM curriculum_courses cc
+				INNER JOIN normal_cards nc ON nc.id = cc.semester_id AND (nc.status = 1 OR nc.status IS NULL)
+				WHERE cc.course_id IN (`+placeholders+`) AND cc.status = 1 AND cc.curriculum_id <> ?
+			) OR EXISTS(
+				SELECT 1 FROM honour_vertical_courses hvc
+				INNER JOIN honour_verticals hv ON hv.id = hvc.honour_vertical_id AND hv.status = 1
+				INNER JOIN honour_cards hc ON hc.id = hv.honour_card_id AND hc.status = 1
+				WHERE hvc.course_id IN (`+placeholders+`) AND hvc.status = 1 AND hc.curriculum_id <> ?
+			)`, append(append(append(args, curriculumID), args...), curriculumID)...).Scan(&inUse); err != nil {
+			return err
+		}
+		if inUse {
+			return errRecycleCourseInUse
+		}
+	}
+
+	for i := len(kind.scopes) - 1; i >= 0; i-- {
+		scope := kind.scopes[i]
+		ids := entry.rows[scope.table]
+		if len(ids) == 0 {
+			continue
+		}
+		placeholders, args := idPlaceholders(ids)
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s) AND status = 0",
+			scope.table, scope.idColumn, placeholders), args...); err != nil {
+			return fmt.Errorf("failed to purge %s: %w", scope.table, err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM recycle_bin WHERE id = ?", entryID); err != nil {
+		return err
+	}
+	if err := logPurge(tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// purgeExpiredRecycleBin purges entries older than the retention period and forgets restored ones
+func purgeExpiredRecycleBin() {
+	days := recycleBinRetentionDays()
+	if _, err := db.DB.Exec("DELETE FROM recycle_bin WHERE restored_at IS NOT NULL AND restored_at < DATE_SUB(NOW(), INTERVAL ? DAY)", days); err != nil {
+		log.Println("Error clearing restored recycle bin entries:", err)
+	}
+
+	rows, err := db.DB.Query("SELECT id FROM recycle_bin WHERE restored_at IS NULL AND deleted_at < DATE_SUB(NOW(), INTERVAL ? DAY)", days)
+	if err != nil {
+		log.Println("Error fetching expired recycle bin entries:", err)
+		return
+	}
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		err := purgeRecycleBinEntry(id, func(tx *sql.Tx, entry *recycleBinEntry) error {
+			if entry.CurriculumID == nil {
+				return nil
+			}
+			return logCurriculumDiffTx(tx, *entry.CurriculumID, logEntity{entry.ItemType, entry.ItemID, opDelete}, "Recycle Bin Purged",
+				fmt.Sprintf("Permanently deleted %s %q after %d days", strings.ReplaceAll(entry.ItemType, "_", " "), entry.Label, days), "System", nil)
+		})
+		if err != nil {
+			log.Printf("Error purging recycle bin entry %d: %v", id, err)
+		}
+	}
+}
+
+// StartRecycleBinPurger purges expired recycle bin entries now and then at every interval
+func StartRecycleBinPurger(interval time.Duration) {
+	purgeExpiredRecycleBin()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		purgeExpiredRecycleBin()
+	}
+}
+
+// parseRecycleBinVars reads the curriculum and entry ids of a recycle bin route
+func parseRecycleBinVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	entryID, err := strconv.Atoi(vars["entryId"])
+	if err != nil {
+		http.Error(w, "Invalid recycle bin entry ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return curriculumID, entryID, true
+}
+
+// checkRecycleBinEntry loads an entry and makes sure it was deleted from the curriculum; it writes the error response itself
+func checkRecycleBinEntry(w http.ResponseWriter, curriculumID, entryID int) bool {
+	entry, err := loadRecycleBinEntry(db.DB, entryID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Recycle bin entry not found", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		log.Println("Error fetching recycle bin entry:", err)
+		http.Error(w, "Failed to fetch recycle bin entry", http.StatusInternalServerError)
+		return false
+	}
+	belongs, err := recycleBinEntryBelongs(entry, curriculumID)
+	if err != nil {
+		log.Println("Error checking recycle bin entry:", err)
+		http.Error(w, "Failed to fetch recycle bin entry", http.StatusInternalServerError)
+		return false
+	}
+	if !belongs {
+		http.Error(w, "Recycle bin entry not found", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// GetRecycleBin handles GET /curriculum/:id/recycle-bin
+// Lists deleted semesters, courses, honour cards and syllabus items of the curriculum, newest first
+func GetRecycleBin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id FROM recycle_bin
+		WHERE restored_at IS NULL
+		  AND (curriculum_id = ? OR (curriculum_id IS NULL AND course_id IN (`+curriculumCourseIDsQuery+`)))
+		ORDER BY deleted_at DESC, id DESC`, curriculumID, curriculumID, curriculumID)
+	if err != nil {
+		log.Println("Error fetching recycle bin:", err)
+		http.Error(w, "Failed to fetch recycle bin", http.StatusInternalServerError)
+		return
+	}
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	response := models.RecycleBinResponse{
+		CurriculumID:  curriculumID,
+		RetentionDays: recycleBinRetentionDays(),
+		Items:         []models.RecycleBinEntry{},
+	}
+	for _, id := range ids {
+		entry, err := loadRecycleBinEntry(db.DB, id)
+		if err != nil {
+			log.Println("Error fetching recycle bin entry:", err)
+			continue
+		}
+		response.Items = append(response.Items, entry.RecycleBinEntry)
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// RestoreRecycleBinEntry handles POST /curriculum/:id/recycle-bin/:entryId/restore
+func RestoreRecycleBinEntry(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	curriculumID, entryID, ok := parseRecycleBinVars(w, r)
+	if !ok || !checkRecycleBinEntry(w, curriculumID, entryID) {
+		return
+	}
+
+	user := requestUser(r)
+	entry, err := restoreRecycleBinEntry(entryID, user, func(tx *sql.Tx, entry *recycleBinEntry) error {
+		return logCurriculumDiffTx(tx, curriculumID, logEntity{entry.ItemType, entry.ItemID, opRestore}, "Item Restored",
+			fmt.Sprintf("Restored %s %q from the recycle bin", strings.ReplaceAll(entry.ItemType, "_", " "), entry.Label), user,
+			map[string]map[string]interface{}{"status": {"old": 0, "new": 1}})
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Recycle bin entry not found", http.StatusNotFound)
+		return
+	}
+	if err == errRecycleParentDeleted {
+		http.Error(w, "The item's parent is deleted; restore the parent first", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error restoring recycle bin entry:", err)
+		http.Error(w, "Failed to restore item", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Item restored successfully",
+		"item":    entry.RecycleBinEntry,
+	})
+}
+
+// PurgeRecycleBinEntry handles DELETE /curriculum/:id/recycle-bin/:entryId
+// Permanently deletes an item before its retention period ends
+func PurgeRecycleBinEntry(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	curriculumID, entryID, ok := parseRecycleBinVars(w, r)
+	if !ok || !checkRecycleBinEntry(w, curriculumID, entryID) {
+		return
+	}
+
+	err := purgeRecycleBinEntry(entryID, func(tx *sql.Tx, entry *recycleBinEntry) error {
+		return logCurriculumDiffTx(tx, curriculumID, logEntity{entry.ItemType, entry.ItemID, opDelete}, "Item Purged",
+			fmt.Sprintf("Permanently deleted %s %q", strings.ReplaceAll(entry.ItemType, "_", " "), entry.Label), requestUser(r), nil)
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, "Recycle bin entry not found", http.StatusNotFound)
+		return
+	}
+	if err == errRecycleCourseInUse {
+		http.Error(w, "A deleted course is still used by another curriculum and cannot be purged", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error purging recycle bin entry:", err)
+		http.Error(w, "Failed to purge item", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Item permanently deleted"})
+}
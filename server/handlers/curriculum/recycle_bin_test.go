@@ -0,0 +1,69 @@
+package curriculum
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecycleScopeCaptureQuery(t *testing.T) {
+	tests := []struct {
+		scope recycleScope
+		want  string
+	}{
+		{
+			recycleScope{"syllabus_topics", "id", "title_id = ?"},
+			"SELECT id FROM syllabus_topics WHERE title_id = ? AND (status = 1 OR status IS NULL)",
+		},
+		{
+			courseRecycleScopes("?")[0],
+			"SELECT course_id FROM courses WHERE course_id IN (?) AND (status = 1 OR status IS NULL)",
+		},
+		{
+			courseRecycleScopes("SELECT course_id FROM curriculum_courses WHERE semester_id = ?")[5],
+			"SELECT id FROM syllabus_titles WHERE model_id IN (SELECT id FROM syllabus WHERE course_id IN (SELECT course_id FROM curriculum_courses WHERE semester_id = ?)) AND (status = 1 OR status IS NULL)",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.scope.captureQuery(); got != tt.want {
+			t.Errorf("%s: captureQuery = %q, want %q", tt.scope.table, got, tt.want)
+		}
+	}
+}
+
+func TestRecycleItemTypeScopes(t *testing.T) {
+	// The item itself is captured first, so restores run parents before children and purges the reverse
+	itemTables := map[string]string{
+		"semester":          "normal_cards",
+		"course":            "courses",
+		"curriculum_course": "curriculum_courses",
+		"honour_card":       "honour_cards",
+		"honour_vertical":   "honour_verticals",
+		"honour_course":     "honour_vertical_courses",
+		"syllabus_model":    "syllabus",
+		"syllabus_title":    "syllabus_titles",
+		"syllabus_topic":    "syllabus_topics",
+		"experiment":        "course_experiments",
+	}
+	for itemType, kind := range recycleItemTypes {
+		if len(kind.scopes) == 0 || kind.scopes[0].table != itemTables[itemType] {
+			t.Errorf("%s: first scope is not %s", itemType, itemTables[itemType])
+		}
+
+		// Captured ids are kept per table, and every query gets the item id once
+		seen := map[string]bool{}
+		for _, scope := range kind.scopes {
+			if seen[scope.table] {
+				t.Errorf("%s: %s is captured twice", itemType, scope.table)
+			}
+			seen[scope.table] = true
+			if n := strings.Count(scope.captureQuery(), "?"); n != 1 {
+				t.Errorf("%s: %s capture takes %d ids", itemType, scope.table, n)
+			}
+		}
+		for name, query := range map[string]string{"info": kind.info, "parent": kind.parent} {
+			if query != "" && strings.Count(query, "?") != 1 {
+				t.Errorf("%s: %s query takes %d ids", itemType, name, strings.Count(query, "?"))
+			}
+		}
+	}
+}
@@ -85,8 +85,8 @@ func CreateRegulation(w http.ResponseWriter, r *http.Request) {
 	reg.ID = int(id)
 
 	// Log the activity
-	LogCurriculumActivity(int(id), "Curriculum Created",
-		"Created new curriculum: "+reg.Name+" ("+reg.AcademicYear+")", "System")
+	logCurriculumChange(reg.ID, logEntity{"curriculum", reg.ID, opCreate}, "Curriculum Created",
+		"Created new curriculum: "+reg.Name+" ("+reg.AcademicYear+")", requestUser(r), nil, reg)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(reg)
@@ -247,22 +247,14 @@ func DeleteRegulation(w http.ResponseWriter, r *http.Request) {
 	}
 	rows.Close()
 
-	// Soft-delete all courses and their children
+	// Soft-delete the courses no other curriculum uses, and their children
 	for courseID := range courseIDsMap {
-		_, err = tx.Exec("UPDATE courses SET status = 0 WHERE course_id = ? AND status = 1", courseID)
-		if err != nil {
+		if err := softDeleteCourseIfUnused(tx, courseID); err != nil {
 			log.Printf("Error soft-deleting course %d: %v", courseID, err)
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to courses"})
 			return
 		}
-		// Cascade soft-delete to all course children
-		if err := cascadeSoftDeleteCourse(courseID, tx); err != nil {
-			log.Printf("Error cascading course %d soft-delete: %v", courseID, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to cascade delete to course children"})
-			return
-		}
 	}
 
 	// Commit the transaction
@@ -0,0 +1,328 @@
+package curriculum
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// documentWriter is the layout a regulation book or syllabus is written through; it is
+// implemented by the DOCX writer and the native PDF writer
+type documentWriter interface {
+	Title(text string)
+	Heading(level int, text string)
+	Paragraph(text string)
+	Centered(text string, bold bool)
+	Bold(text string)
+	List(items []string, ordered bool)
+	SubList(items []string)
+	Table(rows [][]string)
+	PageBreak()
+	// Contents writes a table of contents; ContentsEntry marks where entry index starts so its
+	// page number can be filled in
+	Contents(titles []string)
+	ContentsEntry(index int)
+	Bytes() ([]byte, error)
+}
+
+// logoWriter is implemented by document writers that can place the institution logo
+type logoWriter interface {
+	Logo(path string)
+}
+
+// GenerateRegulationDOCX handles GET /api/curriculum/{id}/docx: the regulation book as an editable
+// Word document, written in Go so it does not need Chrome. Takes the same ?attainment_year,
+// ?sections and semester range as the PDF
+func GenerateRegulationDOCX(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	regulationID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid regulation ID", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := regulationBookOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pdfData, err := buildRegulationPDFData(regulationID, opts)
+	if err != nil {
+		log.Println("Error fetching regulation data:", err)
+		http.Error(w, "Failed to fetch regulation data", http.StatusInternalServerError)
+		return
+	}
+
+	doc := newDocxDocument()
+	writeRegulationBook(doc, pdfData)
+	docBytes, err := doc.Bytes()
+	if err != nil {
+		log.Println("Error generating regulation DOCX:", err)
+		http.Error(w, "Failed to generate DOCX", http.StatusInternalServerError)
+		return
+	}
+
+	filename := strings.TrimSuffix(regulationPDFFilename(pdfData), ".pdf") + ".docx"
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.wordprocessingml.document")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(docBytes)))
+	w.Write(docBytes)
+}
+
+// writeRegulationBook writes the regulation book in the same order as the HTML PDF
+func writeRegulationBook(doc documentWriter, data *models.RegulationPDF) {
+	// Cover page
+	branding := data.Branding
+	if lw, ok := doc.(logoWriter); ok && branding.LogoPath != "" {
+		if logoFile, ok := brandingLogoFile(branding.LogoPath); ok {
+			lw.Logo(logoFile)
+		}
+	}
+	doc.Centered(branding.InstitutionName, true)
+	for _, line := range append(append([]string{}, branding.InfoLines...), branding.AddressLines...) {
+		doc.Centered(line, false)
+	}
+	doc.Title(data.RegulationName)
+	doc.Centered(data.AcademicYear, true)
+	if data.Draft {
+		doc.Centered("DRAFT - NOT PUBLISHED", true)
+	}
+	doc.Centered("Generated on "+data.GeneratedDate, false)
+	doc.PageBreak()
+
+	contents := regulationBookContents(data)
+	titles := make([]string, len(contents))
+	for i, entry := range contents {
+		titles[i] = entry.Title
+	}
+	doc.Contents(titles)
+	doc.PageBreak()
+
+	for i, entry := range contents {
+		doc.ContentsEntry(i)
+		writeRegulationBookSection(doc, data, entry.Key)
+	}
+}
+
+// writeRegulationBookSection writes one section of the book, ending with a page break
+func writeRegulationBookSection(doc documentWriter, data *models.RegulationPDF, section string) {
+	switch section {
+	case bookSectionClauses:
+		// Sections and clauses of the linked regulation document, in display order
+		doc.Heading(1, data.RegulationDocument.Regulation.Name)
+		for _, sec := range data.RegulationDocument.Sections {
+			doc.Heading(2, fmt.Sprintf("%d. %s", sec.SectionNo, sec.Title))
+			for _, clause := range sec.Clauses {
+				doc.Bold(strings.TrimSpace(clause.ClauseNo + " " + clause.Title))
+				for _, line := range splitBrandingLines(clause.Content) {
+					doc.Paragraph(line)
+				}
+			}
+		}
+		doc.PageBreak()
+
+	case bookSectionOverview:
+		// Vision, mission and program outcomes
+		doc.Heading(1, "VISION")
+		doc.Paragraph(data.Overview.Vision)
+		doc.Heading(1, "MISSION")
+		doc.List(departmentListTexts(data.Overview.Mission), true)
+		doc.Heading(1, "PROGRAM EDUCATIONAL OBJECTIVES (PEOs)")
+		doc.List(departmentListTexts(data.Overview.PEOs), true)
+		doc.Heading(1, "PROGRAM OUTCOMES (POs)")
+		doc.List(departmentListTexts(data.Overview.POs), true)
+		if len(data.Overview.PSOs) > 0 {
+			doc.Heading(1, "PROGRAM SPECIFIC OUTCOMES (PSOs)")
+			doc.List(departmentListTexts(data.Overview.PSOs), true)
+		}
+		doc.PageBreak()
+
+	case bookSectionPEOPO:
+		// PEO-PO mapping, keyed "peo-po" with both 1-based
+		doc.Heading(1, "PEO-PO MAPPING")
+		peoPO := [][]string{{"PEO/PO"}}
+		for po := 1; po <= len(data.Overview.POs); po++ {
+			peoPO[0] = append(peoPO[0], fmt.Sprintf("PO%d", po))
+		}
+		for peo := 1; peo <= len(data.Overview.PEOs); peo++ {
+			row := []string{fmt.Sprintf("PEO%d", peo)}
+			for po := 1; po <= len(data.Overview.POs); po++ {
+				row = append(row, strconv.Itoa(data.PEOPOMapping[fmt.Sprintf("%d-%d", peo, po)]))
+			}
+			peoPO = append(peoPO, row)
+		}
+		doc.Table(peoPO)
+		doc.PageBreak()
+
+	case bookSectionMappings:
+		if a := data.Articulation; a != nil && len(a.Courses) > 0 {
+			writeArticulationSection(doc, a)
+			doc.PageBreak()
+		}
+		if pa := data.ProgramAttainment; pa != nil {
+			writeProgramAttainmentSection(doc, pa)
+			doc.PageBreak()
+		}
+
+	case bookSectionSemesters:
+		// Credit distribution per semester card
+		doc.Heading(1, "SUMMARY OF CREDIT DISTRIBUTION")
+		for _, sem := range data.Semesters {
+			doc.Heading(2, semesterCardTitle(sem))
+			doc.Table(creditTableRows(sem.Courses))
+		}
+		doc.PageBreak()
+
+	case bookSectionSyllabi:
+		// Course descriptions
+		doc.Heading(1, "COURSE DESCRIPTIONS")
+		for _, sem := range data.Semesters {
+			for _, course := range sem.Courses {
+				writeRegulationCourse(doc, data, course)
+			}
+		}
+
+	case bookSectionHonour:
+		// Honour cards with their verticals, and their course descriptions when those are included
+		for _, honour := range data.HonourCards {
+			doc.Heading(1, honour.Title)
+			for _, vertical := range honour.Verticals {
+				doc.Heading(2, vertical.Name)
+				doc.Table(creditTableRows(vertical.Courses))
+				if data.Sections[bookSectionSyllabi] {
+					for _, course := range vertical.Courses {
+						writeRegulationCourse(doc, data, course)
+					}
+				}
+			}
+			doc.PageBreak()
+		}
+	}
+}
+
+// writeRegulationCourse writes a course description, with its CO-PO mappings only when the
+// mappings section is included
+func writeRegulationCourse(doc documentWriter, data *models.RegulationPDF, course models.CoursePDF) {
+	doc.Heading(2, fmt.Sprintf("%s - %s", course.CourseCode, course.CourseName))
+	poCount, psoCount := len(data.Overview.POs), len(data.Overview.PSOs)
+	if !data.Sections[bookSectionMappings] {
+		poCount, psoCount = 0, 0
+		course.Justifications = nil
+	}
+	writeCourseSection(doc, course, data.CurriculumTemplate, poCount, psoCount, 3)
+	doc.PageBreak()
+}
+
+func writeArticulationSection(doc documentWriter, a *models.ArticulationMatrix) {
+	doc.Heading(1, "ARTICULATION MATRIX")
+	header := []string{"Course Code", "Course Name"}
+	for i := 1; i <= a.POCount; i++ {
+		header = append(header, fmt.Sprintf("PO%d", i))
+	}
+	for i := 1; i <= a.PSOCount; i++ {
+		header = append(header, fmt.Sprintf("PSO%d", i))
+	}
+	rows := [][]string{header}
+	for _, course := range a.Courses {
+		row := []string{course.CourseCode, course.CourseName}
+		for _, v := range append(append([]*float64{}, course.POValues...), course.PSOValues...) {
+			row = append(row, formatScore(v))
+		}
+		rows = append(rows, row)
+	}
+	average := []string{"Average", ""}
+	for _, c := range append(append([]models.OutcomeCoverage{}, a.POCoverage...), a.PSOCoverage...) {
+		cell := formatScore(c.Average)
+		if c.Weak {
+			cell += " (weak)"
+		}
+		average = append(average, cell)
+	}
+	doc.Table(append(rows, average))
+
+	if len(a.WeakOutcomes) > 0 {
+		doc.Paragraph(fmt.Sprintf("Outcomes with weak coverage (average below %.1f or not addressed by any course): %s",
+			a.WeakThreshold, strings.Join(a.WeakOutcomes, ", ")))
+	}
+}
+
+func writeProgramAttainmentSection(doc documentWriter, pa *models.ProgramAttainment) {
+	doc.Heading(1, fmt.Sprintf("PROGRAM OUTCOME ATTAINMENT (%s)", pa.AcademicYear))
+	doc.Paragraph(fmt.Sprintf("Final attainment combines direct (course) attainment weighted %.0f%% and indirect (survey) attainment weighted %.0f%%.",
+		pa.DirectWeight, pa.IndirectWeight))
+	rows := [][]string{{"Outcome", "Courses", "Direct", "Indirect", "Final", "Target", "Status"}}
+	for _, group := range []struct {
+		prefix   string
+		outcomes []models.ProgramOutcomeAttainment
+	}{{"PO", pa.POs}, {"PSO", pa.PSOs}} {
+		for _, o := range group.outcomes {
+			status := "Not Attained"
+			if o.TargetMet {
+				status = "Attained"
+			}
+			rows = append(rows, []string{
+				fmt.Sprintf("%s%d", group.prefix, o.Index),
+				strconv.Itoa(o.CoursesMapped),
+				formatScore(o.Direct),
+				formatScore(o.Indirect),
+				formatScore(o.Final),
+				fmt.Sprintf("%.2f", o.Target),
+				status,
+			})
+		}
+	}
+	doc.Table(rows)
+}
+
+// semesterCardTitle is the heading the regulation book uses for a semester card
+func semesterCardTitle(sem models.SemesterPDF) string {
+	switch sem.CardType {
+	case "semester":
+		return fmt.Sprintf("SEMESTER %d", sem.SemesterNumber)
+	case "vertical":
+		return fmt.Sprintf("VERTICAL %d", sem.SemesterNumber)
+	case "elective":
+		return "ELECTIVE COURSES"
+	case "open_elective":
+		return "OPEN ELECTIVE COURSES"
+	case "one_credit":
+		return "ONE CREDIT COURSES"
+	}
+	return sem.CardType
+}
+
+// creditTableRows lays out the credit distribution table of a list of courses
+func creditTableRows(courses []models.CoursePDF) [][]string {
+	rows := [][]string{{"S.No", "Course Code", "Course Name", "L", "T", "P", "C", "Hours/Week", "CIA", "SEE", "Total", "Category"}}
+	for i, c := range courses {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			c.CourseCode,
+			c.CourseName,
+			strconv.Itoa(c.LectureHours),
+			strconv.Itoa(c.TutorialHours),
+			strconv.Itoa(c.PracticalHours),
+			strconv.Itoa(c.Credit),
+			strconv.Itoa(c.LectureHours + c.TutorialHours + c.PracticalHours),
+			strconv.Itoa(c.CIAMarks),
+			strconv.Itoa(c.SEEMarks),
+			strconv.Itoa(c.TotalMarks),
+			c.Category,
+		})
+	}
+	return rows
+}
+
+func departmentListTexts(items []models.DepartmentListItem) []string {
+	texts := make([]string, 0, len(items))
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
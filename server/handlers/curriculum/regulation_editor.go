@@ -15,8 +15,28 @@ import (
 func GetRegulationStructure(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
-	regulationID := vars["id"]
+	regulationID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid regulation ID", http.StatusBadRequest)
+		return
+	}
 
+	structure, err := fetchRegulationStructure(regulationID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Regulation not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(structure)
+}
+
+// fetchRegulationStructure loads a regulation with its sections and their clauses in display order.
+// It returns sql.ErrNoRows when the regulation does not exist
+func fetchRegulationStructure(regulationID int) (*models.RegulationStructure, error) {
 	// Get regulation details
 	var reg models.Regulation
 	err := db.DB.QueryRow(`
@@ -24,14 +44,8 @@ func GetRegulationStructure(w http.ResponseWriter, r *http.Request) {
 		FROM regulations 
 		WHERE id = ?
 	`, regulationID).Scan(&reg.ID, &reg.Code, &reg.Name, &reg.Status, &reg.CreatedAt, &reg.UpdatedAt)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Regulation not found", http.StatusNotFound)
-		return
-	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
 	// Get sections
@@ -42,8 +56,7 @@ func GetRegulationStructure(w http.ResponseWriter, r *http.Request) {
 		ORDER BY display_order, section_no
 	`, regulationID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 	defer sectionRows.Close()
 
@@ -52,49 +65,68 @@ func GetRegulationStructure(w http.ResponseWriter, r *http.Request) {
 		var section models.RegulationSection
 		if err := sectionRows.Scan(&section.ID, &section.RegulationID, &section.SectionNo,
 			&section.Title, &section.DisplayOrder, &section.CreatedAt, &section.UpdatedAt); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return nil, err
 		}
+		sections = append(sections, models.RegulationSectionWithClauses{RegulationSection: section})
+	}
+	if err := sectionRows.Err(); err != nil {
+		return nil, err
+	}
 
-		// Get clauses for this section
+	// Get clauses for each section
+	for i := range sections {
 		clauseRows, err := db.DB.Query(`
-			SELECT id, regulation_id, section_id, section_no, clause_no, title, content, display_order, created_at, updated_at 
+			SELECT id, regulation_id, section_id, section_no, parent_clause_id, clause_no, title, content, display_order, created_at, updated_at 
 			FROM regulation_clauses 
 			WHERE section_id = ? 
 			ORDER BY display_order, clause_no
-		`, section.ID)
+		`, sections[i].ID)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return nil, err
 		}
 
 		clauses := []models.RegulationClause{}
 		for clauseRows.Next() {
 			var clause models.RegulationClause
+			var parentID sql.NullInt64
 			if err := clauseRows.Scan(&clause.ID, &clause.RegulationID, &clause.SectionID, &clause.SectionNo,
-				&clause.ClauseNo, &clause.Title, &clause.Content, &clause.DisplayOrder,
+				&parentID, &clause.ClauseNo, &clause.Title, &clause.Content, &clause.DisplayOrder,
 				&clause.CreatedAt, &clause.UpdatedAt); err != nil {
 				clauseRows.Close()
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
+				return nil, err
+			}
+			if parentID.Valid {
+				id := int(parentID.Int64)
+				clause.ParentClauseID = &id
 			}
 			clauses = append(clauses, clause)
 		}
 		clauseRows.Close()
-
-		sectionWithClauses := models.RegulationSectionWithClauses{
-			RegulationSection: section,
-			Clauses:           clauses,
-		}
-		sections = append(sections, sectionWithClauses)
+		sections[i].Clauses = clauses
 	}
 
-	structure := models.RegulationStructure{
+	return &models.RegulationStructure{
 		Regulation: reg,
 		Sections:   sections,
-	}
+	}, nil
+}
 
-	json.NewEncoder(w).Encode(structure)
+// fetchCurriculumRegulationDocument loads the regulation document a curriculum is linked to, or
+// returns nil when it has none
+func fetchCurriculumRegulationDocument(curriculumID int) (*models.RegulationStructure, error) {
+	var regulationID sql.NullInt64
+	err := db.DB.QueryRow("SELECT regulation_id FROM curriculum WHERE id = ?", curriculumID).Scan(&regulationID)
+	if err != nil {
+		return nil, err
+	}
+	if !regulationID.Valid {
+		return nil, nil
+	}
+	structure, err := fetchRegulationStructure(int(regulationID.Int64))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return structure, err
 }
 
 // CreateSection creates a new section in a regulation
@@ -124,6 +156,12 @@ func CreateSection(w http.ResponseWriter, r *http.Request) {
 	regID, _ := strconv.Atoi(regulationID)
 	section.RegulationID = regID
 
+	// Without a section number the section follows the last one
+	if section.SectionNo == 0 {
+		db.DB.QueryRow("SELECT COALESCE(MAX(section_no), 0) + 1 FROM regulation_sections WHERE regulation_id = ?",
+			regulationID).Scan(&section.SectionNo)
+	}
+
 	// Get max display order
 	var maxOrder int
 	db.DB.QueryRow("SELECT COALESCE(MAX(display_order), 0) FROM regulation_sections WHERE regulation_id = ?",
@@ -156,7 +194,7 @@ func UpdateSection(w http.ResponseWriter, r *http.Request) {
 	var status string
 	err := db.DB.QueryRow(`
 		SELECT r.status FROM regulations r 
-		JOIN regulation_sections s ON r.id = s.curriculum_id 
+		JOIN regulation_sections s ON r.id = s.regulation_id 
 		WHERE s.id = ?
 	`, sectionID).Scan(&status)
 	if err != nil {
@@ -199,7 +237,7 @@ func DeleteSection(w http.ResponseWriter, r *http.Request) {
 	var status string
 	err := db.DB.QueryRow(`
 		SELECT r.status FROM regulations r 
-		JOIN regulation_sections s ON r.id = s.curriculum_id 
+		JOIN regulation_sections s ON r.id = s.regulation_id 
 		WHERE s.id = ?
 	`, sectionID).Scan(&status)
 	if err != nil {
@@ -235,22 +273,12 @@ func CreateClause(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sectionID := vars["sectionId"]
 
-	// Check if regulation is LOCKED
-	var status string
 	var regulationID int
-	err := db.DB.QueryRow(`
-		SELECT r.id, r.status FROM regulations r 
-		JOIN regulation_sections s ON r.id = s.curriculum_id 
-		WHERE s.id = ?
-	`, sectionID).Scan(&regulationID, &status)
+	err := db.DB.QueryRow("SELECT regulation_id FROM regulation_sections WHERE id = ?", sectionID).Scan(&regulationID)
 	if err != nil {
 		http.Error(w, "Section not found", http.StatusNotFound)
 		return
 	}
-	if status == "LOCKED" {
-		http.Error(w, "Cannot edit LOCKED regulation", http.StatusForbidden)
-		return
-	}
 
 	var clause models.RegulationClause
 	if err := json.NewDecoder(r.Body).Decode(&clause); err != nil {
@@ -262,22 +290,47 @@ func CreateClause(w http.ResponseWriter, r *http.Request) {
 	clause.SectionID = secID
 	clause.RegulationID = regulationID
 
+	// The regulation stays locked until the new clause is numbered, so clauses created at the
+	// same time are numbered one after the other
+	tx, ok := beginRegulationEdit(w, regulationID)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	// A sub-clause goes under a clause of the same section
+	if clause.ParentClauseID != nil {
+		var exists int
+		if err := tx.QueryRow("SELECT 1 FROM regulation_clauses WHERE id = ? AND section_id = ?",
+			*clause.ParentClauseID, secID).Scan(&exists); err != nil {
+			http.Error(w, "Parent clause not found in this section", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Get section number
 	var sectionNo int
-	db.DB.QueryRow("SELECT section_no FROM regulation_sections WHERE id = ?", sectionID).Scan(&sectionNo)
+	tx.QueryRow("SELECT section_no FROM regulation_sections WHERE id = ?", sectionID).Scan(&sectionNo)
 	clause.SectionNo = sectionNo
 
 	// Get max display order
 	var maxOrder int
-	db.DB.QueryRow("SELECT COALESCE(MAX(display_order), 0) FROM regulation_clauses WHERE section_id = ?",
+	tx.QueryRow("SELECT COALESCE(MAX(display_order), 0) FROM regulation_clauses WHERE section_id = ?",
 		sectionID).Scan(&maxOrder)
 	clause.DisplayOrder = maxOrder + 1
 
-	result, err := db.DB.Exec(`
+	// Without a clause number, and for sub-clauses, the new clause's siblings are renumbered so
+	// it gets the next number after them
+	autoNumber := clause.ClauseNo == "" || clause.ParentClauseID != nil
+	if autoNumber {
+		clause.ClauseNo = pendingClauseNo
+	}
+
+	result, err := tx.Exec(`
 		INSERT INTO regulation_clauses 
-		(regulation_id, section_id, section_no, clause_no, title, content, display_order, created_at, updated_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
-	`, clause.RegulationID, clause.SectionID, clause.SectionNo, clause.ClauseNo,
+		(regulation_id, section_id, section_no, parent_clause_id, clause_no, title, content, display_order, created_at, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`, clause.RegulationID, clause.SectionID, clause.SectionNo, clause.ParentClauseID, clause.ClauseNo,
 		clause.Title, clause.Content, clause.DisplayOrder)
 
 	if err != nil {
@@ -288,6 +341,22 @@ func CreateClause(w http.ResponseWriter, r *http.Request) {
 	id, _ := result.LastInsertId()
 	clause.ID = int(id)
 
+	if autoNumber {
+		if err := renumberClauseSiblings(tx, regulationID, secID, clause.ParentClauseID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.QueryRow("SELECT clause_no, display_order FROM regulation_clauses WHERE id = ?", clause.ID).
+			Scan(&clause.ClauseNo, &clause.DisplayOrder); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	json.NewEncoder(w).Encode(clause)
 }
 
@@ -144,6 +144,12 @@ func DeleteRegulationNew(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Curricula that printed this regulation no longer have one
+	if _, err := db.DB.Exec("UPDATE curriculum SET regulation_id = NULL WHERE regulation_id = ?", id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Regulation deleted successfully"})
 }
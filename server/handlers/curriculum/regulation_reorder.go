@@ -0,0 +1,502 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// pendingClauseNo numbers a new clause until its siblings are renumbered in the same transaction
+const pendingClauseNo = "~new"
+
+// sectionPlacement is where a section goes after a reorder
+type sectionPlacement struct {
+	ID           int
+	SectionNo    int
+	DisplayOrder int
+}
+
+// clausePlacement is where a clause goes after a reorder
+type clausePlacement struct {
+	ID           int
+	SectionID    int
+	SectionNo    int
+	ParentID     *int
+	ClauseNo     string
+	DisplayOrder int
+}
+
+// regulationOrderIDs returns the ids of a regulation's sections and clauses
+func regulationOrderIDs(tx *sql.Tx, regulationID int) (map[int]bool, map[int]bool, error) {
+	sections := map[int]bool{}
+	clauses := map[int]bool{}
+	for _, q := range []struct {
+		query string
+		ids   map[int]bool
+	}{
+		{"SELECT id FROM regulation_sections WHERE regulation_id = ?", sections},
+		{"SELECT id FROM regulation_clauses WHERE regulation_id = ?", clauses},
+	} {
+		rows, err := tx.Query(q.query, regulationID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			q.ids[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return sections, clauses, nil
+}
+
+// validateRegulationOrder checks that an order lists every section and clause of the regulation exactly once
+func validateRegulationOrder(order *models.RegulationOrder, sections, clauses map[int]bool) error {
+	seenSections := map[int]bool{}
+	seenClauses := map[int]bool{}
+	var checkClauses func(list []models.RegulationClauseOrder) error
+	checkClauses = func(list []models.RegulationClauseOrder) error {
+		for _, c := range list {
+			if !clauses[c.ID] {
+				return fmt.Errorf("clause %d does not belong to this regulation", c.ID)
+			}
+			if seenClauses[c.ID] {
+				return fmt.Errorf("clause %d is listed more than once", c.ID)
+			}
+			seenClauses[c.ID] = true
+			if err := checkClauses(c.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, sec := range order.Sections {
+		if !sections[sec.ID] {
+			return fmt.Errorf("section %d does not belong to this regulation", sec.ID)
+		}
+		if seenSections[sec.ID] {
+			return fmt.Errorf("section %d is listed more than once", sec.ID)
+		}
+		seenSections[sec.ID] = true
+		if err := checkClauses(sec.Clauses); err != nil {
+			return err
+		}
+	}
+	if len(seenSections) != len(sections) {
+		return fmt.Errorf("every section of the regulation must be listed")
+	}
+	if len(seenClauses) != len(clauses) {
+		return fmt.Errorf("every clause of the regulation must be listed")
+	}
+	return nil
+}
+
+// numberRegulationOrder numbers sections 1, 2, ... in order and clauses hierarchically under
+// their section and parent (4.1, 4.2, 4.2.1). A clause's display order is its place in the
+// section when read top to bottom, sub-clauses right after their parent
+func numberRegulationOrder(order *models.RegulationOrder) ([]sectionPlacement, []clausePlacement, error) {
+	sections := []sectionPlacement{}
+	clauses := []clausePlacement{}
+
+	for i, sec := range order.Sections {
+		sectionNo := i + 1
+		sections = append(sections, sectionPlacement{ID: sec.ID, SectionNo: sectionNo, DisplayOrder: sectionNo})
+
+		displayOrder := 0
+		placed, err := numberClauses(sec.Clauses, sec.ID, sectionNo, strconv.Itoa(sectionNo), nil, &displayOrder)
+		if err != nil {
+			return nil, nil, err
+		}
+		clauses = append(clauses, placed...)
+	}
+	return sections, clauses, nil
+}
+
+// numberClauses numbers a list of sibling clauses prefix.1, prefix.2, ... and their sub-clauses
+// under them, counting display orders on from displayOrder
+func numberClauses(list []models.RegulationClauseOrder, sectionID, sectionNo int, prefix string, parentID *int, displayOrder *int) ([]clausePlacement, error) {
+	clauses := []clausePlacement{}
+	for k, c := range list {
+		clauseNo := fmt.Sprintf("%s.%d", prefix, k+1)
+		if len(clauseNo) > 10 {
+			return nil, fmt.Errorf("clause number %s would be longer than 10 characters", clauseNo)
+		}
+		*displayOrder++
+		clauses = append(clauses, clausePlacement{
+			ID:           c.ID,
+			SectionID:    sectionID,
+			SectionNo:    sectionNo,
+			ParentID:     parentID,
+			ClauseNo:     clauseNo,
+			DisplayOrder: *displayOrder,
+		})
+		id := c.ID
+		children, err := numberClauses(c.Children, sectionID, sectionNo, clauseNo, &id, displayOrder)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, children...)
+	}
+	return clauses, nil
+}
+
+// findClauseOrder finds a clause anywhere under a list of clauses
+func findClauseOrder(list []models.RegulationClauseOrder, id int) *models.RegulationClauseOrder {
+	for i := range list {
+		if list[i].ID == id {
+			return &list[i]
+		}
+		if c := findClauseOrder(list[i].Children, id); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// applyRegulationOrder writes the new numbers and order. Numbers are unique within a regulation,
+// so every section and clause is first moved to a temporary number that cannot clash
+func applyRegulationOrder(tx *sql.Tx, regulationID int, sections []sectionPlacement, clauses []clausePlacement) error {
+	if _, err := tx.Exec("UPDATE regulation_sections SET section_no = -id WHERE regulation_id = ?", regulationID); err != nil {
+		return fmt.Errorf("failed to clear section numbers: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE regulation_clauses SET clause_no = CONCAT('~', id) WHERE regulation_id = ?", regulationID); err != nil {
+		return fmt.Errorf("failed to clear clause numbers: %w", err)
+	}
+
+	for _, s := range sections {
+		if _, err := tx.Exec(`
+			UPDATE regulation_sections SET section_no = ?, display_order = ?, updated_at = NOW()
+			WHERE id = ?`, s.SectionNo, s.DisplayOrder, s.ID); err != nil {
+			return fmt.Errorf("failed to renumber section %d: %w", s.ID, err)
+		}
+	}
+	for _, c := range clauses {
+		if _, err := tx.Exec(`
+			UPDATE regulation_clauses
+			SET section_id = ?, section_no = ?, parent_clause_id = ?, clause_no = ?, display_order = ?, updated_at = NOW()
+			WHERE id = ?`, c.SectionID, c.SectionNo, c.ParentID, c.ClauseNo, c.DisplayOrder, c.ID); err != nil {
+			return fmt.Errorf("failed to renumber clause %d: %w", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// regulationClauseRow is a clause as stored, before it is placed in the order
+type regulationClauseRow struct {
+	id, sectionID int
+	parentID      sql.NullInt64
+}
+
+// loadRegulationOrder reads the current order of a regulation: sections and sibling clauses by
+// display order, sub-clauses under their parent
+func loadRegulationOrder(tx *sql.Tx, regulationID int) (*models.RegulationOrder, error) {
+	sectionIDs := []int{}
+	rows, err := tx.Query(`
+		SELECT id FROM regulation_sections WHERE regulation_id = ?
+		ORDER BY display_order, section_no`, regulationID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sectionIDs = append(sectionIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	clauseRows := []regulationClauseRow{}
+	rows, err = tx.Query(`
+		SELECT id, section_id, parent_clause_id FROM regulation_clauses WHERE regulation_id = ?
+		ORDER BY display_order, clause_no`, regulationID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var c regulationClauseRow
+		if err := rows.Scan(&c.id, &c.sectionID, &c.parentID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		clauseRows = append(clauseRows, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return buildRegulationOrder(sectionIDs, clauseRows), nil
+}
+
+// buildRegulationOrder arranges sections and clauses, each already in display order, into the
+// order tree. Every clause is placed exactly once, whatever the stored parents say
+func buildRegulationOrder(sectionIDs []int, clauseRows []regulationClauseRow) *models.RegulationOrder {
+	order := &models.RegulationOrder{Sections: []models.RegulationSectionOrder{}}
+	sectionIndex := map[int]int{}
+	for _, id := range sectionIDs {
+		sectionIndex[id] = len(order.Sections)
+		order.Sections = append(order.Sections, models.RegulationSectionOrder{ID: id})
+	}
+	clauseSection := map[int]int{}
+	for _, c := range clauseRows {
+		clauseSection[c.id] = c.sectionID
+	}
+
+	// A clause whose parent is missing or in another section is kept as a top-level clause
+	children := map[int][]int{}
+	roots := map[int][]int{}
+	for _, c := range clauseRows {
+		parent := int(c.parentID.Int64)
+		if c.parentID.Valid && parent != c.id && clauseSection[parent] == c.sectionID {
+			children[parent] = append(children[parent], c.id)
+		} else {
+			roots[c.sectionID] = append(roots[c.sectionID], c.id)
+		}
+	}
+
+	placed := map[int]bool{}
+	var build func(ids []int) []models.RegulationClauseOrder
+	build = func(ids []int) []models.RegulationClauseOrder {
+		list := []models.RegulationClauseOrder{}
+		for _, id := range ids {
+			if placed[id] {
+				continue
+			}
+			placed[id] = true
+			list = append(list, models.RegulationClauseOrder{ID: id, Children: build(children[id])})
+		}
+		return list
+	}
+	for id, i := range sectionIndex {
+		order.Sections[i].Clauses = build(roots[id])
+	}
+
+	// Clauses caught in a parent cycle are not reachable from a top-level clause; keep them at the end
+	for _, c := range clauseRows {
+		if !placed[c.id] {
+			i := sectionIndex[c.sectionID]
+			order.Sections[i].Clauses = append(order.Sections[i].Clauses, build([]int{c.id})...)
+		}
+	}
+	return order
+}
+
+// renumberRegulation renumbers a regulation in its current order
+func renumberRegulation(tx *sql.Tx, regulationID int) error {
+	order, err := loadRegulationOrder(tx, regulationID)
+	if err != nil {
+		return err
+	}
+	sections, clauses, err := numberRegulationOrder(order)
+	if err != nil {
+		return err
+	}
+	return applyRegulationOrder(tx, regulationID, sections, clauses)
+}
+
+// renumberClauseSiblings renumbers one list of sibling clauses, the top-level clauses of a
+// section or the sub-clauses of a clause, in their current order, together with the clauses
+// under them. The rest of the regulation keeps its numbers; only display orders in the section
+// move, so the list still reads in place
+func renumberClauseSiblings(tx *sql.Tx, regulationID, sectionID int, parentID *int) error {
+	order, err := loadRegulationOrder(tx, regulationID)
+	if err != nil {
+		return err
+	}
+	var section *models.RegulationSectionOrder
+	for i := range order.Sections {
+		if order.Sections[i].ID == sectionID {
+			section = &order.Sections[i]
+		}
+	}
+	if section == nil {
+		return fmt.Errorf("section %d does not belong to regulation %d", sectionID, regulationID)
+	}
+
+	var sectionNo int
+	if err := tx.QueryRow("SELECT section_no FROM regulation_sections WHERE id = ?", sectionID).Scan(&sectionNo); err != nil {
+		return err
+	}
+	prefix := strconv.Itoa(sectionNo)
+	siblings := section.Clauses
+	if parentID != nil {
+		parent := findClauseOrder(section.Clauses, *parentID)
+		if parent == nil {
+			return fmt.Errorf("clause %d is not in section %d", *parentID, sectionID)
+		}
+		if err := tx.QueryRow("SELECT clause_no FROM regulation_clauses WHERE id = ?", *parentID).Scan(&prefix); err != nil {
+			return err
+		}
+		siblings = parent.Children
+	}
+
+	displayOrder := 0
+	renumbered, err := numberClauses(siblings, sectionID, sectionNo, prefix, parentID, &displayOrder)
+	if err != nil {
+		return err
+	}
+	displayOrder = 0
+	reading, err := numberClauses(section.Clauses, sectionID, sectionNo, strconv.Itoa(sectionNo), nil, &displayOrder)
+	if err != nil {
+		return err
+	}
+
+	// Clause numbers are unique within a section, so the list is moved out of the way first
+	for _, c := range renumbered {
+		if _, err := tx.Exec("UPDATE regulation_clauses SET clause_no = CONCAT('~', id) WHERE id = ?", c.ID); err != nil {
+			return fmt.Errorf("failed to clear clause number %d: %w", c.ID, err)
+		}
+	}
+	for _, c := range renumbered {
+		if _, err := tx.Exec("UPDATE regulation_clauses SET clause_no = ?, updated_at = NOW() WHERE id = ?", c.ClauseNo, c.ID); err != nil {
+			return fmt.Errorf("failed to renumber clause %d: %w", c.ID, err)
+		}
+	}
+	for _, c := range reading {
+		if _, err := tx.Exec("UPDATE regulation_clauses SET display_order = ? WHERE id = ?", c.DisplayOrder, c.ID); err != nil {
+			return fmt.Errorf("failed to reorder clause %d: %w", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// beginRegulationEdit starts a transaction on a regulation that is not LOCKED, writing the error
+// response and returning ok false when it cannot be edited
+func beginRegulationEdit(w http.ResponseWriter, regulationID int) (*sql.Tx, bool) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting regulation transaction:", err)
+		http.Error(w, "Failed to update regulation", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	var status string
+	err = tx.QueryRow("SELECT status FROM regulations WHERE id = ? FOR UPDATE", regulationID).Scan(&status)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		http.Error(w, "Regulation not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		tx.Rollback()
+		log.Println("Error fetching regulation status:", err)
+		http.Error(w, "Failed to update regulation", http.StatusInternalServerError)
+		return nil, false
+	}
+	if status == "LOCKED" {
+		tx.Rollback()
+		http.Error(w, "Cannot edit LOCKED regulation", http.StatusForbidden)
+		return nil, false
+	}
+	return tx, true
+}
+
+// commitRegulationOrder commits a reorder and responds with the renumbered regulation
+func commitRegulationOrder(w http.ResponseWriter, tx *sql.Tx, regulationID int) {
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing regulation order:", err)
+		http.Error(w, "Failed to reorder regulation", http.StatusInternalServerError)
+		return
+	}
+
+	structure, err := fetchRegulationStructure(regulationID)
+	if err != nil {
+		log.Println("Error fetching reordered regulation:", err)
+		http.Error(w, "Failed to fetch regulation", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(structure)
+}
+
+// ReorderRegulation handles PUT /api/regulations/{id}/order with the complete order of sections
+// and clauses, e.g. after a drag and drop. Clauses may move between sections and under other
+// clauses; sections are renumbered 1, 2, ... and clauses 4.1, 4.2, 4.2.1 in one transaction
+func ReorderRegulation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	regulationID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid regulation ID", http.StatusBadRequest)
+		return
+	}
+
+	var order models.RegulationOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tx, ok := beginRegulationEdit(w, regulationID)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	sectionIDs, clauseIDs, err := regulationOrderIDs(tx, regulationID)
+	if err != nil {
+		log.Println("Error fetching regulation order:", err)
+		http.Error(w, "Failed to reorder regulation", http.StatusInternalServerError)
+		return
+	}
+	if err := validateRegulationOrder(&order, sectionIDs, clauseIDs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sections, clauses, err := numberRegulationOrder(&order)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := applyRegulationOrder(tx, regulationID, sections, clauses); err != nil {
+		log.Println("Error reordering regulation:", err)
+		http.Error(w, "Failed to reorder regulation", http.StatusInternalServerError)
+		return
+	}
+
+	commitRegulationOrder(w, tx, regulationID)
+}
+
+// RenumberRegulation handles POST /api/regulations/{id}/renumber: renumbers the sections and
+// clauses in their current order, closing gaps left by deletions
+func RenumberRegulation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	regulationID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid regulation ID", http.StatusBadRequest)
+		return
+	}
+
+	tx, ok := beginRegulationEdit(w, regulationID)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	if err := renumberRegulation(tx, regulationID); err != nil {
+		log.Println("Error renumbering regulation:", err)
+		http.Error(w, "Failed to renumber regulation", http.StatusInternalServerError)
+		return
+	}
+
+	commitRegulationOrder(w, tx, regulationID)
+}
@@ -0,0 +1,189 @@
+package curriculum
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"server/models"
+	"strings"
+	"testing"
+)
+
+func clauseOrder(id int, children ...models.RegulationClauseOrder) models.RegulationClauseOrder {
+	return models.RegulationClauseOrder{ID: id, Children: children}
+}
+
+// describeOrder writes an order as "section[clause[child]]", e.g. "1[10 11[12]] 2[]"
+func describeOrder(order *models.RegulationOrder) string {
+	var describe func(list []models.RegulationClauseOrder) string
+	describe = func(list []models.RegulationClauseOrder) string {
+		parts := []string{}
+		for _, c := range list {
+			part := fmt.Sprint(c.ID)
+			if len(c.Children) > 0 {
+				part += describe(c.Children)
+			}
+			parts = append(parts, part)
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	}
+	sections := []string{}
+	for _, s := range order.Sections {
+		sections = append(sections, fmt.Sprint(s.ID)+describe(s.Clauses))
+	}
+	return strings.Join(sections, " ")
+}
+
+func TestNumberRegulationOrder(t *testing.T) {
+	order := &models.RegulationOrder{Sections: []models.RegulationSectionOrder{
+		{ID: 7, Clauses: []models.RegulationClauseOrder{
+			clauseOrder(70),
+			clauseOrder(71, clauseOrder(72), clauseOrder(73, clauseOrder(74))),
+			clauseOrder(75),
+		}},
+		{ID: 3, Clauses: []models.RegulationClauseOrder{}},
+		{ID: 5, Clauses: []models.RegulationClauseOrder{clauseOrder(50)}},
+	}}
+
+	sections, clauses, err := numberRegulationOrder(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSections := []sectionPlacement{{7, 1, 1}, {3, 2, 2}, {5, 3, 3}}
+	if !reflect.DeepEqual(sections, wantSections) {
+		t.Errorf("sections = %+v, want %+v", sections, wantSections)
+	}
+
+	got := []string{}
+	for _, c := range clauses {
+		parent := "-"
+		if c.ParentID != nil {
+			parent = fmt.Sprint(*c.ParentID)
+		}
+		got = append(got, fmt.Sprintf("%d:%d/%s@%d^%s", c.ID, c.SectionID, c.ClauseNo, c.DisplayOrder, parent))
+	}
+	want := []string{
+		"70:7/1.1@1^-", "71:7/1.2@2^-", "72:7/1.2.1@3^71", "73:7/1.2.2@4^71", "74:7/1.2.2.1@5^73", "75:7/1.3@6^-",
+		"50:5/3.1@1^-",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clauses = %v, want %v", got, want)
+	}
+}
+
+func TestNumberRegulationOrderTooDeep(t *testing.T) {
+	deep := clauseOrder(9)
+	for id := 8; id > 0; id-- {
+		deep = clauseOrder(id, deep)
+	}
+	order := &models.RegulationOrder{Sections: []models.RegulationSectionOrder{{ID: 1, Clauses: []models.RegulationClauseOrder{deep}}}}
+	if _, _, err := numberRegulationOrder(order); err == nil {
+		t.Error("numberRegulationOrder accepted a clause number longer than 10 characters")
+	}
+}
+
+func TestNumberClausesSiblingList(t *testing.T) {
+	// Renumbering 4.2's sub-clauses leaves 4.2 itself alone
+	parent := 42
+	displayOrder := 0
+	clauses, err := numberClauses([]models.RegulationClauseOrder{clauseOrder(8), clauseOrder(6, clauseOrder(9))}, 4, 4, "4.2", &parent, &displayOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, c := range clauses {
+		got = append(got, fmt.Sprintf("%d:%s^%d", c.ID, c.ClauseNo, *c.ParentID))
+	}
+	if want := []string{"8:4.2.1^42", "6:4.2.2^42", "9:4.2.2.1^6"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("clauses = %v, want %v", got, want)
+	}
+	if displayOrder != 3 {
+		t.Errorf("display order ended at %d, want 3", displayOrder)
+	}
+}
+
+func TestFindClauseOrder(t *testing.T) {
+	list := []models.RegulationClauseOrder{clauseOrder(1, clauseOrder(2, clauseOrder(3))), clauseOrder(4)}
+	if c := findClauseOrder(list, 3); c == nil || c.ID != 3 {
+		t.Errorf("findClauseOrder(3) = %v", c)
+	}
+	if c := findClauseOrder(list, 5); c != nil {
+		t.Errorf("findClauseOrder(5) = %v, want nil", c)
+	}
+}
+
+func TestBuildRegulationOrder(t *testing.T) {
+	parent := func(id int64) sql.NullInt64 { return sql.NullInt64{Int64: id, Valid: true} }
+
+	tests := []struct {
+		name     string
+		sections []int
+		clauses  []regulationClauseRow
+		want     string
+	}{
+		{
+			"nested",
+			[]int{1, 2},
+			[]regulationClauseRow{{10, 1, sql.NullInt64{}}, {11, 1, sql.NullInt64{}}, {12, 1, parent(11)}, {20, 2, sql.NullInt64{}}},
+			"1[10 11[12]] 2[20]",
+		},
+		{
+			"child listed before its parent",
+			[]int{1},
+			[]regulationClauseRow{{12, 1, parent(11)}, {10, 1, sql.NullInt64{}}, {11, 1, sql.NullInt64{}}},
+			"1[10 11[12]]",
+		},
+		{
+			"own parent",
+			[]int{1},
+			[]regulationClauseRow{{10, 1, parent(10)}, {11, 1, sql.NullInt64{}}},
+			"1[10 11]",
+		},
+		{
+			"parent in another section",
+			[]int{1, 2},
+			[]regulationClauseRow{{10, 1, sql.NullInt64{}}, {20, 2, parent(10)}},
+			"1[10] 2[20]",
+		},
+		{
+			"missing parent",
+			[]int{1},
+			[]regulationClauseRow{{10, 1, parent(99)}},
+			"1[10]",
+		},
+		{
+			"two-clause cycle",
+			[]int{1},
+			[]regulationClauseRow{{10, 1, sql.NullInt64{}}, {11, 1, parent(12)}, {12, 1, parent(11)}},
+			"1[10 11[12]]",
+		},
+		{
+			"cycle with a branch",
+			[]int{1},
+			[]regulationClauseRow{{11, 1, parent(13)}, {12, 1, parent(11)}, {13, 1, parent(12)}, {14, 1, parent(12)}},
+			"1[11[12[13 14]]]",
+		},
+	}
+	for _, tt := range tests {
+		order := buildRegulationOrder(tt.sections, tt.clauses)
+		if got := describeOrder(order); got != tt.want {
+			t.Errorf("%s: order = %s, want %s", tt.name, got, tt.want)
+		}
+
+		// Every clause is placed once, so the order can always be renumbered
+		sectionIDs := map[int]bool{}
+		for _, id := range tt.sections {
+			sectionIDs[id] = true
+		}
+		clauseIDs := map[int]bool{}
+		for _, c := range tt.clauses {
+			clauseIDs[c.id] = true
+		}
+		if err := validateRegulationOrder(order, sectionIDs, clauseIDs); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if _, _, err := numberRegulationOrder(order); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package curriculum
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"server/models"
+	"strconv"
+	"strings"
+)
+
+// Sections of the regulation book that can be chosen with ?sections=
+const (
+	bookSectionClauses   = "clauses"
+	bookSectionOverview  = "overview"
+	bookSectionPEOPO     = "peo_po"
+	bookSectionMappings  = "mappings"
+	bookSectionSemesters = "semesters"
+	bookSectionSyllabi   = "syllabi"
+	bookSectionHonour    = "honour"
+)
+
+// regulationBookSections lists the sections in book order with their table of contents titles
+var regulationBookSections = []struct{ key, title string }{
+	{bookSectionClauses, "Academic Regulations"},
+	{bookSectionOverview, "Vision, Mission and Program Outcomes"},
+	{bookSectionPEOPO, "PEO-PO Mapping"},
+	{bookSectionMappings, "Articulation Matrix and Outcome Attainment"},
+	{bookSectionSemesters, "Summary of Credit Distribution"},
+	{bookSectionSyllabi, "Course Descriptions"},
+	{bookSectionHonour, "Honour Cards"},
+}
+
+// regulationBookOptions chooses what goes into a regulation book
+type regulationBookOptions struct {
+	AttainmentYear string
+	Sections       map[string]bool
+	// SemesterFrom and SemesterTo limit the book to those semester cards; 0 means no limit
+	SemesterFrom int
+	SemesterTo   int
+}
+
+// allBookSections selects every section
+func allBookSections() map[string]bool {
+	sections := map[string]bool{}
+	for _, s := range regulationBookSections {
+		sections[s.key] = true
+	}
+	return sections
+}
+
+// regulationBookOptionsFromRequest reads ?attainment_year, ?sections (comma separated, all by
+// default) and ?semester_from / ?semester_to
+func regulationBookOptionsFromRequest(r *http.Request) (regulationBookOptions, error) {
+	q := r.URL.Query()
+	opts := regulationBookOptions{AttainmentYear: q.Get("attainment_year"), Sections: allBookSections()}
+
+	if v := q.Get("sections"); v != "" {
+		valid := opts.Sections
+		opts.Sections = map[string]bool{}
+		for _, key := range strings.Split(v, ",") {
+			key = strings.TrimSpace(key)
+			if !valid[key] {
+				return opts, fmt.Errorf("unknown section %q", key)
+			}
+			opts.Sections[key] = true
+		}
+	}
+
+	for _, bound := range []struct {
+		param string
+		value *int
+	}{{"semester_from", &opts.SemesterFrom}, {"semester_to", &opts.SemesterTo}} {
+		if v := q.Get(bound.param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("invalid %s", bound.param)
+			}
+			*bound.value = n
+		}
+	}
+	if opts.SemesterFrom > 0 && opts.SemesterTo > 0 && opts.SemesterFrom > opts.SemesterTo {
+		return opts, fmt.Errorf("semester_from must not be after semester_to")
+	}
+	return opts, nil
+}
+
+// applySemesterRange keeps only the semester cards in the range; other cards such as electives
+// are dropped when a range is given
+func applySemesterRange(semesters []models.SemesterPDF, from, to int) []models.SemesterPDF {
+	if from == 0 && to == 0 {
+		return semesters
+	}
+	kept := []models.SemesterPDF{}
+	for _, sem := range semesters {
+		if sem.CardType != "semester" {
+			continue
+		}
+		if (from > 0 && sem.SemesterNumber < from) || (to > 0 && sem.SemesterNumber > to) {
+			continue
+		}
+		kept = append(kept, sem)
+	}
+	return kept
+}
+
+// contentsEntry is one line of the table of contents; Page is 0 until it is known
+type contentsEntry struct {
+	Key   string
+	Title string
+	Page  int
+}
+
+// regulationBookContents lists the selected sections that have something to show
+func regulationBookContents(data *models.RegulationPDF) []contentsEntry {
+	hasCourses := false
+	for _, sem := range data.Semesters {
+		if len(sem.Courses) > 0 {
+			hasCourses = true
+		}
+	}
+	present := map[string]bool{
+		bookSectionClauses:   data.RegulationDocument != nil && len(data.RegulationDocument.Sections) > 0,
+		bookSectionOverview:  true,
+		bookSectionPEOPO:     true,
+		bookSectionMappings:  (data.Articulation != nil && len(data.Articulation.Courses) > 0) || data.ProgramAttainment != nil,
+		bookSectionSemesters: len(data.Semesters) > 0,
+		bookSectionSyllabi:   hasCourses,
+		bookSectionHonour:    len(data.HonourCards) > 0,
+	}
+
+	contents := []contentsEntry{}
+	for _, s := range regulationBookSections {
+		if data.Sections[s.key] && present[s.key] {
+			contents = append(contents, contentsEntry{Key: s.key, Title: s.title})
+		}
+	}
+	return contents
+}
+
+// regulationHTMLView is what one rendering of the regulation template shows: the cover with the
+// table of contents, the sections in Render and, for counting pages, a marker page at the end
+type regulationHTMLView struct {
+	Cover      bool
+	Contents   []contentsEntry
+	Render     map[string]bool
+	PageMarker bool
+}
+
+// fullRegulationView shows the whole book with the given table of contents
+func fullRegulationView(contents []contentsEntry) regulationHTMLView {
+	render := map[string]bool{}
+	for _, entry := range contents {
+		render[entry.Key] = true
+	}
+	return regulationHTMLView{Cover: true, Contents: contents, Render: render}
+}
+
+// renderPaginatedRegulationHTML renders the book with page numbers in its table of contents.
+// Every section starts on a new page, so the cover and each section are first printed on their
+// own, followed by a marker page, to count the pages they take
+func renderPaginatedRegulationHTML(data *models.RegulationPDF, print func(htmlContent string) ([]byte, error)) (string, error) {
+	contents := regulationBookContents(data)
+
+	// Templates without a table of contents need no page numbers
+	if data.TemplateHTML != "" && !strings.Contains(data.TemplateHTML, ".Contents") {
+		return renderRegulationHTML(data, fullRegulationView(contents))
+	}
+
+	countPages := func(view regulationHTMLView) (int, error) {
+		view.PageMarker = true
+		htmlContent, err := renderRegulationHTML(data, view)
+		if err != nil {
+			return 0, err
+		}
+		pdfBytes, err := print(htmlContent)
+		if err != nil {
+			return 0, err
+		}
+		return pdfPageCount(pdfBytes) - 1, nil
+	}
+
+	pages, err := countPages(regulationHTMLView{Cover: true, Contents: contents})
+	if err != nil {
+		return "", err
+	}
+	next := pages + 1
+	for i := range contents {
+		contents[i].Page = next
+		pages, err := countPages(regulationHTMLView{Render: map[string]bool{contents[i].Key: true}})
+		if err != nil {
+			return "", err
+		}
+		next += pages
+	}
+
+	return renderRegulationHTML(data, fullRegulationView(contents))
+}
+
+var pdfPageObject = regexp.MustCompile(`/Type\s*/Page[\s/>]`)
+
+// pdfPageCount counts the page objects of a PDF
+func pdfPageCount(pdfBytes []byte) int {
+	return len(pdfPageObject.FindAll(pdfBytes, -1))
+}
@@ -0,0 +1,76 @@
+package curriculum
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"server/models"
+	"testing"
+)
+
+func TestRegulationBookOptionsFromRequest(t *testing.T) {
+	tests := []struct {
+		query    string
+		sections map[string]bool
+		from, to int
+		wantErr  bool
+	}{
+		{"", allBookSections(), 0, 0, false},
+		{"?attainment_year=2025&sections=clauses,%20semesters", map[string]bool{"clauses": true, "semesters": true}, 0, 0, false},
+		{"?sections=clauses,appendix", nil, 0, 0, true},
+		{"?semester_from=3", allBookSections(), 3, 0, false},
+		{"?semester_to=4", allBookSections(), 0, 4, false},
+		{"?semester_from=2&semester_to=2", allBookSections(), 2, 2, false},
+		{"?semester_from=5&semester_to=2", nil, 0, 0, true},
+		{"?semester_from=0", nil, 0, 0, true},
+		{"?semester_to=four", nil, 0, 0, true},
+	}
+	for _, tt := range tests {
+		opts, err := regulationBookOptionsFromRequest(httptest.NewRequest("GET", "/api/regulation/1/pdf"+tt.query, nil))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error = %v, want error %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !reflect.DeepEqual(opts.Sections, tt.sections) || opts.SemesterFrom != tt.from || opts.SemesterTo != tt.to {
+			t.Errorf("%q: options = %+v", tt.query, opts)
+		}
+	}
+
+	opts, _ := regulationBookOptionsFromRequest(httptest.NewRequest("GET", "/api/regulation/1/pdf?attainment_year=2025", nil))
+	if opts.AttainmentYear != "2025" {
+		t.Errorf("attainment year = %q, want 2025", opts.AttainmentYear)
+	}
+}
+
+func TestApplySemesterRange(t *testing.T) {
+	semesters := []models.SemesterPDF{
+		{SemesterNumber: 1, CardType: "semester"},
+		{SemesterNumber: 2, CardType: "semester"},
+		{SemesterNumber: 3, CardType: "semester"},
+		{SemesterNumber: 4, CardType: "semester"},
+		{SemesterNumber: 0, CardType: "elective"},
+	}
+
+	tests := []struct {
+		name     string
+		from, to int
+		want     []int
+	}{
+		{"no range keeps every card", 0, 0, []int{1, 2, 3, 4, 0}},
+		{"from and to", 2, 3, []int{2, 3}},
+		{"from only", 3, 0, []int{3, 4}},
+		{"to only", 0, 1, []int{1}},
+		{"beyond the last semester", 6, 8, []int{}},
+	}
+	for _, tt := range tests {
+		got := []int{}
+		for _, sem := range applySemesterRange(semesters, tt.from, tt.to) {
+			got = append(got, sem.SemesterNumber)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: semesters = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// errRegulationCodeExists is returned when importing a regulation under a code that is already used
+var errRegulationCodeExists = errors.New("a regulation with this code already exists")
+
+// buildRegulationExport collects a regulation with its sections and clauses and, when withHistory
+// is set, the edit history of every clause
+func buildRegulationExport(regulationID int, withHistory bool) (*models.RegulationExport, error) {
+	structure, err := fetchRegulationStructure(regulationID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := map[int][]models.RegulationExportHistory{}
+	if withHistory {
+		rows, err := db.DB.Query(`
+			SELECT h.clause_id, h.old_content, h.new_content, COALESCE(h.changed_by, ''), h.changed_at, COALESCE(h.change_reason, '')
+			FROM regulation_clause_history h
+			INNER JOIN regulation_clauses c ON c.id = h.clause_id
+			WHERE c.regulation_id = ?
+			ORDER BY h.changed_at, h.id`, regulationID)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var clauseID int
+			var h models.RegulationExportHistory
+			if err := rows.Scan(&clauseID, &h.OldContent, &h.NewContent, &h.ChangedBy, &h.ChangedAt, &h.ChangeReason); err != nil {
+				return nil, err
+			}
+			history[clauseID] = append(history[clauseID], h)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	export := &models.RegulationExport{
+		FormatVersion: models.RegulationExportFormat,
+		Code:          structure.Regulation.Code,
+		Name:          structure.Regulation.Name,
+		Status:        structure.Regulation.Status,
+		Sections:      []models.RegulationExportSection{},
+	}
+	for _, sec := range structure.Sections {
+		section := models.RegulationExportSection{
+			SectionNo:    sec.SectionNo,
+			Title:        sec.Title,
+			DisplayOrder: sec.DisplayOrder,
+			Clauses:      []models.RegulationExportClause{},
+		}
+		clauseNos := map[int]string{}
+		for _, c := range sec.Clauses {
+			clauseNos[c.ID] = c.ClauseNo
+		}
+		for _, c := range sec.Clauses {
+			clause := models.RegulationExportClause{
+				ClauseNo:     c.ClauseNo,
+				Title:        c.Title,
+				Content:      c.Content,
+				DisplayOrder: c.DisplayOrder,
+				History:      history[c.ID],
+			}
+			if c.ParentClauseID != nil {
+				clause.ParentClauseNo = clauseNos[*c.ParentClauseID]
+			}
+			section.Clauses = append(section.Clauses, clause)
+		}
+		export.Sections = append(export.Sections, section)
+	}
+	return export, nil
+}
+
+// validateRegulationExport checks a regulation before it is imported. A missing status becomes
+// DRAFT and a missing display order follows the position in the file
+func validateRegulationExport(data *models.RegulationExport) error {
+	if data.FormatVersion > models.RegulationExportFormat {
+		return fmt.Errorf("export format %d is newer than supported format %d", data.FormatVersion, models.RegulationExportFormat)
+	}
+	data.Code = strings.TrimSpace(data.Code)
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Code == "" || data.Name == "" {
+		return fmt.Errorf("code and name are required")
+	}
+	if len(data.Code) > 20 {
+		return fmt.Errorf("code must be at most 20 characters")
+	}
+	if len(data.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters")
+	}
+	if data.Status == "" {
+		data.Status = "DRAFT"
+	}
+	if data.Status != "DRAFT" && data.Status != "PUBLISHED" && data.Status != "LOCKED" {
+		return fmt.Errorf("status must be DRAFT, PUBLISHED or LOCKED")
+	}
+
+	sectionNos := map[int]bool{}
+	for i := range data.Sections {
+		sec := &data.Sections[i]
+		sec.Title = strings.TrimSpace(sec.Title)
+		if sec.SectionNo < 1 {
+			return fmt.Errorf("section %d: section_no must be a positive number", i+1)
+		}
+		if sectionNos[sec.SectionNo] {
+			return fmt.Errorf("section %d is listed more than once", sec.SectionNo)
+		}
+		sectionNos[sec.SectionNo] = true
+		if sec.Title == "" || len(sec.Title) > 255 {
+			return fmt.Errorf("section %d: title is required and must be at most 255 characters", sec.SectionNo)
+		}
+		if sec.DisplayOrder == 0 {
+			sec.DisplayOrder = i + 1
+		}
+
+		clauseNos := map[string]bool{}
+		for j := range sec.Clauses {
+			c := &sec.Clauses[j]
+			c.ClauseNo = strings.TrimSpace(c.ClauseNo)
+			if c.ClauseNo == "" || len(c.ClauseNo) > 10 {
+				return fmt.Errorf("section %d, clause %d: clause_no is required and must be at most 10 characters", sec.SectionNo, j+1)
+			}
+			if clauseNos[c.ClauseNo] {
+				return fmt.Errorf("section %d: clause %s is listed more than once", sec.SectionNo, c.ClauseNo)
+			}
+			clauseNos[c.ClauseNo] = true
+			if len(c.Title) > 255 {
+				return fmt.Errorf("clause %s: title must be at most 255 characters", c.ClauseNo)
+			}
+			if c.DisplayOrder == 0 {
+				c.DisplayOrder = j + 1
+			}
+		}
+		// Parents are checked once every clause number of the section is known
+		for _, c := range sec.Clauses {
+			if c.ParentClauseNo != "" && (!clauseNos[c.ParentClauseNo] || c.ParentClauseNo == c.ClauseNo) {
+				return fmt.Errorf("clause %s: parent clause %s is not in section %d", c.ClauseNo, c.ParentClauseNo, sec.SectionNo)
+			}
+		}
+	}
+	return nil
+}
+
+// importRegulation creates a new regulation from validated export data in one transaction and
+// returns its id
+func importRegulation(data *models.RegulationExport) (int, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow("SELECT 1 FROM regulations WHERE code = ?", data.Code).Scan(&exists)
+	if err == nil {
+		return 0, errRegulationCodeExists
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO regulations (code, name, status, created_at, updated_at)
+		VALUES (?, ?, ?, NOW(), NOW())`, data.Code, data.Name, data.Status)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create regulation: %w", err)
+	}
+	regulationID64, _ := result.LastInsertId()
+	regulationID := int(regulationID64)
+
+	for _, sec := range data.Sections {
+		result, err := tx.Exec(`
+			INSERT INTO regulation_sections (regulation_id, section_no, title, display_order, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NOW(), NOW())`, regulationID, sec.SectionNo, sec.Title, sec.DisplayOrder)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create section %d: %w", sec.SectionNo, err)
+		}
+		sectionID, _ := result.LastInsertId()
+
+		clauseIDs := map[string]int64{}
+		for _, c := range sec.Clauses {
+			result, err := tx.Exec(`
+				INSERT INTO regulation_clauses
+				(regulation_id, section_id, section_no, clause_no, title, content, display_order, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())`,
+				regulationID, sectionID, sec.SectionNo, c.ClauseNo, c.Title, c.Content, c.DisplayOrder)
+			if err != nil {
+				return 0, fmt.Errorf("failed to create clause %s: %w", c.ClauseNo, err)
+			}
+			clauseID, _ := result.LastInsertId()
+			clauseIDs[c.ClauseNo] = clauseID
+
+			for _, h := range c.History {
+				if _, err := tx.Exec(`
+					INSERT INTO regulation_clause_history
+					(clause_id, old_content, new_content, changed_by, changed_at, change_reason)
+					VALUES (?, ?, ?, ?, ?, ?)`,
+					clauseID, h.OldContent, h.NewContent, h.ChangedBy, h.ChangedAt, h.ChangeReason); err != nil {
+					return 0, fmt.Errorf("failed to copy history of clause %s: %w", c.ClauseNo, err)
+				}
+			}
+		}
+
+		// Link sub-clauses once their parents exist
+		for _, c := range sec.Clauses {
+			if c.ParentClauseNo == "" {
+				continue
+			}
+			if _, err := tx.Exec("UPDATE regulation_clauses SET parent_clause_id = ? WHERE id = ?",
+				clauseIDs[c.ParentClauseNo], clauseIDs[c.ClauseNo]); err != nil {
+				return 0, fmt.Errorf("failed to link clause %s to its parent: %w", c.ClauseNo, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return regulationID, nil
+}
+
+// regulationImportResult summarises what importing data creates
+func regulationImportResult(data *models.RegulationExport, warnings []string) models.RegulationImportResult {
+	result := models.RegulationImportResult{Regulation: *data, SectionCount: len(data.Sections), Warnings: warnings}
+	for _, sec := range data.Sections {
+		result.ClauseCount += len(sec.Clauses)
+	}
+	if result.Warnings == nil {
+		result.Warnings = []string{}
+	}
+	return result
+}
+
+// writeRegulationImport validates and imports data, or with ?preview=true only reports what would be created
+func writeRegulationImport(w http.ResponseWriter, r *http.Request, data *models.RegulationExport, warnings []string) {
+	if err := validateRegulationExport(data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := regulationImportResult(data, warnings)
+	if r.URL.Query().Get("preview") == "true" {
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	regulationID, err := importRegulation(data)
+	if err == errRegulationCodeExists {
+		http.Error(w, fmt.Sprintf("A regulation with code %s already exists", data.Code), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error importing regulation:", err)
+		http.Error(w, "Failed to import regulation", http.StatusInternalServerError)
+		return
+	}
+
+	result.RegulationID = regulationID
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ExportRegulation handles GET /api/regulations/{id}/export: the regulation with its sections
+// and clauses as a JSON file, including each clause's edit history with ?history=true
+func ExportRegulation(w http.ResponseWriter, r *http.Request) {
+	regulationID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid regulation ID", http.StatusBadRequest)
+		return
+	}
+
+	export, err := buildRegulationExport(regulationID, r.URL.Query().Get("history") == "true")
+	if err == sql.ErrNoRows {
+		http.Error(w, "Regulation not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error exporting regulation:", err)
+		http.Error(w, "Failed to export regulation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=Regulation_%s.json",
+		strings.ReplaceAll(export.Code, " ", "_")))
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(export)
+}
+
+// ImportRegulation handles POST /api/regulations/import with an exported regulation as the body.
+// ?code= and ?name= import it under another code and name, and ?preview=true only validates it
+func ImportRegulation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data models.RegulationExport
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid regulation export", http.StatusBadRequest)
+		return
+	}
+	if code := r.URL.Query().Get("code"); code != "" {
+		data.Code = code
+	}
+	if name := r.URL.Query().Get("name"); name != "" {
+		data.Name = name
+	}
+
+	writeRegulationImport(w, r, &data, nil)
+}
+
+// ImportRegulationMarkdown handles POST /api/regulations/import/markdown: headings become sections
+// and numbered paragraphs become clauses. With ?preview=true the parsed regulation and any
+// warnings are returned without creating it
+func ImportRegulationMarkdown(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body models.RegulationMarkdownImport
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Markdown) == "" {
+		http.Error(w, "markdown is required", http.StatusBadRequest)
+		return
+	}
+
+	sections, warnings, err := parseRegulationMarkdown(body.Markdown)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data := models.RegulationExport{
+		FormatVersion: models.RegulationExportFormat,
+		Code:          body.Code,
+		Name:          body.Name,
+		Status:        body.Status,
+		Sections:      sections,
+	}
+	writeRegulationImport(w, r, &data, warnings)
+}
+
+var (
+	// markdownHeading matches "## 4. Attendance", with optional closing hashes
+	markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	// markdownSectionNumber splits a heading like "4. Attendance" or "Section 4: Attendance"
+	markdownSectionNumber = regexp.MustCompile(`(?i)^(?:section\s+)?(\d+)[.):]?\s+(.+)$`)
+	// markdownClause matches a numbered paragraph: "4.1 Text", "4.1. Text", "3. Text" or "3) Text"
+	markdownClause = regexp.MustCompile(`^(\d+(?:\.\d+)+\.?|\d+[.)])\s+(.*)$`)
+	// markdownClauseTitle splits a bold title off the start of a clause: "**Attendance** Text",
+	// "**Attendance:** Text"
+	markdownClauseTitle = regexp.MustCompile(`^\*\*(.+?)\*\*[:.]?\s*(.*)$`)
+)
+
+// parseRegulationMarkdown turns Markdown into sections and clauses. Every heading starts a section,
+// numbered by the number in its text or else following the previous one. Every numbered paragraph
+// starts a clause, and the lines after it up to the next clause or heading are its content; a
+// clause numbered under an earlier one (4.2.1 after 4.2) is its sub-clause. Text that belongs to
+// no clause is skipped with a warning
+func parseRegulationMarkdown(markdown string) ([]models.RegulationExportSection, []string, error) {
+	sections := []models.RegulationExportSection{}
+	warnings := []string{}
+	var section *models.RegulationExportSection
+	var clause *models.RegulationExportClause
+	var content []string
+	sectionNos := map[int]bool{}
+	clauseNos := map[string]bool{}
+
+	// finishClause stores the content gathered for the current clause
+	finishClause := func() {
+		if clause == nil {
+			return
+		}
+		clause.Content = strings.TrimSpace(strings.Join(content, "\n"))
+		if clause.Content == "" && clause.Title == "" {
+			warnings = append(warnings, fmt.Sprintf("clause %s has no text", clause.ClauseNo))
+		}
+		section.Clauses = append(section.Clauses, *clause)
+		clause = nil
+		content = nil
+	}
+	finishSection := func() {
+		finishClause()
+		if section != nil {
+			sections = append(sections, *section)
+			section = nil
+		}
+	}
+
+	inFence := false
+	for i, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+
+		if m := markdownHeading.FindStringSubmatch(trimmed); m != nil && !inFence {
+			finishSection()
+			title := m[2]
+			sectionNo := len(sections) + 1
+			if len(sections) > 0 {
+				sectionNo = sections[len(sections)-1].SectionNo + 1
+			}
+			if n := markdownSectionNumber.FindStringSubmatch(title); n != nil {
+				sectionNo, _ = strconv.Atoi(n[1])
+				title = n[2]
+			}
+			title = strings.Trim(title, "* ")
+			if title == "" {
+				return nil, nil, fmt.Errorf("line %d: heading has no title", lineNo)
+			}
+			if sectionNos[sectionNo] {
+				return nil, nil, fmt.Errorf("line %d: section %d is used more than once", lineNo, sectionNo)
+			}
+			sectionNos[sectionNo] = true
+			clauseNos = map[string]bool{}
+			section = &models.RegulationExportSection{
+				SectionNo:    sectionNo,
+				Title:        title,
+				DisplayOrder: len(sections) + 1,
+				Clauses:      []models.RegulationExportClause{},
+			}
+			continue
+		}
+
+		if m := markdownClause.FindStringSubmatch(trimmed); m != nil && !inFence {
+			if section == nil {
+				warnings = append(warnings, fmt.Sprintf("line %d: numbered paragraph before the first heading was skipped", lineNo))
+				continue
+			}
+			finishClause()
+			clauseNo := strings.TrimRight(m[1], ".)")
+			if len(clauseNo) > 10 {
+				return nil, nil, fmt.Errorf("line %d: clause number %s is longer than 10 characters", lineNo, clauseNo)
+			}
+			if clauseNos[clauseNo] {
+				return nil, nil, fmt.Errorf("line %d: clause %s is used more than once in section %d", lineNo, clauseNo, section.SectionNo)
+			}
+			clauseNos[clauseNo] = true
+			clause = &models.RegulationExportClause{ClauseNo: clauseNo, DisplayOrder: len(section.Clauses) + 1}
+			// 4.2.1 is a sub-clause of 4.2 when that clause came before it
+			if i := strings.LastIndex(clauseNo, "."); i > 0 && clauseNos[clauseNo[:i]] {
+				clause.ParentClauseNo = clauseNo[:i]
+			}
+			text := m[2]
+			if t := markdownClauseTitle.FindStringSubmatch(text); t != nil {
+				clause.Title = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(t[1]), ":"))
+				text = t[2]
+			}
+			if text != "" {
+				content = append(content, text)
+			}
+			continue
+		}
+
+		if clause != nil {
+			// Keep a single blank line between paragraphs
+			if trimmed == "" && (len(content) == 0 || content[len(content)-1] == "") {
+				continue
+			}
+			content = append(content, strings.TrimRight(line, " \t"))
+			continue
+		}
+		if trimmed != "" {
+			if section == nil {
+				warnings = append(warnings, fmt.Sprintf("line %d: text before the first heading was skipped", lineNo))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("line %d: text outside a numbered clause in section %d was skipped", lineNo, section.SectionNo))
+			}
+		}
+	}
+	finishSection()
+
+	if len(sections) == 0 {
+		return nil, nil, fmt.Errorf("no headings found; every section must start with a Markdown heading")
+	}
+	return sections, warnings, nil
+}
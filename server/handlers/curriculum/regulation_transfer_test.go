@@ -0,0 +1,102 @@
+package curriculum
+
+import (
+	"reflect"
+	"server/models"
+	"strings"
+	"testing"
+)
+
+func TestParseRegulationMarkdown(t *testing.T) {
+	markdown := strings.Join([]string{
+		"## 1. Admission",
+		"1.1 **Eligibility** Candidates shall have passed the",
+		"higher secondary examination.",
+		"",
+		"",
+		"Lateral entry is open to diploma holders.",
+		"1.2 Admission is made through counselling.",
+		"## Section 4: Attendance ##",
+		"4.1 **Minimum attendance:** 75% in every course.",
+		"4.1.1 Medical leave counts as attendance.",
+		"4.2. Shortage of attendance",
+		"```",
+		"5.1 not a clause",
+		"```",
+		"### Examinations",
+		"1) Every course has an end semester examination.",
+	}, "\r\n")
+
+	sections, warnings, err := parseRegulationMarkdown(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.RegulationExportSection{
+		{SectionNo: 1, Title: "Admission", DisplayOrder: 1, Clauses: []models.RegulationExportClause{
+			{ClauseNo: "1.1", Title: "Eligibility", Content: "Candidates shall have passed the\nhigher secondary examination.\n\nLateral entry is open to diploma holders.", DisplayOrder: 1},
+			{ClauseNo: "1.2", Content: "Admission is made through counselling.", DisplayOrder: 2},
+		}},
+		{SectionNo: 4, Title: "Attendance", DisplayOrder: 2, Clauses: []models.RegulationExportClause{
+			{ClauseNo: "4.1", Title: "Minimum attendance", Content: "75% in every course.", DisplayOrder: 1},
+			{ClauseNo: "4.1.1", ParentClauseNo: "4.1", Content: "Medical leave counts as attendance.", DisplayOrder: 2},
+			{ClauseNo: "4.2", Content: "Shortage of attendance\n```\n5.1 not a clause\n```", DisplayOrder: 3},
+		}},
+		{SectionNo: 5, Title: "Examinations", DisplayOrder: 3, Clauses: []models.RegulationExportClause{
+			{ClauseNo: "1", Content: "Every course has an end semester examination.", DisplayOrder: 1},
+		}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("sections = %+v\nwant %+v", sections, want)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+}
+
+func TestParseRegulationMarkdownWarnings(t *testing.T) {
+	markdown := strings.Join([]string{
+		"Preamble text",
+		"1.1 Numbered before any heading",
+		"## 1. General",
+		"Introduction without a number",
+		"1.1 **Definitions**",
+		"1.2",
+	}, "\n")
+
+	sections, warnings, err := parseRegulationMarkdown(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 1 || len(sections[0].Clauses) != 1 || sections[0].Clauses[0].Title != "Definitions" {
+		t.Errorf("sections = %+v", sections)
+	}
+	want := []string{
+		"line 1: text before the first heading was skipped",
+		"line 2: numbered paragraph before the first heading was skipped",
+		"line 4: text outside a numbered clause in section 1 was skipped",
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+}
+
+func TestParseRegulationMarkdownErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		err      string
+	}{
+		{"no headings", "1.1 Some clause", "no headings found"},
+		{"empty heading", "## **", "line 1: heading has no title"},
+		{"repeated section", "## 2. General\n## 2. Again", "line 2: section 2 is used more than once"},
+		{"repeated clause", "## General\n1.1 First\n1.1 Again", "line 3: clause 1.1 is used more than once in section 1"},
+		{"long clause number", "## General\n1.2.3.4.5.6 Deep", "line 2: clause number 1.2.3.4.5.6 is longer than 10 characters"},
+	}
+	for _, tt := range tests {
+		_, _, err := parseRegulationMarkdown(tt.markdown)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
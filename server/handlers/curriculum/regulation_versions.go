@@ -0,0 +1,522 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"server/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// CloneRegulation handles POST /api/regulations/{id}/clone with {"code", "name"}: a new DRAFT
+// regulation with copies of all sections and clauses, to start the next version from. Clause
+// history is not copied
+func CloneRegulation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	regulationID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid regulation ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	data, err := buildRegulationExport(regulationID, false)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Regulation not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching regulation to clone:", err)
+		http.Error(w, "Failed to clone regulation", http.StatusInternalServerError)
+		return
+	}
+	data.Code = body.Code
+	data.Name = body.Name
+	data.Status = "DRAFT"
+	if err := validateRegulationExport(data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cloneID, err := importRegulation(data)
+	if err == errRegulationCodeExists {
+		http.Error(w, fmt.Sprintf("A regulation with code %s already exists", data.Code), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error cloning regulation:", err)
+		http.Error(w, "Failed to clone regulation", http.StatusInternalServerError)
+		return
+	}
+
+	clone, err := fetchRegulationStructure(cloneID)
+	if err != nil {
+		log.Println("Error fetching cloned regulation:", err)
+		http.Error(w, "Failed to fetch cloned regulation", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(clone)
+}
+
+// CompareRegulations handles GET /api/regulations/{id}/compare?to=: the sections and clauses that
+// differ from regulation {id} to regulation ?to, as JSON or, with ?format=html, as a page
+func CompareRegulations(w http.ResponseWriter, r *http.Request) {
+	fromID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid regulation ID", http.StatusBadRequest)
+		return
+	}
+	toID, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid to regulation", http.StatusBadRequest)
+		return
+	}
+
+	structures := make([]*models.RegulationStructure, 2)
+	for i, id := range []int{fromID, toID} {
+		structures[i], err = fetchRegulationStructure(id)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Regulation not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Println("Error fetching regulation to compare:", err)
+			http.Error(w, "Failed to compare regulations", http.StatusInternalServerError)
+			return
+		}
+	}
+	diff := diffRegulations(structures[0], structures[1])
+
+	if r.URL.Query().Get("format") == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := regulationDiffTemplate.Execute(w, diff); err != nil {
+			log.Println("Error rendering regulation diff:", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// regulationClauseKey matches a clause across regulations by its section and clause number
+func regulationClauseKey(sectionNo int, clauseNo string) string {
+	return fmt.Sprintf("%d/%s", sectionNo, clauseNo)
+}
+
+// clauseMatchThreshold is how alike (0-1, by shared words) two clauses must be to be taken
+// for the same clause when their numbers differ
+const clauseMatchThreshold = 0.6
+
+// numberedClause is a clause with the number of the section it is in
+type numberedClause struct {
+	sectionNo int
+	clause    models.RegulationClause
+}
+
+func (c numberedClause) key() string {
+	return regulationClauseKey(c.sectionNo, c.clause.ClauseNo)
+}
+
+func flattenRegulationClauses(reg *models.RegulationStructure) []numberedClause {
+	clauses := []numberedClause{}
+	for _, sec := range reg.Sections {
+		for _, c := range sec.Clauses {
+			clauses = append(clauses, numberedClause{sec.SectionNo, c})
+		}
+	}
+	return clauses
+}
+
+// clauseText is what clauses are compared by: title and content with whitespace collapsed
+func clauseText(c models.RegulationClause) string {
+	return strings.Join(strings.Fields(c.Title+" "+c.Content), " ")
+}
+
+// clauseSimilarity is the Dice coefficient of the words of two clauses: 1 for the same words, 0 for none shared
+func clauseSimilarity(a, b string) float64 {
+	wordsA := strings.Fields(strings.ToLower(a))
+	wordsB := strings.Fields(strings.ToLower(b))
+	if len(wordsA)+len(wordsB) == 0 {
+		return 1
+	}
+	counts := make(map[string]int)
+	for _, w := range wordsA {
+		counts[w]++
+	}
+	shared := 0
+	for _, w := range wordsB {
+		if counts[w] > 0 {
+			counts[w]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(wordsA)+len(wordsB))
+}
+
+// alignClauses pairs each clause of the newer regulation with the clause of the older one it
+// continues, returning new index -> old index. Clauses are matched by content first, so a clause
+// that was renumbered or moved to another section is still recognised: identical text, then the
+// most similar text above clauseMatchThreshold, and only then the same section and clause number.
+// Ties go to the clause with the same number
+func alignClauses(oldClauses, newClauses []numberedClause) map[int]int {
+	pairs := make(map[int]int)
+	usedOld := make(map[int]bool)
+	oldText := make([]string, len(oldClauses))
+	for i, c := range oldClauses {
+		oldText[i] = clauseText(c.clause)
+	}
+	newText := make([]string, len(newClauses))
+	for j, c := range newClauses {
+		newText[j] = clauseText(c.clause)
+	}
+
+	type candidate struct {
+		oldIdx, newIdx int
+		score          float64
+		sameKey        bool
+	}
+	candidates := []candidate{}
+	for j, n := range newClauses {
+		for i, o := range oldClauses {
+			score := clauseSimilarity(oldText[i], newText[j])
+			if score >= clauseMatchThreshold {
+				candidates = append(candidates, candidate{i, j, score, o.key() == n.key()})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(x, y int) bool {
+		if candidates[x].score != candidates[y].score {
+			return candidates[x].score > candidates[y].score
+		}
+		return candidates[x].sameKey && !candidates[y].sameKey
+	})
+	for _, c := range candidates {
+		if _, taken := pairs[c.newIdx]; taken || usedOld[c.oldIdx] {
+			continue
+		}
+		pairs[c.newIdx] = c.oldIdx
+		usedOld[c.oldIdx] = true
+	}
+
+	// Rewritten clauses keep their place: fall back to the number
+	oldByKey := make(map[string]int)
+	for i, c := range oldClauses {
+		if !usedOld[i] {
+			oldByKey[c.key()] = i
+		}
+	}
+	for j, c := range newClauses {
+		if _, taken := pairs[j]; taken {
+			continue
+		}
+		if i, ok := oldByKey[c.key()]; ok && !usedOld[i] {
+			pairs[j] = i
+			usedOld[i] = true
+		}
+	}
+	return pairs
+}
+
+// diffRegulations compares two regulations. Changed clauses are listed by section, in the order
+// of the newer regulation with removed clauses after those of their section
+func diffRegulations(from, to *models.RegulationStructure) models.RegulationDiff {
+	diff := models.RegulationDiff{
+		From:     from.Regulation,
+		To:       to.Regulation,
+		Sections: []models.RegulationSectionDiff{},
+		Clauses:  []models.RegulationClauseDiff{},
+	}
+
+	oldSections := map[int]string{}
+	for _, sec := range from.Sections {
+		oldSections[sec.SectionNo] = sec.Title
+	}
+	newSections := map[int]bool{}
+	for _, sec := range to.Sections {
+		newSections[sec.SectionNo] = true
+		if oldTitle, ok := oldSections[sec.SectionNo]; !ok {
+			diff.Sections = append(diff.Sections, models.RegulationSectionDiff{SectionNo: sec.SectionNo, Change: "added", NewTitle: sec.Title})
+		} else if strings.TrimSpace(oldTitle) != strings.TrimSpace(sec.Title) {
+			diff.Sections = append(diff.Sections, models.RegulationSectionDiff{SectionNo: sec.SectionNo, Change: "renamed", OldTitle: oldTitle, NewTitle: sec.Title})
+		}
+	}
+	for _, sec := range from.Sections {
+		if !newSections[sec.SectionNo] {
+			diff.Sections = append(diff.Sections, models.RegulationSectionDiff{SectionNo: sec.SectionNo, Change: "removed", OldTitle: sec.Title})
+		}
+	}
+
+	oldClauses := flattenRegulationClauses(from)
+	newClauses := flattenRegulationClauses(to)
+	pairs := alignClauses(oldClauses, newClauses)
+	matchedOld := make(map[int]bool)
+
+	for j, n := range newClauses {
+		i, ok := pairs[j]
+		if !ok {
+			diff.Clauses = append(diff.Clauses, clauseDiff(n.sectionNo, n.clause.ClauseNo, "added", models.RegulationClause{}, n.clause))
+			diff.Summary.Added++
+			continue
+		}
+		matchedOld[i] = true
+		o := oldClauses[i]
+		textChanged := strings.TrimSpace(o.clause.Title) != strings.TrimSpace(n.clause.Title) ||
+			strings.TrimSpace(o.clause.Content) != strings.TrimSpace(n.clause.Content)
+		moved := o.key() != n.key()
+		var d models.RegulationClauseDiff
+		switch {
+		case textChanged:
+			d = clauseDiff(n.sectionNo, n.clause.ClauseNo, "modified", o.clause, n.clause)
+			diff.Summary.Modified++
+		case moved:
+			d = clauseDiff(n.sectionNo, n.clause.ClauseNo, "moved", o.clause, n.clause)
+			diff.Summary.Moved++
+		default:
+			diff.Summary.Unchanged++
+			continue
+		}
+		if moved {
+			d.OldSectionNo = o.sectionNo
+			d.OldClauseNo = o.clause.ClauseNo
+		}
+		diff.Clauses = append(diff.Clauses, d)
+	}
+
+	for i, o := range oldClauses {
+		if !matchedOld[i] {
+			diff.Clauses = append(diff.Clauses, clauseDiff(o.sectionNo, o.clause.ClauseNo, "removed", o.clause, models.RegulationClause{}))
+			diff.Summary.Removed++
+		}
+	}
+
+	// Keep each section's clauses together; the order within a section is kept
+	sort.SliceStable(diff.Clauses, func(i, j int) bool { return diff.Clauses[i].SectionNo < diff.Clauses[j].SectionNo })
+	sort.SliceStable(diff.Sections, func(i, j int) bool { return diff.Sections[i].SectionNo < diff.Sections[j].SectionNo })
+	return diff
+}
+
+// clauseDiff describes a changed clause; an added clause has an empty old clause and a removed one an empty new one
+func clauseDiff(sectionNo int, clauseNo, change string, oldClause, newClause models.RegulationClause) models.RegulationClauseDiff {
+	d := models.RegulationClauseDiff{
+		SectionNo:   sectionNo,
+		ClauseNo:    clauseNo,
+		Change:      change,
+		OldTitle:    oldClause.Title,
+		NewTitle:    newClause.Title,
+		OldContent:  oldClause.Content,
+		NewContent:  newClause.Content,
+		ContentDiff: diffWords(oldClause.Content, newClause.Content),
+	}
+	if oldClause.Title != newClause.Title {
+		d.TitleDiff = diffWords(oldClause.Title, newClause.Title)
+	}
+	return d
+}
+
+// diffWord is a word with the whitespace after it
+var diffWord = regexp.MustCompile(`\S+\s*`)
+
+// wordDiff holds the words being compared and the segments written so far
+type wordDiff struct {
+	a, b       []string // words with their trailing whitespace
+	keyA, keyB []string // the same words without it, which are what is compared
+	segments   []models.DiffSegment
+}
+
+// add appends text, merging it into the last segment when the operation is the same
+func (d *wordDiff) add(op, text string) {
+	if n := len(d.segments); n > 0 && d.segments[n-1].Op == op {
+		d.segments[n-1].Text += text
+		return
+	}
+	d.segments = append(d.segments, models.DiffSegment{Op: op, Text: text})
+}
+
+// lcsRow returns, for every j, the length of the longest common subsequence of keyA[alo:ahi]
+// and keyB[blo:blo+j], keeping only two rows of the table. Backwards it compares the suffixes
+// keyA[alo:ahi] and keyB[bhi-j:bhi] instead
+func (d *wordDiff) lcsRow(alo, ahi, blo, bhi int, backwards bool) []int {
+	n := bhi - blo
+	prev := make([]int, n+1)
+	cur := make([]int, n+1)
+	for x := 0; x < ahi-alo; x++ {
+		i := alo + x
+		if backwards {
+			i = ahi - 1 - x
+		}
+		for y := 1; y <= n; y++ {
+			j := blo + y - 1
+			if backwards {
+				j = bhi - y
+			}
+			if d.keyA[i] == d.keyB[j] {
+				cur[y] = prev[y-1] + 1
+			} else {
+				cur[y] = max(prev[y], cur[y-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// diff writes the diff of a[alo:ahi] against b[blo:bhi] with Hirschberg's algorithm: split a in
+// half, find where the best alignment crosses b from the two halves' LCS rows, and recurse.
+// It finds a longest common subsequence like the full table does, in linear space
+func (d *wordDiff) diff(alo, ahi, blo, bhi int) {
+	// Shared prefix and suffix need no table
+	for alo < ahi && blo < bhi && d.keyA[alo] == d.keyB[blo] {
+		d.add("equal", d.b[blo])
+		alo++
+		blo++
+	}
+	suffix := 0
+	for alo < ahi-suffix && blo < bhi-suffix && d.keyA[ahi-1-suffix] == d.keyB[bhi-1-suffix] {
+		suffix++
+	}
+	ahi -= suffix
+	bhi -= suffix
+
+	switch {
+	case alo == ahi:
+		for j := blo; j < bhi; j++ {
+			d.add("insert", d.b[j])
+		}
+	case blo == bhi:
+		for i := alo; i < ahi; i++ {
+			d.add("delete", d.a[i])
+		}
+	case ahi-alo == 1:
+		match := -1
+		for j := blo; j < bhi; j++ {
+			if d.keyA[alo] == d.keyB[j] {
+				match = j
+				break
+			}
+		}
+		if match < 0 {
+			d.add("delete", d.a[alo])
+			for j := blo; j < bhi; j++ {
+				d.add("insert", d.b[j])
+			}
+			break
+		}
+		for j := blo; j < match; j++ {
+			d.add("insert", d.b[j])
+		}
+		d.add("equal", d.b[match])
+		for j := match + 1; j < bhi; j++ {
+			d.add("insert", d.b[j])
+		}
+	default:
+		mid := (alo + ahi) / 2
+		forward := d.lcsRow(alo, mid, blo, bhi, false)
+		backward := d.lcsRow(mid, ahi, blo, bhi, true)
+		split, best := blo, -1
+		for k := 0; k <= bhi-blo; k++ {
+			if total := forward[k] + backward[bhi-blo-k]; total > best {
+				split, best = blo+k, total
+			}
+		}
+		d.diff(alo, mid, blo, split)
+		d.diff(mid, ahi, split, bhi)
+	}
+
+	for j := bhi; j < bhi+suffix; j++ {
+		d.add("equal", d.b[j])
+	}
+}
+
+// diffWords is a word-level diff from old to new. Words are compared without the whitespace
+// around them, and runs with the same operation are merged
+func diffWords(oldText, newText string) []models.DiffSegment {
+	d := &wordDiff{
+		a:        diffWord.FindAllString(oldText, -1),
+		b:        diffWord.FindAllString(newText, -1),
+		segments: []models.DiffSegment{},
+	}
+	for _, w := range d.a {
+		d.keyA = append(d.keyA, strings.TrimSpace(w))
+	}
+	for _, w := range d.b {
+		d.keyB = append(d.keyB, strings.TrimSpace(w))
+	}
+	d.diff(0, len(d.a), 0, len(d.b))
+	return d.segments
+}
+
+var regulationDiffTemplate = template.Must(template.New("regulation_diff").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>{{.From.Code}} to {{.To.Code}}</title>
+<style>
+	body { font-family: 'Times New Roman', serif; font-size: 12pt; margin: 24px; line-height: 1.5; }
+	h1 { font-size: 16pt; text-align: center; }
+	h2 { font-size: 14pt; margin-top: 24px; }
+	.summary { text-align: center; color: #444; }
+	.clause { border: 1px solid #ccc; margin: 12px 0; padding: 8px 12px; }
+	.clause-header { font-weight: bold; margin-bottom: 4px; }
+	.change { font-size: 9pt; text-transform: uppercase; padding: 1px 6px; margin-left: 8px; }
+	.added .change { background: #d4f4d4; }
+	.removed .change { background: #f8d4d4; }
+	.modified .change { background: #f8ecc4; }
+	.moved .change { background: #d4e4f8; }
+	.moved-from { font-weight: normal; font-size: 9pt; color: #666; margin-left: 8px; }
+	.content { white-space: pre-wrap; }
+	ins { background: #d4f4d4; text-decoration: none; }
+	del { background: #f8d4d4; }
+</style>
+</head>
+<body>
+<h1>{{.From.Code}} – {{.From.Name}} → {{.To.Code}} – {{.To.Name}}</h1>
+<p class="summary">{{.Summary.Added}} added, {{.Summary.Removed}} removed, {{.Summary.Modified}} modified, {{.Summary.Moved}} moved, {{.Summary.Unchanged}} unchanged clauses</p>
+
+{{if .Sections}}
+<h2>Sections</h2>
+<ul>
+{{range .Sections}}
+	<li>Section {{.SectionNo}}:
+	{{if eq .Change "added"}}<ins>{{.NewTitle}}</ins> added
+	{{else if eq .Change "removed"}}<del>{{.OldTitle}}</del> removed
+	{{else}}renamed from <del>{{.OldTitle}}</del> to <ins>{{.NewTitle}}</ins>{{end}}</li>
+{{end}}
+</ul>
+{{end}}
+
+<h2>Clauses</h2>
+{{range .Clauses}}
+<div class="clause {{.Change}}">
+	<div class="clause-header">
+		{{.ClauseNo}}
+		{{if .TitleDiff}}{{range .TitleDiff}}{{if eq .Op "insert"}}<ins>{{.Text}}</ins>{{else if eq .Op "delete"}}<del>{{.Text}}</del>{{else}}{{.Text}}{{end}}{{end}}{{else}}{{.NewTitle}}{{end}}
+		<span class="change">{{.Change}}</span>
+		{{if .OldClauseNo}}<span class="moved-from">was {{.OldClauseNo}} in section {{.OldSectionNo}}</span>{{end}}
+	</div>
+	<div class="content">{{range .ContentDiff}}{{if eq .Op "insert"}}<ins>{{.Text}}</ins>{{else if eq .Op "delete"}}<del>{{.Text}}</del>{{else}}{{.Text}}{{end}}{{end}}</div>
+</div>
+{{else}}
+<p>No clauses differ.</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,208 @@
+package curriculum
+
+import (
+	"server/models"
+	"strings"
+	"testing"
+)
+
+// lcsLength is the textbook table, to check diffWords keeps as many words as it can
+func lcsLength(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				table[i][j] = table[i-1][j-1] + 1
+			} else {
+				table[i][j] = max(table[i-1][j], table[i][j-1])
+			}
+		}
+	}
+	return table[len(a)][len(b)]
+}
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+	}{
+		{"same", "A student shall attend classes", "A student shall attend classes"},
+		{"both empty", "", ""},
+		{"added text", "", "Attendance is compulsory"},
+		{"removed text", "Attendance is compulsory", ""},
+		{"word replaced", "minimum of 75% attendance", "minimum of 80% attendance"},
+		{"words inserted", "shall register for the examination", "shall register online for the end semester examination"},
+		{"reordered", "a b c d e f g", "g f e d c b a"},
+		{"repeated words", "the the course the grade", "the course the the grade the"},
+		{"whitespace only", "credits  are\nawarded", "credits are awarded"},
+		{"rewritten", "Grades are awarded on a ten point scale", "The CGPA is computed from all registered credits"},
+	}
+	for _, tt := range tests {
+		segments := diffWords(tt.old, tt.new)
+
+		var oldText, newText strings.Builder
+		kept := 0
+		for i, s := range segments {
+			if i > 0 && segments[i-1].Op == s.Op {
+				t.Errorf("%s: segments %d and %d are both %s", tt.name, i-1, i, s.Op)
+			}
+			switch s.Op {
+			case "equal":
+				oldText.WriteString(s.Text)
+				newText.WriteString(s.Text)
+				kept += len(strings.Fields(s.Text))
+			case "delete":
+				oldText.WriteString(s.Text)
+			case "insert":
+				newText.WriteString(s.Text)
+			}
+		}
+		if got := strings.Fields(oldText.String()); strings.Join(got, " ") != strings.Join(strings.Fields(tt.old), " ") {
+			t.Errorf("%s: old words rebuilt as %q", tt.name, oldText.String())
+		}
+		if newText.String() != strings.Join(diffWord.FindAllString(tt.new, -1), "") {
+			t.Errorf("%s: new text rebuilt as %q, want %q", tt.name, newText.String(), tt.new)
+		}
+		if want := lcsLength(strings.Fields(tt.old), strings.Fields(tt.new)); kept != want {
+			t.Errorf("%s: %d words kept, want %d", tt.name, kept, want)
+		}
+	}
+}
+
+func TestDiffWordsLongText(t *testing.T) {
+	// A clause of a few thousand words used to need a table of millions of cells
+	var oldWords, newWords []string
+	for i := 0; i < 3000; i++ {
+		word := []string{"credit", "grade", "semester", "course", "student"}[i%5]
+		oldWords = append(oldWords, word)
+		if i%7 != 0 {
+			newWords = append(newWords, word)
+		}
+		if i%11 == 0 {
+			newWords = append(newWords, "revised")
+		}
+	}
+	kept := 0
+	for _, s := range diffWords(strings.Join(oldWords, " "), strings.Join(newWords, " ")) {
+		if s.Op == "equal" {
+			kept += len(strings.Fields(s.Text))
+		}
+	}
+	if want := lcsLength(oldWords, newWords); kept != want {
+		t.Errorf("%d words kept, want %d", kept, want)
+	}
+}
+
+func regulationWithClauses(sections ...models.RegulationSectionWithClauses) *models.RegulationStructure {
+	return &models.RegulationStructure{Sections: sections}
+}
+
+func sectionWithClauses(sectionNo int, title string, clauses ...models.RegulationClause) models.RegulationSectionWithClauses {
+	return models.RegulationSectionWithClauses{
+		RegulationSection: models.RegulationSection{SectionNo: sectionNo, Title: title},
+		Clauses:           clauses,
+	}
+}
+
+func TestDiffRegulations(t *testing.T) {
+	attendance := models.RegulationClause{ClauseNo: "1", Title: "Attendance", Content: "A student shall have a minimum of 75% attendance in every course to write the end semester examination"}
+	grading := models.RegulationClause{ClauseNo: "2", Title: "Grading", Content: "Grades are awarded on a ten point scale from O to RA for every course"}
+	credits := models.RegulationClause{ClauseNo: "3", Title: "Credits", Content: "The total credits for the programme shall be 160 spread over eight semesters"}
+
+	renumbered := func(c models.RegulationClause, no string) models.RegulationClause {
+		c.ClauseNo = no
+		return c
+	}
+
+	from := regulationWithClauses(
+		sectionWithClauses(1, "General", attendance, grading, credits),
+	)
+
+	tests := []struct {
+		name    string
+		to      *models.RegulationStructure
+		want    []string // change and clause of every listed clause
+		summary models.RegulationDiffSummary
+	}{
+		{
+			"unchanged",
+			from,
+			[]string{},
+			models.RegulationDiffSummary{Unchanged: 3},
+		},
+		{
+			"clause inserted before the others",
+			regulationWithClauses(sectionWithClauses(1, "General",
+				models.RegulationClause{ClauseNo: "1", Title: "Eligibility", Content: "Candidates shall have passed the higher secondary examination"},
+				renumbered(attendance, "2"), renumbered(grading, "3"), renumbered(credits, "4"),
+			)),
+			[]string{"added 1/1", "moved 1/2 from 1/1", "moved 1/3 from 1/2", "moved 1/4 from 1/3"},
+			models.RegulationDiffSummary{Added: 1, Moved: 3},
+		},
+		{
+			"clause edited and renumbered",
+			regulationWithClauses(sectionWithClauses(1, "General",
+				models.RegulationClause{ClauseNo: "1", Title: "Attendance", Content: "A student shall have a minimum of 80% attendance in every course to write the end semester examination"},
+				renumbered(credits, "2"),
+			)),
+			[]string{"modified 1/1", "moved 1/2 from 1/3", "removed 1/2"},
+			models.RegulationDiffSummary{Modified: 1, Moved: 1, Removed: 1},
+		},
+		{
+			"clause moved to a new section",
+			regulationWithClauses(
+				sectionWithClauses(1, "General", attendance, credits),
+				sectionWithClauses(2, "Evaluation", renumbered(grading, "1")),
+			),
+			[]string{"moved 2/1 from 1/2"},
+			models.RegulationDiffSummary{Moved: 1, Unchanged: 2},
+		},
+		{
+			"clause rewritten in place",
+			regulationWithClauses(sectionWithClauses(1, "General",
+				attendance,
+				models.RegulationClause{ClauseNo: "2", Title: "Grading", Content: "Performance is graded relatively using the class mean and standard deviation"},
+				credits,
+			)),
+			[]string{"modified 1/2"},
+			models.RegulationDiffSummary{Modified: 1, Unchanged: 2},
+		},
+	}
+	for _, tt := range tests {
+		diff := diffRegulations(from, tt.to)
+		got := []string{}
+		for _, c := range diff.Clauses {
+			entry := c.Change + " " + regulationClauseKey(c.SectionNo, c.ClauseNo)
+			if c.OldClauseNo != "" {
+				entry += " from " + regulationClauseKey(c.OldSectionNo, c.OldClauseNo)
+			}
+			got = append(got, entry)
+		}
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("%s: clauses = %v, want %v", tt.name, got, tt.want)
+		}
+		if diff.Summary != tt.summary {
+			t.Errorf("%s: summary = %+v, want %+v", tt.name, diff.Summary, tt.summary)
+		}
+	}
+}
+
+func TestClauseSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"minimum attendance", "Minimum  attendance", 1},
+		{"minimum attendance", "grading scale", 0},
+		{"a b c d", "a b c e", 0.75},
+	}
+	for _, tt := range tests {
+		if got := clauseSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("clauseSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
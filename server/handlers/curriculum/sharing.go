@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"server/db"
 
@@ -170,7 +171,7 @@ func fetchCoursesForSemester(regulationID, semesterID int) []map[string]interfac
 		SELECT c.course_id, c.course_code, c.course_name, COALESCE(c.visibility, 'UNIQUE') as visibility
 		FROM courses c
 		JOIN curriculum_courses cc ON c.course_id = cc.course_id
-		WHERE cc.curriculum_id = ? AND cc.semester_id = ?
+		WHERE cc.curriculum_id = ? AND cc.semester_id = ? AND cc.status = 1
 		ORDER BY c.course_code
 	`
 	rows, err := db.DB.Query(query, regulationID, semesterID)
@@ -433,6 +434,22 @@ func UpdateItemVisibility(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Record the sharing change on the owning curriculum once it succeeds
+	curriculumID, before := sharingLogState(reqData.ItemType, reqData.ItemID)
+	logSharing := func() {
+		if curriculumID == 0 {
+			return
+		}
+		operation, action := opShare, "Item Shared"
+		if reqData.Visibility == "UNIQUE" || reqData.SharingMode == "remove" {
+			operation, action = opUnshare, "Item Unshared"
+		}
+		_, after := sharingLogState(reqData.ItemType, reqData.ItemID)
+		logCurriculumChange(curriculumID, logEntity{reqData.ItemType, reqData.ItemID, operation}, action,
+			fmt.Sprintf("Changed sharing of %s %d to %s", strings.ReplaceAll(reqData.ItemType, "_", " "), reqData.ItemID, reqData.Visibility),
+			requestUser(r), before, after)
+	}
+
 	// Handle semester, course, and honour_card separately
 	if reqData.ItemType == "semester" {
 		// Default to "replace" mode if not specified
@@ -446,6 +463,7 @@ func UpdateItemVisibility(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to update semester visibility: %v", err)})
 			return
 		}
+		logSharing()
 		json.NewEncoder(w).Encode(map[string]string{"message": "Semester visibility updated successfully"})
 		return
 	}
@@ -462,6 +480,7 @@ func UpdateItemVisibility(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to update course visibility: %v", err)})
 			return
 		}
+		logSharing()
 		json.NewEncoder(w).Encode(map[string]string{"message": "Course visibility updated successfully"})
 		return
 	}
@@ -478,6 +497,7 @@ func UpdateItemVisibility(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to update honour card visibility: %v", err)})
 			return
 		}
+		logSharing()
 		json.NewEncoder(w).Encode(map[string]string{"message": "Honour card visibility updated successfully"})
 		return
 	}
@@ -549,12 +569,55 @@ func UpdateItemVisibility(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logSharing()
 	json.NewEncoder(w).Encode(map[string]string{
 		"message":    "Visibility updated successfully",
 		"visibility": reqData.Visibility,
 	})
 }
 
+// sharingLogQueries find the owning curriculum and visibility of a shareable item
+var sharingLogQueries = map[string]string{
+	"semester":    "SELECT curriculum_id, visibility FROM normal_cards WHERE id = ?",
+	"honour_card": "SELECT curriculum_id, visibility FROM honour_cards WHERE id = ?",
+	"course": `
+		SELECT cc.curriculum_id, c.visibility
+		FROM courses c INNER JOIN curriculum_courses cc ON c.course_id = cc.course_id
+		WHERE c.course_id = ? ORDER BY cc.id LIMIT 1`,
+	"mission": "SELECT curriculum_id, visibility FROM curriculum_mission WHERE id = ?",
+	"peos":    "SELECT curriculum_id, visibility FROM curriculum_peos WHERE id = ?",
+	"psos":    "SELECT curriculum_id, visibility FROM curriculum_psos WHERE id = ?",
+}
+
+// sharingLogState returns the owning curriculum of a shareable item and its sharing state for the activity log
+func sharingLogState(itemType string, itemID int) (int, map[string]interface{}) {
+	query, ok := sharingLogQueries[itemType]
+	if !ok {
+		return 0, nil
+	}
+	var curriculumID int
+	var visibility sql.NullString
+	if err := db.DB.QueryRow(query, itemID).Scan(&curriculumID, &visibility); err != nil {
+		return 0, nil
+	}
+
+	sharedWith := []int{}
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT target_curriculum_id FROM sharing_tracking
+		WHERE source_item_id = ? AND item_type = ?
+		ORDER BY target_curriculum_id`, itemID, itemType)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var targetID int
+			if rows.Scan(&targetID) == nil {
+				sharedWith = append(sharedWith, targetID)
+			}
+		}
+	}
+	return curriculumID, map[string]interface{}{"visibility": visibility.String, "shared_with": sharedWith}
+}
+
 // shareItemToCluster copies an item to selected or all other departments in the same cluster
 func shareItemToCluster(sourceDeptID, itemID int, tableName, columnName string, targetDepartments []int) error {
 	// Get cluster ID for this department
@@ -1396,7 +1459,7 @@ func copyCoursesBetweenSemesters(sourceRegID, sourceSemID, targetRegID, targetSe
 		       c.total_marks, c.total_hours
 		FROM courses c
 		JOIN curriculum_courses cc ON c.course_id = cc.course_id
-		WHERE cc.curriculum_id = ? AND cc.semester_id = ?
+		WHERE cc.curriculum_id = ? AND cc.semester_id = ? AND cc.status = 1
 	`, sourceRegID, sourceSemID)
 	if err != nil {
 		return err
@@ -1581,7 +1644,7 @@ func fetchSharedCourses(regulationID, semesterID int) []map[string]interface{} {
 		SELECT c.course_id, c.course_code, c.course_name
 		FROM courses c
 		JOIN curriculum_courses cc ON c.course_id = cc.course_id
-		WHERE cc.curriculum_id = ? AND cc.semester_id = ? AND c.visibility = 'CLUSTER'
+		WHERE cc.curriculum_id = ? AND cc.semester_id = ? AND cc.status = 1 AND c.visibility = 'CLUSTER'
 		ORDER BY c.course_code
 	`
 	rows, err := db.DB.Query(query, regulationID, semesterID)
@@ -0,0 +1,876 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// likertMax is the highest rating of a survey question; ratings run from 1 to likertMax and
+// are converted to the 0-3 attainment scale as (average - 1) / (likertMax - 1) * 3
+const likertMax = 5
+
+var validSurveyTypes = map[string]bool{
+	"COURSE_EXIT":   true,
+	"GRADUATE_EXIT": true,
+	"ALUMNI":        true,
+	"EMPLOYER":      true,
+}
+
+// surveyIssuedToStudents reports whether responses of a survey type come from issued students
+func surveyIssuedToStudents(surveyType string) bool {
+	return surveyType == "COURSE_EXIT" || surveyType == "GRADUATE_EXIT"
+}
+
+// likertToAttainment converts an average Likert rating to the 0-3 attainment scale, so the
+// lowest rating maps to 0 and the highest to 3
+func likertToAttainment(average float64) float64 {
+	return roundTo2((average - 1) / (likertMax - 1) * 3)
+}
+
+// surveyRespondentKey normalises the email an alumni or employer respondent submits with
+func surveyRespondentKey(email string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(address.Address), nil
+}
+
+// fetchSurvey loads an active survey with its questions and response counts
+func fetchSurvey(surveyID int) (*models.Survey, error) {
+	var survey models.Survey
+	var courseID sql.NullInt64
+	err := db.DB.QueryRow(`
+		SELECT id, curriculum_id, course_id, title, survey_type, academic_year, is_open, created_at
+		FROM surveys WHERE id = ? AND status = 1`, surveyID).
+		Scan(&survey.ID, &survey.CurriculumID, &courseID, &survey.Title, &survey.SurveyType,
+			&survey.AcademicYear, &survey.IsOpen, &survey.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if courseID.Valid {
+		id := int(courseID.Int64)
+		survey.CourseID = &id
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, question_text, outcome_type, outcome_index, position
+		FROM survey_questions
+		WHERE survey_id = ? AND status = 1
+		ORDER BY position, id`, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	survey.Questions = []models.SurveyQuestion{}
+	for rows.Next() {
+		var q models.SurveyQuestion
+		if err := rows.Scan(&q.ID, &q.QuestionText, &q.OutcomeType, &q.OutcomeIndex, &q.Position); err == nil {
+			survey.Questions = append(survey.Questions, q)
+		}
+	}
+
+	db.DB.QueryRow("SELECT COUNT(*) FROM survey_recipients WHERE survey_id = ?", surveyID).Scan(&survey.Recipients)
+	db.DB.QueryRow("SELECT COUNT(*) FROM survey_responses WHERE survey_id = ?", surveyID).Scan(&survey.Responses)
+
+	return &survey, nil
+}
+
+// validateSurvey checks the survey type and that every question maps to an existing PO or PSO
+func validateSurvey(curriculumID int, survey models.Survey) error {
+	if strings.TrimSpace(survey.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if !validSurveyTypes[survey.SurveyType] {
+		return fmt.Errorf("invalid survey_type %q", survey.SurveyType)
+	}
+	if survey.AcademicYear == "" {
+		return fmt.Errorf("academic_year is required")
+	}
+	if survey.SurveyType == "COURSE_EXIT" && survey.CourseID == nil {
+		return fmt.Errorf("course_id is required for course exit surveys")
+	}
+	if len(survey.Questions) == 0 {
+		return fmt.Errorf("at least one question is required")
+	}
+
+	poCount := len(fetchDepartmentList(curriculumID, "curriculum_pos", "po_text"))
+	psoCount := len(fetchDepartmentList(curriculumID, "curriculum_psos", "pso_text"))
+	for i, q := range survey.Questions {
+		if strings.TrimSpace(q.QuestionText) == "" {
+			return fmt.Errorf("question %d has no text", i+1)
+		}
+		switch q.OutcomeType {
+		case "PO":
+			if q.OutcomeIndex < 1 || q.OutcomeIndex > poCount {
+				return fmt.Errorf("question %d maps to PO%d but the curriculum has %d POs", i+1, q.OutcomeIndex, poCount)
+			}
+		case "PSO":
+			if q.OutcomeIndex < 1 || q.OutcomeIndex > psoCount {
+				return fmt.Errorf("question %d maps to PSO%d but the curriculum has %d PSOs", i+1, q.OutcomeIndex, psoCount)
+			}
+		default:
+			return fmt.Errorf("question %d must map to a PO or PSO", i+1)
+		}
+	}
+	return nil
+}
+
+// insertSurveyQuestions adds questions to a survey inside a transaction
+func insertSurveyQuestions(tx *sql.Tx, surveyID int, questions []models.SurveyQuestion) error {
+	for i, q := range questions {
+		_, err := tx.Exec(`
+			INSERT INTO survey_questions (survey_id, question_text, outcome_type, outcome_index, position)
+			VALUES (?, ?, ?, ?, ?)`, surveyID, q.QuestionText, q.OutcomeType, q.OutcomeIndex, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computeSurveyOutcomes averages ratings per PO/PSO over the given surveys and converts them to attainment
+func computeSurveyOutcomes(surveyIDs []int) ([]models.IndirectAttainmentValue, error) {
+	outcomes := []models.IndirectAttainmentValue{}
+	if len(surveyIDs) == 0 {
+		return outcomes, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(surveyIDs)), ",")
+	args := make([]interface{}, len(surveyIDs))
+	for i, id := range surveyIDs {
+		args[i] = id
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT q.outcome_type, q.outcome_index, AVG(a.rating)
+		FROM survey_answers a
+		INNER JOIN survey_questions q ON q.id = a.question_id
+		WHERE q.status = 1 AND q.survey_id IN (`+placeholders+`)
+		GROUP BY q.outcome_type, q.outcome_index
+		ORDER BY q.outcome_type, q.outcome_index`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v models.IndirectAttainmentValue
+		var average float64
+		if err := rows.Scan(&v.OutcomeType, &v.Index, &average); err != nil {
+			log.Println("Error scanning survey outcome:", err)
+			continue
+		}
+		v.Value = likertToAttainment(average)
+		v.Source = "SURVEY"
+		outcomes = append(outcomes, v)
+	}
+	return outcomes, nil
+}
+
+// GetSurveys handles GET /curriculum/:id/surveys?academic_year=
+func GetSurveys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	query := "SELECT id FROM surveys WHERE curriculum_id = ? AND status = 1"
+	args := []interface{}{curriculumID}
+	if academicYear := r.URL.Query().Get("academic_year"); academicYear != "" {
+		query += " AND academic_year = ?"
+		args = append(args, academicYear)
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching surveys:", err)
+		http.Error(w, "Failed to fetch surveys", http.StatusInternalServerError)
+		return
+	}
+	surveyIDs := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			surveyIDs = append(surveyIDs, id)
+		}
+	}
+	rows.Close()
+
+	surveys := []models.Survey{}
+	for _, id := range surveyIDs {
+		survey, err := fetchSurvey(id)
+		if err != nil {
+			log.Println("Error fetching survey:", err)
+			continue
+		}
+		surveys = append(surveys, *survey)
+	}
+
+	json.NewEncoder(w).Encode(surveys)
+}
+
+// CreateSurvey handles POST /curriculum/:id/surveys
+func CreateSurvey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	var survey models.Survey
+	if err := json.NewDecoder(r.Body).Decode(&survey); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateSurvey(curriculumID, survey); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if survey.SurveyType != "COURSE_EXIT" {
+		survey.CourseID = nil
+	}
+
+	if survey.CourseID != nil {
+		var exists bool
+		err := db.DB.QueryRow(`
+			SELECT EXISTS(SELECT 1 FROM curriculum_courses WHERE curriculum_id = ? AND course_id = ? AND status = 1)`,
+			curriculumID, *survey.CourseID).Scan(&exists)
+		if err != nil || !exists {
+			http.Error(w, "Course does not belong to this curriculum", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to create survey", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO surveys (curriculum_id, course_id, title, survey_type, academic_year)
+		VALUES (?, ?, ?, ?, ?)`, curriculumID, survey.CourseID, survey.Title, survey.SurveyType, survey.AcademicYear)
+	if err != nil {
+		log.Println("Error creating survey:", err)
+		http.Error(w, "Failed to create survey", http.StatusInternalServerError)
+		return
+	}
+	surveyID, _ := result.LastInsertId()
+
+	if err := insertSurveyQuestions(tx, int(surveyID), survey.Questions); err != nil {
+		log.Println("Error creating survey questions:", err)
+		http.Error(w, "Failed to create survey", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing survey:", err)
+		http.Error(w, "Failed to create survey", http.StatusInternalServerError)
+		return
+	}
+
+	logCurriculumDiff(curriculumID, logEntity{"survey", int(surveyID), opCreate}, "Survey Created",
+		fmt.Sprintf("Created %s survey '%s' for %s with %d questions", survey.SurveyType, survey.Title, survey.AcademicYear, len(survey.Questions)),
+		requestUser(r), nil)
+
+	created, err := fetchSurvey(int(surveyID))
+	if err != nil {
+		log.Println("Error fetching created survey:", err)
+		http.Error(w, "Failed to fetch survey", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetSurvey handles GET /survey/:surveyId
+func GetSurvey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	surveyID, err := strconv.Atoi(vars["surveyId"])
+	if err != nil {
+		http.Error(w, "Invalid survey ID", http.StatusBadRequest)
+		return
+	}
+
+	survey, err := fetchSurvey(surveyID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Survey not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching survey:", err)
+		http.Error(w, "Failed to fetch survey", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(survey)
+}
+
+// UpdateSurvey handles PUT /survey/:surveyId
+// Questions can only be replaced while the survey has no responses
+func UpdateSurvey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	surveyID, err := strconv.Atoi(vars["surveyId"])
+	if err != nil {
+		http.Error(w, "Invalid survey ID", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := fetchSurvey(surveyID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Survey not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching survey:", err)
+		http.Error(w, "Failed to update survey", http.StatusInternalServerError)
+		return
+	}
+	if existing.Responses > 0 {
+		http.Error(w, "Survey already has responses and can no longer be edited", http.StatusConflict)
+		return
+	}
+
+	var survey models.Survey
+	if err := json.NewDecoder(r.Body).Decode(&survey); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	survey.SurveyType = existing.SurveyType
+	survey.CourseID = existing.CourseID
+	if survey.AcademicYear == "" {
+		survey.AcademicYear = existing.AcademicYear
+	}
+	if err := validateSurvey(existing.CurriculumID, survey); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to update survey", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE surveys SET title = ?, academic_year = ? WHERE id = ?",
+		survey.Title, survey.AcademicYear, surveyID); err != nil {
+		log.Println("Error updating survey:", err)
+		http.Error(w, "Failed to update survey", http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec("UPDATE survey_questions SET status = 0 WHERE survey_id = ?", surveyID); err != nil {
+		log.Println("Error clearing survey questions:", err)
+		http.Error(w, "Failed to update survey", http.StatusInternalServerError)
+		return
+	}
+	if err := insertSurveyQuestions(tx, surveyID, survey.Questions); err != nil {
+		log.Println("Error saving survey questions:", err)
+		http.Error(w, "Failed to update survey", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing survey:", err)
+		http.Error(w, "Failed to update survey", http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := fetchSurvey(surveyID)
+	if err != nil {
+		log.Println("Error fetching updated survey:", err)
+		http.Error(w, "Failed to fetch survey", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(updated)
+}
+
+// CloseSurvey handles POST /survey/:surveyId/close
+func CloseSurvey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	surveyID, err := strconv.Atoi(vars["surveyId"])
+	if err != nil {
+		http.Error(w, "Invalid survey ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.DB.Exec("UPDATE surveys SET is_open = 0 WHERE id = ? AND status = 1", surveyID)
+	if err != nil {
+		log.Println("Error closing survey:", err)
+		http.Error(w, "Failed to close survey", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var exists bool
+		db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM surveys WHERE id = ? AND status = 1)", surveyID).Scan(&exists)
+		if !exists {
+			http.Error(w, "Survey not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Survey closed successfully"})
+}
+
+// DeleteSurvey handles DELETE /survey/:surveyId
+func DeleteSurvey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	surveyID, err := strconv.Atoi(vars["surveyId"])
+	if err != nil {
+		http.Error(w, "Invalid survey ID", http.StatusBadRequest)
+		return
+	}
+
+	var curriculumID int
+	var title string
+	err = db.DB.QueryRow("SELECT curriculum_id, title FROM surveys WHERE id = ? AND status = 1", surveyID).
+		Scan(&curriculumID, &title)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Survey not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching survey:", err)
+		http.Error(w, "Failed to delete survey", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := db.DB.Exec("UPDATE surveys SET status = 0 WHERE id = ?", surveyID); err != nil {
+		log.Println("Error deleting survey:", err)
+		http.Error(w, "Failed to delete survey", http.StatusInternalServerError)
+		return
+	}
+
+	logCurriculumDiff(curriculumID, logEntity{"survey", surveyID, opDelete}, "Survey Deleted",
+		fmt.Sprintf("Deleted survey '%s'", title), requestUser(r), nil)
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Survey deleted successfully"})
+}
+
+// IssueSurvey handles POST /survey/:surveyId/issue
+// Course exit surveys go to students of the course's semester in the curriculum, graduate exit
+// surveys to a batch; an explicit student_ids list overrides either
+func IssueSurvey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	surveyID, err := strconv.Atoi(vars["surveyId"])
+	if err != nil {
+		http.Error(w, "Invalid survey ID", http.StatusBadRequest)
+		return
+	}
+
+	survey, err := fetchSurvey(surveyID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Survey not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching survey:", err)
+		http.Error(w, "Failed to issue survey", http.StatusInternalServerError)
+		return
+	}
+	if !surveyIssuedToStudents(survey.SurveyType) {
+		http.Error(w, "Only course exit and graduate exit surveys are issued to students", http.StatusBadRequest)
+		return
+	}
+	if !survey.IsOpen {
+		http.Error(w, "Survey is closed", http.StatusConflict)
+		return
+	}
+
+	var req models.SurveyIssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	studentIDs := req.StudentIDs
+	if len(studentIDs) == 0 {
+		var query string
+		var args []interface{}
+		switch {
+		case survey.CourseID != nil:
+			query = `
+				SELECT DISTINCT ad.student_id
+				FROM academic_details ad
+				INNER JOIN normal_cards nc ON nc.curriculum_id = ad.curriculum_id AND nc.semester_number = ad.semester
+				INNER JOIN curriculum_courses cc ON cc.semester_id = nc.id AND cc.status = 1
+				WHERE ad.curriculum_id = ? AND cc.course_id = ? AND ad.student_id IS NOT NULL`
+			args = []interface{}{survey.CurriculumID, *survey.CourseID}
+		case req.Batch != "":
+			query = `
+				SELECT DISTINCT ad.student_id FROM academic_details ad
+				WHERE ad.curriculum_id = ? AND ad.batch = ? AND ad.student_id IS NOT NULL`
+			args = []interface{}{survey.CurriculumID, req.Batch}
+		default:
+			http.Error(w, "batch or student_ids is required", http.StatusBadRequest)
+			return
+		}
+		if req.Section != "" {
+			query += " AND ad.section = ?"
+			args = append(args, req.Section)
+		}
+
+		rows, err := db.DB.Query(query, args...)
+		if err != nil {
+			log.Println("Error fetching survey recipients:", err)
+			http.Error(w, "Failed to issue survey", http.StatusInternalServerError)
+			return
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err == nil {
+				studentIDs = append(studentIDs, id)
+			}
+		}
+		rows.Close()
+	}
+
+	if len(studentIDs) == 0 {
+		http.Error(w, "No students matched the selection", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to issue survey", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	issued := 0
+	for _, studentID := range studentIDs {
+		result, err := tx.Exec("INSERT IGNORE INTO survey_recipients (survey_id, student_id) VALUES (?, ?)", surveyID, studentID)
+		if err != nil {
+			log.Println("Error issuing survey:", err)
+			http.Error(w, "Failed to issue survey", http.StatusInternalServerError)
+			return
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			issued++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing survey recipients:", err)
+		http.Error(w, "Failed to issue survey", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Survey issued successfully",
+		"issued":  issued,
+		"total":   survey.Recipients + issued,
+	})
+}
+
+// SubmitSurveyResponse handles POST /survey/:surveyId/responses
+func SubmitSurveyResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	surveyID, err := strconv.Atoi(vars["surveyId"])
+	if err != nil {
+		http.Error(w, "Invalid survey ID", http.StatusBadRequest)
+		return
+	}
+
+	survey, err := fetchSurvey(surveyID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Survey not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching survey:", err)
+		http.Error(w, "Failed to submit response", http.StatusInternalServerError)
+		return
+	}
+	if !survey.IsOpen {
+		http.Error(w, "Survey is closed", http.StatusConflict)
+		return
+	}
+
+	var req models.SurveyResponseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("Error decoding request body:", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if surveyIssuedToStudents(survey.SurveyType) {
+		if req.StudentID == nil {
+			http.Error(w, "student_id is required", http.StatusBadRequest)
+			return
+		}
+		var issued bool
+		db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM survey_recipients WHERE survey_id = ? AND student_id = ?)",
+			surveyID, *req.StudentID).Scan(&issued)
+		if !issued {
+			http.Error(w, "Survey was not issued to this student", http.StatusForbidden)
+			return
+		}
+	}
+	var respondentKey interface{}
+	if !surveyIssuedToStudents(survey.SurveyType) {
+		req.StudentID = nil
+		if strings.TrimSpace(req.RespondentName) == "" {
+			http.Error(w, "respondent_name is required", http.StatusBadRequest)
+			return
+		}
+		key, err := surveyRespondentKey(req.RespondentEmail)
+		if err != nil {
+			http.Error(w, "A valid respondent_email is required", http.StatusBadRequest)
+			return
+		}
+		respondentKey = key
+	}
+
+	questionIDs := make(map[int]bool)
+	for _, q := range survey.Questions {
+		questionIDs[q.ID] = true
+	}
+	answered := make(map[int]bool)
+	for _, a := range req.Answers {
+		if !questionIDs[a.QuestionID] {
+			http.Error(w, fmt.Sprintf("Question %d does not belong to this survey", a.QuestionID), http.StatusBadRequest)
+			return
+		}
+		if a.Rating < 1 || a.Rating > likertMax {
+			http.Error(w, fmt.Sprintf("Ratings must be between 1 and %d", likertMax), http.StatusBadRequest)
+			return
+		}
+		answered[a.QuestionID] = true
+	}
+	if len(answered) != len(questionIDs) || len(req.Answers) != len(answered) {
+		http.Error(w, "Every question must be answered exactly once", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to submit response", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var respondentName interface{}
+	if req.RespondentName != "" {
+		respondentName = req.RespondentName
+	}
+	result, err := tx.Exec("INSERT INTO survey_responses (survey_id, student_id, respondent_name, respondent_key) VALUES (?, ?, ?, ?)",
+		surveyID, req.StudentID, respondentName, respondentKey)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			if respondentKey != nil {
+				http.Error(w, "A response from this email has already been recorded", http.StatusConflict)
+			} else {
+				http.Error(w, "This student has already responded", http.StatusConflict)
+			}
+			return
+		}
+		log.Println("Error saving survey response:", err)
+		http.Error(w, "Failed to submit response", http.StatusInternalServerError)
+		return
+	}
+	responseID, _ := result.LastInsertId()
+
+	for _, a := range req.Answers {
+		if _, err := tx.Exec("INSERT INTO survey_answers (response_id, question_id, rating) VALUES (?, ?, ?)",
+			responseID, a.QuestionID, a.Rating); err != nil {
+			log.Println("Error saving survey answer:", err)
+			http.Error(w, "Failed to submit response", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing survey response:", err)
+		http.Error(w, "Failed to submit response", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     "Response recorded successfully",
+		"response_id": responseID,
+	})
+}
+
+// GetSurveyResults handles GET /survey/:surveyId/results
+func GetSurveyResults(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	surveyID, err := strconv.Atoi(vars["surveyId"])
+	if err != nil {
+		http.Error(w, "Invalid survey ID", http.StatusBadRequest)
+		return
+	}
+
+	survey, err := fetchSurvey(surveyID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Survey not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error fetching survey:", err)
+		http.Error(w, "Failed to fetch survey results", http.StatusInternalServerError)
+		return
+	}
+
+	stats := make(map[int]models.SurveyQuestionResult)
+	rows, err := db.DB.Query(`
+		SELECT a.question_id, COUNT(*), AVG(a.rating)
+		FROM survey_answers a
+		INNER JOIN survey_questions q ON q.id = a.question_id
+		WHERE q.survey_id = ? AND q.status = 1
+		GROUP BY a.question_id`, surveyID)
+	if err != nil {
+		log.Println("Error fetching survey results:", err)
+		http.Error(w, "Failed to fetch survey results", http.StatusInternalServerError)
+		return
+	}
+	for rows.Next() {
+		var s models.SurveyQuestionResult
+		if err := rows.Scan(&s.QuestionID, &s.Responses, &s.AverageRating); err == nil {
+			s.AverageRating = roundTo2(s.AverageRating)
+			stats[s.QuestionID] = s
+		}
+	}
+	rows.Close()
+
+	results := models.SurveyResults{
+		SurveyID:  surveyID,
+		Responses: survey.Responses,
+		Questions: []models.SurveyQuestionResult{},
+	}
+	for _, q := range survey.Questions {
+		s := stats[q.ID]
+		s.QuestionID = q.ID
+		s.QuestionText = q.QuestionText
+		s.OutcomeType = q.OutcomeType
+		s.OutcomeIndex = q.OutcomeIndex
+		results.Questions = append(results.Questions, s)
+	}
+
+	results.Outcomes, err = computeSurveyOutcomes([]int{surveyID})
+	if err != nil {
+		log.Println("Error computing survey outcomes:", err)
+		http.Error(w, "Failed to fetch survey results", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(results)
+}
+
+// ComputeIndirectAttainment handles POST /curriculum/:id/indirect-attainment/compute?academic_year=
+// Ratings from every survey of the year are averaged per PO/PSO and stored as SURVEY indirect
+// attainment, replacing earlier values for those outcomes; outcomes no survey covers are left untouched
+func ComputeIndirectAttainment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	curriculumID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+
+	academicYear := r.URL.Query().Get("academic_year")
+	if academicYear == "" {
+		http.Error(w, "academic_year is required", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query("SELECT id FROM surveys WHERE curriculum_id = ? AND academic_year = ? AND status = 1",
+		curriculumID, academicYear)
+	if err != nil {
+		log.Println("Error fetching surveys:", err)
+		http.Error(w, "Failed to compute indirect attainment", http.StatusInternalServerError)
+		return
+	}
+	surveyIDs := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			surveyIDs = append(surveyIDs, id)
+		}
+	}
+	rows.Close()
+
+	values, err := computeSurveyOutcomes(surveyIDs)
+	if err != nil {
+		log.Println("Error computing survey outcomes:", err)
+		http.Error(w, "Failed to compute indirect attainment", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting transaction:", err)
+		http.Error(w, "Failed to compute indirect attainment", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, v := range values {
+		_, err := tx.Exec(`
+			INSERT INTO indirect_attainment (curriculum_id, academic_year, outcome_type, outcome_index, value, source)
+			VALUES (?, ?, ?, ?, ?, 'SURVEY')
+			ON DUPLICATE KEY UPDATE value = VALUES(value), source = VALUES(source)
+		`, curriculumID, academicYear, v.OutcomeType, v.Index, v.Value)
+		if err != nil {
+			log.Println("Error saving indirect attainment:", err)
+			http.Error(w, "Failed to compute indirect attainment", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing indirect attainment:", err)
+		http.Error(w, "Failed to compute indirect attainment", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.IndirectAttainmentRequest{
+		AcademicYear: academicYear,
+		Values:       values,
+	})
+}
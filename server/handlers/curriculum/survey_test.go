@@ -0,0 +1,42 @@
+package curriculum
+
+import "testing"
+
+func TestLikertToAttainment(t *testing.T) {
+	tests := []struct {
+		average float64
+		want    float64
+	}{
+		{1, 0},
+		{2, 0.75},
+		{3, 1.5},
+		{4, 2.25},
+		{5, 3},
+		{4.2, 2.4},
+	}
+	for _, tt := range tests {
+		if got := likertToAttainment(tt.average); got != tt.want {
+			t.Errorf("likertToAttainment(%v) = %v, want %v", tt.average, got, tt.want)
+		}
+	}
+}
+
+func TestSurveyRespondentKey(t *testing.T) {
+	tests := []struct {
+		email   string
+		want    string
+		wantErr bool
+	}{
+		{"alumni@example.com", "alumni@example.com", false},
+		{"  HR@Example.COM ", "hr@example.com", false},
+		{"Priya Raman <Priya@Example.com>", "priya@example.com", false},
+		{"", "", true},
+		{"not an email", "", true},
+	}
+	for _, tt := range tests {
+		got, err := surveyRespondentKey(tt.email)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("surveyRespondentKey(%q) = %q, %v", tt.email, got, err)
+		}
+	}
+}
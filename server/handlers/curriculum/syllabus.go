@@ -2,6 +2,7 @@ package curriculum
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"server/models"
@@ -83,6 +84,8 @@ func SaveCourseSyllabus(w http.ResponseWriter, r *http.Request) {
 		log.Printf("  - SelfLearning: nil")
 	}
 
+	before := courseSyllabusLogState(courseID)
+
 	// Save all data to normalized tables (course-centric design)
 	if err := saveObjectives(courseID, requestData.Objectives); err != nil {
 		log.Println("Error saving objectives:", err)
@@ -120,6 +123,9 @@ func SaveCourseSyllabus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logCourseChange(courseID, logEntity{"syllabus", courseID, opUpdate}, "Syllabus Saved",
+		fmt.Sprintf("Updated syllabus details of course %d", courseID), requestUser(r), before, courseSyllabusLogState(courseID))
+
 	// Return success response
 	response := models.Syllabus{
 		ID:            courseID, // Use course_id as identifier
@@ -132,3 +138,15 @@ func SaveCourseSyllabus(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(response)
 }
+
+// courseSyllabusLogState is the saved syllabus header of a course for the activity log
+func courseSyllabusLogState(courseID int) map[string]interface{} {
+	state := map[string]interface{}{}
+	state["objectives"], _ = fetchObjectives(courseID)
+	state["outcomes"], _ = fetchOutcomes(courseID)
+	state["reference_list"], _ = fetchReferences(courseID)
+	state["prerequisites"], _ = fetchPrerequisites(courseID)
+	state["teamwork"], _ = fetchTeamwork(courseID)
+	state["selflearning"], _ = fetchSelfLearning(courseID)
+	return state
+}
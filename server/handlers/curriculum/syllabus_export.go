@@ -0,0 +1,576 @@
+package curriculum
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// GenerateCourseSyllabusPDF handles GET /api/course/{courseId}/syllabus/pdf.
+// The syllabus is shown as it appears in ?curriculum_id (its pinned version and template),
+// defaulting to the first curriculum using the course
+func GenerateCourseSyllabusPDF(w http.ResponseWriter, r *http.Request) {
+	data, ok := courseSyllabusExportData(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if we should return HTML preview (for debugging when Chrome is not installed)
+	if r.URL.Query().Get("preview") == "html" {
+		htmlContent, err := renderCourseSyllabusHTML(data)
+		if err != nil {
+			log.Println("Error rendering course syllabus:", err)
+			http.Error(w, "Failed to render syllabus", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", "inline; filename=syllabus_preview.html")
+		w.Write([]byte(htmlContent))
+		return
+	}
+
+	// Serve unchanged syllabi straight from the cache
+	hash, err := pdfContentHash(data, pdfLayoutKey(data.TemplateHTML))
+	if err != nil {
+		log.Println("Error hashing syllabus data:", err)
+		http.Error(w, "Failed to generate PDF", http.StatusInternalServerError)
+		return
+	}
+	pdfBytes, cached := cachedPDF(hash)
+	if !cached {
+		pdfBytes, err = renderPDFOnWorkers(r.Context(), courseSyllabusFilename(data, "pdf"), hash, func(print htmlPrinter) ([]byte, string, error) {
+			pdfBytes, native, err := renderPDF(
+				pageSetupFor(data.Branding, data.Draft),
+				func() (string, error) { return renderCourseSyllabusHTML(data) },
+				print,
+				func(doc documentWriter) { writeCourseSyllabus(doc, data) },
+			)
+			if err != nil {
+				return nil, "", err
+			}
+			hash, err := pdfContentHash(data, renderedLayoutKey(data.TemplateHTML, native))
+			return pdfBytes, hash, err
+		})
+		if err != nil {
+			log.Println("Error generating syllabus PDF:", err)
+			if status := pdfQueueErrorStatus(err); status != http.StatusInternalServerError {
+				http.Error(w, err.Error(), status)
+				return
+			}
+			http.Error(w, fmt.Sprintf("Failed to generate PDF: %v\n\nChrome/Chromium is required for PDF generation.\n"+
+				"Or set PDF_RENDERER=native (or auto) to render without Chrome\n\n"+
+				"Or download the HTML preview by adding ?preview=html to the URL", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", courseSyllabusFilename(data, "pdf")))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdfBytes)))
+	w.Write(pdfBytes)
+}
+
+// GenerateCourseSyllabusDOCX handles GET /api/course/{courseId}/syllabus/docx, taking the same
+// ?curriculum_id as the PDF export
+func GenerateCourseSyllabusDOCX(w http.ResponseWriter, r *http.Request) {
+	data, ok := courseSyllabusExportData(w, r)
+	if !ok {
+		return
+	}
+
+	doc := newDocxDocument()
+	writeCourseSyllabus(doc, data)
+	docBytes, err := doc.Bytes()
+	if err != nil {
+		log.Println("Error generating syllabus DOCX:", err)
+		http.Error(w, "Failed to generate DOCX", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.wordprocessingml.document")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", courseSyllabusFilename(data, "docx")))
+	w.Header().Set("Content-Length", strconv.Itoa(len(docBytes)))
+	w.Write(docBytes)
+}
+
+// courseSyllabusExportData parses the request and loads the syllabus, writing the error response on failure
+func courseSyllabusExportData(w http.ResponseWriter, r *http.Request) (*models.CourseSyllabusPDF, bool) {
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return nil, false
+	}
+	curriculumID := 0
+	if v := r.URL.Query().Get("curriculum_id"); v != "" {
+		if curriculumID, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	data, err := fetchCourseSyllabusData(courseID, curriculumID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Println("Error fetching course syllabus:", err)
+		http.Error(w, "Failed to fetch course syllabus", http.StatusInternalServerError)
+		return nil, false
+	}
+	return data, true
+}
+
+// fetchCourseSyllabusData builds the syllabus of one course from the same data as the regulation PDF.
+// With curriculumID 0 the first curriculum using the course is used; a course in no curriculum is
+// exported live with the default template and no PO/PSO columns, under the institution's branding
+func fetchCourseSyllabusData(courseID, curriculumID int) (*models.CourseSyllabusPDF, error) {
+	var exists int
+	if err := db.DB.QueryRow("SELECT 1 FROM courses WHERE course_id = ? AND status = 1", courseID).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	if curriculumID == 0 {
+		if ids := courseCurriculumIDs(courseID); len(ids) > 0 {
+			curriculumID = ids[0]
+		}
+	}
+
+	data := &models.CourseSyllabusPDF{CurriculumID: curriculumID, CurriculumTemplate: "2026", GeneratedDate: pdfGeneratedDate()}
+	departmentID := 0
+	if curriculumID > 0 {
+		var tmpl sql.NullString
+		err := db.DB.QueryRow("SELECT name, academic_year, curriculum_template FROM curriculum WHERE id = ?", curriculumID).
+			Scan(&data.RegulationName, &data.AcademicYear, &tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch curriculum: %v", err)
+		}
+		if tmpl.Valid && tmpl.String != "" {
+			data.CurriculumTemplate = tmpl.String
+		}
+		data.POCount = len(fetchDepartmentListItemsHTML(curriculumID, "curriculum_pos", "po_text"))
+		data.PSOCount = len(fetchDepartmentListItemsHTML(curriculumID, "curriculum_psos", "pso_text"))
+		departmentID, data.Draft = curriculumExportInfo(curriculumID)
+	}
+	data.Branding = loadPDFBranding(departmentID)
+	data.TemplateHTML = resolvePDFTemplate(pdfTemplateSyllabus, departmentID, data.CurriculumTemplate)
+
+	data.Course = loadCoursePDF(curriculumID, courseID, data.CurriculumTemplate)
+	return data, nil
+}
+
+// courseSyllabusFilename is the download name of a syllabus export
+func courseSyllabusFilename(data *models.CourseSyllabusPDF, ext string) string {
+	name := "Syllabus_" + strings.ReplaceAll(data.Course.CourseCode, " ", "_")
+	if data.AcademicYear != "" {
+		name += "_" + strings.ReplaceAll(data.AcademicYear, " ", "_")
+	}
+	return name + "." + ext
+}
+
+// regulationPDFStyles is the stylesheet of the regulation template, shared so a syllabus looks the same
+func regulationPDFStyles() template.CSS {
+	start := strings.Index(htmlTemplate, "<style>")
+	end := strings.Index(htmlTemplate, "</style>")
+	if start < 0 || end < start {
+		return ""
+	}
+	return template.CSS(htmlTemplate[start+len("<style>") : end])
+}
+
+// renderCourseSyllabusHTML renders a single course syllabus into printable HTML
+func renderCourseSyllabusHTML(data *models.CourseSyllabusPDF) (string, error) {
+	templateHTML := data.TemplateHTML
+	if templateHTML == "" {
+		templateHTML = courseSyllabusTemplate
+	}
+	tmpl, err := parsePDFTemplate("syllabus", templateHTML)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct {
+		*models.CourseSyllabusPDF
+		Styles     template.CSS
+		PageStyles template.CSS
+		LogoBase64 template.URL
+	}{data, regulationPDFStyles(), pageSetupFor(data.Branding, data.Draft).pageStyles(),
+		brandingLogoDataURI(data.Branding.LogoPath)})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// writeCourseSyllabus writes the syllabus sections in the same order as the HTML PDF
+func writeCourseSyllabus(doc documentWriter, data *models.CourseSyllabusPDF) {
+	course := data.Course
+	doc.Title(fmt.Sprintf("%s - %s", course.CourseCode, course.CourseName))
+	if data.RegulationName != "" {
+		doc.Centered(fmt.Sprintf("%s (%s)", data.RegulationName, data.AcademicYear), false)
+	}
+	writeCourseSection(doc, course, data.CurriculumTemplate, data.POCount, data.PSOCount, 2)
+}
+
+// writeCourseSection writes a course's details and syllabus with its sections at the given heading level
+func writeCourseSection(doc documentWriter, course models.CoursePDF, curriculumTemplate string, poCount, psoCount, level int) {
+	isLab := isLabCourse(course.CourseType)
+
+	doc.Table([][]string{
+		{"Course Code", "Category", "L-T-P-C", "Hours/Week", "CIA Marks", "SEE Marks"},
+		{
+			course.CourseCode,
+			course.Category,
+			fmt.Sprintf("%d-%d-%d-%d", course.LectureHours, course.TutorialHours, course.PracticalHours, course.Credit),
+			strconv.Itoa(course.LectureHours + course.TutorialHours + course.PracticalHours),
+			strconv.Itoa(course.CIAMarks),
+			strconv.Itoa(course.SEEMarks),
+		},
+	})
+
+	if curriculumTemplate != "2022" && len(course.Syllabus.Prerequisites) > 0 {
+		doc.Heading(level, "Prerequisites")
+		doc.List(course.Syllabus.Prerequisites, false)
+	}
+	if len(course.Syllabus.Objectives) > 0 {
+		doc.Heading(level, "Course Objectives")
+		doc.List(course.Syllabus.Objectives, true)
+	}
+	if len(course.Syllabus.Outcomes) > 0 {
+		doc.Heading(level, "Course Outcomes")
+		doc.Paragraph("Upon successful completion of this course, students will be able to:")
+		doc.List(course.Syllabus.Outcomes, true)
+	}
+
+	if len(course.Syllabus.Outcomes) > 0 && poCount > 0 {
+		doc.Heading(level, "CO-PO Mapping")
+		doc.Table(courseMappingRows("PO", poCount, len(course.Syllabus.Outcomes), course.COPOMapping))
+	}
+	if len(course.Syllabus.Outcomes) > 0 && psoCount > 0 {
+		doc.Heading(level, "CO-PSO Mapping")
+		doc.Table(courseMappingRows("PSO", psoCount, len(course.Syllabus.Outcomes), course.COPSOMapping))
+	}
+	if len(course.Justifications) > 0 {
+		doc.Heading(level, "Justification for Mapping")
+		rows := [][]string{{"CO", "PO/PSO", "Level", "Justification"}}
+		for _, note := range course.Justifications {
+			rows = append(rows, []string{fmt.Sprintf("CO%d", note.CO), note.Outcome, strconv.Itoa(note.Value), note.Justification})
+		}
+		doc.Table(rows)
+	}
+
+	if len(course.Models) > 0 {
+		if isLab {
+			doc.Heading(level, "List of Experiments")
+		} else {
+			doc.Heading(level, "Course Content")
+		}
+		for _, model := range course.Models {
+			doc.Heading(level+1, model.ModelName)
+			for _, title := range model.Titles {
+				label := title.TitleName
+				if title.Hours > 0 {
+					label += fmt.Sprintf(" (%d hours)", title.Hours)
+				}
+				doc.Bold(label)
+				topics := make([]string, 0, len(title.Topics))
+				for _, topic := range title.Topics {
+					topics = append(topics, topic.Topic)
+				}
+				doc.SubList(topics)
+			}
+		}
+	}
+
+	if curriculumTemplate == "2022" && len(course.Experiments) > 0 {
+		doc.Heading(level, "Experiments")
+		for _, exp := range course.Experiments {
+			doc.Heading(level+1, fmt.Sprintf("Experiment %d: %s", exp.ExperimentNumber, exp.ExperimentName))
+			doc.SubList(exp.Topics)
+		}
+	}
+
+	if curriculumTemplate != "2022" {
+		if tw := course.Syllabus.Teamwork; tw != nil {
+			doc.Heading(level, fmt.Sprintf("Teamwork (%d hours)", tw.Hours))
+			doc.List(tw.Activities, false)
+		}
+		if sl := course.Syllabus.SelfLearning; sl != nil {
+			doc.Heading(level, fmt.Sprintf("Self Learning (%d hours)", sl.Hours))
+			for _, input := range sl.MainInputs {
+				doc.Bold(input.Main)
+				doc.SubList(input.Internal)
+			}
+		}
+	}
+
+	if len(course.Syllabus.ReferenceList) > 0 {
+		if isLab {
+			doc.Heading(level, "References / Manuals")
+		} else {
+			doc.Heading(level, "Text Books and References")
+		}
+		doc.List(course.Syllabus.ReferenceList, true)
+	}
+}
+
+// courseMappingRows lays out a CO x PO/PSO matrix keyed "co_index-outcome" (co_index 0-based, outcome 1-based)
+func courseMappingRows(prefix string, outcomes, cos int, matrix map[string]int) [][]string {
+	header := []string{"CO/" + prefix}
+	for i := 1; i <= outcomes; i++ {
+		header = append(header, fmt.Sprintf("%s%d", prefix, i))
+	}
+	rows := [][]string{header}
+	for co := 0; co < cos; co++ {
+		row := []string{fmt.Sprintf("CO%d", co+1)}
+		for i := 1; i <= outcomes; i++ {
+			value := "-"
+			if v := matrix[fmt.Sprintf("%d-%d", co, i)]; v > 0 {
+				value = strconv.Itoa(v)
+			}
+			row = append(row, value)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+const courseSyllabusTemplate = `<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="UTF-8">
+	<title>{{.Course.CourseCode}} - {{.Course.CourseName}}</title>
+	<style>{{.Styles}}</style>
+	<style>{{.PageStyles}}</style>
+</head>
+<body>
+{{if .Draft}}<div class="draft-watermark">DRAFT</div>{{end}}
+{{$course := .Course}}
+<div class="course-section">
+	<div class="course-header">
+		{{$course.CourseCode}} - {{$course.CourseName}}
+	</div>
+	{{if .RegulationName}}
+	<p style="text-align: center; margin-bottom: 10px;">{{.RegulationName}} ({{.AcademicYear}}) &middot; Generated on {{.GeneratedDate}}</p>
+	{{end}}
+
+	<!-- Course Info Grid -->
+	<div class="grid-table">
+		<div class="grid-row">
+			<div class="grid-cell grid-header">Course Code</div>
+			<div class="grid-cell">{{$course.CourseCode}}</div>
+			<div class="grid-cell grid-header">Category</div>
+			<div class="grid-cell">{{$course.Category}}</div>
+		</div>
+		<div class="grid-row">
+			<div class="grid-cell grid-header">L-T-P-C</div>
+			<div class="grid-cell">{{$course.LectureHours}}-{{$course.TutorialHours}}-{{$course.PracticalHours}}-{{$course.Credit}}</div>
+			<div class="grid-cell grid-header">Hours/Week</div>
+			<div class="grid-cell">{{totalHours $course.LectureHours $course.TutorialHours $course.PracticalHours}}</div>
+		</div>
+		<div class="grid-row">
+			<div class="grid-cell grid-header">CIA Marks</div>
+			<div class="grid-cell">{{$course.CIAMarks}}</div>
+			<div class="grid-cell grid-header">SEE Marks</div>
+			<div class="grid-cell">{{$course.SEEMarks}}</div>
+		</div>
+	</div>
+
+	<!-- Prerequisites -->
+	{{if and (ne $.CurriculumTemplate "2022") $course.Syllabus.Prerequisites}}
+	<h3>Prerequisites</h3>
+	<ul>
+	{{range $course.Syllabus.Prerequisites}}
+		<li>{{.}}</li>
+	{{end}}
+	</ul>
+	{{end}}
+
+	<!-- Objectives -->
+	{{if $course.Syllabus.Objectives}}
+	<h3>Course Objectives</h3>
+	<ol>
+	{{range $course.Syllabus.Objectives}}
+		<li>{{.}}</li>
+	{{end}}
+	</ol>
+	{{end}}
+
+	<!-- Course Outcomes -->
+	{{if $course.Syllabus.Outcomes}}
+	<h3>Course Outcomes</h3>
+	<p>Upon successful completion of this course, students will be able to:</p>
+	<ol>
+	{{range $course.Syllabus.Outcomes}}
+		<li>{{.}}</li>
+	{{end}}
+	</ol>
+	{{end}}
+
+	<!-- CO-PO Mapping -->
+	{{if and $course.Syllabus.Outcomes (gt $.POCount 0)}}
+	<h3>CO-PO Mapping</h3>
+	<table class="mapping-table">
+		<thead>
+			<tr>
+				<th>CO/PO</th>
+				{{range $i := iterate $.POCount}}
+				<th>PO{{add $i 1}}</th>
+				{{end}}
+			</tr>
+		</thead>
+		<tbody>
+			{{range $coIdx, $co := $course.Syllabus.Outcomes}}
+			<tr>
+				<th>CO{{add $coIdx 1}}</th>
+				{{range $poIdx := iterate $.POCount}}
+				<td class="center">{{index $course.COPOMapping (printf "%d-%d" $coIdx (add $poIdx 1))}}</td>
+				{{end}}
+			</tr>
+			{{end}}
+		</tbody>
+	</table>
+	{{end}}
+
+	<!-- CO-PSO Mapping -->
+	{{if and $course.Syllabus.Outcomes (gt $.PSOCount 0)}}
+	<h3>CO-PSO Mapping</h3>
+	<table class="mapping-table">
+		<thead>
+			<tr>
+				<th>CO/PSO</th>
+				{{range $i := iterate $.PSOCount}}
+				<th>PSO{{add $i 1}}</th>
+				{{end}}
+			</tr>
+		</thead>
+		<tbody>
+			{{range $coIdx, $co := $course.Syllabus.Outcomes}}
+			<tr>
+				<th>CO{{add $coIdx 1}}</th>
+				{{range $psoIdx := iterate $.PSOCount}}
+				<td class="center">{{index $course.COPSOMapping (printf "%d-%d" $coIdx (add $psoIdx 1))}}</td>
+				{{end}}
+			</tr>
+			{{end}}
+		</tbody>
+	</table>
+	{{end}}
+
+	<!-- Mapping Justification -->
+	{{if $course.Justifications}}
+	<h3>Justification for Mapping</h3>
+	<table class="mapping-table">
+		<thead>
+			<tr>
+				<th>CO</th>
+				<th>PO/PSO</th>
+				<th>Level</th>
+				<th>Justification</th>
+			</tr>
+		</thead>
+		<tbody>
+			{{range $course.Justifications}}
+			<tr>
+				<td class="center">CO{{.CO}}</td>
+				<td class="center">{{.Outcome}}</td>
+				<td class="center">{{.Value}}</td>
+				<td>{{.Justification}}</td>
+			</tr>
+			{{end}}
+		</tbody>
+	</table>
+	{{end}}
+
+	<!-- Course Content / Modules -->
+	{{if $course.Models}}
+	<h3>{{if isLab $course.CourseType}}List of Experiments{{else}}Course Content{{end}}</h3>
+	{{range $course.Models}}
+	<div class="module">
+		<div class="module-title">{{.ModelName}}</div>
+		{{range .Titles}}
+		<div style="margin-left: 15px;">
+			<strong>{{.TitleName}}</strong>{{if gt .Hours 0}} ({{.Hours}} hours){{end}}
+			{{if .Topics}}
+			<div class="topic-list">
+			{{range .Topics}}
+				<div>• {{.Topic}}</div>
+			{{end}}
+			</div>
+			{{end}}
+		</div>
+		{{end}}
+	</div>
+	{{end}}
+	{{end}}
+
+	<!-- Experiments for 2022 Template -->
+	{{if and (eq $.CurriculumTemplate "2022") $course.Experiments}}
+	<h3>Experiments</h3>
+	{{range $course.Experiments}}
+	<div class="module">
+		<div class="module-title">Experiment {{.ExperimentNumber}}: {{.ExperimentName}}</div>
+		{{if .Topics}}
+		<div class="topic-list">
+		{{range .Topics}}
+			<div>• {{.}}</div>
+		{{end}}
+		</div>
+		{{end}}
+	</div>
+	{{end}}
+	{{end}}
+
+	{{if ne $.CurriculumTemplate "2022"}}
+	<!-- Teamwork -->
+	{{if $course.Syllabus.Teamwork}}
+	<h3>Teamwork ({{$course.Syllabus.Teamwork.Hours}} hours)</h3>
+	<ul>
+	{{range $course.Syllabus.Teamwork.Activities}}
+		<li>{{.}}</li>
+	{{end}}
+	</ul>
+	{{end}}
+
+	<!-- Self Learning -->
+	{{if $course.Syllabus.SelfLearning}}
+	<h3>Self Learning ({{$course.Syllabus.SelfLearning.Hours}} hours)</h3>
+	{{range $course.Syllabus.SelfLearning.MainInputs}}
+	<div style="margin: 8px 0;">
+		<strong>{{.Main}}</strong>
+		{{if .Internal}}
+		<ul style="margin-left: 30px;">
+		{{range .Internal}}
+			<li>{{.}}</li>
+		{{end}}
+		</ul>
+		{{end}}
+	</div>
+	{{end}}
+	{{end}}
+	{{end}}
+
+	<!-- References -->
+	{{if $course.Syllabus.ReferenceList}}
+	<h3>{{if isLab $course.CourseType}}References / Manuals{{else}}Text Books and References{{end}}</h3>
+	<ol>
+	{{range $course.Syllabus.ReferenceList}}
+		<li>{{.}}</li>
+	{{end}}
+	</ol>
+	{{end}}
+</div>
+</body>
+</html>
+`
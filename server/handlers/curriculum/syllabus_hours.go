@@ -0,0 +1,136 @@
+package curriculum
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"server/db"
+	"server/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// checkSyllabusHours compares the hours entered for one syllabus component against the course total
+// ok is false when neither side has any hours and the check does not apply
+func checkSyllabusHours(component, source string, expected, actual int) (models.SyllabusHourCheck, bool) {
+	check := models.SyllabusHourCheck{
+		Component: component,
+		Source:    source,
+		Expected:  expected,
+		Actual:    actual,
+		Status:    "OK",
+	}
+	if expected == 0 && actual == 0 {
+		return check, false
+	}
+
+	switch {
+	case actual == 0:
+		check.Status = "MISSING"
+		check.Message = fmt.Sprintf("Course declares %d %s hours but the syllabus has none", expected, component)
+	case actual != expected:
+		check.Status = "MISMATCH"
+		check.Message = fmt.Sprintf("%s hours add up to %d but the course declares %d", source, actual, expected)
+	}
+	return check, true
+}
+
+// reconcileSyllabusHours checks that the hours in a course's syllabus agree with its L-T-P totals:
+// module titles against theory_total_hrs, experiments against practical_total_hrs and
+// teamwork plus self-learning against tw/sl
+func reconcileSyllabusHours(courseID int) (*models.SyllabusHourReport, error) {
+	report := &models.SyllabusHourReport{
+		CourseID: courseID,
+		Checks:   []models.SyllabusHourCheck{},
+		Warnings: []string{},
+	}
+
+	var theoryTotal, practicalTotal, twslTotal int
+	err := db.DB.QueryRow(`
+		SELECT course_code, course_name, COALESCE(theory_total_hrs, 0),
+		       COALESCE(practical_total_hrs, 0), COALESCE(`+"`tw/sl`"+`, 0)
+		FROM courses WHERE course_id = ?`, courseID).Scan(
+		&report.CourseCode, &report.CourseName, &theoryTotal, &practicalTotal, &twslTotal)
+	if err != nil {
+		return nil, err
+	}
+
+	var titleHours int
+	err = db.DB.QueryRow(`
+		SELECT COALESCE(SUM(t.hours), 0)
+		FROM syllabus_titles t
+		INNER JOIN syllabus s ON t.model_id = s.id
+		WHERE s.course_id = ? AND (s.status = 1 OR s.status IS NULL)
+		  AND (t.status = 1 OR t.status IS NULL)`, courseID).Scan(&titleHours)
+	if err != nil {
+		return nil, err
+	}
+
+	var experimentHours int
+	err = db.DB.QueryRow(`
+		SELECT COALESCE(SUM(hours), 0)
+		FROM course_experiments
+		WHERE course_id = ? AND status = 1`, courseID).Scan(&experimentHours)
+	if err != nil {
+		return nil, err
+	}
+
+	var teamworkHours, selfLearningHours int
+	err = db.DB.QueryRow("SELECT total_hours FROM course_teamwork WHERE course_id = ?", courseID).Scan(&teamworkHours)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	err = db.DB.QueryRow("SELECT total_hours FROM course_selflearning WHERE course_id = ?", courseID).Scan(&selfLearningHours)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	for _, c := range []struct {
+		component, source string
+		expected, actual  int
+	}{
+		{"theory", "Module title", theoryTotal, titleHours},
+		{"practical", "Experiment", practicalTotal, experimentHours},
+		{"teamwork/self-learning", "Teamwork and self-learning", twslTotal, teamworkHours + selfLearningHours},
+	} {
+		check, ok := checkSyllabusHours(c.component, c.source, c.expected, c.actual)
+		if !ok {
+			continue
+		}
+		report.Checks = append(report.Checks, check)
+		if check.Status != "OK" {
+			report.Warnings = append(report.Warnings, check.Message)
+		}
+	}
+
+	report.Valid = len(report.Warnings) == 0
+	return report, nil
+}
+
+// GetSyllabusHourValidation handles GET /course/:courseId/syllabus/validation
+func GetSyllabusHourValidation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	courseID, err := strconv.Atoi(vars["courseId"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	report, err := reconcileSyllabusHours(courseID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error reconciling syllabus hours:", err)
+		http.Error(w, "Failed to validate syllabus", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
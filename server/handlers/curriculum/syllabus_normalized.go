@@ -251,8 +251,8 @@ func saveObjectives(courseID int, objectives []string) error {
 	if len(incoming) == len(existing) {
 		for i := range incoming {
 			if _, err := tx.Exec(
-				"UPDATE course_objectives SET objective = ?, position = ?, status = 1 WHERE id = ?",
-				incoming[i], i, existing[i].ID,
+				"UPDATE course_objectives SET objective = ?, position = ?, status = 1, bloom_level = IF(bloom_level_manual = 1, bloom_level, ?) WHERE id = ?",
+				incoming[i], i, suggestBloomLevel(incoming[i]), existing[i].ID,
 			); err != nil {
 				log.Printf("ERROR saveObjectives update id=%d: %v", existing[i].ID, err)
 				return err
@@ -342,8 +342,8 @@ func saveObjectives(courseID int, objectives []string) error {
 			continue
 		}
 		if _, err := tx.Exec(
-			"INSERT INTO course_objectives (course_id, objective, position, status) VALUES (?, ?, ?, 1)",
-			courseID, text, idx,
+			"INSERT INTO course_objectives (course_id, objective, position, status, bloom_level) VALUES (?, ?, ?, 1, ?)",
+			courseID, text, idx, suggestBloomLevel(text),
 		); err != nil {
 			log.Printf("ERROR saveObjectives insert position=%d: %v", idx, err)
 			return err
@@ -407,8 +407,8 @@ func saveOutcomes(courseID int, outcomes []string) error {
 	if len(incoming) == len(existing) {
 		for i := range incoming {
 			if _, err := tx.Exec(
-				"UPDATE course_outcomes SET outcome = ?, position = ?, status = 1 WHERE id = ?",
-				incoming[i], i, existing[i].ID,
+				"UPDATE course_outcomes SET outcome = ?, position = ?, status = 1, bloom_level = IF(bloom_level_manual = 1, bloom_level, ?) WHERE id = ?",
+				incoming[i], i, suggestBloomLevel(incoming[i]), existing[i].ID,
 			); err != nil {
 				log.Printf("ERROR saveOutcomes update id=%d: %v", existing[i].ID, err)
 				return err
@@ -498,8 +498,8 @@ func saveOutcomes(courseID int, outcomes []string) error {
 			continue
 		}
 		if _, err := tx.Exec(
-			"INSERT INTO course_outcomes (course_id, outcome, position, status) VALUES (?, ?, ?, 1)",
-			courseID, text, idx,
+			"INSERT INTO course_outcomes (course_id, outcome, position, status, bloom_level) VALUES (?, ?, ?, 1, ?)",
+			courseID, text, idx, suggestBloomLevel(text),
 		); err != nil {
 			log.Printf("ERROR saveOutcomes insert position=%d: %v", idx, err)
 			return err
@@ -672,8 +672,16 @@ func saveReferences(courseID int, references []string) error {
 
 // savePrerequisites saves prerequisites for a course, replacing existing ones
 func savePrerequisites(courseID int, prerequisites []string) error {
+	// Keep course links of unchanged lines; new lines are linked when they start with a course code
+	// and the link passes the prerequisite graph checks
+	links := fetchPrerequisiteLinks(courseID)
+	requires, err := prerequisiteEdges(db.DB)
+	if err != nil {
+		return err
+	}
+
 	// Delete existing
-	_, err := db.DB.Exec("DELETE FROM course_prerequisites WHERE course_id = ?", courseID)
+	_, err = db.DB.Exec("DELETE FROM course_prerequisites WHERE course_id = ?", courseID)
 	if err != nil {
 		return err
 	}
@@ -683,9 +691,13 @@ func savePrerequisites(courseID int, prerequisites []string) error {
 		if text == "" {
 			continue
 		}
+		link, ok := links[text]
+		if !ok {
+			link = autoLinkPrerequisite(requires, courseID, text)
+		}
 		_, err := db.DB.Exec(`
-			INSERT INTO course_prerequisites (course_id, prerequisite, position) 
-			VALUES (?, ?, ?)`, courseID, text, i)
+			INSERT INTO course_prerequisites (course_id, prerequisite, prerequisite_course_id, position) 
+			VALUES (?, ?, ?, ?)`, courseID, text, link, i)
 		if err != nil {
 			return err
 		}
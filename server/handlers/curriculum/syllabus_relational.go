@@ -2,6 +2,7 @@ package curriculum
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"server/db"
@@ -22,6 +23,23 @@ func GetCourseSyllabusNested(w http.ResponseWriter, r *http.Request) {
 
 	curriculumTemplate := getCurriculumTemplateForCourse(courseID)
 
+	// A curriculum pinned to a published version of the course sees that version's syllabus
+	curriculumID, err := curriculumIDParam(r)
+	if err != nil {
+		http.Error(w, "Invalid curriculum ID", http.StatusBadRequest)
+		return
+	}
+	snapshot, pinned, err := pinnedCourseSnapshot(curriculumID, courseID)
+	if err != nil {
+		log.Println("Error loading pinned course version:", err)
+		http.Error(w, "Failed to load pinned course version", http.StatusInternalServerError)
+		return
+	}
+	if snapshot != nil {
+		json.NewEncoder(w).Encode(snapshotCourseSyllabus(courseID, curriculumTemplate, snapshot, pinned))
+		return
+	}
+
 	var resp models.CourseSyllabusResponse
 	resp.CurriculumTemplate = curriculumTemplate
 
@@ -157,6 +175,13 @@ func GetCourseSyllabusNested(w http.ResponseWriter, r *http.Request) {
 		resp.Experiments = experiments
 	}
 
+	// Warn when the syllabus hours do not agree with the course's L-T-P totals
+	if report, err := reconcileSyllabusHours(courseID); err != nil {
+		log.Println("Error reconciling syllabus hours:", err)
+	} else if len(report.Warnings) > 0 {
+		resp.Warnings = report.Warnings
+	}
+
 	json.NewEncoder(w).Encode(resp)
 }
 
@@ -198,6 +223,11 @@ func CreateModel(w http.ResponseWriter, r *http.Request) {
 
 	modelID, _ := result.LastInsertId()
 	log.Printf("DEBUG CreateModel: successfully created model with ID=%d", modelID)
+
+	if courseID, after := courseItemLogState("syllabus_model", int(modelID)); courseID > 0 {
+		logCourseChange(courseID, logEntity{"syllabus_model", int(modelID), opCreate}, "Syllabus Model Added",
+			"Added syllabus model: "+body.ModelName, requestUser(r), nil, after)
+	}
 	json.NewEncoder(w).Encode(map[string]int{"id": int(modelID)})
 }
 
@@ -219,6 +249,8 @@ func UpdateModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	courseID, before := courseItemLogState("syllabus_model", modelID)
+
 	_, err = db.DB.Exec(`
 		UPDATE syllabus 
 		SET model_name = ?, name = ?, position = ? 
@@ -230,6 +262,12 @@ func UpdateModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if courseID > 0 {
+		_, after := courseItemLogState("syllabus_model", modelID)
+		logCourseChange(courseID, logEntity{"syllabus_model", modelID, opUpdate}, "Syllabus Model Updated",
+			fmt.Sprintf("Updated syllabus model %d", modelID), requestUser(r), before, after)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -242,6 +280,8 @@ func DeleteModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logCourseID, before := courseItemLogState("syllabus_model", modelID)
+
 	tx, err := db.DB.Begin()
 	if err != nil {
 		log.Println("DeleteModel begin tx error:", err)
@@ -261,6 +301,13 @@ func DeleteModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Keep what is about to be deleted in the recycle bin
+	if err := recordDeletion(tx, "syllabus_model", modelID, 0, requestUser(r)); err != nil {
+		log.Println("DeleteModel record error:", err)
+		http.Error(w, "Failed to delete model", http.StatusInternalServerError)
+		return
+	}
+
 	// Soft delete the model
 	if _, err := tx.Exec("UPDATE syllabus SET status = 0 WHERE id = ? AND (status = 1 OR status IS NULL)", modelID); err != nil {
 		log.Println("DeleteModel soft delete error:", err)
@@ -293,6 +340,15 @@ func DeleteModel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if logCourseID > 0 {
+		if err := logCourseChangeTx(tx, logCourseID, logEntity{"syllabus_model", modelID, opDelete}, "Syllabus Model Deleted",
+			fmt.Sprintf("Deleted syllabus model %d", modelID), requestUser(r), before, nil); err != nil {
+			log.Println("DeleteModel log error:", err)
+			http.Error(w, "Failed to delete model", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		log.Println("DeleteModel commit error:", err)
 		http.Error(w, "Failed to delete model", http.StatusInternalServerError)
@@ -340,6 +396,11 @@ func CreateTitle(w http.ResponseWriter, r *http.Request) {
 
 	titleID, _ := result.LastInsertId()
 	log.Printf("DEBUG CreateTitle: successfully created title with ID=%d", titleID)
+
+	if courseID, after := courseItemLogState("syllabus_title", int(titleID)); courseID > 0 {
+		logCourseChange(courseID, logEntity{"syllabus_title", int(titleID), opCreate}, "Syllabus Title Added",
+			"Added syllabus title: "+body.TitleName, requestUser(r), nil, after)
+	}
 	json.NewEncoder(w).Encode(map[string]int{"id": int(titleID)})
 }
 
@@ -362,6 +423,8 @@ func UpdateTitle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	courseID, before := courseItemLogState("syllabus_title", titleID)
+
 	_, err = db.DB.Exec(`
 		UPDATE syllabus_titles 
 		SET title = ?, hours = ?, position = ? 
@@ -373,6 +436,12 @@ func UpdateTitle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if courseID > 0 {
+		_, after := courseItemLogState("syllabus_title", titleID)
+		logCourseChange(courseID, logEntity{"syllabus_title", titleID, opUpdate}, "Syllabus Title Updated",
+			fmt.Sprintf("Updated syllabus title %d", titleID), requestUser(r), before, after)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -385,6 +454,8 @@ func DeleteTitle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logCourseID, before := courseItemLogState("syllabus_title", titleID)
+
 	tx, err := db.DB.Begin()
 	if err != nil {
 		log.Println("DeleteTitle begin tx error:", err)
@@ -404,6 +475,13 @@ func DeleteTitle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Keep what is about to be deleted in the recycle bin
+	if err := recordDeletion(tx, "syllabus_title", titleID, 0, requestUser(r)); err != nil {
+		log.Println("DeleteTitle record error:", err)
+		http.Error(w, "Failed to delete title", http.StatusInternalServerError)
+		return
+	}
+
 	// Soft delete the title
 	if _, err := tx.Exec("UPDATE syllabus_titles SET status = 0 WHERE id = ? AND (status = 1 OR status IS NULL)", titleID); err != nil {
 		log.Println("DeleteTitle soft delete error:", err)
@@ -428,6 +506,15 @@ func DeleteTitle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if logCourseID > 0 {
+		if err := logCourseChangeTx(tx, logCourseID, logEntity{"syllabus_title", titleID, opDelete}, "Syllabus Title Deleted",
+			fmt.Sprintf("Deleted syllabus title %d", titleID), requestUser(r), before, nil); err != nil {
+			log.Println("DeleteTitle log error:", err)
+			http.Error(w, "Failed to delete title", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		log.Println("DeleteTitle commit error:", err)
 		http.Error(w, "Failed to delete title", http.StatusInternalServerError)
@@ -474,6 +561,11 @@ func CreateTopic(w http.ResponseWriter, r *http.Request) {
 
 	topicID, _ := result.LastInsertId()
 	log.Printf("DEBUG CreateTopic: successfully created topic with ID=%d", topicID)
+
+	if courseID, after := courseItemLogState("syllabus_topic", int(topicID)); courseID > 0 {
+		logCourseChange(courseID, logEntity{"syllabus_topic", int(topicID), opCreate}, "Syllabus Topic Added",
+			"Added syllabus topic: "+body.Topic, requestUser(r), nil, after)
+	}
 	json.NewEncoder(w).Encode(map[string]int{"id": int(topicID)})
 }
 
@@ -495,6 +587,8 @@ func UpdateTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	courseID, before := courseItemLogState("syllabus_topic", topicID)
+
 	_, err = db.DB.Exec(`
 		UPDATE syllabus_topics 
 		SET topic = ?, position = ? 
@@ -506,6 +600,12 @@ func UpdateTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if courseID > 0 {
+		_, after := courseItemLogState("syllabus_topic", topicID)
+		logCourseChange(courseID, logEntity{"syllabus_topic", topicID, opUpdate}, "Syllabus Topic Updated",
+			fmt.Sprintf("Updated syllabus topic %d", topicID), requestUser(r), before, after)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -518,6 +618,8 @@ func DeleteTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logCourseID, before := courseItemLogState("syllabus_topic", topicID)
+
 	tx, err := db.DB.Begin()
 	if err != nil {
 		log.Println("DeleteTopic begin tx error:", err)
@@ -537,6 +639,13 @@ func DeleteTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Keep what is about to be deleted in the recycle bin
+	if err := recordDeletion(tx, "syllabus_topic", topicID, 0, requestUser(r)); err != nil {
+		log.Println("DeleteTopic record error:", err)
+		http.Error(w, "Failed to delete topic", http.StatusInternalServerError)
+		return
+	}
+
 	// Soft delete topic
 	if _, err := tx.Exec("UPDATE syllabus_topics SET status = 0 WHERE id = ? AND (status = 1 OR status IS NULL)", topicID); err != nil {
 		log.Println("DeleteTopic soft delete error:", err)
@@ -554,6 +663,15 @@ func DeleteTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if logCourseID > 0 {
+		if err := logCourseChangeTx(tx, logCourseID, logEntity{"syllabus_topic", topicID, opDelete}, "Syllabus Topic Deleted",
+			fmt.Sprintf("Deleted syllabus topic %d", topicID), requestUser(r), before, nil); err != nil {
+			log.Println("DeleteTopic log error:", err)
+			http.Error(w, "Failed to delete topic", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		log.Println("DeleteTopic commit error:", err)
 		http.Error(w, "Failed to delete topic", http.StatusInternalServerError)
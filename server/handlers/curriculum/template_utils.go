@@ -30,7 +30,7 @@ func getCurriculumTemplateForCourse(courseID int) string {
         SELECT c.curriculum_template
         FROM curriculum_courses cc
         INNER JOIN curriculum c ON c.id = cc.curriculum_id
-        WHERE cc.course_id = ?
+        WHERE cc.course_id = ? AND cc.status = 1
         LIMIT 1`, courseID).Scan(&tmpl)
 	if err == nil && tmpl.Valid && tmpl.String != "" {
 		return tmpl.String
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"server/db"
+	"server/handlers/curriculum"
 	"server/middleware"
 	"server/routes"
 
@@ -92,6 +99,88 @@ func main() {
 		log.Fatal("Failed to remove name column from normal_cards:", err)
 	}
 
+	// Create CIA assessment tables
+	if err := db.CreateAssessmentTables(); err != nil {
+		log.Fatal("Failed to create assessment tables:", err)
+	}
+
+	// Create CO attainment tables
+	if err := db.CreateAttainmentTables(); err != nil {
+		log.Fatal("Failed to create attainment tables:", err)
+	}
+
+	// Create program-level attainment tables
+	if err := db.CreateProgramAttainmentTables(); err != nil {
+		log.Fatal("Failed to create program attainment tables:", err)
+	}
+
+	// Create indirect assessment survey tables
+	if err := db.CreateSurveyTables(); err != nil {
+		log.Fatal("Failed to create survey tables:", err)
+	}
+
+	// Add justification and outcome tracking columns to CO-PO/PSO mappings
+	if err := db.AddMappingJustificationColumns(); err != nil {
+		log.Fatal("Failed to add mapping justification columns:", err)
+	}
+
+	// Add Bloom's taxonomy level columns to course outcomes and objectives
+	if err := db.AddBloomLevelColumns(); err != nil {
+		log.Fatal("Failed to add bloom level columns:", err)
+	}
+
+	// Add course reference column to course prerequisites
+	if err := db.AddPrerequisiteCourseColumn(); err != nil {
+		log.Fatal("Failed to add prerequisite course column:", err)
+	}
+
+	// Add catalogue version and fork tracking columns to courses
+	if err := db.AddCourseCatalogueColumns(); err != nil {
+		log.Fatal("Failed to add course catalogue columns:", err)
+	}
+
+	// Create course version snapshot and curriculum pin tables
+	if err := db.CreateCourseVersionTables(); err != nil {
+		log.Fatal("Failed to create course version tables:", err)
+	}
+
+	// Create curriculum snapshot table
+	if err := db.CreateCurriculumSnapshotTables(); err != nil {
+		log.Fatal("Failed to create curriculum snapshot tables:", err)
+	}
+
+	// Create recycle bin table and purge expired entries in the background
+	if err := db.CreateRecycleBinTable(); err != nil {
+		log.Fatal("Failed to create recycle bin table:", err)
+	}
+	go curriculum.StartRecycleBinPurger(6 * time.Hour)
+
+	// Add change-capture columns and per-field change table to curriculum logs
+	if err := db.AddCurriculumLogChangeColumns(); err != nil {
+		log.Fatal("Failed to add curriculum log change columns:", err)
+	}
+
+	// Add curriculum publish state and create PDF branding and template tables
+	if err := db.CreatePDFTemplateTables(); err != nil {
+		log.Fatal("Failed to create PDF template tables:", err)
+	}
+
+	// Link curricula to the regulation document printed in their book
+	if err := db.AddCurriculumRegulationColumn(); err != nil {
+		log.Fatal("Failed to add curriculum regulation column:", err)
+	}
+
+	// Allow nested sub-clauses in regulation documents
+	if err := db.AddRegulationClauseParentColumn(); err != nil {
+		log.Fatal("Failed to add regulation clause parent column:", err)
+	}
+
+	// Write activity logs in order from a single background writer
+	curriculum.StartActivityLogger()
+
+	// Render regulation PDFs in the background with a bounded pool of Chrome workers
+	curriculum.StartPDFWorkers()
+
 	// Setup routes
 	router := routes.SetupRoutes()
 
@@ -103,6 +192,29 @@ func main() {
 	// Wrap with CORS middleware
 	handler := middleware.CORSMiddleware(router)
 
-	fmt.Println("Server started at http://localhost:5000")
-	log.Fatal(http.ListenAndServe(":5000", handler))
+	server := &http.Server{Addr: ":5000", Handler: handler}
+	go func() {
+		fmt.Println("Server started at http://localhost:5000")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Shut down gracefully: finish in-flight requests and PDF jobs, then flush pending activity logs
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	log.Println("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("Error shutting down server:", err)
+	}
+	if err := curriculum.StopPDFWorkers(ctx); err != nil {
+		log.Println("Error stopping PDF workers:", err)
+	}
+	if err := curriculum.StopActivityLogger(ctx); err != nil {
+		log.Println("Error flushing activity logs:", err)
+	}
 }
@@ -0,0 +1,33 @@
+package models
+
+// ArticulationRow is one course's row of the curriculum articulation matrix
+// Each value is the average of the course's non-zero CO mappings to that PO/PSO; nil when unmapped
+type ArticulationRow struct {
+	CourseID   int        `json:"course_id"`
+	CourseCode string     `json:"course_code"`
+	CourseName string     `json:"course_name"`
+	Semester   int        `json:"semester"`
+	POValues   []*float64 `json:"po_values"`  // index 0 is PO1
+	PSOValues  []*float64 `json:"pso_values"` // index 0 is PSO1
+}
+
+// OutcomeCoverage summarises how strongly the program addresses one PO or PSO
+type OutcomeCoverage struct {
+	OutcomeType   string   `json:"outcome_type"`
+	Index         int      `json:"index"`
+	CoursesMapped int      `json:"courses_mapped"`
+	Average       *float64 `json:"average"`
+	Weak          bool     `json:"weak"`
+}
+
+// ArticulationMatrix is the course x PO/PSO articulation matrix of a curriculum
+type ArticulationMatrix struct {
+	CurriculumID  int               `json:"curriculum_id"`
+	POCount       int               `json:"po_count"`
+	PSOCount      int               `json:"pso_count"`
+	WeakThreshold float64           `json:"weak_threshold"`
+	Courses       []ArticulationRow `json:"courses"`
+	POCoverage    []OutcomeCoverage `json:"po_coverage"`
+	PSOCoverage   []OutcomeCoverage `json:"pso_coverage"`
+	WeakOutcomes  []string          `json:"weak_outcomes"`
+}
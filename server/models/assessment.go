@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// AssessmentComponent is one CIA component of a course (test, assignment, lab record...)
+// Weightage is the share of the course's cia_marks this component contributes
+type AssessmentComponent struct {
+	ID            int     `json:"id"`
+	CourseID      int     `json:"course_id"`
+	Name          string  `json:"name"`
+	ComponentType string  `json:"component_type"` // TEST, ASSIGNMENT, LAB_RECORD, OTHER
+	MaxMarks      float64 `json:"max_marks"`
+	Weightage     float64 `json:"weightage"`
+	Position      int     `json:"position"`
+}
+
+// AssessmentComponentsRequest replaces the full set of components for a course
+type AssessmentComponentsRequest struct {
+	Components []AssessmentComponent `json:"components"`
+}
+
+// AssessmentComponentsResponse lists components together with the course CIA split
+type AssessmentComponentsResponse struct {
+	CourseID       int                   `json:"course_id"`
+	CIAMarks       int                   `json:"cia_marks"`
+	TotalWeightage float64               `json:"total_weightage"`
+	Components     []AssessmentComponent `json:"components"`
+}
+
+// AssessmentMark is the marks a student obtained in one component
+type AssessmentMark struct {
+	ID            int       `json:"id"`
+	ComponentID   int       `json:"component_id"`
+	StudentID     int       `json:"student_id"`
+	StudentName   string    `json:"student_name,omitempty"`
+	AcademicYear  string    `json:"academic_year"`
+	Section       string    `json:"section"`
+	MarksObtained float64   `json:"marks_obtained"`
+	EnteredBy     int       `json:"entered_by"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// MarksEntryRequest is submitted by an allocated teacher for one course/section;
+// the teacher is the signed-in user, never an id from the body
+type MarksEntryRequest struct {
+	AcademicYear string           `json:"academic_year"`
+	Section      string           `json:"section"`
+	Marks        []AssessmentMark `json:"marks"`
+}
+
+// MarksSubmission records that a course/section marks sheet was submitted and locked
+type MarksSubmission struct {
+	ID           int        `json:"id"`
+	CourseID     int        `json:"course_id"`
+	AcademicYear string     `json:"academic_year"`
+	Section      string     `json:"section"`
+	SubmittedBy  int        `json:"submitted_by"` // set from the signed-in teacher
+	IsLocked     bool       `json:"is_locked"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+}
+
+// MarksSheetResponse is the marks sheet for one course/section
+type MarksSheetResponse struct {
+	CourseID     int                   `json:"course_id"`
+	AcademicYear string                `json:"academic_year"`
+	Section      string                `json:"section"`
+	IsLocked     bool                  `json:"is_locked"`
+	Components   []AssessmentComponent `json:"components"`
+	Marks        []AssessmentMark      `json:"marks"`
+}
+
+// StudentCIATotal is the computed CIA total of one student for a course
+type StudentCIATotal struct {
+	StudentID       int                `json:"student_id"`
+	StudentName     string             `json:"student_name"`
+	ComponentScores map[string]float64 `json:"component_scores"` // key: component_id, value: weighted score
+	CIATotal        float64            `json:"cia_total"`
+	CIAMarks        int                `json:"cia_marks"`
+}
@@ -0,0 +1,95 @@
+package models
+
+// AssessmentQuestion is a question inside a CIA component tagged to one course outcome
+// COIndex follows co_po_mapping: the 0-based position of the outcome in course_outcomes.
+// The question is stored against OutcomeID, so it keeps its outcome when outcomes are reordered
+type AssessmentQuestion struct {
+	ID          int     `json:"id"`
+	ComponentID int     `json:"component_id"`
+	QuestionNo  string  `json:"question_no"`
+	MaxMarks    float64 `json:"max_marks"`
+	COIndex     int     `json:"co_index"`
+	OutcomeID   *int    `json:"outcome_id,omitempty"`
+	Position    int     `json:"position"`
+	Orphaned    string  `json:"orphaned,omitempty"` // why the question no longer has an outcome, when it does not
+}
+
+// OrphanedQuestion is a question whose course outcome has been deleted; it counts towards no CO
+type OrphanedQuestion struct {
+	QuestionID    int    `json:"question_id"`
+	ComponentID   int    `json:"component_id"`
+	ComponentName string `json:"component_name"`
+	QuestionNo    string `json:"question_no"`
+	Message       string `json:"message"`
+}
+
+// AssessmentQuestionsRequest replaces the full set of questions for a component
+type AssessmentQuestionsRequest struct {
+	Questions []AssessmentQuestion `json:"questions"`
+}
+
+// QuestionMark is the marks a student obtained for one question
+type QuestionMark struct {
+	QuestionID    int     `json:"question_id"`
+	StudentID     int     `json:"student_id"`
+	MarksObtained float64 `json:"marks_obtained"`
+}
+
+// QuestionMarksRequest is submitted by an allocated teacher for one course/section;
+// the teacher is the signed-in user, never an id from the body
+type QuestionMarksRequest struct {
+	AcademicYear string         `json:"academic_year"`
+	Section      string         `json:"section"`
+	Marks        []QuestionMark `json:"marks"`
+}
+
+// AttainmentTargets configures how CO attainment levels are derived for a course
+// A student attains a CO when scoring at least StudentThreshold percent on its questions;
+// the CO reaches level N when the share of such students is at least LevelN percent
+type AttainmentTargets struct {
+	CourseID         int     `json:"course_id"`
+	StudentThreshold float64 `json:"student_threshold"`
+	Level1           float64 `json:"level_1"`
+	Level2           float64 `json:"level_2"`
+	Level3           float64 `json:"level_3"`
+}
+
+// COAttainment is the direct attainment of a single course outcome
+type COAttainment struct {
+	COIndex          int     `json:"co_index"`
+	Outcome          string  `json:"outcome"`
+	StudentsAssessed int     `json:"students_assessed"`
+	StudentsAttained int     `json:"students_attained"`
+	Percentage       float64 `json:"percentage"`
+	Level            int     `json:"level"`
+}
+
+// OutcomeAttainment is the attainment of a PO or PSO rolled up through the CO mapping matrix
+type OutcomeAttainment struct {
+	Index      int     `json:"index"` // 1-based PO/PSO number
+	Attainment float64 `json:"attainment"`
+}
+
+// CourseAttainment is the full CO, PO and PSO attainment of a course
+type CourseAttainment struct {
+	CourseID      int                 `json:"course_id"`
+	CourseCode    string              `json:"course_code"`
+	CourseName    string              `json:"course_name"`
+	AcademicYear  string              `json:"academic_year"`
+	Section       string              `json:"section,omitempty"`
+	Targets       AttainmentTargets   `json:"targets"`
+	COs           []COAttainment      `json:"cos"`
+	POAttainment  []OutcomeAttainment `json:"po_attainment"`
+	PSOAttainment []OutcomeAttainment `json:"pso_attainment"`
+	PinnedVersion int                 `json:"pinned_version,omitempty"` // outcomes and mappings come from this pinned course version
+	Orphaned      []OrphanedQuestion  `json:"orphaned_questions"`
+}
+
+// CurriculumAttainment aggregates course attainment across a curriculum
+type CurriculumAttainment struct {
+	CurriculumID  int                 `json:"curriculum_id"`
+	AcademicYear  string              `json:"academic_year"`
+	Courses       []CourseAttainment  `json:"courses"`
+	POAttainment  []OutcomeAttainment `json:"po_attainment"`
+	PSOAttainment []OutcomeAttainment `json:"pso_attainment"`
+}
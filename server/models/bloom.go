@@ -0,0 +1,61 @@
+package models
+
+// BloomAnalysis is the server's reading of a course outcome or objective statement
+type BloomAnalysis struct {
+	Verb           string `json:"verb"`
+	SuggestedLevel string `json:"suggested_level,omitempty"` // K1-K6
+	LevelName      string `json:"level_name,omitempty"`
+	Measurable     bool   `json:"measurable"`
+	Warning        string `json:"warning,omitempty"`
+}
+
+// BloomSuggestRequest asks for the Bloom's level of a statement
+type BloomSuggestRequest struct {
+	Text string `json:"text"`
+}
+
+// BloomStatement is an outcome or objective with its stored and suggested Bloom's level
+// Manual levels were set by hand; the others follow the suggestion as the text is edited
+type BloomStatement struct {
+	ID         int           `json:"id"`
+	Position   int           `json:"position"`
+	Text       string        `json:"text"`
+	BloomLevel string        `json:"bloom_level"`
+	Manual     bool          `json:"bloom_level_manual"`
+	Analysis   BloomAnalysis `json:"analysis"`
+}
+
+// BloomLevelUpdate sets the Bloom's level of one outcome or objective by hand; an empty level
+// clears it and hands the statement back to the suggestion
+type BloomLevelUpdate struct {
+	ID         int    `json:"id"`
+	BloomLevel string `json:"bloom_level"`
+}
+
+// BloomLevelsRequest updates Bloom's levels of a course's outcomes and objectives
+type BloomLevelsRequest struct {
+	Outcomes   []BloomLevelUpdate `json:"outcomes"`
+	Objectives []BloomLevelUpdate `json:"objectives"`
+}
+
+// CourseBloomReport lists a course's statements with Bloom's levels and their distribution
+// Distributions are keyed K1-K6, with "UNCLASSIFIED" for statements that have no level
+type CourseBloomReport struct {
+	CourseID              int              `json:"course_id"`
+	CourseCode            string           `json:"course_code,omitempty"`
+	CourseName            string           `json:"course_name,omitempty"`
+	Outcomes              []BloomStatement `json:"outcomes"`
+	Objectives            []BloomStatement `json:"objectives"`
+	OutcomeDistribution   map[string]int   `json:"outcome_distribution"`
+	ObjectiveDistribution map[string]int   `json:"objective_distribution"`
+	Warnings              []string         `json:"warnings"`
+}
+
+// CurriculumBloomDistribution aggregates Bloom's distribution across a curriculum's courses
+type CurriculumBloomDistribution struct {
+	CurriculumID          int                 `json:"curriculum_id"`
+	Courses               []CourseBloomReport `json:"courses"`
+	OutcomeDistribution   map[string]int      `json:"outcome_distribution"`
+	ObjectiveDistribution map[string]int      `json:"objective_distribution"`
+	WarningCount          int                 `json:"warning_count"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// CourseUsage is one place a catalogue course is used: a semester card or an honour vertical of a curriculum
+type CourseUsage struct {
+	CurriculumID   int    `json:"curriculum_id"`
+	CurriculumName string `json:"curriculum_name"`
+	CardType       string `json:"card_type"`
+	SemesterID     *int   `json:"semester_id,omitempty"`
+	SemesterNumber *int   `json:"semester_number,omitempty"`
+	VerticalID     *int   `json:"vertical_id,omitempty"`
+	VerticalName   string `json:"vertical_name,omitempty"`
+}
+
+// CatalogueCourse is a course in the master catalogue with where it is used
+// OwnerCurriculumID is set on curriculum-specific copies forked from ForkedFromID
+type CatalogueCourse struct {
+	Course
+	Version           int           `json:"version"`
+	ForkedFromID      *int          `json:"forked_from_course_id"`
+	OwnerCurriculumID *int          `json:"owner_curriculum_id"`
+	Usage             []CourseUsage `json:"usage"`
+}
@@ -0,0 +1,66 @@
+package models
+
+// CourseSnapshot is the complete content of a course at one published version
+type CourseSnapshot struct {
+	Course         Course             `json:"course"`
+	Objectives     []string           `json:"objectives"`
+	Outcomes       []string           `json:"outcomes"`
+	Prerequisites  []string           `json:"prerequisites"`
+	ReferenceList  []string           `json:"reference_list"`
+	Teamwork       *Teamwork          `json:"teamwork,omitempty"`
+	SelfLearning   *SelfLearning      `json:"selflearning,omitempty"`
+	Models         []SyllabusModelPDF `json:"models"`
+	Experiments    []Experiment       `json:"experiments"`
+	COPOMapping    map[string]int     `json:"co_po_mapping"`
+	COPSOMapping   map[string]int     `json:"co_pso_mapping"`
+	Justifications []MappingNotePDF   `json:"justifications"`
+}
+
+// CourseVersion is a published version of a course; Snapshot is only filled when a single version is fetched
+type CourseVersion struct {
+	ID        int             `json:"id"`
+	CourseID  int             `json:"course_id"`
+	Version   int             `json:"version"`
+	Note      string          `json:"note"`
+	CreatedBy string          `json:"created_by"`
+	CreatedAt string          `json:"created_at"`
+	Snapshot  *CourseSnapshot `json:"snapshot,omitempty"`
+}
+
+// PublishCourseVersionRequest publishes the current state of a course as a new version
+type PublishCourseVersionRequest struct {
+	Note string `json:"note"`
+}
+
+// CourseVersionDiff lists the fields that differ between two versions of a course
+// ToVersion is nil when comparing against the current, unpublished state
+type CourseVersionDiff struct {
+	CourseID    int                               `json:"course_id"`
+	FromVersion int                               `json:"from_version"`
+	ToVersion   *int                              `json:"to_version"`
+	Changes     map[string]map[string]interface{} `json:"changes"`
+}
+
+// CurriculumCourseVersion is the version a curriculum uses for one of its courses
+// PinnedVersion is nil when the curriculum follows the live course
+type CurriculumCourseVersion struct {
+	CourseID         int    `json:"course_id"`
+	CourseCode       string `json:"course_code"`
+	CourseName       string `json:"course_name"`
+	PinnedVersion    *int   `json:"pinned_version"`
+	LatestVersion    int    `json:"latest_version"`
+	UpgradeAvailable bool   `json:"upgrade_available"`
+}
+
+// PinCourseVersionRequest pins a curriculum to a published version of a course; version 0 unpins it
+type PinCourseVersionRequest struct {
+	CourseID int `json:"course_id"`
+	Version  int `json:"version"`
+}
+
+// UpgradeCourseVersionRequest moves a curriculum's pin to a newer version (the latest when Version is 0)
+// With DryRun set only the diff is returned
+type UpgradeCourseVersionRequest struct {
+	Version int  `json:"version"`
+	DryRun  bool `json:"dry_run"`
+}
@@ -29,6 +29,7 @@ type Course struct {
 	TotalMarks         int    `json:"total_marks"`
 	CountTowardsLimit  *bool  `json:"count_towards_limit,omitempty"`
 	CurriculumTemplate string `json:"curriculum_template,omitempty"`
+	LinkMode           string `json:"link_mode,omitempty"` // "link" or "fork" when the code already exists in the catalogue
 }
 
 type RegulationCourse struct {
@@ -61,6 +62,7 @@ type CourseWithDetails struct {
 	CountTowardsLimit  *bool  `json:"count_towards_limit,omitempty"`
 	RegCourseID        int    `json:"reg_course_id"`
 	CurriculumTemplate string `json:"curriculum_template,omitempty"`
+	PinnedVersion      *int   `json:"pinned_version,omitempty"`
 }
 
 type HonourCard struct {
@@ -11,6 +11,9 @@ type CurriculumLog struct {
 	Action       string          `json:"action"`
 	Description  string          `json:"description"`
 	ChangedBy    string          `json:"changed_by"`
+	EntityType   string          `json:"entity_type,omitempty"`
+	EntityID     *int            `json:"entity_id,omitempty"`
+	Operation    string          `json:"operation,omitempty"`
 	Diff         json.RawMessage `json:"diff,omitempty"`
 	CreatedAt    time.Time       `json:"created_at"`
 }
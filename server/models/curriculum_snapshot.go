@@ -0,0 +1,87 @@
+package models
+
+// CurriculumSnapshotFormat is the format_version written into new curriculum snapshots
+const CurriculumSnapshotFormat = 1
+
+// SnapshotSemester is a semester (or other normal card) of a curriculum at snapshot time
+type SnapshotSemester struct {
+	ID             int    `json:"id"`
+	SemesterNumber *int   `json:"semester_number"`
+	CardType       string `json:"card_type"`
+}
+
+// SnapshotCourseLink places a course in a semester card
+type SnapshotCourseLink struct {
+	SemesterID        int  `json:"semester_id"`
+	CourseID          int  `json:"course_id"`
+	CountTowardsLimit bool `json:"count_towards_limit"`
+}
+
+// SnapshotHonourVertical is a vertical of an honour card with the ids of its courses
+type SnapshotHonourVertical struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	CourseIDs []int  `json:"course_ids"`
+}
+
+// SnapshotHonourCard is an honour card with its verticals
+type SnapshotHonourCard struct {
+	ID        int                      `json:"id"`
+	Title     string                   `json:"title"`
+	Verticals []SnapshotHonourVertical `json:"verticals"`
+}
+
+// CurriculumSnapshotData is the full content of a curriculum stored as versioned JSON.
+// Courses are keyed by course id; Pins maps course id to the pinned course version
+type CurriculumSnapshotData struct {
+	FormatVersion int                        `json:"format_version"`
+	Name          string                     `json:"name"`
+	AcademicYear  string                     `json:"academic_year"`
+	Template      string                     `json:"curriculum_template"`
+	MaxCredits    int                        `json:"max_credits"`
+	Overview      DepartmentOverview         `json:"overview"`
+	PEOPOMapping  []PEOPOMapping             `json:"peo_po_mapping"`
+	Semesters     []SnapshotSemester         `json:"semesters"`
+	CourseLinks   []SnapshotCourseLink       `json:"course_links"`
+	HonourCards   []SnapshotHonourCard       `json:"honour_cards"`
+	Courses       map[string]*CourseSnapshot `json:"courses"`
+	Pins          map[string]int             `json:"pins"`
+}
+
+// CurriculumSnapshot is a named point-in-time copy of a curriculum; Data is only filled when a single snapshot is fetched
+type CurriculumSnapshot struct {
+	ID           int                     `json:"id"`
+	CurriculumID int                     `json:"curriculum_id"`
+	Version      int                     `json:"version"`
+	Name         string                  `json:"name"`
+	CreatedBy    string                  `json:"created_by"`
+	CreatedAt    string                  `json:"created_at"`
+	Data         *CurriculumSnapshotData `json:"data,omitempty"`
+}
+
+// CreateCurriculumSnapshotRequest names a new snapshot of the current curriculum
+type CreateCurriculumSnapshotRequest struct {
+	Name      string `json:"name"`
+	CreatedBy string `json:"created_by"`
+}
+
+// CurriculumSnapshotDiff lists what differs between two snapshots of a curriculum.
+// ToSnapshotID is nil when comparing against the live curriculum
+type CurriculumSnapshotDiff struct {
+	CurriculumID   int                                          `json:"curriculum_id"`
+	FromSnapshotID int                                          `json:"from_snapshot_id"`
+	ToSnapshotID   *int                                         `json:"to_snapshot_id"`
+	Changes        map[string]map[string]interface{}            `json:"changes"`
+	AddedCourses   []int                                        `json:"added_courses"`
+	RemovedCourses []int                                        `json:"removed_courses"`
+	CourseChanges  map[string]map[string]map[string]interface{} `json:"course_changes"`
+}
+
+// RestoreCurriculumSnapshotResult reports what a restore changed
+type RestoreCurriculumSnapshotResult struct {
+	CurriculumID    int         `json:"curriculum_id"`
+	SnapshotID      int         `json:"snapshot_id"`
+	RestoredCourses []int       `json:"restored_courses"`
+	ForkedCourses   map[int]int `json:"forked_courses"`
+	Message         string      `json:"message"`
+}
@@ -1,28 +1,55 @@
 package models
 
 type COPOMapping struct {
-	ID           int `json:"id"`
-	CourseID     int `json:"course_id"`
-	COIndex      int `json:"co_index"`
-	POIndex      int `json:"po_index"`
-	MappingValue int `json:"mapping_value"`
+	ID            int     `json:"id"`
+	CourseID      int     `json:"course_id"`
+	COIndex       int     `json:"co_index"`
+	POIndex       int     `json:"po_index"`
+	MappingValue  int     `json:"mapping_value"`
+	Justification *string `json:"justification,omitempty"` // nil keeps the stored justification
 }
 
 type COPSOMapping struct {
-	ID           int `json:"id"`
-	CourseID     int `json:"course_id"`
-	COIndex      int `json:"co_index"`
-	PSOIndex     int `json:"pso_index"`
-	MappingValue int `json:"mapping_value"`
+	ID            int     `json:"id"`
+	CourseID      int     `json:"course_id"`
+	COIndex       int     `json:"co_index"`
+	PSOIndex      int     `json:"pso_index"`
+	MappingValue  int     `json:"mapping_value"`
+	Justification *string `json:"justification,omitempty"` // nil keeps the stored justification
 }
 
 type MappingResponse struct {
-	COs         []string       `json:"cos"`
-	COPOMatrix  map[string]int `json:"co_po_matrix"`  // key: "co_index-po_index"
-	COPSOMatrix map[string]int `json:"co_pso_matrix"` // key: "co_index-pso_index"
+	COs                 []string          `json:"cos"`
+	COPOMatrix          map[string]int    `json:"co_po_matrix"`          // key: "co_index-po_index"
+	COPSOMatrix         map[string]int    `json:"co_pso_matrix"`         // key: "co_index-pso_index"
+	COPOJustifications  map[string]string `json:"co_po_justifications"`  // key: "co_index-po_index"
+	COPSOJustifications map[string]string `json:"co_pso_justifications"` // key: "co_index-pso_index"
+	Issues              []MappingIssue    `json:"issues"`
+	PinnedVersion       int               `json:"pinned_version,omitempty"` // set when served from a curriculum's pinned course version
 }
 
 type MappingRequest struct {
 	COPOMatrix  []COPOMapping  `json:"co_po_matrix"`
 	COPSOMatrix []COPSOMapping `json:"co_pso_matrix"`
 }
+
+// MappingIssue is a problem found in a stored CO-PO or CO-PSO mapping cell
+type MappingIssue struct {
+	Matrix       string `json:"matrix"` // CO-PO or CO-PSO
+	IssueType    string `json:"issue_type"`
+	COIndex      int    `json:"co_index"`
+	OutcomeIndex int    `json:"outcome_index"` // PO or PSO number
+	Message      string `json:"message"`
+}
+
+// MappingValidation summarises the consistency of a course's CO-PO/PSO mappings
+type MappingValidation struct {
+	CourseID   int            `json:"course_id"`
+	CourseCode string         `json:"course_code,omitempty"`
+	CourseName string         `json:"course_name,omitempty"`
+	COCount    int            `json:"co_count"`
+	POCount    int            `json:"po_count"`
+	PSOCount   int            `json:"pso_count"`
+	Valid      bool           `json:"valid"`
+	Issues     []MappingIssue `json:"issues"`
+}
@@ -1,15 +1,80 @@
 package models
 
+import "time"
+
 // RegulationPDF represents all data needed for PDF generation
 type RegulationPDF struct {
-	CurriculumID       int                `json:"curriculum_id"`
-	RegulationName     string             `json:"regulation_name"`
-	AcademicYear       string             `json:"academic_year"`
-	CurriculumTemplate string             `json:"curriculum_template"`
-	Overview           DepartmentOverview `json:"overview"`
-	Semesters          []SemesterPDF      `json:"semesters"`
-	HonourCards        []HonourCardPDF    `json:"honour_cards"`
-	PEOPOMapping       map[string]int     `json:"peo_po_mapping"`
+	CurriculumID       int                 `json:"curriculum_id"`
+	RegulationName     string              `json:"regulation_name"`
+	AcademicYear       string              `json:"academic_year"`
+	CurriculumTemplate string              `json:"curriculum_template"`
+	Overview           DepartmentOverview  `json:"overview"`
+	Semesters          []SemesterPDF       `json:"semesters"`
+	HonourCards        []HonourCardPDF     `json:"honour_cards"`
+	PEOPOMapping       map[string]int      `json:"peo_po_mapping"`
+	ProgramAttainment  *ProgramAttainment  `json:"program_attainment,omitempty"`
+	Articulation       *ArticulationMatrix `json:"articulation,omitempty"`
+	Branding           PDFBranding         `json:"branding"`
+	Draft              bool                `json:"draft"`
+	// RegulationDocument is the regulation whose sections and clauses open the book, if one is linked
+	RegulationDocument *RegulationStructure `json:"regulation_document,omitempty"`
+	// Sections are the parts of the book that were asked for, keyed like "overview" or "syllabi"
+	Sections map[string]bool `json:"sections"`
+	// GeneratedDate is printed on the cover; it is part of the cache key so a cached PDF never shows a stale date
+	GeneratedDate string `json:"generated_date"`
+	// TemplateHTML is the HTML template resolved for the curriculum's department and template
+	TemplateHTML string `json:"-"`
+}
+
+// CourseSyllabusPDF is the data of a single-course syllabus document; the curriculum fields
+// come from the curriculum the course is exported for and are empty for an unassigned course
+type CourseSyllabusPDF struct {
+	CurriculumID       int         `json:"curriculum_id"`
+	RegulationName     string      `json:"regulation_name"`
+	AcademicYear       string      `json:"academic_year"`
+	CurriculumTemplate string      `json:"curriculum_template"`
+	POCount            int         `json:"po_count"`
+	PSOCount           int         `json:"pso_count"`
+	Course             CoursePDF   `json:"course"`
+	Branding           PDFBranding `json:"branding"`
+	Draft              bool        `json:"draft"`
+	GeneratedDate      string      `json:"generated_date"`
+	TemplateHTML       string      `json:"-"`
+}
+
+// PDFBranding is the identity and page setup of an institution (DepartmentID 0) or of one
+// department, used on the cover page and in the page layout of exported documents
+type PDFBranding struct {
+	DepartmentID    int      `json:"department_id"`
+	InstitutionName string   `json:"institution_name"`
+	InfoLines       []string `json:"info_lines"`
+	AddressLines    []string `json:"address_lines"`
+	ContactLines    []string `json:"contact_lines"`
+	LogoPath        string   `json:"logo_path"`
+	HeaderText      string   `json:"header_text"`
+	FooterText      string   `json:"footer_text"`
+	PaperSize       string   `json:"paper_size"`
+	Landscape       bool     `json:"landscape"`
+	MarginTop       float64  `json:"margin_top"`
+	MarginBottom    float64  `json:"margin_bottom"`
+	MarginLeft      float64  `json:"margin_left"`
+	MarginRight     float64  `json:"margin_right"`
+	PageNumbers     bool     `json:"page_numbers"`
+}
+
+// PDFTemplate is an uploaded HTML template for regulation or syllabus PDFs. Templates are
+// chosen by department and curriculum template, DepartmentID 0 and an empty
+// CurriculumTemplate matching any
+type PDFTemplate struct {
+	ID                 int       `json:"id"`
+	Name               string    `json:"name"`
+	Kind               string    `json:"kind"`
+	DepartmentID       int       `json:"department_id"`
+	CurriculumTemplate string    `json:"curriculum_template"`
+	HTML               string    `json:"html,omitempty"`
+	CreatedBy          string    `json:"created_by"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 type SemesterPDF struct {
@@ -50,9 +115,18 @@ type CoursePDF struct {
 	Models         []SyllabusModelPDF `json:"models"`
 	COPOMapping    map[string]int     `json:"co_po_mapping"`
 	COPSOMapping   map[string]int     `json:"co_pso_mapping"`
+	Justifications []MappingNotePDF   `json:"justifications,omitempty"`
 	Experiments    []Experiment       `json:"experiments,omitempty"`
 }
 
+// MappingNotePDF is the justification of one mapped CO-PO/PSO cell
+type MappingNotePDF struct {
+	CO            int    `json:"co"`      // 1-based CO number
+	Outcome       string `json:"outcome"` // PO3, PSO1...
+	Value         int    `json:"value"`
+	Justification string `json:"justification"`
+}
+
 type SyllabusPDF struct {
 	Objectives    []string      `json:"objectives"`
 	Outcomes      []string      `json:"outcomes"`
@@ -0,0 +1,50 @@
+package models
+
+// CoursePrerequisite is one prerequisite line of a course
+// CourseID/CourseCode are set when the line references a real course; otherwise it is free text
+type CoursePrerequisite struct {
+	Text       string `json:"text"`
+	CourseID   *int   `json:"course_id"`
+	CourseCode string `json:"course_code,omitempty"`
+	CourseName string `json:"course_name,omitempty"`
+}
+
+// PrerequisitesRequest replaces the prerequisites of a course
+// An item may name a course by course_code; its text defaults to the course code and name
+type PrerequisitesRequest struct {
+	Prerequisites []CoursePrerequisite `json:"prerequisites"`
+}
+
+// PrerequisiteNode is a course of the curriculum prerequisite graph
+type PrerequisiteNode struct {
+	CourseID   int    `json:"course_id"`
+	CourseCode string `json:"course_code"`
+	CourseName string `json:"course_name"`
+	Semester   int    `json:"semester"`
+	FreeText   int    `json:"free_text_prerequisites"`
+}
+
+// PrerequisiteEdge points from a prerequisite course to the course that requires it
+type PrerequisiteEdge struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// PrerequisiteIssue describes a prerequisite that breaks the curriculum ordering
+// IssueType is NOT_IN_CURRICULUM, SEMESTER_ORDER or CYCLE
+type PrerequisiteIssue struct {
+	IssueType string `json:"issue_type"`
+	CourseIDs []int  `json:"course_ids"`
+	Message   string `json:"message"`
+}
+
+// PrerequisiteGraph is the prerequisite DAG of a curriculum
+// Order is a topological order of the courses and is empty when the graph has a cycle
+type PrerequisiteGraph struct {
+	CurriculumID int                 `json:"curriculum_id"`
+	Nodes        []PrerequisiteNode  `json:"nodes"`
+	Edges        []PrerequisiteEdge  `json:"edges"`
+	Order        []int               `json:"order"`
+	Valid        bool                `json:"valid"`
+	Issues       []PrerequisiteIssue `json:"issues"`
+}
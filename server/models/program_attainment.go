@@ -0,0 +1,54 @@
+package models
+
+// OutcomeTarget is the attainment target (0-3 scale) of a single PO or PSO
+type OutcomeTarget struct {
+	OutcomeType string  `json:"outcome_type"` // PO or PSO
+	Index       int     `json:"index"`        // 1-based PO/PSO number
+	Target      float64 `json:"target"`
+}
+
+// ProgramAttainmentConfig holds the direct/indirect weights and per-outcome targets of a curriculum
+type ProgramAttainmentConfig struct {
+	CurriculumID   int             `json:"curriculum_id"`
+	DirectWeight   float64         `json:"direct_weight"`
+	IndirectWeight float64         `json:"indirect_weight"`
+	DefaultTarget  float64         `json:"default_target"`
+	Targets        []OutcomeTarget `json:"targets"`
+}
+
+// IndirectAttainmentValue is an indirect (survey based) attainment value for a PO or PSO
+type IndirectAttainmentValue struct {
+	OutcomeType string  `json:"outcome_type"`
+	Index       int     `json:"index"`
+	Value       float64 `json:"value"`
+	Source      string  `json:"source,omitempty"`
+}
+
+// IndirectAttainmentRequest records indirect attainment values for an academic year
+type IndirectAttainmentRequest struct {
+	AcademicYear string                    `json:"academic_year"`
+	Values       []IndirectAttainmentValue `json:"values"`
+}
+
+// ProgramOutcomeAttainment is the combined attainment of one PO or PSO for a curriculum
+type ProgramOutcomeAttainment struct {
+	OutcomeType   string   `json:"outcome_type"`
+	Index         int      `json:"index"`
+	Statement     string   `json:"statement"`
+	Direct        *float64 `json:"direct"`
+	Indirect      *float64 `json:"indirect"`
+	Final         *float64 `json:"final"`
+	Target        float64  `json:"target"`
+	TargetMet     bool     `json:"target_met"`
+	CoursesMapped int      `json:"courses_mapped"`
+}
+
+// ProgramAttainment is the program-level PO/PSO attainment report of a curriculum
+type ProgramAttainment struct {
+	CurriculumID   int                        `json:"curriculum_id"`
+	AcademicYear   string                     `json:"academic_year"`
+	DirectWeight   float64                    `json:"direct_weight"`
+	IndirectWeight float64                    `json:"indirect_weight"`
+	POs            []ProgramOutcomeAttainment `json:"pos"`
+	PSOs           []ProgramOutcomeAttainment `json:"psos"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// RecycleBinEntry is an item soft-deleted from a curriculum together with the child rows its delete cascaded to.
+// CurriculumID is nil for syllabus items, which belong to a course rather than a curriculum
+type RecycleBinEntry struct {
+	ID           int            `json:"id"`
+	CurriculumID *int           `json:"curriculum_id"`
+	CourseID     *int           `json:"course_id"`
+	ItemType     string         `json:"item_type"`
+	ItemID       int            `json:"item_id"`
+	Label        string         `json:"label"`
+	DeletedBy    string         `json:"deleted_by"`
+	DeletedAt    string         `json:"deleted_at"`
+	PurgeAfter   string         `json:"purge_after"`
+	RowCounts    map[string]int `json:"row_counts"`
+}
+
+// RecycleBinResponse lists the restorable items of a curriculum
+type RecycleBinResponse struct {
+	CurriculumID  int               `json:"curriculum_id"`
+	RetentionDays int               `json:"retention_days"`
+	Items         []RecycleBinEntry `json:"items"`
+}
@@ -25,16 +25,17 @@ type RegulationSection struct {
 
 // RegulationClause represents a single clause within a section
 type RegulationClause struct {
-	ID           int       `json:"id"`
-	RegulationID int       `json:"regulation_id"`
-	SectionID    int       `json:"section_id"`
-	SectionNo    int       `json:"section_no"`
-	ClauseNo     string    `json:"clause_no"`
-	Title        string    `json:"title"`
-	Content      string    `json:"content"`
-	DisplayOrder int       `json:"display_order"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             int       `json:"id"`
+	RegulationID   int       `json:"regulation_id"`
+	SectionID      int       `json:"section_id"`
+	SectionNo      int       `json:"section_no"`
+	ParentClauseID *int      `json:"parent_clause_id"` // set for a sub-clause, numbered under its parent (4.2.1 under 4.2)
+	ClauseNo       string    `json:"clause_no"`
+	Title          string    `json:"title"`
+	Content        string    `json:"content"`
+	DisplayOrder   int       `json:"display_order"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // RegulationClauseHistory tracks changes to regulation clauses
@@ -60,6 +61,24 @@ type RegulationSectionWithClauses struct {
 	Clauses []RegulationClause `json:"clauses"`
 }
 
+// RegulationOrder is the complete order of a regulation's sections and clauses, as sent to
+// the reorder API; every section and clause must appear exactly once
+type RegulationOrder struct {
+	Sections []RegulationSectionOrder `json:"sections"`
+}
+
+// RegulationSectionOrder places a section and lists its top-level clauses in order
+type RegulationSectionOrder struct {
+	ID      int                     `json:"id"`
+	Clauses []RegulationClauseOrder `json:"clauses"`
+}
+
+// RegulationClauseOrder places a clause and lists its sub-clauses in order
+type RegulationClauseOrder struct {
+	ID       int                     `json:"id"`
+	Children []RegulationClauseOrder `json:"children"`
+}
+
 // Legacy Regulation model for curriculum compatibility
 type LegacyRegulation struct {
 	ID                 int       `json:"id"`
@@ -0,0 +1,51 @@
+package models
+
+// RegulationDiff lists what differs between two regulations. Sections are matched by section
+// number; clauses by their text first and by section and clause number after that
+type RegulationDiff struct {
+	From     Regulation              `json:"from"`
+	To       Regulation              `json:"to"`
+	Sections []RegulationSectionDiff `json:"sections"`
+	Clauses  []RegulationClauseDiff  `json:"clauses"`
+	Summary  RegulationDiffSummary   `json:"summary"`
+}
+
+// RegulationSectionDiff is a section that was added, removed or renamed
+type RegulationSectionDiff struct {
+	SectionNo int    `json:"section_no"`
+	Change    string `json:"change"` // added, removed, renamed
+	OldTitle  string `json:"old_title,omitempty"`
+	NewTitle  string `json:"new_title,omitempty"`
+}
+
+// RegulationClauseDiff is a clause that was added, removed, modified or moved. A clause that was
+// renumbered or moved to another section has its old numbers in OldSectionNo and OldClauseNo.
+// TitleDiff and ContentDiff are word-level diffs from the old text to the new one
+type RegulationClauseDiff struct {
+	SectionNo    int           `json:"section_no"`
+	ClauseNo     string        `json:"clause_no"`
+	Change       string        `json:"change"` // added, removed, modified, moved
+	OldSectionNo int           `json:"old_section_no,omitempty"`
+	OldClauseNo  string        `json:"old_clause_no,omitempty"`
+	OldTitle     string        `json:"old_title"`
+	NewTitle     string        `json:"new_title"`
+	OldContent   string        `json:"old_content"`
+	NewContent   string        `json:"new_content"`
+	TitleDiff    []DiffSegment `json:"title_diff,omitempty"`
+	ContentDiff  []DiffSegment `json:"content_diff,omitempty"`
+}
+
+// DiffSegment is a run of text that is kept, inserted or deleted
+type DiffSegment struct {
+	Op   string `json:"op"` // equal, insert, delete
+	Text string `json:"text"`
+}
+
+// RegulationDiffSummary counts the clauses by change
+type RegulationDiffSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Modified  int `json:"modified"`
+	Moved     int `json:"moved"`
+	Unchanged int `json:"unchanged"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// RegulationExportFormat is the format_version written into new regulation exports
+const RegulationExportFormat = 1
+
+// RegulationExport is a regulation document as it moves between environments. It carries no
+// database IDs, so it can be imported anywhere; sections and clauses keep their numbers and order
+type RegulationExport struct {
+	FormatVersion int                       `json:"format_version"`
+	Code          string                    `json:"code"`
+	Name          string                    `json:"name"`
+	Status        string                    `json:"status"`
+	Sections      []RegulationExportSection `json:"sections"`
+}
+
+// RegulationExportSection is a section of an exported regulation with its clauses
+type RegulationExportSection struct {
+	SectionNo    int                      `json:"section_no"`
+	Title        string                   `json:"title"`
+	DisplayOrder int                      `json:"display_order"`
+	Clauses      []RegulationExportClause `json:"clauses"`
+}
+
+// RegulationExportClause is a clause of an exported regulation. A sub-clause names its parent by
+// clause number within the section; History is only filled when asked for
+type RegulationExportClause struct {
+	ClauseNo       string                    `json:"clause_no"`
+	ParentClauseNo string                    `json:"parent_clause_no,omitempty"`
+	Title          string                    `json:"title"`
+	Content        string                    `json:"content"`
+	DisplayOrder   int                       `json:"display_order"`
+	History        []RegulationExportHistory `json:"history,omitempty"`
+}
+
+// RegulationExportHistory is one recorded edit of an exported clause
+type RegulationExportHistory struct {
+	OldContent   string    `json:"old_content"`
+	NewContent   string    `json:"new_content"`
+	ChangedBy    string    `json:"changed_by"`
+	ChangedAt    time.Time `json:"changed_at"`
+	ChangeReason string    `json:"change_reason"`
+}
+
+// RegulationMarkdownImport is the body of a Markdown import
+type RegulationMarkdownImport struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Markdown string `json:"markdown"`
+}
+
+// RegulationImportResult is what an import created, or for a preview what it would create;
+// RegulationID is 0 for a preview
+type RegulationImportResult struct {
+	RegulationID int              `json:"regulation_id,omitempty"`
+	Regulation   RegulationExport `json:"regulation"`
+	SectionCount int              `json:"section_count"`
+	ClauseCount  int              `json:"clause_count"`
+	Warnings     []string         `json:"warnings"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Survey is an indirect assessment questionnaire (course exit, graduate exit, alumni or employer)
+// CourseID is set only for course exit surveys
+type Survey struct {
+	ID           int              `json:"id"`
+	CurriculumID int              `json:"curriculum_id"`
+	CourseID     *int             `json:"course_id,omitempty"`
+	Title        string           `json:"title"`
+	SurveyType   string           `json:"survey_type"` // COURSE_EXIT, GRADUATE_EXIT, ALUMNI, EMPLOYER
+	AcademicYear string           `json:"academic_year"`
+	IsOpen       bool             `json:"is_open"`
+	Questions    []SurveyQuestion `json:"questions"`
+	Recipients   int              `json:"recipients"`
+	Responses    int              `json:"responses"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+// SurveyQuestion is a Likert question mapped to one PO or PSO
+type SurveyQuestion struct {
+	ID           int    `json:"id"`
+	QuestionText string `json:"question_text"`
+	OutcomeType  string `json:"outcome_type"`  // PO or PSO
+	OutcomeIndex int    `json:"outcome_index"` // 1-based PO/PSO number
+	Position     int    `json:"position"`
+}
+
+// SurveyIssueRequest selects who receives a survey. Course exit surveys default to the students
+// studying the course; otherwise a batch or an explicit list of students is required
+type SurveyIssueRequest struct {
+	Section    string `json:"section,omitempty"`
+	Batch      string `json:"batch,omitempty"`
+	StudentIDs []int  `json:"student_ids,omitempty"`
+}
+
+// SurveyAnswer is a Likert rating (1-5) for one question
+type SurveyAnswer struct {
+	QuestionID int `json:"question_id"`
+	Rating     int `json:"rating"`
+}
+
+// SurveyResponseRequest is a completed questionnaire. Student surveys require StudentID;
+// alumni and employer surveys identify the respondent by name and email, one response per email
+type SurveyResponseRequest struct {
+	StudentID       *int           `json:"student_id,omitempty"`
+	RespondentName  string         `json:"respondent_name,omitempty"`
+	RespondentEmail string         `json:"respondent_email,omitempty"`
+	Answers         []SurveyAnswer `json:"answers"`
+}
+
+// SurveyQuestionResult summarises the ratings received by a question
+type SurveyQuestionResult struct {
+	QuestionID    int     `json:"question_id"`
+	QuestionText  string  `json:"question_text"`
+	OutcomeType   string  `json:"outcome_type"`
+	OutcomeIndex  int     `json:"outcome_index"`
+	Responses     int     `json:"responses"`
+	AverageRating float64 `json:"average_rating"`
+}
+
+// SurveyResults is the per-question and per-outcome summary of a survey
+type SurveyResults struct {
+	SurveyID  int                       `json:"survey_id"`
+	Responses int                       `json:"responses"`
+	Questions []SurveyQuestionResult    `json:"questions"`
+	Outcomes  []IndirectAttainmentValue `json:"outcomes"`
+}
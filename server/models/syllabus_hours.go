@@ -0,0 +1,22 @@
+package models
+
+// SyllabusHourCheck compares the hours entered in a syllabus component against the course's L-T-P totals
+// Status is OK, MISMATCH or MISSING (course declares hours but the syllabus has none)
+type SyllabusHourCheck struct {
+	Component string `json:"component"`
+	Source    string `json:"source"`
+	Expected  int    `json:"expected"`
+	Actual    int    `json:"actual"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// SyllabusHourReport is the hour reconciliation result of a course syllabus
+type SyllabusHourReport struct {
+	CourseID   int                 `json:"course_id"`
+	CourseCode string              `json:"course_code"`
+	CourseName string              `json:"course_name"`
+	Valid      bool                `json:"valid"`
+	Checks     []SyllabusHourCheck `json:"checks"`
+	Warnings   []string            `json:"warnings"`
+}
@@ -36,4 +36,6 @@ type CourseSyllabusResponse struct {
 	Models             []SyllabusModel `json:"models"`
 	Experiments        []Experiment    `json:"experiments,omitempty"`
 	CurriculumTemplate string          `json:"curriculum_template,omitempty"`
+	Warnings           []string        `json:"warnings,omitempty"`
+	PinnedVersion      int             `json:"pinned_version,omitempty"` // set when served from a curriculum's pinned course version
 }
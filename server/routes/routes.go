@@ -26,10 +26,15 @@ func SetupRoutes() *mux.Router {
 	router.HandleFunc("/api/curriculum/create", curriculum.CreateRegulation).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/curriculum/delete", curriculum.DeleteRegulation).Methods("DELETE", "OPTIONS")
 	router.HandleFunc("/api/curriculum/{id}", curriculum.UpdateCurriculum).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/publish-status", curriculum.UpdateCurriculumPublishStatus).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/department", curriculum.UpdateCurriculumDepartment).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/regulation", curriculum.UpdateCurriculumRegulation).Methods("PUT", "OPTIONS")
 
 	// NEW Regulation Management routes (isolated from curriculum)
 	router.HandleFunc("/api/regulations", curriculum.GetRegulationsNew).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/regulations", curriculum.CreateRegulationNew).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/regulations/import", curriculum.ImportRegulation).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/regulations/import/markdown", curriculum.ImportRegulationMarkdown).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/regulations/{id}", curriculum.GetRegulationByID).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/regulations/{id}", curriculum.UpdateRegulationNew).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/api/regulations/{id}", curriculum.DeleteRegulationNew).Methods("DELETE", "OPTIONS")
@@ -42,6 +47,11 @@ func SetupRoutes() *mux.Router {
 
 	// Regulation Editor routes (structured editing)
 	router.HandleFunc("/api/regulations/{id}/structure", curriculum.GetRegulationStructure).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/regulations/{id}/export", curriculum.ExportRegulation).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/regulations/{id}/clone", curriculum.CloneRegulation).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/regulations/{id}/compare", curriculum.CompareRegulations).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/regulations/{id}/order", curriculum.ReorderRegulation).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/regulations/{id}/renumber", curriculum.RenumberRegulation).Methods("POST", "OPTIONS")
 
 	// Section management
 	router.HandleFunc("/api/regulations/{id}/sections", curriculum.CreateSection).Methods("POST", "OPTIONS")
@@ -69,6 +79,35 @@ func SetupRoutes() *mux.Router {
 	router.HandleFunc("/api/course/{id}", curriculum.UpdateCourse).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/api/curriculum-course/{id}", curriculum.UpdateCurriculumCourse).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/api/curriculum/{id}/semester/{semId}/course/{courseId}", curriculum.RemoveCourseFromSemester).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/course/{courseId}/fork", curriculum.ForkCurriculumCourse).Methods("POST", "OPTIONS")
+
+	// Course catalogue routes
+	router.HandleFunc("/api/catalogue/courses", curriculum.GetCatalogueCourses).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/catalogue/courses", curriculum.CreateCatalogueCourse).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/catalogue/courses/{id}", curriculum.GetCatalogueCourse).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/catalogue/courses/{id}", curriculum.UpdateCourse).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/catalogue/courses/{id}", curriculum.DeleteCatalogueCourse).Methods("DELETE", "OPTIONS")
+
+	// Course version routes
+	router.HandleFunc("/api/course/{courseId}/versions", curriculum.GetCourseVersions).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/versions", curriculum.PublishCourseVersion).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/versions/diff", curriculum.GetCourseVersionDiff).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/versions/{version}", curriculum.GetCourseVersion).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/course-versions", curriculum.GetCurriculumCourseVersions).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/course-versions", curriculum.PinCurriculumCourseVersion).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/course-versions/{courseId}/upgrade", curriculum.UpgradeCurriculumCourseVersion).Methods("POST", "OPTIONS")
+
+	// Curriculum snapshot routes
+	router.HandleFunc("/api/curriculum/{id}/snapshots", curriculum.GetCurriculumSnapshots).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/snapshots", curriculum.CreateCurriculumSnapshot).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/snapshots/compare", curriculum.CompareCurriculumSnapshots).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/snapshots/{snapshotId}", curriculum.GetCurriculumSnapshot).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/snapshots/{snapshotId}/restore", curriculum.RestoreCurriculumSnapshot).Methods("POST", "OPTIONS")
+
+	// Recycle bin routes
+	router.HandleFunc("/api/curriculum/{id}/recycle-bin", curriculum.GetRecycleBin).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/recycle-bin/{entryId}/restore", curriculum.RestoreRecycleBinEntry).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/recycle-bin/{entryId}", curriculum.PurgeRecycleBinEntry).Methods("DELETE", "OPTIONS")
 
 	// Honour Card routes
 	router.HandleFunc("/api/curriculum/{id}/honour-cards", curriculum.GetHonourCards).Methods("GET", "OPTIONS")
@@ -84,6 +123,10 @@ func SetupRoutes() *mux.Router {
 	router.HandleFunc("/api/course/{courseId}/syllabus", curriculum.GetCourseSyllabusNested).Methods("GET", "OPTIONS")
 	// Save header-only fields (outcomes, resources, prerequisites)
 	router.HandleFunc("/api/course/{courseId}/syllabus", curriculum.SaveCourseSyllabus).Methods("POST", "OPTIONS")
+	// Check syllabus hours against the course's L-T-P totals
+	router.HandleFunc("/api/course/{courseId}/syllabus/validation", curriculum.GetSyllabusHourValidation).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/syllabus/pdf", curriculum.GenerateCourseSyllabusPDF).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/syllabus/docx", curriculum.GenerateCourseSyllabusDOCX).Methods("GET", "OPTIONS")
 
 	// Relational CRUD
 	router.HandleFunc("/api/course/{courseId}/syllabus/model", curriculum.CreateModel).Methods("POST", "OPTIONS")
@@ -99,6 +142,56 @@ func SetupRoutes() *mux.Router {
 	// CO-PO and CO-PSO Mapping routes
 	router.HandleFunc("/api/course/{courseId}/mapping", curriculum.GetCourseMapping).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/course/{courseId}/mapping", curriculum.SaveCourseMapping).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/mapping/validation", curriculum.GetCourseMappingValidation).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/mapping-issues", curriculum.GetCurriculumMappingIssues).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/articulation-matrix", curriculum.GetArticulationMatrix).Methods("GET", "OPTIONS")
+
+	// Bloom's taxonomy routes
+	router.HandleFunc("/api/bloom/suggest", curriculum.SuggestBloomLevel).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/bloom", curriculum.GetCourseBloomLevels).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/bloom", curriculum.SaveCourseBloomLevels).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/bloom-distribution", curriculum.GetCurriculumBloomDistribution).Methods("GET", "OPTIONS")
+
+	// Prerequisite routes
+	router.HandleFunc("/api/course/{courseId}/prerequisites", curriculum.GetCoursePrerequisites).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/prerequisites", curriculum.SaveCoursePrerequisites).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/prerequisite-graph", curriculum.GetPrerequisiteGraph).Methods("GET", "OPTIONS")
+
+	// CIA Assessment routes
+	router.HandleFunc("/api/course/{courseId}/assessment-components", curriculum.GetAssessmentComponents).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/assessment-components", curriculum.SaveAssessmentComponents).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/marks", curriculum.GetMarksSheet).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/marks", curriculum.SaveMarks).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/marks/submit", curriculum.SubmitMarks).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/cia-totals", curriculum.GetCIATotals).Methods("GET", "OPTIONS")
+
+	// CO Attainment routes
+	router.HandleFunc("/api/assessment-component/{componentId}/questions", curriculum.GetAssessmentQuestions).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/assessment-component/{componentId}/questions", curriculum.SaveAssessmentQuestions).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/question-marks", curriculum.SaveQuestionMarks).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/attainment-targets", curriculum.GetAttainmentTargets).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/attainment-targets", curriculum.SaveAttainmentTargets).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/course/{courseId}/attainment", curriculum.GetCourseAttainment).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/attainment", curriculum.GetCurriculumAttainment).Methods("GET", "OPTIONS")
+
+	// Program Attainment routes
+	router.HandleFunc("/api/curriculum/{id}/attainment-config", curriculum.GetProgramAttainmentConfig).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/attainment-config", curriculum.SaveProgramAttainmentConfig).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/indirect-attainment", curriculum.GetIndirectAttainment).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/indirect-attainment", curriculum.SaveIndirectAttainment).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/program-attainment", curriculum.GetProgramAttainment).Methods("GET", "OPTIONS")
+
+	// Indirect assessment survey routes
+	router.HandleFunc("/api/curriculum/{id}/surveys", curriculum.GetSurveys).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/surveys", curriculum.CreateSurvey).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/indirect-attainment/compute", curriculum.ComputeIndirectAttainment).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/survey/{surveyId}", curriculum.GetSurvey).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/survey/{surveyId}", curriculum.UpdateSurvey).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/survey/{surveyId}", curriculum.DeleteSurvey).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/survey/{surveyId}/close", curriculum.CloseSurvey).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/survey/{surveyId}/issue", curriculum.IssueSurvey).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/survey/{surveyId}/responses", curriculum.SubmitSurveyResponse).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/survey/{surveyId}/results", curriculum.GetSurveyResults).Methods("GET", "OPTIONS")
 
 	// PEO-PO Mapping routes
 	router.HandleFunc("/api/curriculum/{id}/peo-po-mapping", curriculum.GetPEOPOMapping).Methods("GET", "OPTIONS")
@@ -114,8 +207,24 @@ func SetupRoutes() *mux.Router {
 	router.HandleFunc("/api/curriculum/{id}/log", curriculum.CreateCurriculumLog).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/curriculum/{id}/logs", curriculum.GetCurriculumLogs).Methods("GET", "OPTIONS")
 
-	// PDF Generation route
+	// PDF Generation routes
 	router.HandleFunc("/api/curriculum/{id}/pdf", curriculum.GenerateRegulationPDFHTML).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/docx", curriculum.GenerateRegulationDOCX).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/curriculum/{id}/pdf/jobs", curriculum.SubmitRegulationPDFJob).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/pdf/jobs/{jobId}", curriculum.GetPDFJob).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/pdf/jobs/{jobId}/events", curriculum.StreamPDFJob).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/pdf/jobs/{jobId}/download", curriculum.DownloadPDFJob).Methods("GET", "OPTIONS")
+
+	// PDF branding and template registry, per institution (no department_id) or department
+	router.HandleFunc("/api/pdf/branding", curriculum.GetPDFBranding).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/pdf/branding", curriculum.UpdatePDFBranding).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/pdf/branding/logo", curriculum.UploadPDFBrandingLogo).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/pdf/templates", curriculum.ListPDFTemplates).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/pdf/templates", curriculum.CreatePDFTemplate).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/pdf/templates/default", curriculum.GetBuiltInPDFTemplate).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/pdf/templates/{templateId}", curriculum.GetPDFTemplate).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/pdf/templates/{templateId}", curriculum.UpdatePDFTemplate).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/pdf/templates/{templateId}", curriculum.DeletePDFTemplate).Methods("DELETE", "OPTIONS")
 
 	// Course Allocation routes
 	router.HandleFunc("/api/allocations", curriculum.GetCourseAllocations).Methods("GET", "OPTIONS")